@@ -31,10 +31,24 @@ type AgentSpec struct {
 	AgentConfig `json:",inline"`
 }
 
+// AgentType decides how the agent chooses the tools to call
+type AgentType string
+
+const (
+	// AgentTypeZeroShot is a ReAct agent which parses the tool to call from the text output of the llm
+	AgentTypeZeroShot AgentType = "zeroShot"
+	// AgentTypeConversational is a ReAct agent which takes the chat history into account
+	AgentTypeConversational AgentType = "conversational"
+	// AgentTypeFunctionCalling sends the tools as json schemas to the llm and calls the tools the llm returns,
+	// the llm should support OpenAI style function/tool calling
+	AgentTypeFunctionCalling AgentType = "functionCalling"
+)
+
 type AgentConfig struct {
-	// type, can be zeroShot or conversational
-	//+kubebuilder:default="zeroShot"
-	Type string `json:"type,omitempty"`
+	// type, can be zeroShot, conversational or functionCalling
+	//+kubebuilder:validation:Enum=zeroShot;conversational;functionCalling
+	//+kubebuilder:default="conversational"
+	Type AgentType `json:"type,omitempty"`
 	// Prompt used to instruct the LLM of agent
 	Prompt string `json:"prompt,omitempty"`
 	// list of allowed tools for this agent
@@ -66,9 +80,11 @@ type Options struct {
 
 // Tool/Capability that this agent will use
 type Tool struct {
-	// Name of the tool
+	// Name of the tool, should be one of the tools registered in the tool registry,
+	// use the tool `openapi` with the param `apiTool` to call the REST endpoints in an APITool
 	Name string `json:"name,omitempty"`
-	// Map of key/value that will be passed to the tool
+	// Map of key/value that will be passed to the tool,
+	// values are converted to the types declared by the tool, like int or bool
 	Params map[string]string `json:"params,omitempty"`
 }
 
@@ -78,12 +94,17 @@ type AgentStatus struct {
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
+	// Type is the type of agent which is actually used
+	// +optional
+	Type AgentType `json:"type,omitempty"`
+
 	// ConditionedStatus is the current status
 	v1alpha1.ConditionedStatus `json:",inline"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="type",type=string,JSONPath=`.status.type`
 
 // Agent is the Schema for the Agent API
 type Agent struct {
@@ -109,6 +130,15 @@ func init() {
 
 var _ node.Node = (*Agent)(nil)
 
+// GetType returns the agent type, conversational is used if it is not set, which is the agent used before the type
+// takes effect
+func (c AgentConfig) GetType() AgentType {
+	if c.Type == "" {
+		return AgentTypeConversational
+	}
+	return c.Type
+}
+
 func (c *Agent) SetRef() {
 	annotations := node.SetRefAnnotations(c.GetAnnotations(), []node.Ref{node.InputRef.Len(1)}, []node.Ref{node.CommonRef.Len(1)})
 	if c.GetAnnotations() == nil {
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// APIToolSpec defines the desired state of APITool
+type APIToolSpec struct {
+	v1alpha1.CommonSpec `json:",inline"`
+
+	// Schema is the OpenAPI v3 document in json or yaml, which describes the REST endpoints.
+	// Each operation in the document becomes a tool named by its operationId.
+	// +kubebuilder:validation:Required
+	Schema string `json:"schema"`
+	// Server overrides the first server url in the schema
+	// +optional
+	Server string `json:"server,omitempty"`
+	// Operations are the operationIds to be used as tools, all operations are used if it is empty
+	// +optional
+	Operations []string `json:"operations,omitempty"`
+	// AuthSecret is a secret with the field apiKey, which will be sent in the AuthHeader of every request
+	// +optional
+	AuthSecret *v1alpha1.TypedObjectReference `json:"authSecret,omitempty"`
+	// AuthHeader is the header to send the apiKey in,
+	// if it is Authorization, the apiKey will be sent as a bearer token
+	// +kubebuilder:default="Authorization"
+	// +optional
+	AuthHeader string `json:"authHeader,omitempty"`
+	// Timeout of each request, in seconds
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=30
+	// +optional
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// APIToolStatus defines the observed state of APITool
+type APIToolStatus struct {
+	// ObservedGeneration is the last observed generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ConditionedStatus is the current status
+	v1alpha1.ConditionedStatus `json:",inline"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// APITool is the Schema for the APITool API, it wraps REST endpoints as tools of agents
+type APITool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   APIToolSpec   `json:"spec,omitempty"`
+	Status APIToolStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// APIToolList contains a list of APITool
+type APIToolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []APITool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&APITool{}, &APIToolList{})
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	node "github.com/kubeagi/arcadia/api/app-node"
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// SupervisorSpec defines the desired state of Supervisor
+type SupervisorSpec struct {
+	v1alpha1.CommonSpec `json:",inline"`
+
+	SupervisorConfig `json:",inline"`
+}
+
+type SupervisorConfig struct {
+	// Agents are the worker agents the supervisor hands the sub-tasks to
+	// +kubebuilder:validation:MinItems=1
+	Agents []SupervisedAgent `json:"agents"`
+	// Prompt is the instruction of the planner, like the role of the supervisor or how to split the task,
+	// it is added before the default instruction about the agents and the answer format
+	// +optional
+	Prompt string `json:"prompt,omitempty"`
+	// MaxTurns is the max number of sub-tasks handed to the agents, the supervisor answers with the results so far
+	// when it is reached
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20
+	// +kubebuilder:default=5
+	// +optional
+	MaxTurns int `json:"maxTurns,omitempty"`
+	// ShowHandoff shows the sub-tasks handed to the agents as tool actions in the streaming output
+	// +kubebuilder:default=true
+	// +optional
+	ShowHandoff *bool `json:"showHandoff,omitempty"`
+}
+
+// SupervisedAgent is an Agent which works for the supervisor
+type SupervisedAgent struct {
+	// Name of the Agent in the namespace of the supervisor, which is also the name the planner hands sub-tasks to
+	Name string `json:"name"`
+	// Description tells the planner what sub-tasks the agent is good at
+	Description string `json:"description"`
+}
+
+// SupervisorStatus defines the observed state of Supervisor
+type SupervisorStatus struct {
+	// ObservedGeneration is the last observed generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ConditionedStatus is the current status
+	v1alpha1.ConditionedStatus `json:",inline"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="maxTurns",type=integer,JSONPath=`.spec.maxTurns`
+
+// Supervisor is the Schema for the Supervisor API, it plans a task with the llm and hands the sub-tasks to agents
+type Supervisor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SupervisorSpec   `json:"spec,omitempty"`
+	Status SupervisorStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SupervisorList contains a list of Supervisor
+type SupervisorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Supervisor `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Supervisor{}, &SupervisorList{})
+}
+
+var _ node.Node = (*Supervisor)(nil)
+
+// GetMaxTurns returns the max turns, 5 is used if it is not set
+func (c SupervisorConfig) GetMaxTurns() int {
+	if c.MaxTurns <= 0 {
+		return 5
+	}
+	return c.MaxTurns
+}
+
+// GetShowHandoff returns whether to show the hand-offs, they are shown if it is not set
+func (c SupervisorConfig) GetShowHandoff() bool {
+	return c.ShowHandoff == nil || *c.ShowHandoff
+}
+
+func (c *Supervisor) SetRef() {
+	annotations := node.SetRefAnnotations(c.GetAnnotations(), []node.Ref{node.InputRef.Len(1), node.LLMRef.Len(1)}, []node.Ref{node.CommonRef.Len(1)})
+	if c.GetAnnotations() == nil {
+		c.SetAnnotations(annotations)
+	}
+	for k, v := range annotations {
+		c.Annotations[k] = v
+	}
+}
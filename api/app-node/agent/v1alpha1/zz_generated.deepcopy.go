@@ -22,9 +22,111 @@ limitations under the License.
 package v1alpha1
 
 import (
+	basev1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APITool) DeepCopyInto(out *APITool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APITool.
+func (in *APITool) DeepCopy() *APITool {
+	if in == nil {
+		return nil
+	}
+	out := new(APITool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *APITool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIToolList) DeepCopyInto(out *APIToolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]APITool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIToolList.
+func (in *APIToolList) DeepCopy() *APIToolList {
+	if in == nil {
+		return nil
+	}
+	out := new(APIToolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *APIToolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIToolSpec) DeepCopyInto(out *APIToolSpec) {
+	*out = *in
+	out.CommonSpec = in.CommonSpec
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AuthSecret != nil {
+		in, out := &in.AuthSecret, &out.AuthSecret
+		*out = new(basev1alpha1.TypedObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIToolSpec.
+func (in *APIToolSpec) DeepCopy() *APIToolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(APIToolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIToolStatus) DeepCopyInto(out *APIToolStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIToolStatus.
+func (in *APIToolStatus) DeepCopy() *APIToolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(APIToolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Agent) DeepCopyInto(out *Agent) {
 	*out = *in
@@ -156,6 +258,138 @@ func (in *Options) DeepCopy() *Options {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisedAgent) DeepCopyInto(out *SupervisedAgent) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisedAgent.
+func (in *SupervisedAgent) DeepCopy() *SupervisedAgent {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisedAgent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Supervisor) DeepCopyInto(out *Supervisor) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Supervisor.
+func (in *Supervisor) DeepCopy() *Supervisor {
+	if in == nil {
+		return nil
+	}
+	out := new(Supervisor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Supervisor) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisorConfig) DeepCopyInto(out *SupervisorConfig) {
+	*out = *in
+	if in.Agents != nil {
+		in, out := &in.Agents, &out.Agents
+		*out = make([]SupervisedAgent, len(*in))
+		copy(*out, *in)
+	}
+	if in.ShowHandoff != nil {
+		in, out := &in.ShowHandoff, &out.ShowHandoff
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisorConfig.
+func (in *SupervisorConfig) DeepCopy() *SupervisorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisorList) DeepCopyInto(out *SupervisorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Supervisor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisorList.
+func (in *SupervisorList) DeepCopy() *SupervisorList {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SupervisorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisorSpec) DeepCopyInto(out *SupervisorSpec) {
+	*out = *in
+	out.CommonSpec = in.CommonSpec
+	in.SupervisorConfig.DeepCopyInto(&out.SupervisorConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisorSpec.
+func (in *SupervisorSpec) DeepCopy() *SupervisorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SupervisorStatus) DeepCopyInto(out *SupervisorStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SupervisorStatus.
+func (in *SupervisorStatus) DeepCopy() *SupervisorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SupervisorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Tool) DeepCopyInto(out *Tool) {
 	*out = *in
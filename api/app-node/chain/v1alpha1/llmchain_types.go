@@ -30,6 +30,23 @@ type LLMChainSpec struct {
 	v1alpha1.CommonSpec `json:",inline"`
 
 	CommonChainConfig `json:",inline"`
+
+	// OutputSchema makes the llm answer a JSON value which follows the JSON Schema.
+	// The parsed value is returned as the structured answer of the application, and the run fails if the answer
+	// is still not valid after the retries.
+	OutputSchema *OutputSchema `json:"outputSchema,omitempty"`
+}
+
+// OutputSchema is the JSON Schema of the answer
+type OutputSchema struct {
+	// Schema is the JSON Schema in JSON
+	// +kubebuilder:validation:Required
+	Schema string `json:"schema"`
+	// MaxRetries is the max number of times to ask the llm to fix the answer with the validation errors
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=5
+	// +kubebuilder:default=2
+	MaxRetries *int `json:"maxRetries,omitempty"`
 }
 
 type CommonChainConfig struct {
@@ -78,6 +95,17 @@ type Memory struct {
 	// +kubebuilder:validation:Maximum=30
 	// +kubebuilder:default=5
 	ConversionWindowSize *int `json:"conversionWindowSize,omitempty"`
+	// Summary keeps a rolling summary of the earlier conversation made by the llm, and the last ConversionWindowSize rounds as they are.
+	// The summary is saved with the conversation, so only the rounds out of the window are summarized in a turn.
+	// MaxTokenLimit is ignored if Summary is set.
+	Summary *SummaryMemory `json:"summary,omitempty"`
+}
+
+// SummaryMemory is the config of the summary memory
+type SummaryMemory struct {
+	// Prompt is the prompt to update the summary, {{.summary}} is the current summary and {{.new_lines}} is the rounds to add to it.
+	// A default prompt is used if empty.
+	Prompt string `json:"prompt,omitempty"`
 }
 
 // LLMChainStatus defines the observed state of LLMChain
@@ -222,6 +222,11 @@ func (in *LLMChainSpec) DeepCopyInto(out *LLMChainSpec) {
 	*out = *in
 	out.CommonSpec = in.CommonSpec
 	in.CommonChainConfig.DeepCopyInto(&out.CommonChainConfig)
+	if in.OutputSchema != nil {
+		in, out := &in.OutputSchema, &out.OutputSchema
+		*out = new(OutputSchema)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LLMChainSpec.
@@ -258,6 +263,11 @@ func (in *Memory) DeepCopyInto(out *Memory) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.Summary != nil {
+		in, out := &in.Summary, &out.Summary
+		*out = new(SummaryMemory)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Memory.
@@ -270,6 +280,26 @@ func (in *Memory) DeepCopy() *Memory {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputSchema) DeepCopyInto(out *OutputSchema) {
+	*out = *in
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputSchema.
+func (in *OutputSchema) DeepCopy() *OutputSchema {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputSchema)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RetrievalQAChain) DeepCopyInto(out *RetrievalQAChain) {
 	*out = *in
@@ -361,3 +391,18 @@ func (in *RetrievalQAChainStatus) DeepCopy() *RetrievalQAChainStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SummaryMemory) DeepCopyInto(out *SummaryMemory) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SummaryMemory.
+func (in *SummaryMemory) DeepCopy() *SummaryMemory {
+	if in == nil {
+		return nil
+	}
+	out := new(SummaryMemory)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -36,6 +36,9 @@ type DocumentLoaderSpec struct {
 	// BatchSize for text splitter
 	// +kubebuilder:default=10
 	BatchSize int `json:"batchSize,omitempty"`
+	// Splitter is the strategy to split documents into chunks, semantic is not supported as there is no embedder
+	// +kubebuilder:default=recursiveCharacter
+	Splitter v1alpha1.SplitterType `json:"splitter,omitempty"`
 	// FileExtName the type of documents, can be .pdf, .txt, .mp3, etc ...
 	FileExtName string `json:"fileExtName,omitempty"`
 	// LoaderConfig defines the config of loader tools
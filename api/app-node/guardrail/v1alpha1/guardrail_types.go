@@ -0,0 +1,199 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	node "github.com/kubeagi/arcadia/api/app-node"
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// GuardrailSpec defines the desired state of Guardrail
+type GuardrailSpec struct {
+	v1alpha1.CommonSpec `json:",inline"`
+
+	GuardrailConfig `json:",inline"`
+}
+
+// GuardrailStage is what the guardrail checks
+type GuardrailStage string
+
+const (
+	// GuardrailStageInput checks the question before the next nodes
+	GuardrailStageInput GuardrailStage = "input"
+	// GuardrailStageOutput checks the answer of the application, including the streamed answer
+	GuardrailStageOutput GuardrailStage = "output"
+)
+
+// GuardrailAction is what to do when a check fails
+type GuardrailAction string
+
+const (
+	// GuardrailActionBlock replies the blocked reply instead
+	GuardrailActionBlock GuardrailAction = "block"
+	// GuardrailActionMask replaces the sensitive words and PII with stars or their replacements,
+	// the text is blocked if the classifier finds it unsafe since it can't be masked
+	GuardrailActionMask GuardrailAction = "mask"
+	// GuardrailActionFlag only records the failed checks on the message
+	GuardrailActionFlag GuardrailAction = "flag"
+)
+
+// DefaultBlockedReply is the reply of blocked questions and answers if the blocked reply is not set
+const DefaultBlockedReply = "抱歉，这个问题我无法回答。"
+
+type GuardrailConfig struct {
+	// Stages are what the guardrail checks, both input and output if it is empty.
+	// The guardrail node should be put before the prompt node to check the input, and before the chain to check the output.
+	Stages []GuardrailStage `json:"stages,omitempty"`
+	// Action is what to do when a check fails, can be block, mask or flag
+	//+kubebuilder:validation:Enum=block;mask;flag
+	//+kubebuilder:default="block"
+	Action GuardrailAction `json:"action,omitempty"`
+	// BlockedReply is the canned reply of the blocked question or answer, DefaultBlockedReply is used if it is empty
+	BlockedReply string `json:"blockedReply,omitempty"`
+	// SensitiveWords checks if the text contains any of the words, case-insensitive
+	SensitiveWords *SensitiveWords `json:"sensitiveWords,omitempty"`
+	// PII are the detectors of the personally identifiable information
+	PII []PIIDetector `json:"pii,omitempty"`
+	// Classifier asks the llm from the prev node whether the text is safe
+	Classifier *Classifier `json:"classifier,omitempty"`
+}
+
+// SensitiveWords are the words listed here and the words in a ConfigMap or a file of a VersionedDataset
+type SensitiveWords struct {
+	// Words are the sensitive words
+	Words []string `json:"words,omitempty"`
+	// From is a ConfigMap or a VersionedDataset which has the sensitive words, one word a line
+	From *v1alpha1.TypedObjectReference `json:"from,omitempty"`
+	// Key is the data key of the ConfigMap or the file path in the VersionedDataset
+	Key string `json:"key,omitempty"`
+}
+
+// PIIDetector finds the personally identifiable information by a regular expression
+type PIIDetector struct {
+	// Name of the detector, the builtin expression is used for phone, email, idcard and bankcard if Regexp is empty
+	Name string `json:"name"`
+	// Regexp is the regular expression to find the information
+	Regexp string `json:"regexp,omitempty"`
+	// Replacement replaces the information when the action is mask, `***` if it is empty
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// BuiltinPIIRegexps are the expressions used by the PII detectors with these names if their Regexp are empty
+var BuiltinPIIRegexps = map[string]string{
+	"phone":    `(?:\+?86[- ]?)?1[3-9]\d{9}`,
+	"email":    `[\w.+-]+@[\w-]+(?:\.[\w-]+)+`,
+	"idcard":   `[1-9]\d{5}(?:19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]`,
+	"bankcard": `[1-9]\d{15,18}`,
+}
+
+// GetRegexp returns the regular expression of the detector, the builtin one is used if it is empty
+func (d PIIDetector) GetRegexp() string {
+	if d.Regexp != "" {
+		return d.Regexp
+	}
+	return BuiltinPIIRegexps[d.Name]
+}
+
+// Classifier checks the text by the llm
+type Classifier struct {
+	// Prompt asks the llm to answer safe or unsafe, `{{.text}}` will be replaced by the text,
+	// a default prompt is used if it is empty
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// GuardrailStatus defines the observed state of Guardrail
+type GuardrailStatus struct {
+	// ObservedGeneration is the last observed generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ConditionedStatus is the current status
+	v1alpha1.ConditionedStatus `json:",inline"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="action",type=string,JSONPath=`.spec.action`
+
+// Guardrail is the Schema for the Guardrail API, it checks the question and the answer of the application
+type Guardrail struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GuardrailSpec   `json:"spec,omitempty"`
+	Status GuardrailStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GuardrailList contains a list of Guardrail
+type GuardrailList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Guardrail `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Guardrail{}, &GuardrailList{})
+}
+
+var _ node.Node = (*Guardrail)(nil)
+
+// HasStage checks if the guardrail checks the stage
+func (c GuardrailConfig) HasStage(stage GuardrailStage) bool {
+	if len(c.Stages) == 0 {
+		return true
+	}
+	for _, s := range c.Stages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAction returns the action, block is used if it is not set
+func (c GuardrailConfig) GetAction() GuardrailAction {
+	if c.Action == "" {
+		return GuardrailActionBlock
+	}
+	return c.Action
+}
+
+// GetBlockedReply returns the blocked reply, DefaultBlockedReply is used if it is not set
+func (c GuardrailConfig) GetBlockedReply() string {
+	if c.BlockedReply == "" {
+		return DefaultBlockedReply
+	}
+	return c.BlockedReply
+}
+
+func (c *Guardrail) SetRef() {
+	inputs := []node.Ref{node.InputRef.Len(1)}
+	if c.Spec.Classifier != nil {
+		inputs = append(inputs, node.LLMRef.Len(1))
+	}
+	annotations := node.SetRefAnnotations(c.GetAnnotations(), inputs, []node.Ref{{}})
+	if c.GetAnnotations() == nil {
+		c.SetAnnotations(annotations)
+	}
+	for k, v := range annotations {
+		c.Annotations[k] = v
+	}
+}
@@ -47,8 +47,26 @@ type CommonRetrieverConfig struct {
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=50
 	NumDocuments int `json:"numDocuments,omitempty"`
+	// SearchMode is how to search the knowledgebase, only used by the knowledgebase retriever.
+	// hybrid merges the results of vector search and keyword search by reciprocal rank fusion.
+	// keyword and hybrid search are only supported by pgvector.
+	// +kubebuilder:default=vector
+	SearchMode SearchMode `json:"searchMode,omitempty"`
 }
 
+// SearchMode is how to search the knowledgebase
+// +kubebuilder:validation:Enum=vector;keyword;hybrid
+type SearchMode string
+
+const (
+	// SearchModeVector searches by the similarity of embeddings
+	SearchModeVector SearchMode = "vector"
+	// SearchModeKeyword searches by BM25 with the keywords in the question
+	SearchModeKeyword SearchMode = "keyword"
+	// SearchModeHybrid searches by both and merges the results
+	SearchModeHybrid SearchMode = "hybrid"
+)
+
 // KnowledgeBaseRetrieverStatus defines the observed state of KnowledgeBaseRetriever
 type KnowledgeBaseRetrieverStatus struct {
 	// ObservedGeneration is the last observed generation.
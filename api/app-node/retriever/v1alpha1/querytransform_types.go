@@ -0,0 +1,121 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	node "github.com/kubeagi/arcadia/api/app-node"
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// QueryTransformSpec defines the desired state of QueryTransform
+type QueryTransformSpec struct {
+	v1alpha1.CommonSpec `json:",inline"`
+
+	QueryTransformConfig `json:",inline"`
+}
+
+// QueryTransformType decides how the query for retrievers is rewritten
+type QueryTransformType string
+
+const (
+	// QueryTransformTypeCondense rewrites a follow-up question into a standalone question by the chat history
+	QueryTransformTypeCondense QueryTransformType = "condense"
+	// QueryTransformTypeHyDE writes a hypothetical answer of the question, and retrievers search with the answer
+	QueryTransformTypeHyDE QueryTransformType = "hyde"
+	// QueryTransformTypeKeywords extracts the keywords of the question, and retrievers search with the keywords
+	QueryTransformTypeKeywords QueryTransformType = "keywords"
+)
+
+type QueryTransformConfig struct {
+	// Type can be condense, hyde or keywords
+	//+kubebuilder:validation:Enum=condense;hyde;keywords
+	//+kubebuilder:default="condense"
+	Type QueryTransformType `json:"type,omitempty"`
+	// Prompt is the prompt to rewrite the query, `{{.question}}` will be replaced by the query and `{{.history}}`
+	// will be replaced by the chat history, a default prompt of the type is used if it is empty
+	Prompt string `json:"prompt,omitempty"`
+	// ConversionWindowSize is the number of recent rounds of the chat history used by condense, 5 if it is not set
+	//+kubebuilder:validation:Minimum=0
+	ConversionWindowSize *int `json:"conversionWindowSize,omitempty"`
+}
+
+// QueryTransformStatus defines the observed state of QueryTransform
+type QueryTransformStatus struct {
+	// ObservedGeneration is the last observed generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ConditionedStatus is the current status
+	v1alpha1.ConditionedStatus `json:",inline"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="type",type=string,JSONPath=`.spec.type`
+
+// QueryTransform is the Schema for the QueryTransform API, it rewrites the query used by the next retrievers,
+// the question in the final prompt is not changed
+type QueryTransform struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   QueryTransformSpec   `json:"spec,omitempty"`
+	Status QueryTransformStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// QueryTransformList contains a list of QueryTransform
+type QueryTransformList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QueryTransform `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QueryTransform{}, &QueryTransformList{})
+}
+
+var _ node.Node = (*QueryTransform)(nil)
+
+// GetType returns the query transform type, condense is used if it is not set
+func (c QueryTransformConfig) GetType() QueryTransformType {
+	if c.Type == "" {
+		return QueryTransformTypeCondense
+	}
+	return c.Type
+}
+
+// GetConversionWindowSize returns the number of rounds of the chat history used by condense
+func (c QueryTransformConfig) GetConversionWindowSize() int {
+	if c.ConversionWindowSize == nil {
+		return 5
+	}
+	return *c.ConversionWindowSize
+}
+
+func (c *QueryTransform) SetRef() {
+	annotations := node.SetRefAnnotations(c.GetAnnotations(), []node.Ref{node.InputRef.Len(1), node.LLMRef.Len(1)}, []node.Ref{node.RetrieverRef})
+	if c.GetAnnotations() == nil {
+		c.SetAnnotations(annotations)
+	}
+	for k, v := range annotations {
+		c.Annotations[k] = v
+	}
+}
@@ -321,6 +321,118 @@ func (in *MultiQueryRetrieverStatus) DeepCopy() *MultiQueryRetrieverStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryTransform) DeepCopyInto(out *QueryTransform) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryTransform.
+func (in *QueryTransform) DeepCopy() *QueryTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QueryTransform) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryTransformConfig) DeepCopyInto(out *QueryTransformConfig) {
+	*out = *in
+	if in.ConversionWindowSize != nil {
+		in, out := &in.ConversionWindowSize, &out.ConversionWindowSize
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryTransformConfig.
+func (in *QueryTransformConfig) DeepCopy() *QueryTransformConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryTransformConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryTransformList) DeepCopyInto(out *QueryTransformList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QueryTransform, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryTransformList.
+func (in *QueryTransformList) DeepCopy() *QueryTransformList {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryTransformList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QueryTransformList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryTransformSpec) DeepCopyInto(out *QueryTransformSpec) {
+	*out = *in
+	out.CommonSpec = in.CommonSpec
+	in.QueryTransformConfig.DeepCopyInto(&out.QueryTransformConfig)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryTransformSpec.
+func (in *QueryTransformSpec) DeepCopy() *QueryTransformSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryTransformSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QueryTransformStatus) DeepCopyInto(out *QueryTransformStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QueryTransformStatus.
+func (in *QueryTransformStatus) DeepCopy() *QueryTransformStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QueryTransformStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RerankRetriever) DeepCopyInto(out *RerankRetriever) {
 	*out = *in
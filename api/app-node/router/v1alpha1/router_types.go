@@ -0,0 +1,151 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	node "github.com/kubeagi/arcadia/api/app-node"
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// RouterSpec defines the desired state of Router
+type RouterSpec struct {
+	v1alpha1.CommonSpec `json:",inline"`
+
+	RouterConfig `json:",inline"`
+}
+
+// RouterType decides how the router picks the route
+type RouterType string
+
+const (
+	// RouterTypeRule picks the first route whose keywords or regular expressions match the question
+	RouterTypeRule RouterType = "rule"
+	// RouterTypeLLM asks the llm from the prev node to classify the intent of the question into one of the routes
+	RouterTypeLLM RouterType = "llm"
+	// RouterTypeArg picks the first route whose values contain the value of ArgKey in args
+	RouterTypeArg RouterType = "arg"
+)
+
+type RouterConfig struct {
+	// Type can be rule, llm or arg
+	//+kubebuilder:validation:Enum=rule;llm;arg
+	//+kubebuilder:default="rule"
+	Type RouterType `json:"type,omitempty"`
+	// Routes are the routes to pick from, only one route is taken in a run
+	//+kubebuilder:validation:MinItems=1
+	Routes []Route `json:"routes"`
+	// DefaultNextNodeName are the next nodes to take when no route matches,
+	// the run fails if no route matches and it is empty
+	DefaultNextNodeName []string `json:"defaultNextNodeName,omitempty"`
+	// ArgKey is the key of the value in args to compare with the values of routes, only used by the arg router
+	ArgKey string `json:"argKey,omitempty"`
+	// Prompt is the prompt to classify the question by the llm router, `{{.routes}}` and `{{.question}}` will be
+	// replaced by the routes and the question, a default prompt is used if it is empty
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// Route is one of the ways to go on from the router
+type Route struct {
+	// Name of the route, which is also the intent name for the llm router
+	Name string `json:"name"`
+	// Description tells the llm router what kind of questions this route is for
+	Description string `json:"description,omitempty"`
+	// Keywords match the question if it contains any of them, case-insensitive
+	Keywords []string `json:"keywords,omitempty"`
+	// Regexps match the question if any of them matches
+	Regexps []string `json:"regexps,omitempty"`
+	// Values match the value of argKey in args if it equals any of them
+	Values []string `json:"values,omitempty"`
+	// NextNodeName are the names of the next nodes in the application to take when this route is picked
+	//+kubebuilder:validation:MinItems=1
+	NextNodeName []string `json:"nextNodeName"`
+}
+
+// RouterStatus defines the observed state of Router
+type RouterStatus struct {
+	// ObservedGeneration is the last observed generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ConditionedStatus is the current status
+	v1alpha1.ConditionedStatus `json:",inline"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="type",type=string,JSONPath=`.spec.type`
+
+// Router is the Schema for the Router API, it takes some of the next nodes in the application
+type Router struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RouterSpec   `json:"spec,omitempty"`
+	Status RouterStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RouterList contains a list of Router
+type RouterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Router `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Router{}, &RouterList{})
+}
+
+var _ node.Node = (*Router)(nil)
+
+// GetType returns the router type, rule is used if it is not set
+func (c RouterConfig) GetType() RouterType {
+	if c.Type == "" {
+		return RouterTypeRule
+	}
+	return c.Type
+}
+
+// RouteNextNodeNames returns the next nodes of each route, and the default next nodes at last if they are set
+func (c RouterConfig) RouteNextNodeNames() [][]string {
+	res := make([][]string, 0, len(c.Routes)+1)
+	for _, r := range c.Routes {
+		res = append(res, r.NextNodeName)
+	}
+	if len(c.DefaultNextNodeName) > 0 {
+		res = append(res, c.DefaultNextNodeName)
+	}
+	return res
+}
+
+func (c *Router) SetRef() {
+	inputs := []node.Ref{node.InputRef.Len(1)}
+	if c.Spec.GetType() == RouterTypeLLM {
+		inputs = append(inputs, node.LLMRef.Len(1))
+	}
+	// a router has one or more next nodes
+	annotations := node.SetRefAnnotations(c.GetAnnotations(), inputs, []node.Ref{{}})
+	if c.GetAnnotations() == nil {
+		c.SetAnnotations(annotations)
+	}
+	for k, v := range annotations {
+		c.Annotations[k] = v
+	}
+}
@@ -39,3 +39,28 @@ const (
 func ConversationFilePath(appName string, conversationID string, fileName string) string {
 	return fmt.Sprintf("application/%s/conversation/%s/%s", appName, conversationID, fileName)
 }
+
+// DefaultAnswerCacheScoreThreshold and DefaultAnswerCacheTTLSeconds are used if they are not set in AnswerCache
+const (
+	DefaultAnswerCacheScoreThreshold float32 = 0.95
+	DefaultAnswerCacheTTLSeconds     int64   = 86400
+)
+
+func (c AnswerCache) GetScoreThreshold() float32 {
+	if c.ScoreThreshold == nil {
+		return DefaultAnswerCacheScoreThreshold
+	}
+	return *c.ScoreThreshold
+}
+
+func (c AnswerCache) GetTTLSeconds() int64 {
+	if c.TTLSeconds <= 0 {
+		return DefaultAnswerCacheTTLSeconds
+	}
+	return c.TTLSeconds
+}
+
+// AnswerCacheCollectionName is the collection of the cached answers of the application in the vectorstore
+func AnswerCacheCollectionName(appNamespace, appName string) string {
+	return "answercache_" + appNamespace + "_" + appName
+}
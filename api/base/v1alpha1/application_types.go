@@ -54,6 +54,83 @@ type ApplicationSpec struct {
 	// +kubebuilder:validation:Minimum:=1
 	// +kubebuilder:default:=60
 	ChatTimeoutSecond float64 `json:"chatTimeoutSecond,omitempty"`
+	// AnswerCache reuses the answers of earlier questions which are similar enough to the question,
+	// the cache is disabled if it is not set
+	AnswerCache *AnswerCache `json:"answerCache,omitempty"`
+	// InputVariables are the inputs of the application besides the question, which are filled in a form by the user.
+	// They can be used in the prompts by their names, like {{.language}}
+	InputVariables []InputVariable `json:"inputVariables,omitempty"`
+	// RateLimit limits the chat requests and the tokens used by the application, no limit if it is not set.
+	// The limits are checked before the application runs, and the counters are kept in the relational datasource
+	// so they hold across the apiserver replicas.
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+}
+
+// RateLimit is the limits of the application, 0 means no limit.
+// A user is the logged in user, or the client ip for the anonymous users of a public application.
+type RateLimit struct {
+	// RequestsPerMinute is the max number of chat requests of a user in a minute
+	// +kubebuilder:validation:Minimum=0
+	RequestsPerMinute int64 `json:"requestsPerMinute,omitempty"`
+	// ConcurrentStreams is the max number of streaming chats of a user at the same time
+	// +kubebuilder:validation:Minimum=0
+	ConcurrentStreams int64 `json:"concurrentStreams,omitempty"`
+	// DailyTokensPerUser is the max number of llm tokens used by the chats of a user in a day (UTC)
+	// +kubebuilder:validation:Minimum=0
+	DailyTokensPerUser int64 `json:"dailyTokensPerUser,omitempty"`
+	// DailyTokens is the max number of llm tokens used by all the chats of the application in a day (UTC)
+	// +kubebuilder:validation:Minimum=0
+	DailyTokens int64 `json:"dailyTokens,omitempty"`
+}
+
+// InputVariableType is the type of the value of an input variable
+// +kubebuilder:validation:Enum=text;enum;number
+type InputVariableType string
+
+const (
+	InputVariableTypeText   InputVariableType = "text"
+	InputVariableTypeEnum   InputVariableType = "enum"
+	InputVariableTypeNumber InputVariableType = "number"
+)
+
+// InputVariable is an input of the application
+type InputVariable struct {
+	// Name is the name of the variable in the prompts and the chat request,
+	// question, context, date and history are used by the application and can't be the name
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9_]*$`
+	Name string `json:"name"`
+	// DisplayName is the label of the variable in the form
+	DisplayName string `json:"displayName,omitempty"`
+	// Description is the help text of the variable in the form
+	Description string `json:"description,omitempty"`
+	// Type is the type of the value, text by default
+	// +kubebuilder:default=text
+	Type InputVariableType `json:"type,omitempty"`
+	// Required means the value must be given if there is no default value
+	Required bool `json:"required,omitempty"`
+	// Default is the value used if the value is not given
+	Default string `json:"default,omitempty"`
+	// Options are the allowed values of an enum variable
+	Options []string `json:"options,omitempty"`
+	// MaxLength is the max number of characters of a text value, 0 means no limit
+	// +kubebuilder:validation:Minimum=0
+	MaxLength int `json:"maxLength,omitempty"`
+}
+
+// AnswerCache is the semantic answer cache of the application.
+// The questions are embedded by the embedder of the knowledgebase in the application and stored in its vectorstore,
+// and the cached answers are dropped when the files of the knowledgebase change.
+// Only the first question of a conversation without files uses the cache, follow-up questions depend on the history.
+type AnswerCache struct {
+	// ScoreThreshold is the min similarity between the question and a cached question to use the cached answer
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1
+	// +kubebuilder:default=0.95
+	ScoreThreshold *float32 `json:"scoreThreshold,omitempty"`
+	// TTLSeconds is how long a cached answer can be used
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:default:=86400
+	TTLSeconds int64 `json:"ttlSeconds,omitempty"`
 }
 
 // WebConfig is the configuration for web interface
@@ -78,6 +155,10 @@ type ApplicationStatus struct {
 
 	// ConditionedStatus is the current status
 	ConditionedStatus `json:",inline"`
+
+	// AnswerCacheVersion is the files version of the knowledgebase which the cached answers come from
+	// +optional
+	AnswerCacheVersion string `json:"answerCacheVersion,omitempty"`
 }
 
 //+kubebuilder:object:root=true
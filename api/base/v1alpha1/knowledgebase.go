@@ -1,6 +1,10 @@
 package v1alpha1
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
@@ -40,6 +44,9 @@ func (kb *KnowledgeBase) EmbeddingOptions() EmbeddingOptions {
 	if kb.Spec.EmbeddingOptions.BatchSize == 0 {
 		options.BatchSize = DefaultBatchSize
 	}
+	if kb.Spec.EmbeddingOptions.Splitter == "" {
+		options.Splitter = SplitterTypeRecursiveCharacter
+	}
 	return options
 }
 
@@ -47,6 +54,24 @@ func (kb *KnowledgeBase) VectorStoreCollectionName() string {
 	return kb.Namespace + "_" + kb.Name
 }
 
+// FilesVersion changes when a file of the knowledgebase is added, removed or processed again, or the rows of a table
+// are loaded by the watermark. Only the processed files are counted, so it doesn't change with the embedding progress.
+func (kb *KnowledgeBase) FilesVersion() string {
+	h := sha256.New()
+	for _, group := range kb.Status.FileGroupDetail {
+		if group.Source != nil {
+			fmt.Fprintf(h, "%s/%s/%s\n", group.Source.Kind, group.Source.GetNamespace(kb.Namespace), group.Source.Name)
+		}
+		for _, f := range group.FileDetails {
+			if f.Phase != FileProcessPhaseSucceeded {
+				continue
+			}
+			fmt.Fprintf(h, "%s|%s|%s|%s\n", f.Path, f.Version, f.Checksum, f.Watermark)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
 func (kb *KnowledgeBase) InitCondition() Condition {
 	return Condition{
 		Type:               TypeReady,
@@ -109,4 +134,11 @@ func (f *FileGroupDetail) Init(group FileGroup) {
 		f.FileDetails[i].Phase = FileProcessPhasePending
 		f.FileDetails[i].LastUpdateTime = metav1.Now()
 	}
+	for _, t := range group.Tables {
+		f.FileDetails = append(f.FileDetails, FileDetails{
+			Path:           t.SourceName(),
+			Phase:          FileProcessPhasePending,
+			LastUpdateTime: metav1.Now(),
+		})
+	}
 }
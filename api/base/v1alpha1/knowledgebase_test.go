@@ -0,0 +1,45 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilesVersion(t *testing.T) {
+	t.Parallel()
+	kb := &KnowledgeBase{Status: KnowledgeBaseStatus{FileGroupDetail: []FileGroupDetail{{FileDetails: []FileDetails{
+		{Path: "a.pdf", Version: "v1", Checksum: "c1", Phase: FileProcessPhaseSucceeded},
+		{Path: "public.faq", Watermark: "2024-01-01", Phase: FileProcessPhaseSucceeded},
+		{Path: "b.pdf", Version: "v1", Checksum: "c2", Phase: FileProcessPhaseProcessing, ProcessedChunks: 10},
+	}}}}}
+	version := kb.FilesVersion()
+
+	// the embedding progress of a file doesn't change the version
+	kb.Status.FileGroupDetail[0].FileDetails[2].ProcessedChunks = 20
+	assert.Equal(t, version, kb.FilesVersion())
+
+	kb.Status.FileGroupDetail[0].FileDetails[2].Phase = FileProcessPhaseSucceeded
+	processed := kb.FilesVersion()
+	assert.NotEqual(t, version, processed)
+
+	// the rows of the table are loaded by the watermark
+	kb.Status.FileGroupDetail[0].FileDetails[1].Watermark = "2024-01-02"
+	assert.NotEqual(t, processed, kb.FilesVersion())
+}
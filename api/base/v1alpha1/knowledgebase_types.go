@@ -51,8 +51,30 @@ type EmbeddingOptions struct {
 	// BatchSize for text splitter
 	// +kubebuilder:default=10
 	BatchSize int `json:"batchSize,omitempty"`
+	// Splitter is the strategy to split documents into chunks
+	// +kubebuilder:default=recursiveCharacter
+	Splitter SplitterType `json:"splitter,omitempty"`
 }
 
+// SplitterType is the strategy to split documents into chunks
+// +kubebuilder:validation:Enum=recursiveCharacter;token;markdown;page;sentence;semantic
+type SplitterType string
+
+const (
+	// SplitterTypeRecursiveCharacter splits text by paragraphs, lines and words until the chunks are small enough
+	SplitterTypeRecursiveCharacter SplitterType = "recursiveCharacter"
+	// SplitterTypeToken splits text by tokens, the chunk size and overlap are counted in tokens
+	SplitterTypeToken SplitterType = "token"
+	// SplitterTypeMarkdown splits markdown by headers and keeps the header path of each chunk
+	SplitterTypeMarkdown SplitterType = "markdown"
+	// SplitterTypePage keeps each page of a pdf as a chunk, only the pages longer than the chunk size are split
+	SplitterTypePage SplitterType = "page"
+	// SplitterTypeSentence never splits in the middle of a sentence
+	SplitterTypeSentence SplitterType = "sentence"
+	// SplitterTypeSemantic splits sentences into chunks where the meaning changes, it needs an embedder
+	SplitterTypeSemantic SplitterType = "semantic"
+)
+
 type FileGroupDetail struct {
 	// From defines the datasource which provides these files
 	Source *TypedObjectReference `json:"source,omitempty"`
@@ -91,6 +113,15 @@ type FileDetails struct {
 
 	// Version file version
 	Version string `json:"version,omitempty"`
+
+	// Watermark is the largest watermark of the loaded rows, only for the tables of a PostgreSQL datasource
+	Watermark string `json:"watermark,omitempty"`
+
+	// ProcessedChunks is the number of chunks added to the vectorstore so far, updated while the file is processing
+	ProcessedChunks int64 `json:"processedChunks,omitempty"`
+
+	// TotalChunks is the number of chunks of the file, only known after the whole file is loaded
+	TotalChunks int64 `json:"totalChunks,omitempty"`
 }
 
 type FileProcessPhase string
@@ -35,6 +35,11 @@ type LLMSpec struct {
 	// Models provided by this LLM
 	// If not set,we will use default model list based on LLMType
 	Models []string `json:"models,omitempty"`
+
+	// ToolCalling sends the functions of the function calling agents as tools, and reads the tool calls in the
+	// responses, for the OpenAI compatible llms which only support tools, like the ones served by vllm
+	// +optional
+	ToolCalling bool `json:"toolCalling,omitempty"`
 }
 
 // LLMStatus defines the observed state of LLM
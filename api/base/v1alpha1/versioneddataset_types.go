@@ -38,6 +38,59 @@ type FileGroup struct {
 	Paths []string `json:"paths,omitempty"` // nolint
 
 	Files []FileWithVersion `json:"files,omitempty"`
+
+	// Tables defines the tables or queries to load rows from when the source is a PostgreSQL datasource.
+	// Only knowledgebases support tables for now.
+	Tables []TableSource `json:"tables,omitempty"`
+}
+
+// TableSource defines how to load rows from a table or a query and which columns to use.
+// Each row is embedded as a document.
+type TableSource struct {
+	// Name is the name of the table source in the status, it defaults to Table.
+	// It is required when Query is used.
+	Name string `json:"name,omitempty"`
+
+	// Table is the name of the table to load, `schema.table` is supported
+	Table string `json:"table,omitempty"`
+
+	// Query is a SQL query to load rows, it takes precedence over Table
+	Query string `json:"query,omitempty"`
+
+	// ContentColumns are the columns embedded as the content of the document
+	ContentColumns []string `json:"contentColumns,omitempty"`
+
+	// MetadataColumns are the columns stored as the metadata of the document
+	MetadataColumns []string `json:"metadataColumns,omitempty"`
+
+	// QuestionColumn and AnswerColumn define the columns of q/a pairs like a QA csv file,
+	// the question is embedded and the answer is stored in the metadata.
+	// ContentColumns is ignored if they are set.
+	QuestionColumn string `json:"questionColumn,omitempty"`
+	AnswerColumn   string `json:"answerColumn,omitempty"`
+
+	// WatermarkColumn is a column which increases when a row is inserted or updated, like `updated_at` or an id.
+	// If set, only the rows with a larger watermark than the last loaded one are loaded when refreshing.
+	// KeyColumn is required with it, so the documents of the rows loaded again are replaced.
+	WatermarkColumn string `json:"watermarkColumn,omitempty"`
+
+	// KeyColumn is a column which identifies a row, like the primary key. It is stored in the metadata of the
+	// documents, and the documents of the rows loaded again by the watermark are replaced when refreshing.
+	// Only pgvector supports replacing the documents.
+	KeyColumn string `json:"keyColumn,omitempty"`
+}
+
+// SourceName returns the name of the table source in the status
+func (t TableSource) SourceName() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.Table
+}
+
+// IsQA returns whether the rows are q/a pairs
+func (t TableSource) IsQA() bool {
+	return t.QuestionColumn != "" && t.AnswerColumn != ""
 }
 
 // VersionedDatasetSpec defines the desired state of VersionedDataset
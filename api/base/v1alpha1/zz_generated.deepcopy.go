@@ -27,6 +27,26 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnswerCache) DeepCopyInto(out *AnswerCache) {
+	*out = *in
+	if in.ScoreThreshold != nil {
+		in, out := &in.ScoreThreshold, &out.ScoreThreshold
+		*out = new(float32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnswerCache.
+func (in *AnswerCache) DeepCopy() *AnswerCache {
+	if in == nil {
+		return nil
+	}
+	out := new(AnswerCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Application) DeepCopyInto(out *Application) {
 	*out = *in
@@ -98,6 +118,23 @@ func (in *ApplicationSpec) DeepCopyInto(out *ApplicationSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AnswerCache != nil {
+		in, out := &in.AnswerCache, &out.AnswerCache
+		*out = new(AnswerCache)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InputVariables != nil {
+		in, out := &in.InputVariables, &out.InputVariables
+		*out = make([]InputVariable, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimit)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationSpec.
@@ -569,6 +606,13 @@ func (in *FileGroup) DeepCopyInto(out *FileGroup) {
 		*out = make([]FileWithVersion, len(*in))
 		copy(*out, *in)
 	}
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]TableSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileGroup.
@@ -661,6 +705,26 @@ func (in *Image) DeepCopy() *Image {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InputVariable) DeepCopyInto(out *InputVariable) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InputVariable.
+func (in *InputVariable) DeepCopy() *InputVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(InputVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KnowledgeBase) DeepCopyInto(out *KnowledgeBase) {
 	*out = *in
@@ -1213,6 +1277,46 @@ func (in *RDMA) DeepCopy() *RDMA {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimit) DeepCopyInto(out *RateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimit.
+func (in *RateLimit) DeepCopy() *RateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TableSource) DeepCopyInto(out *TableSource) {
+	*out = *in
+	if in.ContentColumns != nil {
+		in, out := &in.ContentColumns, &out.ContentColumns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MetadataColumns != nil {
+		in, out := &in.MetadataColumns, &out.MetadataColumns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TableSource.
+func (in *TableSource) DeepCopy() *TableSource {
+	if in == nil {
+		return nil
+	}
+	out := new(TableSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TypedObjectReference) DeepCopyInto(out *TypedObjectReference) {
 	*out = *in
@@ -56,6 +56,9 @@ type ServerConfig struct {
 
 	// DataProcessURL is the URL of the data process service
 	DataProcessURL string
+
+	// EnableAppCache is true when the initialized applications are cached for chats
+	EnableAppCache bool
 }
 
 func NewServerFlags() ServerConfig {
@@ -71,6 +74,7 @@ func NewServerFlags() ServerConfig {
 	flag.StringVar(&s.ClientID, "client-id", "", "oidc client id(required when enable odic)")
 	flag.StringVar(&s.ClientSecret, "client-secret", "", "oidc client secret(required when enable odic)")
 	flag.StringVar(&s.DataProcessURL, "data-processing-url", "http://127.0.0.1:28888", "url to access data processing server")
+	flag.BoolVar(&s.EnableAppCache, "enable-app-cache", false, "cache the initialized applications for chats, which are invalidated by watching the resources")
 	flag.BoolVar(&s.Debug, "debug", false, "debug model for apiserver")
 
 	klog.InitFlags(nil)
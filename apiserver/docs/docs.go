@@ -815,7 +815,7 @@ const docTemplate = `{
         },
         "/bff/versioneddataset/files/webcrawler": {
             "post": {
-                "description": "Create a web crawler file which contains crawer params",
+                "description": "Create a web crawler file which contains crawer params, and add it to the versioneddataset, whose controller crawls the website.\nThe crawled pages are stored as html files under the directory named after the datasource, with their url and title in the object tags.\nThe result of the crawl is the status of the file in the versioneddataset, and 409 is returned if the website is being crawled.\nCalling it again crawls the website again, and the pages which are not found any more are removed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -860,6 +860,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -922,6 +931,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/chat.ErrorResp"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/chat.ErrorResp"
+                        }
+                    },
+                    "429": {
+                        "description": "a rate limit of the application is reached, retry after retry_after seconds",
+                        "schema": {
+                            "$ref": "#/definitions/chat.ErrorResp"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1210,6 +1231,72 @@ const docTemplate = `{
                 }
             }
         },
+        "/chat/messages/{messageID}/trace": {
+            "post": {
+                "description": "get the execution trace of every app node for one message",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "application"
+                ],
+                "summary": "get one message execution trace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "namespace this request is in",
+                        "name": "namespace",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "messageID",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/chat.MessageReqBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/storage.MessageTrace"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/chat.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/chat.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/chat.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
         "/chat/prompt-starter": {
             "post": {
                 "description": "get app's prompt starters",
@@ -1601,6 +1688,150 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "base.GuardrailEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Action is what is done with the text, block, mask or flag",
+                    "type": "string",
+                    "example": "block"
+                },
+                "node": {
+                    "type": "string",
+                    "example": "guardrail-node"
+                },
+                "reasons": {
+                    "description": "Reasons are the failed checks, like sensitive_word:xx, pii:phone or classifier",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "pii:phone"
+                    ]
+                },
+                "stage": {
+                    "description": "Stage is input or output",
+                    "type": "string",
+                    "example": "input"
+                }
+            }
+        },
+        "base.NodeTrace": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "description": "Children are the traces of the nodes of the application called by this node",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/base.NodeTrace"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/base.TraceEvent"
+                    }
+                },
+                "group": {
+                    "type": "string",
+                    "example": "chain"
+                },
+                "input_keys": {
+                    "description": "InputKeys are the keys in args when the node starts",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "retrievalqachain"
+                },
+                "latency": {
+                    "description": "Latency is the time cost of this node, in ms",
+                    "type": "integer",
+                    "example": 1000
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Chain"
+                },
+                "output_keys": {
+                    "description": "OutputKeys are the keys added or changed by this node",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "outputs": {
+                    "description": "Outputs are the brief of the values added or changed by this node",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "skipped": {
+                    "description": "Skipped is true if the node is not run because it is not on the branches picked by routers",
+                    "type": "boolean"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2024-01-02T10:21:06.389359092+08:00"
+                }
+            }
+        },
+        "base.TraceEvent": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "Human: 旷工最小计算单位为多少天？"
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2024-01-02T10:21:06.389359092+08:00"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/base.TraceEventType"
+                        }
+                    ],
+                    "example": "llm_start"
+                }
+            }
+        },
+        "base.TraceEventType": {
+            "type": "string",
+            "enum": [
+                "llm_start",
+                "llm_end",
+                "llm_error",
+                "chain_end",
+                "retriever_end",
+                "tool_start",
+                "tool_end",
+                "tool_error",
+                "agent_action",
+                "agent_finish"
+            ],
+            "x-enum-varnames": [
+                "TraceEventLLMStart",
+                "TraceEventLLMEnd",
+                "TraceEventLLMError",
+                "TraceEventChainEnd",
+                "TraceEventRetrieverEnd",
+                "TraceEventToolStart",
+                "TraceEventToolEnd",
+                "TraceEventToolError",
+                "TraceEventAgentAction",
+                "TraceEventAgentFinish"
+            ]
+        },
         "chat.APPMetadata": {
             "type": "object",
             "required": [
@@ -1643,6 +1874,11 @@ const docTemplate = `{
                         "song.mp3"
                     ]
                 },
+                "inputs": {
+                    "description": "Inputs are the values of the input variables of the application, by the names of the variables",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
                 "query": {
                     "description": "Query user query string",
                     "type": "string",
@@ -1704,6 +1940,10 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/retriever.Reference"
                     }
+                },
+                "structured": {
+                    "description": "Structured is the answer parsed by the output schema of the application, only set if it has an output schema",
+                    "type": "object"
                 }
             }
         },
@@ -1748,6 +1988,11 @@ const docTemplate = `{
                 "error": {
                     "type": "string",
                     "example": "conversation is not found"
+                },
+                "retry_after": {
+                    "description": "RetryAfter is the seconds to wait before retrying, only set when a rate limit of the application is reached",
+                    "type": "integer",
+                    "example": 30
                 }
             }
         },
@@ -2006,6 +2251,11 @@ const docTemplate = `{
                     "type": "string",
                     "example": "员工考勤管理制度-2023.pdf"
                 },
+                "heading": {
+                    "description": "Heading is the header path of the markdown chunk",
+                    "type": "string",
+                    "example": "员工手册 \u003e 考勤管理"
+                },
                 "page_number": {
                     "description": "page number in the source file",
                     "type": "integer",
@@ -2036,6 +2286,21 @@ const docTemplate = `{
                     "type": "number",
                     "example": 0.34
                 },
+                "search_mode": {
+                    "description": "SearchMode is the search modes which found the chunk, like ` + "`" + `vector,keyword` + "`" + `",
+                    "type": "string",
+                    "example": "vector,keyword"
+                },
+                "sheet": {
+                    "description": "Sheet is the sheet name of the excel row",
+                    "type": "string",
+                    "example": "Sheet1"
+                },
+                "slide_number": {
+                    "description": "SlideNumber is the slide number of the powerpoint slide",
+                    "type": "integer",
+                    "example": 3
+                },
                 "title": {
                     "description": "Title of the webpage",
                     "type": "string",
@@ -2319,6 +2584,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "旷工最小计算单位为0.5天。"
                 },
+                "blocked": {
+                    "description": "Blocked is true if the question or the answer is blocked by a guardrail",
+                    "type": "boolean"
+                },
                 "documents": {
                     "description": "For Action Upload",
                     "type": "array",
@@ -2333,6 +2602,13 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "guardrails": {
+                    "description": "Guardrails are the failed checks of the guardrails, blocked, masked or flagged",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/base.GuardrailEvent"
+                    }
+                },
                 "id": {
                     "type": "string",
                     "example": "4f3546dd-5404-4bf8-a3bc-4fa3f9a7ba24"
@@ -2353,6 +2629,29 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "storage.MessageTrace": {
+            "type": "object",
+            "properties": {
+                "conversation_id": {
+                    "type": "string",
+                    "example": "5a41f3ca-763b-41ec-91c3-4bbbb00736d0"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-12-21T10:21:06.389359092+08:00"
+                },
+                "message_id": {
+                    "type": "string",
+                    "example": "4f3546dd-5404-4bf8-a3bc-4fa3f9a7ba24"
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/base.NodeTrace"
+                    }
+                }
+            }
         }
     },
     "securityDefinitions": {
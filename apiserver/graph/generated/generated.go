@@ -86,6 +86,7 @@ type ComplexityRoot struct {
 		EnableMultiQuery     func(childComplexity int) int
 		EnableRerank         func(childComplexity int) int
 		EnableUploadFile     func(childComplexity int) int
+		InputVariables       func(childComplexity int) int
 		Knowledgebase        func(childComplexity int) int
 		Knowledgebases       func(childComplexity int) int
 		Llm                  func(childComplexity int) int
@@ -97,15 +98,28 @@ type ComplexityRoot struct {
 		Prologue             func(childComplexity int) int
 		RerankModel          func(childComplexity int) int
 		ScoreThreshold       func(childComplexity int) int
+		SearchMode           func(childComplexity int) int
 		ShowNextGuide        func(childComplexity int) int
 		ShowRespInfo         func(childComplexity int) int
 		ShowRetrievalInfo    func(childComplexity int) int
+		Splitter             func(childComplexity int) int
 		SystemPrompt         func(childComplexity int) int
 		Temperature          func(childComplexity int) int
 		Tools                func(childComplexity int) int
 		UserPrompt           func(childComplexity int) int
 	}
 
+	ApplicationInputVariable struct {
+		Default     func(childComplexity int) int
+		Description func(childComplexity int) int
+		DisplayName func(childComplexity int) int
+		MaxLength   func(childComplexity int) int
+		Name        func(childComplexity int) int
+		Options     func(childComplexity int) int
+		Required    func(childComplexity int) int
+		Type        func(childComplexity int) int
+	}
+
 	ApplicationMetadata struct {
 		Annotations        func(childComplexity int) int
 		Category           func(childComplexity int) int
@@ -133,8 +147,9 @@ type ComplexityRoot struct {
 	}
 
 	ApplicationQuery struct {
-		GetApplication          func(childComplexity int, name string, namespace string) int
-		ListApplicationMetadata func(childComplexity int, input ListCommonInput) int
+		GetApplication             func(childComplexity int, name string, namespace string) int
+		GetApplicationMessageTrace func(childComplexity int, input ApplicationMessageTraceInput) int
+		ListApplicationMetadata    func(childComplexity int, input ListCommonInput) int
 	}
 
 	CountDataProcessItem struct {
@@ -393,6 +408,7 @@ type ComplexityRoot struct {
 		EnableUploadFile   func(childComplexity int) int
 		Hot                func(childComplexity int) int
 		Icon               func(childComplexity int) int
+		InputVariables     func(childComplexity int) int
 		IsRecommended      func(childComplexity int) int
 		Name               func(childComplexity int) int
 		NotReadyReasonCode func(childComplexity int) int
@@ -438,6 +454,7 @@ type ComplexityRoot struct {
 		Name              func(childComplexity int) int
 		Namespace         func(childComplexity int) int
 		Reason            func(childComplexity int) int
+		Splitter          func(childComplexity int) int
 		Status            func(childComplexity int) int
 		UpdateTimestamp   func(childComplexity int) int
 		VectorStore       func(childComplexity int) int
@@ -591,6 +608,26 @@ type ComplexityRoot struct {
 		Values   func(childComplexity int) int
 	}
 
+	NodeTrace struct {
+		Error      func(childComplexity int) int
+		Events     func(childComplexity int) int
+		Group      func(childComplexity int) int
+		InputKeys  func(childComplexity int) int
+		Kind       func(childComplexity int) int
+		Latency    func(childComplexity int) int
+		Name       func(childComplexity int) int
+		OutputKeys func(childComplexity int) int
+		Outputs    func(childComplexity int) int
+		Skipped    func(childComplexity int) int
+		StartedAt  func(childComplexity int) int
+	}
+
+	NodeTraceEvent struct {
+		Content func(childComplexity int) int
+		Time    func(childComplexity int) int
+		Type    func(childComplexity int) int
+	}
+
 	Oss struct {
 		Bucket func(childComplexity int) int
 		Object func(childComplexity int) int
@@ -819,8 +856,10 @@ type ComplexityRoot struct {
 		LatestVersion   func(childComplexity int) int
 		Path            func(childComplexity int) int
 		Phase           func(childComplexity int) int
+		ProcessedChunks func(childComplexity int) int
 		Size            func(childComplexity int) int
 		TimeCost        func(childComplexity int) int
+		TotalChunks     func(childComplexity int) int
 		UpdateTimestamp func(childComplexity int) int
 		Version         func(childComplexity int) int
 	}
@@ -846,6 +885,7 @@ type ApplicationMutationResolver interface {
 type ApplicationQueryResolver interface {
 	GetApplication(ctx context.Context, obj *ApplicationQuery, name string, namespace string) (*Application, error)
 	ListApplicationMetadata(ctx context.Context, obj *ApplicationQuery, input ListCommonInput) (*PaginatedResult, error)
+	GetApplicationMessageTrace(ctx context.Context, obj *ApplicationQuery, input ApplicationMessageTraceInput) ([]*NodeTrace, error)
 }
 type DataProcessMutationResolver interface {
 	CreateDataProcessTask(ctx context.Context, obj *DataProcessMutation, input *AddDataProcessInput) (*DataProcessResponse, error)
@@ -1091,6 +1131,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Application.EnableUploadFile(childComplexity), true
 
+	case "Application.inputVariables":
+		if e.complexity.Application.InputVariables == nil {
+			break
+		}
+
+		return e.complexity.Application.InputVariables(childComplexity), true
+
 	case "Application.knowledgebase":
 		if e.complexity.Application.Knowledgebase == nil {
 			break
@@ -1168,6 +1215,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Application.ScoreThreshold(childComplexity), true
 
+	case "Application.searchMode":
+		if e.complexity.Application.SearchMode == nil {
+			break
+		}
+
+		return e.complexity.Application.SearchMode(childComplexity), true
+
 	case "Application.showNextGuide":
 		if e.complexity.Application.ShowNextGuide == nil {
 			break
@@ -1189,6 +1243,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Application.ShowRetrievalInfo(childComplexity), true
 
+	case "Application.splitter":
+		if e.complexity.Application.Splitter == nil {
+			break
+		}
+
+		return e.complexity.Application.Splitter(childComplexity), true
+
 	case "Application.systemPrompt":
 		if e.complexity.Application.SystemPrompt == nil {
 			break
@@ -1217,6 +1278,62 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Application.UserPrompt(childComplexity), true
 
+	case "ApplicationInputVariable.default":
+		if e.complexity.ApplicationInputVariable.Default == nil {
+			break
+		}
+
+		return e.complexity.ApplicationInputVariable.Default(childComplexity), true
+
+	case "ApplicationInputVariable.description":
+		if e.complexity.ApplicationInputVariable.Description == nil {
+			break
+		}
+
+		return e.complexity.ApplicationInputVariable.Description(childComplexity), true
+
+	case "ApplicationInputVariable.displayName":
+		if e.complexity.ApplicationInputVariable.DisplayName == nil {
+			break
+		}
+
+		return e.complexity.ApplicationInputVariable.DisplayName(childComplexity), true
+
+	case "ApplicationInputVariable.maxLength":
+		if e.complexity.ApplicationInputVariable.MaxLength == nil {
+			break
+		}
+
+		return e.complexity.ApplicationInputVariable.MaxLength(childComplexity), true
+
+	case "ApplicationInputVariable.name":
+		if e.complexity.ApplicationInputVariable.Name == nil {
+			break
+		}
+
+		return e.complexity.ApplicationInputVariable.Name(childComplexity), true
+
+	case "ApplicationInputVariable.options":
+		if e.complexity.ApplicationInputVariable.Options == nil {
+			break
+		}
+
+		return e.complexity.ApplicationInputVariable.Options(childComplexity), true
+
+	case "ApplicationInputVariable.required":
+		if e.complexity.ApplicationInputVariable.Required == nil {
+			break
+		}
+
+		return e.complexity.ApplicationInputVariable.Required(childComplexity), true
+
+	case "ApplicationInputVariable.type":
+		if e.complexity.ApplicationInputVariable.Type == nil {
+			break
+		}
+
+		return e.complexity.ApplicationInputVariable.Type(childComplexity), true
+
 	case "ApplicationMetadata.annotations":
 		if e.complexity.ApplicationMetadata.Annotations == nil {
 			break
@@ -1389,6 +1506,18 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.ApplicationQuery.GetApplication(childComplexity, args["name"].(string), args["namespace"].(string)), true
 
+	case "ApplicationQuery.getApplicationMessageTrace":
+		if e.complexity.ApplicationQuery.GetApplicationMessageTrace == nil {
+			break
+		}
+
+		args, err := ec.field_ApplicationQuery_getApplicationMessageTrace_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.ApplicationQuery.GetApplicationMessageTrace(childComplexity, args["input"].(ApplicationMessageTraceInput)), true
+
 	case "ApplicationQuery.listApplicationMetadata":
 		if e.complexity.ApplicationQuery.ListApplicationMetadata == nil {
 			break
@@ -2707,6 +2836,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.GPT.Icon(childComplexity), true
 
+	case "GPT.inputVariables":
+		if e.complexity.GPT.InputVariables == nil {
+			break
+		}
+
+		return e.complexity.GPT.InputVariables(childComplexity), true
+
 	case "GPT.isRecommended":
 		if e.complexity.GPT.IsRecommended == nil {
 			break
@@ -2948,6 +3084,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.KnowledgeBase.Reason(childComplexity), true
 
+	case "KnowledgeBase.splitter":
+		if e.complexity.KnowledgeBase.Splitter == nil {
+			break
+		}
+
+		return e.complexity.KnowledgeBase.Splitter(childComplexity), true
+
 	case "KnowledgeBase.status":
 		if e.complexity.KnowledgeBase.Status == nil {
 			break
@@ -3774,6 +3917,104 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.NodeSelectorRequirement.Values(childComplexity), true
 
+	case "NodeTrace.error":
+		if e.complexity.NodeTrace.Error == nil {
+			break
+		}
+
+		return e.complexity.NodeTrace.Error(childComplexity), true
+
+	case "NodeTrace.events":
+		if e.complexity.NodeTrace.Events == nil {
+			break
+		}
+
+		return e.complexity.NodeTrace.Events(childComplexity), true
+
+	case "NodeTrace.group":
+		if e.complexity.NodeTrace.Group == nil {
+			break
+		}
+
+		return e.complexity.NodeTrace.Group(childComplexity), true
+
+	case "NodeTrace.inputKeys":
+		if e.complexity.NodeTrace.InputKeys == nil {
+			break
+		}
+
+		return e.complexity.NodeTrace.InputKeys(childComplexity), true
+
+	case "NodeTrace.kind":
+		if e.complexity.NodeTrace.Kind == nil {
+			break
+		}
+
+		return e.complexity.NodeTrace.Kind(childComplexity), true
+
+	case "NodeTrace.latency":
+		if e.complexity.NodeTrace.Latency == nil {
+			break
+		}
+
+		return e.complexity.NodeTrace.Latency(childComplexity), true
+
+	case "NodeTrace.name":
+		if e.complexity.NodeTrace.Name == nil {
+			break
+		}
+
+		return e.complexity.NodeTrace.Name(childComplexity), true
+
+	case "NodeTrace.outputKeys":
+		if e.complexity.NodeTrace.OutputKeys == nil {
+			break
+		}
+
+		return e.complexity.NodeTrace.OutputKeys(childComplexity), true
+
+	case "NodeTrace.outputs":
+		if e.complexity.NodeTrace.Outputs == nil {
+			break
+		}
+
+		return e.complexity.NodeTrace.Outputs(childComplexity), true
+
+	case "NodeTrace.skipped":
+		if e.complexity.NodeTrace.Skipped == nil {
+			break
+		}
+
+		return e.complexity.NodeTrace.Skipped(childComplexity), true
+
+	case "NodeTrace.startedAt":
+		if e.complexity.NodeTrace.StartedAt == nil {
+			break
+		}
+
+		return e.complexity.NodeTrace.StartedAt(childComplexity), true
+
+	case "NodeTraceEvent.content":
+		if e.complexity.NodeTraceEvent.Content == nil {
+			break
+		}
+
+		return e.complexity.NodeTraceEvent.Content(childComplexity), true
+
+	case "NodeTraceEvent.time":
+		if e.complexity.NodeTraceEvent.Time == nil {
+			break
+		}
+
+		return e.complexity.NodeTraceEvent.Time(childComplexity), true
+
+	case "NodeTraceEvent.type":
+		if e.complexity.NodeTraceEvent.Type == nil {
+			break
+		}
+
+		return e.complexity.NodeTraceEvent.Type(childComplexity), true
+
 	case "Oss.bucket":
 		if e.complexity.Oss.Bucket == nil {
 			break
@@ -4891,6 +5132,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Filedetail.Phase(childComplexity), true
 
+	case "filedetail.processedChunks":
+		if e.complexity.Filedetail.ProcessedChunks == nil {
+			break
+		}
+
+		return e.complexity.Filedetail.ProcessedChunks(childComplexity), true
+
 	case "filedetail.size":
 		if e.complexity.Filedetail.Size == nil {
 			break
@@ -4905,6 +5153,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Filedetail.TimeCost(childComplexity), true
 
+	case "filedetail.totalChunks":
+		if e.complexity.Filedetail.TotalChunks == nil {
+			break
+		}
+
+		return e.complexity.Filedetail.TotalChunks(childComplexity), true
+
 	case "filedetail.updateTimestamp":
 		if e.complexity.Filedetail.UpdateTimestamp == nil {
 			break
@@ -4965,6 +5220,7 @@ func (e *executableSchema) Exec(ctx context.Context) graphql.ResponseHandler {
 		ec.unmarshalInputAddDataProcessInput,
 		ec.unmarshalInputAllDataProcessListByCountInput,
 		ec.unmarshalInputAllDataProcessListByPageInput,
+		ec.unmarshalInputApplicationMessageTraceInput,
 		ec.unmarshalInputCheckDataProcessTaskNameInput,
 		ec.unmarshalInputCreateApplicationMetadataInput,
 		ec.unmarshalInputCreateDatasetInput,
@@ -5130,6 +5386,10 @@ var sources = []*ast.Source{
 	{Name: "../schema/application.graphqls", Input: `type ApplicationQuery {
     getApplication(name: String!, namespace: String!): Application!
     listApplicationMetadata(input: ListCommonInput!): PaginatedResult!
+    """
+    获取一条对话消息中各个节点的执行记录
+    """
+    getApplicationMessageTrace(input: ApplicationMessageTraceInput!): [NodeTrace!]!
 }
 
 type ApplicationMutation {
@@ -5208,6 +5468,12 @@ type Application {
     """
     numDocuments: Int
 
+    """
+    searchMode 知识库的检索方式
+    规则: enum { vector, keyword, hybrid }，hybrid 为向量检索与关键词检索结果的融合，keyword 和 hybrid 仅支持 pgvector
+    """
+    searchMode: String
+
     """
     docNullReturn 空搜索回复
     """
@@ -5268,6 +5534,54 @@ type Application {
     batchSize 上传文档做批量处理时的批次大小
     """
     batchSize: Int
+    """
+    splitter 上传文档做文档拆分时的策略，recursiveCharacter, token, markdown, page, sentence 之一，默认为 recursiveCharacter
+    """
+    splitter: String
+    """
+    inputVariables 应用的输入变量，对话时在 inputs 中传入，在 Prompt 中通过 {{.name}} 使用
+    """
+    inputVariables: [ApplicationInputVariable!]
+}
+
+"""
+ApplicationInputVariable
+应用的输入变量，前端根据它展示表单
+"""
+type ApplicationInputVariable {
+    """
+    name 变量名，在 Prompt 中通过 {{.name}} 使用
+    """
+    name: String!
+    """
+    displayName 展示名称
+    """
+    displayName: String
+    """
+    description 描述信息
+    """
+    description: String
+    """
+    type 变量类型
+    规则: enum { text, enum, number }
+    """
+    type: String!
+    """
+    required 是否必填，有默认值时可以不填
+    """
+    required: Boolean
+    """
+    default 默认值
+    """
+    default: String
+    """
+    options 可选值，type 为 enum 时有效
+    """
+    options: [String!]
+    """
+    maxLength 文本的最大长度，0 表示不限制
+    """
+    maxLength: Int
 }
 
 """
@@ -5534,6 +5848,12 @@ input UpdateApplicationConfigInput {
     """
     numDocuments: Int
 
+    """
+    searchMode 知识库的检索方式
+    规则: enum { vector, keyword, hybrid }，hybrid 为向量检索与关键词检索结果的融合，keyword 和 hybrid 仅支持 pgvector
+    """
+    searchMode: String
+
     """
     docNullReturn 空搜索回复
     """
@@ -5594,6 +5914,67 @@ input UpdateApplicationConfigInput {
     batchSize 上传文档做批量处理时的批次大小
     """
     batchSize: Int
+    """
+    splitter 上传文档做文档拆分时的策略，recursiveCharacter, token, markdown, page, sentence 之一，默认为 recursiveCharacter
+    """
+    splitter: String
+}
+
+"""
+ApplicationMessageTraceInput
+查询一条对话消息执行记录的参数
+"""
+input ApplicationMessageTraceInput {
+    """应用名称"""
+    name: String!
+    """应用所在的namespace"""
+    namespace: String!
+    """对话ID"""
+    conversationID: String!
+    """消息ID"""
+    messageID: String!
+}
+
+"""
+NodeTrace
+应用中一个节点的一次执行记录
+"""
+type NodeTrace {
+    """节点名称"""
+    name: String!
+    """节点类型所属的组"""
+    group: String
+    """节点类型"""
+    kind: String!
+    """开始执行的时间"""
+    startedAt: Time!
+    """执行耗时,单位ms"""
+    latency: Int!
+    """节点开始执行时,参数中已有的key"""
+    inputKeys: [String!]
+    """节点新增或修改的key"""
+    outputKeys: [String!]
+    """节点新增或修改的值的摘要"""
+    outputs: Map
+    """节点执行过程中发生的事件,如调用模型,调用工具等"""
+    events: [NodeTraceEvent!]
+    """节点执行失败的错误信息"""
+    error: String
+    """节点不在路由选中的分支上,未执行"""
+    skipped: Boolean
+}
+
+"""
+NodeTraceEvent
+节点执行过程中发生的事件
+"""
+type NodeTraceEvent {
+    """事件类型"""
+    type: String!
+    """事件发生的时间"""
+    time: Time!
+    """事件内容"""
+    content: String
 }
 `, BuiltIn: false},
 	{Name: "../schema/dataprocessing.graphqls", Input: `# 数据处理 Mutation
@@ -6670,6 +7051,10 @@ type GPT {
     - ConfigError: 应用配置错误，比如写了多个Output节点，比如节点名称重复等其他错误
     """
     notReadyReasonCode: String
+    """
+    inputVariables 应用的输入变量，对话时在 inputs 中传入
+    """
+    inputVariables: [ApplicationInputVariable!]
 }
 
 """GPTCategory in gpt store"""
@@ -6803,6 +7188,12 @@ type filedetail{
     """最近一次成功的处理耗时"""
     timeCost: Int!
 
+    """已写入向量数据库的分段数，处理过程中更新"""
+    processedChunks: Int
+
+    """文件的分段总数，文件全部加载后才可知"""
+    totalChunks: Int
+
     """
     文件处理的阶段
     规则: enum { Pending , Processing , Succeeded, Failed, Skipped}
@@ -6909,6 +7300,11 @@ type KnowledgeBase {
     batchSize为知识库做批量处理时的批次大小
     """
     batchSize: Int
+    """
+    splitter为知识库做文档拆分时的策略
+    规则: recursiveCharacter, token, markdown, page, sentence, semantic 之一，默认为 recursiveCharacter
+    """
+    splitter: String
     
     """
     知识库整体连接状态
@@ -6976,6 +7372,11 @@ input CreateKnowledgeBaseInput{
     batchSize为知识库做批量处理时的批次大小
     """
     batchSize: Int
+    """
+    splitter为知识库做文档拆分时的策略
+    规则: recursiveCharacter, token, markdown, page, sentence, semantic 之一，默认为 recursiveCharacter
+    """
+    splitter: String
 }
 
 """知识库更新的输入"""
@@ -7010,6 +7411,11 @@ input UpdateKnowledgeBaseInput {
     batchSize为知识库做批量处理时的批次大小
     """
     batchSize: Int
+    """
+    splitter为知识库做文档拆分时的策略
+    规则: recursiveCharacter, token, markdown, page, sentence, semantic 之一，默认为 recursiveCharacter
+    """
+    splitter: String
 }
 
 """知识库分页列表查询的输入"""
@@ -8459,6 +8865,21 @@ func (ec *executionContext) field_ApplicationMutation_updateApplication_args(ctx
 	return args, nil
 }
 
+func (ec *executionContext) field_ApplicationQuery_getApplicationMessageTrace_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 ApplicationMessageTraceInput
+	if tmp, ok := rawArgs["input"]; ok {
+		ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("input"))
+		arg0, err = ec.unmarshalNApplicationMessageTraceInput2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐApplicationMessageTraceInput(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["input"] = arg0
+	return args, nil
+}
+
 func (ec *executionContext) field_ApplicationQuery_getApplication_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
 	var err error
 	args := map[string]interface{}{}
@@ -10190,6 +10611,47 @@ func (ec *executionContext) fieldContext_Application_numDocuments(ctx context.Co
 	return fc, nil
 }
 
+func (ec *executionContext) _Application_searchMode(ctx context.Context, field graphql.CollectedField, obj *Application) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Application_searchMode(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.SearchMode, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Application_searchMode(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Application",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _Application_docNullReturn(ctx context.Context, field graphql.CollectedField, obj *Application) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_Application_docNullReturn(ctx, field)
 	if err != nil {
@@ -10811,8 +11273,8 @@ func (ec *executionContext) fieldContext_Application_batchSize(ctx context.Conte
 	return fc, nil
 }
 
-func (ec *executionContext) _ApplicationMetadata_name(ctx context.Context, field graphql.CollectedField, obj *ApplicationMetadata) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_ApplicationMetadata_name(ctx, field)
+func (ec *executionContext) _Application_splitter(ctx context.Context, field graphql.CollectedField, obj *Application) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Application_splitter(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -10825,95 +11287,7 @@ func (ec *executionContext) _ApplicationMetadata_name(ctx context.Context, field
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Name, nil
-	})
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
-	}
-	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
-		return graphql.Null
-	}
-	res := resTmp.(string)
-	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
-}
-
-func (ec *executionContext) fieldContext_ApplicationMetadata_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
-	fc = &graphql.FieldContext{
-		Object:     "ApplicationMetadata",
-		Field:      field,
-		IsMethod:   false,
-		IsResolver: false,
-		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
-		},
-	}
-	return fc, nil
-}
-
-func (ec *executionContext) _ApplicationMetadata_namespace(ctx context.Context, field graphql.CollectedField, obj *ApplicationMetadata) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_ApplicationMetadata_namespace(ctx, field)
-	if err != nil {
-		return graphql.Null
-	}
-	ctx = graphql.WithFieldContext(ctx, fc)
-	defer func() {
-		if r := recover(); r != nil {
-			ec.Error(ctx, ec.Recover(ctx, r))
-			ret = graphql.Null
-		}
-	}()
-	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
-		ctx = rctx // use context from middleware stack in children
-		return obj.Namespace, nil
-	})
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
-	}
-	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
-		return graphql.Null
-	}
-	res := resTmp.(string)
-	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
-}
-
-func (ec *executionContext) fieldContext_ApplicationMetadata_namespace(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
-	fc = &graphql.FieldContext{
-		Object:     "ApplicationMetadata",
-		Field:      field,
-		IsMethod:   false,
-		IsResolver: false,
-		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
-		},
-	}
-	return fc, nil
-}
-
-func (ec *executionContext) _ApplicationMetadata_id(ctx context.Context, field graphql.CollectedField, obj *ApplicationMetadata) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_ApplicationMetadata_id(ctx, field)
-	if err != nil {
-		return graphql.Null
-	}
-	ctx = graphql.WithFieldContext(ctx, fc)
-	defer func() {
-		if r := recover(); r != nil {
-			ec.Error(ctx, ec.Recover(ctx, r))
-			ret = graphql.Null
-		}
-	}()
-	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
-		ctx = rctx // use context from middleware stack in children
-		return obj.ID, nil
+		return obj.Splitter, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -10927,9 +11301,9 @@ func (ec *executionContext) _ApplicationMetadata_id(ctx context.Context, field g
 	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_ApplicationMetadata_id(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Application_splitter(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "ApplicationMetadata",
+		Object:     "Application",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -10940,8 +11314,8 @@ func (ec *executionContext) fieldContext_ApplicationMetadata_id(ctx context.Cont
 	return fc, nil
 }
 
-func (ec *executionContext) _ApplicationMetadata_labels(ctx context.Context, field graphql.CollectedField, obj *ApplicationMetadata) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_ApplicationMetadata_labels(ctx, field)
+func (ec *executionContext) _Application_inputVariables(ctx context.Context, field graphql.CollectedField, obj *Application) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Application_inputVariables(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -10954,7 +11328,7 @@ func (ec *executionContext) _ApplicationMetadata_labels(ctx context.Context, fie
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Labels, nil
+		return obj.InputVariables, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -10963,26 +11337,44 @@ func (ec *executionContext) _ApplicationMetadata_labels(ctx context.Context, fie
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(map[string]interface{})
+	res := resTmp.([]*ApplicationInputVariable)
 	fc.Result = res
-	return ec.marshalOMap2map(ctx, field.Selections, res)
+	return ec.marshalOApplicationInputVariable2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐApplicationInputVariableᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_ApplicationMetadata_labels(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_Application_inputVariables(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "ApplicationMetadata",
+		Object:     "Application",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Map does not have child fields")
+			switch field.Name {
+			case "name":
+				return ec.fieldContext_ApplicationInputVariable_name(ctx, field)
+			case "displayName":
+				return ec.fieldContext_ApplicationInputVariable_displayName(ctx, field)
+			case "description":
+				return ec.fieldContext_ApplicationInputVariable_description(ctx, field)
+			case "type":
+				return ec.fieldContext_ApplicationInputVariable_type(ctx, field)
+			case "required":
+				return ec.fieldContext_ApplicationInputVariable_required(ctx, field)
+			case "default":
+				return ec.fieldContext_ApplicationInputVariable_default(ctx, field)
+			case "options":
+				return ec.fieldContext_ApplicationInputVariable_options(ctx, field)
+			case "maxLength":
+				return ec.fieldContext_ApplicationInputVariable_maxLength(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type ApplicationInputVariable", field.Name)
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _ApplicationMetadata_annotations(ctx context.Context, field graphql.CollectedField, obj *ApplicationMetadata) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_ApplicationMetadata_annotations(ctx, field)
+func (ec *executionContext) _ApplicationInputVariable_name(ctx context.Context, field graphql.CollectedField, obj *ApplicationInputVariable) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationInputVariable_name(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -10995,35 +11387,539 @@ func (ec *executionContext) _ApplicationMetadata_annotations(ctx context.Context
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Annotations, nil
+		return obj.Name, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(map[string]interface{})
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOMap2map(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_ApplicationMetadata_annotations(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_ApplicationInputVariable_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "ApplicationMetadata",
+		Object:     "ApplicationInputVariable",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Map does not have child fields")
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _ApplicationMetadata_displayName(ctx context.Context, field graphql.CollectedField, obj *ApplicationMetadata) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_ApplicationMetadata_displayName(ctx, field)
+func (ec *executionContext) _ApplicationInputVariable_displayName(ctx context.Context, field graphql.CollectedField, obj *ApplicationInputVariable) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationInputVariable_displayName(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.DisplayName, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ApplicationInputVariable_displayName(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ApplicationInputVariable",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ApplicationInputVariable_description(ctx context.Context, field graphql.CollectedField, obj *ApplicationInputVariable) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationInputVariable_description(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Description, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ApplicationInputVariable_description(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ApplicationInputVariable",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ApplicationInputVariable_type(ctx context.Context, field graphql.CollectedField, obj *ApplicationInputVariable) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationInputVariable_type(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Type, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ApplicationInputVariable_type(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ApplicationInputVariable",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ApplicationInputVariable_required(ctx context.Context, field graphql.CollectedField, obj *ApplicationInputVariable) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationInputVariable_required(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Required, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*bool)
+	fc.Result = res
+	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ApplicationInputVariable_required(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ApplicationInputVariable",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Boolean does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ApplicationInputVariable_default(ctx context.Context, field graphql.CollectedField, obj *ApplicationInputVariable) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationInputVariable_default(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Default, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ApplicationInputVariable_default(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ApplicationInputVariable",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ApplicationInputVariable_options(ctx context.Context, field graphql.CollectedField, obj *ApplicationInputVariable) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationInputVariable_options(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Options, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.([]string)
+	fc.Result = res
+	return ec.marshalOString2ᚕstringᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ApplicationInputVariable_options(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ApplicationInputVariable",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ApplicationInputVariable_maxLength(ctx context.Context, field graphql.CollectedField, obj *ApplicationInputVariable) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationInputVariable_maxLength(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.MaxLength, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*int)
+	fc.Result = res
+	return ec.marshalOInt2ᚖint(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ApplicationInputVariable_maxLength(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ApplicationInputVariable",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ApplicationMetadata_name(ctx context.Context, field graphql.CollectedField, obj *ApplicationMetadata) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationMetadata_name(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Name, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ApplicationMetadata_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ApplicationMetadata",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ApplicationMetadata_namespace(ctx context.Context, field graphql.CollectedField, obj *ApplicationMetadata) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationMetadata_namespace(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Namespace, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ApplicationMetadata_namespace(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ApplicationMetadata",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ApplicationMetadata_id(ctx context.Context, field graphql.CollectedField, obj *ApplicationMetadata) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationMetadata_id(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ID, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ApplicationMetadata_id(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ApplicationMetadata",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ApplicationMetadata_labels(ctx context.Context, field graphql.CollectedField, obj *ApplicationMetadata) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationMetadata_labels(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Labels, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(map[string]interface{})
+	fc.Result = res
+	return ec.marshalOMap2map(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ApplicationMetadata_labels(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ApplicationMetadata",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Map does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ApplicationMetadata_annotations(ctx context.Context, field graphql.CollectedField, obj *ApplicationMetadata) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationMetadata_annotations(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Annotations, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(map[string]interface{})
+	fc.Result = res
+	return ec.marshalOMap2map(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_ApplicationMetadata_annotations(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "ApplicationMetadata",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Map does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _ApplicationMetadata_displayName(ctx context.Context, field graphql.CollectedField, obj *ApplicationMetadata) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationMetadata_displayName(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -11766,6 +12662,8 @@ func (ec *executionContext) fieldContext_ApplicationMutation_updateApplicationCo
 				return ec.fieldContext_Application_scoreThreshold(ctx, field)
 			case "numDocuments":
 				return ec.fieldContext_Application_numDocuments(ctx, field)
+			case "searchMode":
+				return ec.fieldContext_Application_searchMode(ctx, field)
 			case "docNullReturn":
 				return ec.fieldContext_Application_docNullReturn(ctx, field)
 			case "userPrompt":
@@ -11796,6 +12694,10 @@ func (ec *executionContext) fieldContext_ApplicationMutation_updateApplicationCo
 				return ec.fieldContext_Application_chunkOverlap(ctx, field)
 			case "batchSize":
 				return ec.fieldContext_Application_batchSize(ctx, field)
+			case "splitter":
+				return ec.fieldContext_Application_splitter(ctx, field)
+			case "inputVariables":
+				return ec.fieldContext_Application_inputVariables(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Application", field.Name)
 		},
@@ -11877,6 +12779,8 @@ func (ec *executionContext) fieldContext_ApplicationQuery_getApplication(ctx con
 				return ec.fieldContext_Application_scoreThreshold(ctx, field)
 			case "numDocuments":
 				return ec.fieldContext_Application_numDocuments(ctx, field)
+			case "searchMode":
+				return ec.fieldContext_Application_searchMode(ctx, field)
 			case "docNullReturn":
 				return ec.fieldContext_Application_docNullReturn(ctx, field)
 			case "userPrompt":
@@ -11907,6 +12811,10 @@ func (ec *executionContext) fieldContext_ApplicationQuery_getApplication(ctx con
 				return ec.fieldContext_Application_chunkOverlap(ctx, field)
 			case "batchSize":
 				return ec.fieldContext_Application_batchSize(ctx, field)
+			case "splitter":
+				return ec.fieldContext_Application_splitter(ctx, field)
+			case "inputVariables":
+				return ec.fieldContext_Application_inputVariables(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Application", field.Name)
 		},
@@ -11992,8 +12900,8 @@ func (ec *executionContext) fieldContext_ApplicationQuery_listApplicationMetadat
 	return fc, nil
 }
 
-func (ec *executionContext) _CountDataProcessItem_status(ctx context.Context, field graphql.CollectedField, obj *CountDataProcessItem) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_CountDataProcessItem_status(ctx, field)
+func (ec *executionContext) _ApplicationQuery_getApplicationMessageTrace(ctx context.Context, field graphql.CollectedField, obj *ApplicationQuery) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_ApplicationQuery_getApplicationMessageTrace(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -12006,7 +12914,7 @@ func (ec *executionContext) _CountDataProcessItem_status(ctx context.Context, fi
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Status, nil
+		return ec.resolvers.ApplicationQuery().GetApplicationMessageTrace(rctx, obj, fc.Args["input"].(ApplicationMessageTraceInput))
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -12018,26 +12926,61 @@ func (ec *executionContext) _CountDataProcessItem_status(ctx context.Context, fi
 		}
 		return graphql.Null
 	}
-	res := resTmp.(int)
+	res := resTmp.([]*NodeTrace)
 	fc.Result = res
-	return ec.marshalNInt2int(ctx, field.Selections, res)
+	return ec.marshalNNodeTrace2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐNodeTraceᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_CountDataProcessItem_status(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_ApplicationQuery_getApplicationMessageTrace(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "CountDataProcessItem",
+		Object:     "ApplicationQuery",
 		Field:      field,
-		IsMethod:   false,
-		IsResolver: false,
+		IsMethod:   true,
+		IsResolver: true,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Int does not have child fields")
+			switch field.Name {
+			case "name":
+				return ec.fieldContext_NodeTrace_name(ctx, field)
+			case "group":
+				return ec.fieldContext_NodeTrace_group(ctx, field)
+			case "kind":
+				return ec.fieldContext_NodeTrace_kind(ctx, field)
+			case "startedAt":
+				return ec.fieldContext_NodeTrace_startedAt(ctx, field)
+			case "latency":
+				return ec.fieldContext_NodeTrace_latency(ctx, field)
+			case "inputKeys":
+				return ec.fieldContext_NodeTrace_inputKeys(ctx, field)
+			case "outputKeys":
+				return ec.fieldContext_NodeTrace_outputKeys(ctx, field)
+			case "outputs":
+				return ec.fieldContext_NodeTrace_outputs(ctx, field)
+			case "events":
+				return ec.fieldContext_NodeTrace_events(ctx, field)
+			case "error":
+				return ec.fieldContext_NodeTrace_error(ctx, field)
+			case "skipped":
+				return ec.fieldContext_NodeTrace_skipped(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type NodeTrace", field.Name)
 		},
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ec.Recover(ctx, r)
+			ec.Error(ctx, err)
+		}
+	}()
+	ctx = graphql.WithFieldContext(ctx, fc)
+	if fc.Args, err = ec.field_ApplicationQuery_getApplicationMessageTrace_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+		ec.Error(ctx, err)
+		return fc, err
+	}
 	return fc, nil
 }
 
-func (ec *executionContext) _CountDataProcessItem_data(ctx context.Context, field graphql.CollectedField, obj *CountDataProcessItem) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_CountDataProcessItem_data(ctx, field)
+func (ec *executionContext) _CountDataProcessItem_status(ctx context.Context, field graphql.CollectedField, obj *CountDataProcessItem) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_CountDataProcessItem_status(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -12050,7 +12993,7 @@ func (ec *executionContext) _CountDataProcessItem_data(ctx context.Context, fiel
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Data, nil
+		return obj.Status, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -12067,7 +13010,7 @@ func (ec *executionContext) _CountDataProcessItem_data(ctx context.Context, fiel
 	return ec.marshalNInt2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_CountDataProcessItem_data(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_CountDataProcessItem_status(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "CountDataProcessItem",
 		Field:      field,
@@ -12080,8 +13023,8 @@ func (ec *executionContext) fieldContext_CountDataProcessItem_data(ctx context.C
 	return fc, nil
 }
 
-func (ec *executionContext) _CountDataProcessItem_message(ctx context.Context, field graphql.CollectedField, obj *CountDataProcessItem) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_CountDataProcessItem_message(ctx, field)
+func (ec *executionContext) _CountDataProcessItem_data(ctx context.Context, field graphql.CollectedField, obj *CountDataProcessItem) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_CountDataProcessItem_data(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -12094,7 +13037,51 @@ func (ec *executionContext) _CountDataProcessItem_message(ctx context.Context, f
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Message, nil
+		return obj.Data, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_CountDataProcessItem_data(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "CountDataProcessItem",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Int does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _CountDataProcessItem_message(ctx context.Context, field graphql.CollectedField, obj *CountDataProcessItem) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_CountDataProcessItem_message(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Message, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -20336,6 +21323,65 @@ func (ec *executionContext) fieldContext_GPT_notReadyReasonCode(ctx context.Cont
 	return fc, nil
 }
 
+func (ec *executionContext) _GPT_inputVariables(ctx context.Context, field graphql.CollectedField, obj *Gpt) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_GPT_inputVariables(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.InputVariables, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.([]*ApplicationInputVariable)
+	fc.Result = res
+	return ec.marshalOApplicationInputVariable2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐApplicationInputVariableᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_GPT_inputVariables(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "GPT",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "name":
+				return ec.fieldContext_ApplicationInputVariable_name(ctx, field)
+			case "displayName":
+				return ec.fieldContext_ApplicationInputVariable_displayName(ctx, field)
+			case "description":
+				return ec.fieldContext_ApplicationInputVariable_description(ctx, field)
+			case "type":
+				return ec.fieldContext_ApplicationInputVariable_type(ctx, field)
+			case "required":
+				return ec.fieldContext_ApplicationInputVariable_required(ctx, field)
+			case "default":
+				return ec.fieldContext_ApplicationInputVariable_default(ctx, field)
+			case "options":
+				return ec.fieldContext_ApplicationInputVariable_options(ctx, field)
+			case "maxLength":
+				return ec.fieldContext_ApplicationInputVariable_maxLength(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type ApplicationInputVariable", field.Name)
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _GPTCategory_id(ctx context.Context, field graphql.CollectedField, obj *GPTCategory) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_GPTCategory_id(ctx, field)
 	if err != nil {
@@ -20535,6 +21581,8 @@ func (ec *executionContext) fieldContext_GPTQuery_getGPT(ctx context.Context, fi
 				return ec.fieldContext_GPT_enableUploadFile(ctx, field)
 			case "notReadyReasonCode":
 				return ec.fieldContext_GPT_notReadyReasonCode(ctx, field)
+			case "inputVariables":
+				return ec.fieldContext_GPT_inputVariables(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type GPT", field.Name)
 		},
@@ -21543,6 +22591,47 @@ func (ec *executionContext) fieldContext_KnowledgeBase_batchSize(ctx context.Con
 	return fc, nil
 }
 
+func (ec *executionContext) _KnowledgeBase_splitter(ctx context.Context, field graphql.CollectedField, obj *KnowledgeBase) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_KnowledgeBase_splitter(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Splitter, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_KnowledgeBase_splitter(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "KnowledgeBase",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
 func (ec *executionContext) _KnowledgeBase_status(ctx context.Context, field graphql.CollectedField, obj *KnowledgeBase) (ret graphql.Marshaler) {
 	fc, err := ec.fieldContext_KnowledgeBase_status(ctx, field)
 	if err != nil {
@@ -21739,6 +22828,8 @@ func (ec *executionContext) fieldContext_KnowledgeBaseMutation_createKnowledgeBa
 				return ec.fieldContext_KnowledgeBase_chunkOverlap(ctx, field)
 			case "batchSize":
 				return ec.fieldContext_KnowledgeBase_batchSize(ctx, field)
+			case "splitter":
+				return ec.fieldContext_KnowledgeBase_splitter(ctx, field)
 			case "status":
 				return ec.fieldContext_KnowledgeBase_status(ctx, field)
 			case "reason":
@@ -21836,6 +22927,8 @@ func (ec *executionContext) fieldContext_KnowledgeBaseMutation_updateKnowledgeBa
 				return ec.fieldContext_KnowledgeBase_chunkOverlap(ctx, field)
 			case "batchSize":
 				return ec.fieldContext_KnowledgeBase_batchSize(ctx, field)
+			case "splitter":
+				return ec.fieldContext_KnowledgeBase_splitter(ctx, field)
 			case "status":
 				return ec.fieldContext_KnowledgeBase_status(ctx, field)
 			case "reason":
@@ -21985,6 +23078,8 @@ func (ec *executionContext) fieldContext_KnowledgeBaseQuery_getKnowledgeBase(ctx
 				return ec.fieldContext_KnowledgeBase_chunkOverlap(ctx, field)
 			case "batchSize":
 				return ec.fieldContext_KnowledgeBase_batchSize(ctx, field)
+			case "splitter":
+				return ec.fieldContext_KnowledgeBase_splitter(ctx, field)
 			case "status":
 				return ec.fieldContext_KnowledgeBase_status(ctx, field)
 			case "reason":
@@ -25957,8 +27052,691 @@ func (ec *executionContext) fieldContext_Mutation_dataProcess(ctx context.Contex
 	return fc, nil
 }
 
-func (ec *executionContext) _Mutation_Dataset(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Mutation_Dataset(ctx, field)
+func (ec *executionContext) _Mutation_Dataset(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Mutation_Dataset(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().Dataset(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*DatasetMutation)
+	fc.Result = res
+	return ec.marshalODatasetMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐDatasetMutation(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Mutation_Dataset(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Mutation",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "createDataset":
+				return ec.fieldContext_DatasetMutation_createDataset(ctx, field)
+			case "updateDataset":
+				return ec.fieldContext_DatasetMutation_updateDataset(ctx, field)
+			case "deleteDatasets":
+				return ec.fieldContext_DatasetMutation_deleteDatasets(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type DatasetMutation", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Mutation_Datasource(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Mutation_Datasource(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().Datasource(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*DatasourceMutation)
+	fc.Result = res
+	return ec.marshalODatasourceMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐDatasourceMutation(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Mutation_Datasource(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Mutation",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "createDatasource":
+				return ec.fieldContext_DatasourceMutation_createDatasource(ctx, field)
+			case "updateDatasource":
+				return ec.fieldContext_DatasourceMutation_updateDatasource(ctx, field)
+			case "deleteDatasources":
+				return ec.fieldContext_DatasourceMutation_deleteDatasources(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type DatasourceMutation", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Mutation_Embedder(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Mutation_Embedder(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().Embedder(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*EmbedderMutation)
+	fc.Result = res
+	return ec.marshalOEmbedderMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐEmbedderMutation(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Mutation_Embedder(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Mutation",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "createEmbedder":
+				return ec.fieldContext_EmbedderMutation_createEmbedder(ctx, field)
+			case "updateEmbedder":
+				return ec.fieldContext_EmbedderMutation_updateEmbedder(ctx, field)
+			case "deleteEmbedders":
+				return ec.fieldContext_EmbedderMutation_deleteEmbedders(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type EmbedderMutation", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Mutation_KnowledgeBase(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Mutation_KnowledgeBase(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().KnowledgeBase(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*KnowledgeBaseMutation)
+	fc.Result = res
+	return ec.marshalOKnowledgeBaseMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐKnowledgeBaseMutation(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Mutation_KnowledgeBase(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Mutation",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "createKnowledgeBase":
+				return ec.fieldContext_KnowledgeBaseMutation_createKnowledgeBase(ctx, field)
+			case "updateKnowledgeBase":
+				return ec.fieldContext_KnowledgeBaseMutation_updateKnowledgeBase(ctx, field)
+			case "deleteKnowledgeBase":
+				return ec.fieldContext_KnowledgeBaseMutation_deleteKnowledgeBase(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type KnowledgeBaseMutation", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Mutation_Model(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Mutation_Model(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().Model(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*ModelMutation)
+	fc.Result = res
+	return ec.marshalOModelMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐModelMutation(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Mutation_Model(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Mutation",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "createModel":
+				return ec.fieldContext_ModelMutation_createModel(ctx, field)
+			case "updateModel":
+				return ec.fieldContext_ModelMutation_updateModel(ctx, field)
+			case "deleteModels":
+				return ec.fieldContext_ModelMutation_deleteModels(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type ModelMutation", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Mutation_ModelService(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Mutation_ModelService(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().ModelService(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*ModelServiceMutation)
+	fc.Result = res
+	return ec.marshalOModelServiceMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐModelServiceMutation(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Mutation_ModelService(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Mutation",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "createModelService":
+				return ec.fieldContext_ModelServiceMutation_createModelService(ctx, field)
+			case "updateModelService":
+				return ec.fieldContext_ModelServiceMutation_updateModelService(ctx, field)
+			case "deleteModelService":
+				return ec.fieldContext_ModelServiceMutation_deleteModelService(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type ModelServiceMutation", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Mutation_RAG(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Mutation_RAG(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().Rag(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*RAGMutation)
+	fc.Result = res
+	return ec.marshalORAGMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐRAGMutation(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Mutation_RAG(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Mutation",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "createRAG":
+				return ec.fieldContext_RAGMutation_createRAG(ctx, field)
+			case "updateRAG":
+				return ec.fieldContext_RAGMutation_updateRAG(ctx, field)
+			case "deleteRAG":
+				return ec.fieldContext_RAGMutation_deleteRAG(ctx, field)
+			case "duplicateRAG":
+				return ec.fieldContext_RAGMutation_duplicateRAG(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type RAGMutation", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Mutation_VersionedDataset(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Mutation_VersionedDataset(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().VersionedDataset(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*VersionedDatasetMutation)
+	fc.Result = res
+	return ec.marshalOVersionedDatasetMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐVersionedDatasetMutation(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Mutation_VersionedDataset(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Mutation",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "createVersionedDataset":
+				return ec.fieldContext_VersionedDatasetMutation_createVersionedDataset(ctx, field)
+			case "updateVersionedDataset":
+				return ec.fieldContext_VersionedDatasetMutation_updateVersionedDataset(ctx, field)
+			case "deleteVersionedDatasets":
+				return ec.fieldContext_VersionedDatasetMutation_deleteVersionedDatasets(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type VersionedDatasetMutation", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Mutation_Worker(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Mutation_Worker(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().Worker(rctx)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*WorkerMutation)
+	fc.Result = res
+	return ec.marshalOWorkerMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐWorkerMutation(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Mutation_Worker(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Mutation",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "createWorker":
+				return ec.fieldContext_WorkerMutation_createWorker(ctx, field)
+			case "updateWorker":
+				return ec.fieldContext_WorkerMutation_updateWorker(ctx, field)
+			case "deleteWorkers":
+				return ec.fieldContext_WorkerMutation_deleteWorkers(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type WorkerMutation", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Node_name(ctx context.Context, field graphql.CollectedField, obj *Node) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Node_name(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Name, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Node_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Node",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _Node_labels(ctx context.Context, field graphql.CollectedField, obj *Node) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_Node_labels(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Labels, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(map[string]interface{})
+	fc.Result = res
+	return ec.marshalOMap2map(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_Node_labels(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "Node",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type Map does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _NodeQuery_listNodes(ctx context.Context, field graphql.CollectedField, obj *NodeQuery) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeQuery_listNodes(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.NodeQuery().ListNodes(rctx, obj, fc.Args["input"].(*ListNodeInput))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*PaginatedResult)
+	fc.Result = res
+	return ec.marshalNPaginatedResult2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐPaginatedResult(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_NodeQuery_listNodes(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "NodeQuery",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: true,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "hasNextPage":
+				return ec.fieldContext_PaginatedResult_hasNextPage(ctx, field)
+			case "nodes":
+				return ec.fieldContext_PaginatedResult_nodes(ctx, field)
+			case "page":
+				return ec.fieldContext_PaginatedResult_page(ctx, field)
+			case "pageSize":
+				return ec.fieldContext_PaginatedResult_pageSize(ctx, field)
+			case "totalCount":
+				return ec.fieldContext_PaginatedResult_totalCount(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type PaginatedResult", field.Name)
+		},
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ec.Recover(ctx, r)
+			ec.Error(ctx, err)
+		}
+	}()
+	ctx = graphql.WithFieldContext(ctx, fc)
+	if fc.Args, err = ec.field_NodeQuery_listNodes_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+		ec.Error(ctx, err)
+		return fc, err
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _NodeSelectorRequirement_key(ctx context.Context, field graphql.CollectedField, obj *NodeSelectorRequirement) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeSelectorRequirement_key(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Key, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_NodeSelectorRequirement_key(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "NodeSelectorRequirement",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _NodeSelectorRequirement_operator(ctx context.Context, field graphql.CollectedField, obj *NodeSelectorRequirement) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeSelectorRequirement_operator(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Operator, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext_NodeSelectorRequirement_operator(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "NodeSelectorRequirement",
+		Field:      field,
+		IsMethod:   false,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			return nil, errors.New("field of type String does not have child fields")
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) _NodeSelectorRequirement_values(ctx context.Context, field graphql.CollectedField, obj *NodeSelectorRequirement) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeSelectorRequirement_values(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -25971,43 +27749,38 @@ func (ec *executionContext) _Mutation_Dataset(ctx context.Context, field graphql
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().Dataset(rctx)
+		return obj.Values, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*DatasetMutation)
+	res := resTmp.([]string)
 	fc.Result = res
-	return ec.marshalODatasetMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐDatasetMutation(ctx, field.Selections, res)
+	return ec.marshalNString2ᚕstringᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Mutation_Dataset(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeSelectorRequirement_values(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Mutation",
+		Object:     "NodeSelectorRequirement",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "createDataset":
-				return ec.fieldContext_DatasetMutation_createDataset(ctx, field)
-			case "updateDataset":
-				return ec.fieldContext_DatasetMutation_updateDataset(ctx, field)
-			case "deleteDatasets":
-				return ec.fieldContext_DatasetMutation_deleteDatasets(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type DatasetMutation", field.Name)
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _Mutation_Datasource(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Mutation_Datasource(ctx, field)
+func (ec *executionContext) _NodeTrace_name(ctx context.Context, field graphql.CollectedField, obj *NodeTrace) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTrace_name(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26020,43 +27793,38 @@ func (ec *executionContext) _Mutation_Datasource(ctx context.Context, field grap
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().Datasource(rctx)
+		return obj.Name, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*DatasourceMutation)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalODatasourceMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐDatasourceMutation(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Mutation_Datasource(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTrace_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Mutation",
+		Object:     "NodeTrace",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "createDatasource":
-				return ec.fieldContext_DatasourceMutation_createDatasource(ctx, field)
-			case "updateDatasource":
-				return ec.fieldContext_DatasourceMutation_updateDatasource(ctx, field)
-			case "deleteDatasources":
-				return ec.fieldContext_DatasourceMutation_deleteDatasources(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type DatasourceMutation", field.Name)
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _Mutation_Embedder(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Mutation_Embedder(ctx, field)
+func (ec *executionContext) _NodeTrace_group(ctx context.Context, field graphql.CollectedField, obj *NodeTrace) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTrace_group(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26069,7 +27837,7 @@ func (ec *executionContext) _Mutation_Embedder(ctx context.Context, field graphq
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().Embedder(rctx)
+		return obj.Group, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -26078,34 +27846,26 @@ func (ec *executionContext) _Mutation_Embedder(ctx context.Context, field graphq
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*EmbedderMutation)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalOEmbedderMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐEmbedderMutation(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Mutation_Embedder(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTrace_group(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Mutation",
+		Object:     "NodeTrace",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "createEmbedder":
-				return ec.fieldContext_EmbedderMutation_createEmbedder(ctx, field)
-			case "updateEmbedder":
-				return ec.fieldContext_EmbedderMutation_updateEmbedder(ctx, field)
-			case "deleteEmbedders":
-				return ec.fieldContext_EmbedderMutation_deleteEmbedders(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type EmbedderMutation", field.Name)
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _Mutation_KnowledgeBase(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Mutation_KnowledgeBase(ctx, field)
+func (ec *executionContext) _NodeTrace_kind(ctx context.Context, field graphql.CollectedField, obj *NodeTrace) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTrace_kind(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26118,43 +27878,38 @@ func (ec *executionContext) _Mutation_KnowledgeBase(ctx context.Context, field g
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().KnowledgeBase(rctx)
+		return obj.Kind, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*KnowledgeBaseMutation)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOKnowledgeBaseMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐKnowledgeBaseMutation(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Mutation_KnowledgeBase(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTrace_kind(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Mutation",
+		Object:     "NodeTrace",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "createKnowledgeBase":
-				return ec.fieldContext_KnowledgeBaseMutation_createKnowledgeBase(ctx, field)
-			case "updateKnowledgeBase":
-				return ec.fieldContext_KnowledgeBaseMutation_updateKnowledgeBase(ctx, field)
-			case "deleteKnowledgeBase":
-				return ec.fieldContext_KnowledgeBaseMutation_deleteKnowledgeBase(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type KnowledgeBaseMutation", field.Name)
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _Mutation_Model(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Mutation_Model(ctx, field)
+func (ec *executionContext) _NodeTrace_startedAt(ctx context.Context, field graphql.CollectedField, obj *NodeTrace) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTrace_startedAt(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26167,43 +27922,38 @@ func (ec *executionContext) _Mutation_Model(ctx context.Context, field graphql.C
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().Model(rctx)
+		return obj.StartedAt, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*ModelMutation)
+	res := resTmp.(time.Time)
 	fc.Result = res
-	return ec.marshalOModelMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐModelMutation(ctx, field.Selections, res)
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Mutation_Model(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTrace_startedAt(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Mutation",
+		Object:     "NodeTrace",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "createModel":
-				return ec.fieldContext_ModelMutation_createModel(ctx, field)
-			case "updateModel":
-				return ec.fieldContext_ModelMutation_updateModel(ctx, field)
-			case "deleteModels":
-				return ec.fieldContext_ModelMutation_deleteModels(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type ModelMutation", field.Name)
+			return nil, errors.New("field of type Time does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _Mutation_ModelService(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Mutation_ModelService(ctx, field)
+func (ec *executionContext) _NodeTrace_latency(ctx context.Context, field graphql.CollectedField, obj *NodeTrace) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTrace_latency(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26216,43 +27966,38 @@ func (ec *executionContext) _Mutation_ModelService(ctx context.Context, field gr
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().ModelService(rctx)
+		return obj.Latency, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*ModelServiceMutation)
+	res := resTmp.(int)
 	fc.Result = res
-	return ec.marshalOModelServiceMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐModelServiceMutation(ctx, field.Selections, res)
+	return ec.marshalNInt2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Mutation_ModelService(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTrace_latency(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Mutation",
+		Object:     "NodeTrace",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "createModelService":
-				return ec.fieldContext_ModelServiceMutation_createModelService(ctx, field)
-			case "updateModelService":
-				return ec.fieldContext_ModelServiceMutation_updateModelService(ctx, field)
-			case "deleteModelService":
-				return ec.fieldContext_ModelServiceMutation_deleteModelService(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type ModelServiceMutation", field.Name)
+			return nil, errors.New("field of type Int does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _Mutation_RAG(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Mutation_RAG(ctx, field)
+func (ec *executionContext) _NodeTrace_inputKeys(ctx context.Context, field graphql.CollectedField, obj *NodeTrace) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTrace_inputKeys(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26265,7 +28010,7 @@ func (ec *executionContext) _Mutation_RAG(ctx context.Context, field graphql.Col
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().Rag(rctx)
+		return obj.InputKeys, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -26274,36 +28019,26 @@ func (ec *executionContext) _Mutation_RAG(ctx context.Context, field graphql.Col
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*RAGMutation)
+	res := resTmp.([]string)
 	fc.Result = res
-	return ec.marshalORAGMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐRAGMutation(ctx, field.Selections, res)
+	return ec.marshalOString2ᚕstringᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Mutation_RAG(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTrace_inputKeys(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Mutation",
+		Object:     "NodeTrace",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "createRAG":
-				return ec.fieldContext_RAGMutation_createRAG(ctx, field)
-			case "updateRAG":
-				return ec.fieldContext_RAGMutation_updateRAG(ctx, field)
-			case "deleteRAG":
-				return ec.fieldContext_RAGMutation_deleteRAG(ctx, field)
-			case "duplicateRAG":
-				return ec.fieldContext_RAGMutation_duplicateRAG(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type RAGMutation", field.Name)
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _Mutation_VersionedDataset(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Mutation_VersionedDataset(ctx, field)
+func (ec *executionContext) _NodeTrace_outputKeys(ctx context.Context, field graphql.CollectedField, obj *NodeTrace) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTrace_outputKeys(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26316,7 +28051,7 @@ func (ec *executionContext) _Mutation_VersionedDataset(ctx context.Context, fiel
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().VersionedDataset(rctx)
+		return obj.OutputKeys, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -26325,34 +28060,26 @@ func (ec *executionContext) _Mutation_VersionedDataset(ctx context.Context, fiel
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*VersionedDatasetMutation)
+	res := resTmp.([]string)
 	fc.Result = res
-	return ec.marshalOVersionedDatasetMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐVersionedDatasetMutation(ctx, field.Selections, res)
+	return ec.marshalOString2ᚕstringᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Mutation_VersionedDataset(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTrace_outputKeys(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Mutation",
+		Object:     "NodeTrace",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "createVersionedDataset":
-				return ec.fieldContext_VersionedDatasetMutation_createVersionedDataset(ctx, field)
-			case "updateVersionedDataset":
-				return ec.fieldContext_VersionedDatasetMutation_updateVersionedDataset(ctx, field)
-			case "deleteVersionedDatasets":
-				return ec.fieldContext_VersionedDatasetMutation_deleteVersionedDatasets(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type VersionedDatasetMutation", field.Name)
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _Mutation_Worker(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Mutation_Worker(ctx, field)
+func (ec *executionContext) _NodeTrace_outputs(ctx context.Context, field graphql.CollectedField, obj *NodeTrace) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTrace_outputs(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26365,7 +28092,7 @@ func (ec *executionContext) _Mutation_Worker(ctx context.Context, field graphql.
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().Worker(rctx)
+		return obj.Outputs, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -26374,34 +28101,26 @@ func (ec *executionContext) _Mutation_Worker(ctx context.Context, field graphql.
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*WorkerMutation)
+	res := resTmp.(map[string]interface{})
 	fc.Result = res
-	return ec.marshalOWorkerMutation2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐWorkerMutation(ctx, field.Selections, res)
+	return ec.marshalOMap2map(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Mutation_Worker(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTrace_outputs(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Mutation",
+		Object:     "NodeTrace",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "createWorker":
-				return ec.fieldContext_WorkerMutation_createWorker(ctx, field)
-			case "updateWorker":
-				return ec.fieldContext_WorkerMutation_updateWorker(ctx, field)
-			case "deleteWorkers":
-				return ec.fieldContext_WorkerMutation_deleteWorkers(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type WorkerMutation", field.Name)
+			return nil, errors.New("field of type Map does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _Node_name(ctx context.Context, field graphql.CollectedField, obj *Node) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Node_name(ctx, field)
+func (ec *executionContext) _NodeTrace_events(ctx context.Context, field graphql.CollectedField, obj *NodeTrace) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTrace_events(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26414,38 +28133,43 @@ func (ec *executionContext) _Node_name(ctx context.Context, field graphql.Collec
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Name, nil
+		return obj.Events, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.([]*NodeTraceEvent)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalONodeTraceEvent2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐNodeTraceEventᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Node_name(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTrace_events(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Node",
+		Object:     "NodeTrace",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			switch field.Name {
+			case "type":
+				return ec.fieldContext_NodeTraceEvent_type(ctx, field)
+			case "time":
+				return ec.fieldContext_NodeTraceEvent_time(ctx, field)
+			case "content":
+				return ec.fieldContext_NodeTraceEvent_content(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type NodeTraceEvent", field.Name)
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _Node_labels(ctx context.Context, field graphql.CollectedField, obj *Node) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_Node_labels(ctx, field)
+func (ec *executionContext) _NodeTrace_error(ctx context.Context, field graphql.CollectedField, obj *NodeTrace) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTrace_error(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26458,7 +28182,7 @@ func (ec *executionContext) _Node_labels(ctx context.Context, field graphql.Coll
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Labels, nil
+		return obj.Error, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -26467,26 +28191,26 @@ func (ec *executionContext) _Node_labels(ctx context.Context, field graphql.Coll
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(map[string]interface{})
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalOMap2map(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_Node_labels(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTrace_error(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "Node",
+		Object:     "NodeTrace",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Map does not have child fields")
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _NodeQuery_listNodes(ctx context.Context, field graphql.CollectedField, obj *NodeQuery) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_NodeQuery_listNodes(ctx, field)
+func (ec *executionContext) _NodeTrace_skipped(ctx context.Context, field graphql.CollectedField, obj *NodeTrace) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTrace_skipped(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26499,61 +28223,35 @@ func (ec *executionContext) _NodeQuery_listNodes(ctx context.Context, field grap
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.NodeQuery().ListNodes(rctx, obj, fc.Args["input"].(*ListNodeInput))
+		return obj.Skipped, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(*PaginatedResult)
+	res := resTmp.(*bool)
 	fc.Result = res
-	return ec.marshalNPaginatedResult2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐPaginatedResult(ctx, field.Selections, res)
+	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_NodeQuery_listNodes(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTrace_skipped(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "NodeQuery",
+		Object:     "NodeTrace",
 		Field:      field,
-		IsMethod:   true,
-		IsResolver: true,
+		IsMethod:   false,
+		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "hasNextPage":
-				return ec.fieldContext_PaginatedResult_hasNextPage(ctx, field)
-			case "nodes":
-				return ec.fieldContext_PaginatedResult_nodes(ctx, field)
-			case "page":
-				return ec.fieldContext_PaginatedResult_page(ctx, field)
-			case "pageSize":
-				return ec.fieldContext_PaginatedResult_pageSize(ctx, field)
-			case "totalCount":
-				return ec.fieldContext_PaginatedResult_totalCount(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type PaginatedResult", field.Name)
+			return nil, errors.New("field of type Boolean does not have child fields")
 		},
 	}
-	defer func() {
-		if r := recover(); r != nil {
-			err = ec.Recover(ctx, r)
-			ec.Error(ctx, err)
-		}
-	}()
-	ctx = graphql.WithFieldContext(ctx, fc)
-	if fc.Args, err = ec.field_NodeQuery_listNodes_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
-		ec.Error(ctx, err)
-		return fc, err
-	}
 	return fc, nil
 }
 
-func (ec *executionContext) _NodeSelectorRequirement_key(ctx context.Context, field graphql.CollectedField, obj *NodeSelectorRequirement) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_NodeSelectorRequirement_key(ctx, field)
+func (ec *executionContext) _NodeTraceEvent_type(ctx context.Context, field graphql.CollectedField, obj *NodeTraceEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTraceEvent_type(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26566,7 +28264,7 @@ func (ec *executionContext) _NodeSelectorRequirement_key(ctx context.Context, fi
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Key, nil
+		return obj.Type, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -26583,9 +28281,9 @@ func (ec *executionContext) _NodeSelectorRequirement_key(ctx context.Context, fi
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_NodeSelectorRequirement_key(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTraceEvent_type(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "NodeSelectorRequirement",
+		Object:     "NodeTraceEvent",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -26596,8 +28294,8 @@ func (ec *executionContext) fieldContext_NodeSelectorRequirement_key(ctx context
 	return fc, nil
 }
 
-func (ec *executionContext) _NodeSelectorRequirement_operator(ctx context.Context, field graphql.CollectedField, obj *NodeSelectorRequirement) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_NodeSelectorRequirement_operator(ctx, field)
+func (ec *executionContext) _NodeTraceEvent_time(ctx context.Context, field graphql.CollectedField, obj *NodeTraceEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTraceEvent_time(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26610,7 +28308,7 @@ func (ec *executionContext) _NodeSelectorRequirement_operator(ctx context.Contex
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Operator, nil
+		return obj.Time, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -26622,26 +28320,26 @@ func (ec *executionContext) _NodeSelectorRequirement_operator(ctx context.Contex
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(time.Time)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNTime2timeᚐTime(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_NodeSelectorRequirement_operator(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTraceEvent_time(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "NodeSelectorRequirement",
+		Object:     "NodeTraceEvent",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Time does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _NodeSelectorRequirement_values(ctx context.Context, field graphql.CollectedField, obj *NodeSelectorRequirement) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_NodeSelectorRequirement_values(ctx, field)
+func (ec *executionContext) _NodeTraceEvent_content(ctx context.Context, field graphql.CollectedField, obj *NodeTraceEvent) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_NodeTraceEvent_content(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -26654,26 +28352,23 @@ func (ec *executionContext) _NodeSelectorRequirement_values(ctx context.Context,
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Values, nil
+		return obj.Content, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.([]string)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalNString2ᚕstringᚄ(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_NodeSelectorRequirement_values(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_NodeTraceEvent_content(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "NodeSelectorRequirement",
+		Object:     "NodeTraceEvent",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
@@ -27713,6 +29408,8 @@ func (ec *executionContext) fieldContext_Query_Application(ctx context.Context,
 				return ec.fieldContext_ApplicationQuery_getApplication(ctx, field)
 			case "listApplicationMetadata":
 				return ec.fieldContext_ApplicationQuery_listApplicationMetadata(ctx, field)
+			case "getApplicationMessageTrace":
+				return ec.fieldContext_ApplicationQuery_getApplicationMessageTrace(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type ApplicationQuery", field.Name)
 		},
@@ -28961,6 +30658,8 @@ func (ec *executionContext) fieldContext_RAG_application(ctx context.Context, fi
 				return ec.fieldContext_Application_scoreThreshold(ctx, field)
 			case "numDocuments":
 				return ec.fieldContext_Application_numDocuments(ctx, field)
+			case "searchMode":
+				return ec.fieldContext_Application_searchMode(ctx, field)
 			case "docNullReturn":
 				return ec.fieldContext_Application_docNullReturn(ctx, field)
 			case "userPrompt":
@@ -28991,6 +30690,10 @@ func (ec *executionContext) fieldContext_RAG_application(ctx context.Context, fi
 				return ec.fieldContext_Application_chunkOverlap(ctx, field)
 			case "batchSize":
 				return ec.fieldContext_Application_batchSize(ctx, field)
+			case "splitter":
+				return ec.fieldContext_Application_splitter(ctx, field)
+			case "inputVariables":
+				return ec.fieldContext_Application_inputVariables(ctx, field)
 			}
 			return nil, fmt.Errorf("no field named %q was found under type Application", field.Name)
 		},
@@ -35338,8 +37041,184 @@ func (ec *executionContext) fieldContext___Type_possibleTypes(ctx context.Contex
 	return fc, nil
 }
 
-func (ec *executionContext) ___Type_enumValues(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext___Type_enumValues(ctx, field)
+func (ec *executionContext) ___Type_enumValues(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext___Type_enumValues(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.EnumValues(fc.Args["includeDeprecated"].(bool)), nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.([]introspection.EnumValue)
+	fc.Result = res
+	return ec.marshalO__EnumValue2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐEnumValueᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext___Type_enumValues(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "__Type",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "name":
+				return ec.fieldContext___EnumValue_name(ctx, field)
+			case "description":
+				return ec.fieldContext___EnumValue_description(ctx, field)
+			case "isDeprecated":
+				return ec.fieldContext___EnumValue_isDeprecated(ctx, field)
+			case "deprecationReason":
+				return ec.fieldContext___EnumValue_deprecationReason(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type __EnumValue", field.Name)
+		},
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = ec.Recover(ctx, r)
+			ec.Error(ctx, err)
+		}
+	}()
+	ctx = graphql.WithFieldContext(ctx, fc)
+	if fc.Args, err = ec.field___Type_enumValues_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
+		ec.Error(ctx, err)
+		return fc, err
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) ___Type_inputFields(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext___Type_inputFields(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.InputFields(), nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.([]introspection.InputValue)
+	fc.Result = res
+	return ec.marshalO__InputValue2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐInputValueᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext___Type_inputFields(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "__Type",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "name":
+				return ec.fieldContext___InputValue_name(ctx, field)
+			case "description":
+				return ec.fieldContext___InputValue_description(ctx, field)
+			case "type":
+				return ec.fieldContext___InputValue_type(ctx, field)
+			case "defaultValue":
+				return ec.fieldContext___InputValue_defaultValue(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type __InputValue", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) ___Type_ofType(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext___Type_ofType(ctx, field)
+	if err != nil {
+		return graphql.Null
+	}
+	ctx = graphql.WithFieldContext(ctx, fc)
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.OfType(), nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*introspection.Type)
+	fc.Result = res
+	return ec.marshalO__Type2ᚖgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐType(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) fieldContext___Type_ofType(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+	fc = &graphql.FieldContext{
+		Object:     "__Type",
+		Field:      field,
+		IsMethod:   true,
+		IsResolver: false,
+		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
+			switch field.Name {
+			case "kind":
+				return ec.fieldContext___Type_kind(ctx, field)
+			case "name":
+				return ec.fieldContext___Type_name(ctx, field)
+			case "description":
+				return ec.fieldContext___Type_description(ctx, field)
+			case "fields":
+				return ec.fieldContext___Type_fields(ctx, field)
+			case "interfaces":
+				return ec.fieldContext___Type_interfaces(ctx, field)
+			case "possibleTypes":
+				return ec.fieldContext___Type_possibleTypes(ctx, field)
+			case "enumValues":
+				return ec.fieldContext___Type_enumValues(ctx, field)
+			case "inputFields":
+				return ec.fieldContext___Type_inputFields(ctx, field)
+			case "ofType":
+				return ec.fieldContext___Type_ofType(ctx, field)
+			case "specifiedByURL":
+				return ec.fieldContext___Type_specifiedByURL(ctx, field)
+			}
+			return nil, fmt.Errorf("no field named %q was found under type __Type", field.Name)
+		},
+	}
+	return fc, nil
+}
+
+func (ec *executionContext) ___Type_specifiedByURL(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext___Type_specifiedByURL(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -35352,7 +37231,7 @@ func (ec *executionContext) ___Type_enumValues(ctx context.Context, field graphq
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.EnumValues(fc.Args["includeDeprecated"].(bool)), nil
+		return obj.SpecifiedByURL(), nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -35361,47 +37240,26 @@ func (ec *executionContext) ___Type_enumValues(ctx context.Context, field graphq
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.([]introspection.EnumValue)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalO__EnumValue2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐEnumValueᚄ(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext___Type_enumValues(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext___Type_specifiedByURL(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "__Type",
 		Field:      field,
 		IsMethod:   true,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "name":
-				return ec.fieldContext___EnumValue_name(ctx, field)
-			case "description":
-				return ec.fieldContext___EnumValue_description(ctx, field)
-			case "isDeprecated":
-				return ec.fieldContext___EnumValue_isDeprecated(ctx, field)
-			case "deprecationReason":
-				return ec.fieldContext___EnumValue_deprecationReason(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type __EnumValue", field.Name)
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
-	defer func() {
-		if r := recover(); r != nil {
-			err = ec.Recover(ctx, r)
-			ec.Error(ctx, err)
-		}
-	}()
-	ctx = graphql.WithFieldContext(ctx, fc)
-	if fc.Args, err = ec.field___Type_enumValues_args(ctx, field.ArgumentMap(ec.Variables)); err != nil {
-		ec.Error(ctx, err)
-		return fc, err
-	}
 	return fc, nil
 }
 
-func (ec *executionContext) ___Type_inputFields(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext___Type_inputFields(ctx, field)
+func (ec *executionContext) _filedetail_path(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_filedetail_path(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -35414,108 +37272,38 @@ func (ec *executionContext) ___Type_inputFields(ctx context.Context, field graph
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.InputFields(), nil
+		return obj.Path, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		return graphql.Null
-	}
-	res := resTmp.([]introspection.InputValue)
-	fc.Result = res
-	return ec.marshalO__InputValue2ᚕgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐInputValueᚄ(ctx, field.Selections, res)
-}
-
-func (ec *executionContext) fieldContext___Type_inputFields(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
-	fc = &graphql.FieldContext{
-		Object:     "__Type",
-		Field:      field,
-		IsMethod:   true,
-		IsResolver: false,
-		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "name":
-				return ec.fieldContext___InputValue_name(ctx, field)
-			case "description":
-				return ec.fieldContext___InputValue_description(ctx, field)
-			case "type":
-				return ec.fieldContext___InputValue_type(ctx, field)
-			case "defaultValue":
-				return ec.fieldContext___InputValue_defaultValue(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type __InputValue", field.Name)
-		},
-	}
-	return fc, nil
-}
-
-func (ec *executionContext) ___Type_ofType(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext___Type_ofType(ctx, field)
-	if err != nil {
-		return graphql.Null
-	}
-	ctx = graphql.WithFieldContext(ctx, fc)
-	defer func() {
-		if r := recover(); r != nil {
-			ec.Error(ctx, ec.Recover(ctx, r))
-			ret = graphql.Null
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
 		}
-	}()
-	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
-		ctx = rctx // use context from middleware stack in children
-		return obj.OfType(), nil
-	})
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
-	}
-	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*introspection.Type)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalO__Type2ᚖgithubᚗcomᚋ99designsᚋgqlgenᚋgraphqlᚋintrospectionᚐType(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext___Type_ofType(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_filedetail_path(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "__Type",
+		Object:     "filedetail",
 		Field:      field,
-		IsMethod:   true,
+		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			switch field.Name {
-			case "kind":
-				return ec.fieldContext___Type_kind(ctx, field)
-			case "name":
-				return ec.fieldContext___Type_name(ctx, field)
-			case "description":
-				return ec.fieldContext___Type_description(ctx, field)
-			case "fields":
-				return ec.fieldContext___Type_fields(ctx, field)
-			case "interfaces":
-				return ec.fieldContext___Type_interfaces(ctx, field)
-			case "possibleTypes":
-				return ec.fieldContext___Type_possibleTypes(ctx, field)
-			case "enumValues":
-				return ec.fieldContext___Type_enumValues(ctx, field)
-			case "inputFields":
-				return ec.fieldContext___Type_inputFields(ctx, field)
-			case "ofType":
-				return ec.fieldContext___Type_ofType(ctx, field)
-			case "specifiedByURL":
-				return ec.fieldContext___Type_specifiedByURL(ctx, field)
-			}
-			return nil, fmt.Errorf("no field named %q was found under type __Type", field.Name)
+			return nil, errors.New("field of type String does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) ___Type_specifiedByURL(ctx context.Context, field graphql.CollectedField, obj *introspection.Type) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext___Type_specifiedByURL(ctx, field)
+func (ec *executionContext) _filedetail_fileType(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_filedetail_fileType(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -35528,25 +37316,28 @@ func (ec *executionContext) ___Type_specifiedByURL(ctx context.Context, field gr
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.SpecifiedByURL(), nil
+		return obj.FileType, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext___Type_specifiedByURL(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_filedetail_fileType(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
-		Object:     "__Type",
+		Object:     "filedetail",
 		Field:      field,
-		IsMethod:   true,
+		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
 			return nil, errors.New("field of type String does not have child fields")
@@ -35555,8 +37346,8 @@ func (ec *executionContext) fieldContext___Type_specifiedByURL(ctx context.Conte
 	return fc, nil
 }
 
-func (ec *executionContext) _filedetail_path(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_filedetail_path(ctx, field)
+func (ec *executionContext) _filedetail_count(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_filedetail_count(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -35569,7 +37360,7 @@ func (ec *executionContext) _filedetail_path(ctx context.Context, field graphql.
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Path, nil
+		return obj.Count, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -35586,7 +37377,7 @@ func (ec *executionContext) _filedetail_path(ctx context.Context, field graphql.
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_filedetail_path(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_filedetail_count(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "filedetail",
 		Field:      field,
@@ -35599,8 +37390,8 @@ func (ec *executionContext) fieldContext_filedetail_path(ctx context.Context, fi
 	return fc, nil
 }
 
-func (ec *executionContext) _filedetail_fileType(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_filedetail_fileType(ctx, field)
+func (ec *executionContext) _filedetail_size(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_filedetail_size(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -35613,7 +37404,7 @@ func (ec *executionContext) _filedetail_fileType(ctx context.Context, field grap
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.FileType, nil
+		return obj.Size, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -35630,7 +37421,7 @@ func (ec *executionContext) _filedetail_fileType(ctx context.Context, field grap
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_filedetail_fileType(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_filedetail_size(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "filedetail",
 		Field:      field,
@@ -35643,8 +37434,8 @@ func (ec *executionContext) fieldContext_filedetail_fileType(ctx context.Context
 	return fc, nil
 }
 
-func (ec *executionContext) _filedetail_count(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_filedetail_count(ctx, field)
+func (ec *executionContext) _filedetail_updateTimestamp(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_filedetail_updateTimestamp(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -35657,38 +37448,35 @@ func (ec *executionContext) _filedetail_count(ctx context.Context, field graphql
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Count, nil
+		return obj.UpdateTimestamp, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(*time.Time)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalOTime2ᚖtimeᚐTime(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_filedetail_count(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_filedetail_updateTimestamp(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "filedetail",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Time does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _filedetail_size(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_filedetail_size(ctx, field)
+func (ec *executionContext) _filedetail_timeCost(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_filedetail_timeCost(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -35701,7 +37489,7 @@ func (ec *executionContext) _filedetail_size(ctx context.Context, field graphql.
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Size, nil
+		return obj.TimeCost, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -35713,26 +37501,26 @@ func (ec *executionContext) _filedetail_size(ctx context.Context, field graphql.
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(int)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNInt2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_filedetail_size(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_filedetail_timeCost(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "filedetail",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type String does not have child fields")
+			return nil, errors.New("field of type Int does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _filedetail_updateTimestamp(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_filedetail_updateTimestamp(ctx, field)
+func (ec *executionContext) _filedetail_processedChunks(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_filedetail_processedChunks(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -35745,7 +37533,7 @@ func (ec *executionContext) _filedetail_updateTimestamp(ctx context.Context, fie
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.UpdateTimestamp, nil
+		return obj.ProcessedChunks, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -35754,26 +37542,26 @@ func (ec *executionContext) _filedetail_updateTimestamp(ctx context.Context, fie
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(*time.Time)
+	res := resTmp.(*int)
 	fc.Result = res
-	return ec.marshalOTime2ᚖtimeᚐTime(ctx, field.Selections, res)
+	return ec.marshalOInt2ᚖint(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_filedetail_updateTimestamp(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_filedetail_processedChunks(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "filedetail",
 		Field:      field,
 		IsMethod:   false,
 		IsResolver: false,
 		Child: func(ctx context.Context, field graphql.CollectedField) (*graphql.FieldContext, error) {
-			return nil, errors.New("field of type Time does not have child fields")
+			return nil, errors.New("field of type Int does not have child fields")
 		},
 	}
 	return fc, nil
 }
 
-func (ec *executionContext) _filedetail_timeCost(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
-	fc, err := ec.fieldContext_filedetail_timeCost(ctx, field)
+func (ec *executionContext) _filedetail_totalChunks(ctx context.Context, field graphql.CollectedField, obj *Filedetail) (ret graphql.Marshaler) {
+	fc, err := ec.fieldContext_filedetail_totalChunks(ctx, field)
 	if err != nil {
 		return graphql.Null
 	}
@@ -35786,24 +37574,21 @@ func (ec *executionContext) _filedetail_timeCost(ctx context.Context, field grap
 	}()
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.TimeCost, nil
+		return obj.TotalChunks, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(int)
+	res := resTmp.(*int)
 	fc.Result = res
-	return ec.marshalNInt2int(ctx, field.Selections, res)
+	return ec.marshalOInt2ᚖint(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) fieldContext_filedetail_timeCost(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
+func (ec *executionContext) fieldContext_filedetail_totalChunks(ctx context.Context, field graphql.CollectedField) (fc *graphql.FieldContext, err error) {
 	fc = &graphql.FieldContext{
 		Object:     "filedetail",
 		Field:      field,
@@ -36190,6 +37975,10 @@ func (ec *executionContext) fieldContext_filegroupdetail_filedetails(ctx context
 				return ec.fieldContext_filedetail_updateTimestamp(ctx, field)
 			case "timeCost":
 				return ec.fieldContext_filedetail_timeCost(ctx, field)
+			case "processedChunks":
+				return ec.fieldContext_filedetail_processedChunks(ctx, field)
+			case "totalChunks":
+				return ec.fieldContext_filedetail_totalChunks(ctx, field)
 			case "phase":
 				return ec.fieldContext_filedetail_phase(ctx, field)
 			case "version":
@@ -36338,48 +38127,96 @@ func (ec *executionContext) unmarshalInputAllDataProcessListByCountInput(ctx con
 	return it, nil
 }
 
-func (ec *executionContext) unmarshalInputAllDataProcessListByPageInput(ctx context.Context, obj interface{}) (AllDataProcessListByPageInput, error) {
-	var it AllDataProcessListByPageInput
+func (ec *executionContext) unmarshalInputAllDataProcessListByPageInput(ctx context.Context, obj interface{}) (AllDataProcessListByPageInput, error) {
+	var it AllDataProcessListByPageInput
+	asMap := map[string]interface{}{}
+	for k, v := range obj.(map[string]interface{}) {
+		asMap[k] = v
+	}
+
+	fieldsInOrder := [...]string{"pageIndex", "pageSize", "keyword", "namespace"}
+	for _, k := range fieldsInOrder {
+		v, ok := asMap[k]
+		if !ok {
+			continue
+		}
+		switch k {
+		case "pageIndex":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("pageIndex"))
+			data, err := ec.unmarshalNInt2int(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.PageIndex = data
+		case "pageSize":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("pageSize"))
+			data, err := ec.unmarshalNInt2int(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.PageSize = data
+		case "keyword":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("keyword"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Keyword = data
+		case "namespace":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("namespace"))
+			data, err := ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Namespace = data
+		}
+	}
+
+	return it, nil
+}
+
+func (ec *executionContext) unmarshalInputApplicationMessageTraceInput(ctx context.Context, obj interface{}) (ApplicationMessageTraceInput, error) {
+	var it ApplicationMessageTraceInput
 	asMap := map[string]interface{}{}
 	for k, v := range obj.(map[string]interface{}) {
 		asMap[k] = v
 	}
 
-	fieldsInOrder := [...]string{"pageIndex", "pageSize", "keyword", "namespace"}
+	fieldsInOrder := [...]string{"name", "namespace", "conversationID", "messageID"}
 	for _, k := range fieldsInOrder {
 		v, ok := asMap[k]
 		if !ok {
 			continue
 		}
 		switch k {
-		case "pageIndex":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("pageIndex"))
-			data, err := ec.unmarshalNInt2int(ctx, v)
+		case "name":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("name"))
+			data, err := ec.unmarshalNString2string(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.PageIndex = data
-		case "pageSize":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("pageSize"))
-			data, err := ec.unmarshalNInt2int(ctx, v)
+			it.Name = data
+		case "namespace":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("namespace"))
+			data, err := ec.unmarshalNString2string(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.PageSize = data
-		case "keyword":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("keyword"))
+			it.Namespace = data
+		case "conversationID":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("conversationID"))
 			data, err := ec.unmarshalNString2string(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.Keyword = data
-		case "namespace":
-			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("namespace"))
+			it.ConversationID = data
+		case "messageID":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("messageID"))
 			data, err := ec.unmarshalNString2string(ctx, v)
 			if err != nil {
 				return it, err
 			}
-			it.Namespace = data
+			it.MessageID = data
 		}
 	}
 
@@ -36766,7 +38603,7 @@ func (ec *executionContext) unmarshalInputCreateKnowledgeBaseInput(ctx context.C
 		asMap[k] = v
 	}
 
-	fieldsInOrder := [...]string{"name", "namespace", "labels", "annotations", "displayName", "description", "embedder", "vectorStore", "fileGroups", "chunkSize", "chunkOverlap", "batchSize"}
+	fieldsInOrder := [...]string{"name", "namespace", "labels", "annotations", "displayName", "description", "embedder", "vectorStore", "fileGroups", "chunkSize", "chunkOverlap", "batchSize", "splitter"}
 	for _, k := range fieldsInOrder {
 		v, ok := asMap[k]
 		if !ok {
@@ -36857,6 +38694,13 @@ func (ec *executionContext) unmarshalInputCreateKnowledgeBaseInput(ctx context.C
 				return it, err
 			}
 			it.BatchSize = data
+		case "splitter":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("splitter"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Splitter = data
 		}
 	}
 
@@ -39289,7 +41133,7 @@ func (ec *executionContext) unmarshalInputUpdateApplicationConfigInput(ctx conte
 		asMap[k] = v
 	}
 
-	fieldsInOrder := [...]string{"name", "namespace", "prologue", "model", "llm", "temperature", "maxLength", "maxTokens", "conversionWindowSize", "knowledgebase", "knowledgebases", "scoreThreshold", "numDocuments", "docNullReturn", "userPrompt", "systemPrompt", "showRespInfo", "showRetrievalInfo", "showNextGuide", "tools", "enableRerank", "rerankModel", "enableMultiQuery", "chatTimeout", "enableUploadFile", "chunkSize", "chunkOverlap", "batchSize"}
+	fieldsInOrder := [...]string{"name", "namespace", "prologue", "model", "llm", "temperature", "maxLength", "maxTokens", "conversionWindowSize", "knowledgebase", "knowledgebases", "scoreThreshold", "numDocuments", "searchMode", "docNullReturn", "userPrompt", "systemPrompt", "showRespInfo", "showRetrievalInfo", "showNextGuide", "tools", "enableRerank", "rerankModel", "enableMultiQuery", "chatTimeout", "enableUploadFile", "chunkSize", "chunkOverlap", "batchSize", "splitter"}
 	for _, k := range fieldsInOrder {
 		v, ok := asMap[k]
 		if !ok {
@@ -39387,6 +41231,13 @@ func (ec *executionContext) unmarshalInputUpdateApplicationConfigInput(ctx conte
 				return it, err
 			}
 			it.NumDocuments = data
+		case "searchMode":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("searchMode"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.SearchMode = data
 		case "docNullReturn":
 			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("docNullReturn"))
 			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
@@ -39492,6 +41343,13 @@ func (ec *executionContext) unmarshalInputUpdateApplicationConfigInput(ctx conte
 				return it, err
 			}
 			it.BatchSize = data
+		case "splitter":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("splitter"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Splitter = data
 		}
 	}
 
@@ -39830,7 +41688,7 @@ func (ec *executionContext) unmarshalInputUpdateKnowledgeBaseInput(ctx context.C
 		asMap[k] = v
 	}
 
-	fieldsInOrder := [...]string{"name", "namespace", "labels", "annotations", "displayName", "description", "fileGroups", "chunkSize", "chunkOverlap", "batchSize"}
+	fieldsInOrder := [...]string{"name", "namespace", "labels", "annotations", "displayName", "description", "fileGroups", "chunkSize", "chunkOverlap", "batchSize", "splitter"}
 	for _, k := range fieldsInOrder {
 		v, ok := asMap[k]
 		if !ok {
@@ -39907,6 +41765,13 @@ func (ec *executionContext) unmarshalInputUpdateKnowledgeBaseInput(ctx context.C
 				return it, err
 			}
 			it.BatchSize = data
+		case "splitter":
+			ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("splitter"))
+			data, err := ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+			it.Splitter = data
 		}
 	}
 
@@ -40702,6 +42567,8 @@ func (ec *executionContext) _Application(ctx context.Context, sel ast.SelectionS
 			out.Values[i] = ec._Application_scoreThreshold(ctx, field, obj)
 		case "numDocuments":
 			out.Values[i] = ec._Application_numDocuments(ctx, field, obj)
+		case "searchMode":
+			out.Values[i] = ec._Application_searchMode(ctx, field, obj)
 		case "docNullReturn":
 			out.Values[i] = ec._Application_docNullReturn(ctx, field, obj)
 		case "userPrompt":
@@ -40732,6 +42599,66 @@ func (ec *executionContext) _Application(ctx context.Context, sel ast.SelectionS
 			out.Values[i] = ec._Application_chunkOverlap(ctx, field, obj)
 		case "batchSize":
 			out.Values[i] = ec._Application_batchSize(ctx, field, obj)
+		case "splitter":
+			out.Values[i] = ec._Application_splitter(ctx, field, obj)
+		case "inputVariables":
+			out.Values[i] = ec._Application_inputVariables(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var applicationInputVariableImplementors = []string{"ApplicationInputVariable"}
+
+func (ec *executionContext) _ApplicationInputVariable(ctx context.Context, sel ast.SelectionSet, obj *ApplicationInputVariable) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, applicationInputVariableImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("ApplicationInputVariable")
+		case "name":
+			out.Values[i] = ec._ApplicationInputVariable_name(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "displayName":
+			out.Values[i] = ec._ApplicationInputVariable_displayName(ctx, field, obj)
+		case "description":
+			out.Values[i] = ec._ApplicationInputVariable_description(ctx, field, obj)
+		case "type":
+			out.Values[i] = ec._ApplicationInputVariable_type(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "required":
+			out.Values[i] = ec._ApplicationInputVariable_required(ctx, field, obj)
+		case "default":
+			out.Values[i] = ec._ApplicationInputVariable_default(ctx, field, obj)
+		case "options":
+			out.Values[i] = ec._ApplicationInputVariable_options(ctx, field, obj)
+		case "maxLength":
+			out.Values[i] = ec._ApplicationInputVariable_maxLength(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -41084,6 +43011,42 @@ func (ec *executionContext) _ApplicationQuery(ctx context.Context, sel ast.Selec
 				continue
 			}
 
+			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
+		case "getApplicationMessageTrace":
+			field := field
+
+			innerFunc := func(ctx context.Context, fs *graphql.FieldSet) (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._ApplicationQuery_getApplicationMessageTrace(ctx, field, obj)
+				if res == graphql.Null {
+					atomic.AddUint32(&fs.Invalids, 1)
+				}
+				return res
+			}
+
+			if field.Deferrable != nil {
+				dfs, ok := deferred[field.Deferrable.Label]
+				di := 0
+				if ok {
+					dfs.AddField(field)
+					di = len(dfs.Values) - 1
+				} else {
+					dfs = graphql.NewFieldSet([]graphql.CollectedField{field})
+					deferred[field.Deferrable.Label] = dfs
+				}
+				dfs.Concurrently(di, func(ctx context.Context) graphql.Marshaler {
+					return innerFunc(ctx, dfs)
+				})
+
+				// don't run the out.Concurrently() call below
+				out.Values[i] = graphql.Null
+				continue
+			}
+
 			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
@@ -43511,6 +45474,8 @@ func (ec *executionContext) _GPT(ctx context.Context, sel ast.SelectionSet, obj
 			out.Values[i] = ec._GPT_enableUploadFile(ctx, field, obj)
 		case "notReadyReasonCode":
 			out.Values[i] = ec._GPT_notReadyReasonCode(ctx, field, obj)
+		case "inputVariables":
+			out.Values[i] = ec._GPT_inputVariables(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -43856,6 +45821,8 @@ func (ec *executionContext) _KnowledgeBase(ctx context.Context, sel ast.Selectio
 			out.Values[i] = ec._KnowledgeBase_chunkOverlap(ctx, field, obj)
 		case "batchSize":
 			out.Values[i] = ec._KnowledgeBase_batchSize(ctx, field, obj)
+		case "splitter":
+			out.Values[i] = ec._KnowledgeBase_splitter(ctx, field, obj)
 		case "status":
 			out.Values[i] = ec._KnowledgeBase_status(ctx, field, obj)
 		case "reason":
@@ -45214,94 +47181,211 @@ func (ec *executionContext) _Mutation(ctx context.Context, sel ast.SelectionSet)
 	return out
 }
 
-var nodeImplementors = []string{"Node", "PageNode"}
+var nodeImplementors = []string{"Node", "PageNode"}
+
+func (ec *executionContext) _Node(ctx context.Context, sel ast.SelectionSet, obj *Node) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, nodeImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("Node")
+		case "name":
+			out.Values[i] = ec._Node_name(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "labels":
+			out.Values[i] = ec._Node_labels(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var nodeQueryImplementors = []string{"NodeQuery"}
+
+func (ec *executionContext) _NodeQuery(ctx context.Context, sel ast.SelectionSet, obj *NodeQuery) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, nodeQueryImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("NodeQuery")
+		case "listNodes":
+			field := field
+
+			innerFunc := func(ctx context.Context, fs *graphql.FieldSet) (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._NodeQuery_listNodes(ctx, field, obj)
+				if res == graphql.Null {
+					atomic.AddUint32(&fs.Invalids, 1)
+				}
+				return res
+			}
+
+			if field.Deferrable != nil {
+				dfs, ok := deferred[field.Deferrable.Label]
+				di := 0
+				if ok {
+					dfs.AddField(field)
+					di = len(dfs.Values) - 1
+				} else {
+					dfs = graphql.NewFieldSet([]graphql.CollectedField{field})
+					deferred[field.Deferrable.Label] = dfs
+				}
+				dfs.Concurrently(di, func(ctx context.Context) graphql.Marshaler {
+					return innerFunc(ctx, dfs)
+				})
+
+				// don't run the out.Concurrently() call below
+				out.Values[i] = graphql.Null
+				continue
+			}
+
+			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var nodeSelectorRequirementImplementors = []string{"NodeSelectorRequirement"}
+
+func (ec *executionContext) _NodeSelectorRequirement(ctx context.Context, sel ast.SelectionSet, obj *NodeSelectorRequirement) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, nodeSelectorRequirementImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	deferred := make(map[string]*graphql.FieldSet)
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("NodeSelectorRequirement")
+		case "key":
+			out.Values[i] = ec._NodeSelectorRequirement_key(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "operator":
+			out.Values[i] = ec._NodeSelectorRequirement_operator(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "values":
+			out.Values[i] = ec._NodeSelectorRequirement_values(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch(ctx)
+	if out.Invalids > 0 {
+		return graphql.Null
+	}
+
+	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
+
+	for label, dfs := range deferred {
+		ec.processDeferredGroup(graphql.DeferredGroup{
+			Label:    label,
+			Path:     graphql.GetPath(ctx),
+			FieldSet: dfs,
+			Context:  ctx,
+		})
+	}
+
+	return out
+}
+
+var nodeTraceImplementors = []string{"NodeTrace"}
 
-func (ec *executionContext) _Node(ctx context.Context, sel ast.SelectionSet, obj *Node) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, nodeImplementors)
+func (ec *executionContext) _NodeTrace(ctx context.Context, sel ast.SelectionSet, obj *NodeTrace) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, nodeTraceImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	deferred := make(map[string]*graphql.FieldSet)
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("Node")
+			out.Values[i] = graphql.MarshalString("NodeTrace")
 		case "name":
-			out.Values[i] = ec._Node_name(ctx, field, obj)
+			out.Values[i] = ec._NodeTrace_name(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
-		case "labels":
-			out.Values[i] = ec._Node_labels(ctx, field, obj)
-		default:
-			panic("unknown field " + strconv.Quote(field.Name))
-		}
-	}
-	out.Dispatch(ctx)
-	if out.Invalids > 0 {
-		return graphql.Null
-	}
-
-	atomic.AddInt32(&ec.deferred, int32(len(deferred)))
-
-	for label, dfs := range deferred {
-		ec.processDeferredGroup(graphql.DeferredGroup{
-			Label:    label,
-			Path:     graphql.GetPath(ctx),
-			FieldSet: dfs,
-			Context:  ctx,
-		})
-	}
-
-	return out
-}
-
-var nodeQueryImplementors = []string{"NodeQuery"}
-
-func (ec *executionContext) _NodeQuery(ctx context.Context, sel ast.SelectionSet, obj *NodeQuery) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, nodeQueryImplementors)
-
-	out := graphql.NewFieldSet(fields)
-	deferred := make(map[string]*graphql.FieldSet)
-	for i, field := range fields {
-		switch field.Name {
-		case "__typename":
-			out.Values[i] = graphql.MarshalString("NodeQuery")
-		case "listNodes":
-			field := field
-
-			innerFunc := func(ctx context.Context, fs *graphql.FieldSet) (res graphql.Marshaler) {
-				defer func() {
-					if r := recover(); r != nil {
-						ec.Error(ctx, ec.Recover(ctx, r))
-					}
-				}()
-				res = ec._NodeQuery_listNodes(ctx, field, obj)
-				if res == graphql.Null {
-					atomic.AddUint32(&fs.Invalids, 1)
-				}
-				return res
+		case "group":
+			out.Values[i] = ec._NodeTrace_group(ctx, field, obj)
+		case "kind":
+			out.Values[i] = ec._NodeTrace_kind(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
 			}
-
-			if field.Deferrable != nil {
-				dfs, ok := deferred[field.Deferrable.Label]
-				di := 0
-				if ok {
-					dfs.AddField(field)
-					di = len(dfs.Values) - 1
-				} else {
-					dfs = graphql.NewFieldSet([]graphql.CollectedField{field})
-					deferred[field.Deferrable.Label] = dfs
-				}
-				dfs.Concurrently(di, func(ctx context.Context) graphql.Marshaler {
-					return innerFunc(ctx, dfs)
-				})
-
-				// don't run the out.Concurrently() call below
-				out.Values[i] = graphql.Null
-				continue
+		case "startedAt":
+			out.Values[i] = ec._NodeTrace_startedAt(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
 			}
-
-			out.Concurrently(i, func(ctx context.Context) graphql.Marshaler { return innerFunc(ctx, out) })
+		case "latency":
+			out.Values[i] = ec._NodeTrace_latency(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				out.Invalids++
+			}
+		case "inputKeys":
+			out.Values[i] = ec._NodeTrace_inputKeys(ctx, field, obj)
+		case "outputKeys":
+			out.Values[i] = ec._NodeTrace_outputKeys(ctx, field, obj)
+		case "outputs":
+			out.Values[i] = ec._NodeTrace_outputs(ctx, field, obj)
+		case "events":
+			out.Values[i] = ec._NodeTrace_events(ctx, field, obj)
+		case "error":
+			out.Values[i] = ec._NodeTrace_error(ctx, field, obj)
+		case "skipped":
+			out.Values[i] = ec._NodeTrace_skipped(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -45325,32 +47409,29 @@ func (ec *executionContext) _NodeQuery(ctx context.Context, sel ast.SelectionSet
 	return out
 }
 
-var nodeSelectorRequirementImplementors = []string{"NodeSelectorRequirement"}
+var nodeTraceEventImplementors = []string{"NodeTraceEvent"}
 
-func (ec *executionContext) _NodeSelectorRequirement(ctx context.Context, sel ast.SelectionSet, obj *NodeSelectorRequirement) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, nodeSelectorRequirementImplementors)
+func (ec *executionContext) _NodeTraceEvent(ctx context.Context, sel ast.SelectionSet, obj *NodeTraceEvent) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, nodeTraceEventImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	deferred := make(map[string]*graphql.FieldSet)
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("NodeSelectorRequirement")
-		case "key":
-			out.Values[i] = ec._NodeSelectorRequirement_key(ctx, field, obj)
-			if out.Values[i] == graphql.Null {
-				out.Invalids++
-			}
-		case "operator":
-			out.Values[i] = ec._NodeSelectorRequirement_operator(ctx, field, obj)
+			out.Values[i] = graphql.MarshalString("NodeTraceEvent")
+		case "type":
+			out.Values[i] = ec._NodeTraceEvent_type(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
-		case "values":
-			out.Values[i] = ec._NodeSelectorRequirement_values(ctx, field, obj)
+		case "time":
+			out.Values[i] = ec._NodeTraceEvent_time(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
+		case "content":
+			out.Values[i] = ec._NodeTraceEvent_content(ctx, field, obj)
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -48057,6 +50138,10 @@ func (ec *executionContext) _filedetail(ctx context.Context, sel ast.SelectionSe
 			if out.Values[i] == graphql.Null {
 				out.Invalids++
 			}
+		case "processedChunks":
+			out.Values[i] = ec._filedetail_processedChunks(ctx, field, obj)
+		case "totalChunks":
+			out.Values[i] = ec._filedetail_totalChunks(ctx, field, obj)
 		case "phase":
 			out.Values[i] = ec._filedetail_phase(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
@@ -48191,6 +50276,21 @@ func (ec *executionContext) marshalNApplication2ᚖgithubᚗcomᚋkubeagiᚋarca
 	return ec._Application(ctx, sel, v)
 }
 
+func (ec *executionContext) unmarshalNApplicationMessageTraceInput2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐApplicationMessageTraceInput(ctx context.Context, v interface{}) (ApplicationMessageTraceInput, error) {
+	res, err := ec.unmarshalInputApplicationMessageTraceInput(ctx, v)
+	return res, graphql.ErrorOnPath(ctx, err)
+}
+
+func (ec *executionContext) marshalNApplicationInputVariable2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐApplicationInputVariable(ctx context.Context, sel ast.SelectionSet, v *ApplicationInputVariable) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+		return graphql.Null
+	}
+	return ec._ApplicationInputVariable(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalNApplicationMetadata2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐApplicationMetadata(ctx context.Context, sel ast.SelectionSet, v ApplicationMetadata) graphql.Marshaler {
 	return ec._ApplicationMetadata(ctx, sel, &v)
 }
@@ -48608,6 +50708,70 @@ func (ec *executionContext) marshalNModelService2ᚖgithubᚗcomᚋkubeagiᚋarc
 	return ec._ModelService(ctx, sel, v)
 }
 
+func (ec *executionContext) marshalNNodeTrace2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐNodeTraceᚄ(ctx context.Context, sel ast.SelectionSet, v []*NodeTrace) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNNodeTrace2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐNodeTrace(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+
+	for _, e := range ret {
+		if e == graphql.Null {
+			return graphql.Null
+		}
+	}
+
+	return ret
+}
+
+func (ec *executionContext) marshalNNodeTrace2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐNodeTrace(ctx context.Context, sel ast.SelectionSet, v *NodeTrace) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+		return graphql.Null
+	}
+	return ec._NodeTrace(ctx, sel, v)
+}
+
+func (ec *executionContext) marshalNNodeTraceEvent2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐNodeTraceEvent(ctx context.Context, sel ast.SelectionSet, v *NodeTraceEvent) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "the requested element is null which the schema does not allow")
+		}
+		return graphql.Null
+	}
+	return ec._NodeTraceEvent(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalNPageNode2githubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐPageNode(ctx context.Context, sel ast.SelectionSet, v PageNode) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
@@ -49227,6 +51391,53 @@ func (ec *executionContext) unmarshalOAllDataProcessListByPageInput2ᚖgithubᚗ
 	return &res, graphql.ErrorOnPath(ctx, err)
 }
 
+func (ec *executionContext) marshalOApplicationInputVariable2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐApplicationInputVariableᚄ(ctx context.Context, sel ast.SelectionSet, v []*ApplicationInputVariable) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNApplicationInputVariable2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐApplicationInputVariable(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+
+	for _, e := range ret {
+		if e == graphql.Null {
+			return graphql.Null
+		}
+	}
+
+	return ret
+}
+
 func (ec *executionContext) marshalOApplicationMetadata2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐApplicationMetadata(ctx context.Context, sel ast.SelectionSet, v *ApplicationMetadata) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
@@ -50438,6 +52649,53 @@ func (ec *executionContext) unmarshalONodeSelectorRequirementInput2ᚖgithubᚗc
 	return &res, graphql.ErrorOnPath(ctx, err)
 }
 
+func (ec *executionContext) marshalONodeTraceEvent2ᚕᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐNodeTraceEventᚄ(ctx context.Context, sel ast.SelectionSet, v []*NodeTraceEvent) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNNodeTraceEvent2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐNodeTraceEvent(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+
+	for _, e := range ret {
+		if e == graphql.Null {
+			return graphql.Null
+		}
+	}
+
+	return ret
+}
+
 func (ec *executionContext) marshalOOss2ᚖgithubᚗcomᚋkubeagiᚋarcadiaᚋapiserverᚋgraphᚋgeneratedᚐOss(ctx context.Context, sel ast.SelectionSet, v *Oss) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
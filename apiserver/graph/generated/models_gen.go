@@ -62,6 +62,9 @@ type Application struct {
 	ScoreThreshold *float64 `json:"scoreThreshold,omitempty"`
 	// numDocuments  最终返回结果的引用上限
 	NumDocuments *int `json:"numDocuments,omitempty"`
+	// searchMode 知识库的检索方式
+	// 规则: enum { vector, keyword, hybrid }，hybrid 为向量检索与关键词检索结果的融合，keyword 和 hybrid 仅支持 pgvector
+	SearchMode *string `json:"searchMode,omitempty"`
 	// docNullReturn 空搜索回复
 	DocNullReturn *string `json:"docNullReturn,omitempty"`
 	// userPrompt 用户级别的 Prompt
@@ -92,6 +95,45 @@ type Application struct {
 	ChunkOverlap *int `json:"chunkOverlap,omitempty"`
 	// batchSize 上传文档做批量处理时的批次大小
 	BatchSize *int `json:"batchSize,omitempty"`
+	// splitter 上传文档做文档拆分时的策略，recursiveCharacter, token, markdown, page, sentence 之一，默认为 recursiveCharacter
+	Splitter *string `json:"splitter,omitempty"`
+	// inputVariables 应用的输入变量，对话时在 inputs 中传入，在 Prompt 中通过 {{.name}} 使用
+	InputVariables []*ApplicationInputVariable `json:"inputVariables,omitempty"`
+}
+
+// ApplicationInputVariable
+// 应用的输入变量，前端根据它展示表单
+type ApplicationInputVariable struct {
+	// name 变量名，在 Prompt 中通过 {{.name}} 使用
+	Name string `json:"name"`
+	// displayName 展示名称
+	DisplayName *string `json:"displayName,omitempty"`
+	// description 描述信息
+	Description *string `json:"description,omitempty"`
+	// type 变量类型
+	// 规则: enum { text, enum, number }
+	Type string `json:"type"`
+	// required 是否必填，有默认值时可以不填
+	Required *bool `json:"required,omitempty"`
+	// default 默认值
+	Default *string `json:"default,omitempty"`
+	// options 可选值，type 为 enum 时有效
+	Options []string `json:"options,omitempty"`
+	// maxLength 文本的最大长度，0 表示不限制
+	MaxLength *int `json:"maxLength,omitempty"`
+}
+
+// ApplicationMessageTraceInput
+// 查询一条对话消息执行记录的参数
+type ApplicationMessageTraceInput struct {
+	// 应用名称
+	Name string `json:"name"`
+	// 应用所在的namespace
+	Namespace string `json:"namespace"`
+	// 对话ID
+	ConversationID string `json:"conversationID"`
+	// 消息ID
+	MessageID string `json:"messageID"`
 }
 
 // Application
@@ -153,6 +195,8 @@ type ApplicationMutation struct {
 type ApplicationQuery struct {
 	GetApplication          Application     `json:"getApplication"`
 	ListApplicationMetadata PaginatedResult `json:"listApplicationMetadata"`
+	// 获取一条对话消息中各个节点的执行记录
+	GetApplicationMessageTrace []*NodeTrace `json:"getApplicationMessageTrace"`
 }
 
 type CheckDataProcessTaskNameInput struct {
@@ -290,6 +334,9 @@ type CreateKnowledgeBaseInput struct {
 	ChunkOverlap *int `json:"chunkOverlap,omitempty"`
 	// batchSize为知识库做批量处理时的批次大小
 	BatchSize *int `json:"batchSize,omitempty"`
+	// splitter为知识库做文档拆分时的策略
+	// 规则: recursiveCharacter, token, markdown, page, sentence, semantic 之一，默认为 recursiveCharacter
+	Splitter *string `json:"splitter,omitempty"`
 }
 
 type CreateLLMInput struct {
@@ -909,6 +956,8 @@ type Gpt struct {
 	// - LLMNotReady: 模型服务没有就绪
 	// - ConfigError: 应用配置错误，比如写了多个Output节点，比如节点名称重复等其他错误
 	NotReadyReasonCode *string `json:"notReadyReasonCode,omitempty"`
+	// inputVariables 应用的输入变量，对话时在 inputs 中传入
+	InputVariables []*ApplicationInputVariable `json:"inputVariables,omitempty"`
 }
 
 func (Gpt) IsPageNode() {}
@@ -972,6 +1021,9 @@ type KnowledgeBase struct {
 	ChunkOverlap *int `json:"chunkOverlap,omitempty"`
 	// batchSize为知识库做批量处理时的批次大小
 	BatchSize *int `json:"batchSize,omitempty"`
+	// splitter为知识库做文档拆分时的策略
+	// 规则: recursiveCharacter, token, markdown, page, sentence, semantic 之一，默认为 recursiveCharacter
+	Splitter *string `json:"splitter,omitempty"`
 	// 知识库整体连接状态
 	// 规则: True 代表正常 False代表异常
 	// 规则: Deleting 代表删除中
@@ -1385,6 +1437,44 @@ type NodeSelectorRequirementInput struct {
 	Values   []string `json:"values"`
 }
 
+// NodeTrace
+// 应用中一个节点的一次执行记录
+type NodeTrace struct {
+	// 节点名称
+	Name string `json:"name"`
+	// 节点类型所属的组
+	Group *string `json:"group,omitempty"`
+	// 节点类型
+	Kind string `json:"kind"`
+	// 开始执行的时间
+	StartedAt time.Time `json:"startedAt"`
+	// 执行耗时,单位ms
+	Latency int `json:"latency"`
+	// 节点开始执行时,参数中已有的key
+	InputKeys []string `json:"inputKeys,omitempty"`
+	// 节点新增或修改的key
+	OutputKeys []string `json:"outputKeys,omitempty"`
+	// 节点新增或修改的值的摘要
+	Outputs map[string]interface{} `json:"outputs,omitempty"`
+	// 节点执行过程中发生的事件,如调用模型,调用工具等
+	Events []*NodeTraceEvent `json:"events,omitempty"`
+	// 节点执行失败的错误信息
+	Error *string `json:"error,omitempty"`
+	// 节点不在路由选中的分支上,未执行
+	Skipped *bool `json:"skipped,omitempty"`
+}
+
+// NodeTraceEvent
+// 节点执行过程中发生的事件
+type NodeTraceEvent struct {
+	// 事件类型
+	Type string `json:"type"`
+	// 事件发生的时间
+	Time time.Time `json:"time"`
+	// 事件内容
+	Content *string `json:"content,omitempty"`
+}
+
 // 对象存储的使用信息
 type Oss struct {
 	// 所用的bucket名称
@@ -1717,6 +1807,9 @@ type UpdateApplicationConfigInput struct {
 	ScoreThreshold *float64 `json:"scoreThreshold,omitempty"`
 	// numDocuments  最终返回结果的引用上限
 	NumDocuments *int `json:"numDocuments,omitempty"`
+	// searchMode 知识库的检索方式
+	// 规则: enum { vector, keyword, hybrid }，hybrid 为向量检索与关键词检索结果的融合，keyword 和 hybrid 仅支持 pgvector
+	SearchMode *string `json:"searchMode,omitempty"`
 	// docNullReturn 空搜索回复
 	DocNullReturn *string `json:"docNullReturn,omitempty"`
 	// userPrompt 用户级别的 Prompt
@@ -1747,6 +1840,8 @@ type UpdateApplicationConfigInput struct {
 	ChunkOverlap *int `json:"chunkOverlap,omitempty"`
 	// batchSize 上传文档做批量处理时的批次大小
 	BatchSize *int `json:"batchSize,omitempty"`
+	// splitter 上传文档做文档拆分时的策略，recursiveCharacter, token, markdown, page, sentence 之一，默认为 recursiveCharacter
+	Splitter *string `json:"splitter,omitempty"`
 }
 
 type UpdateApplicationMetadataInput struct {
@@ -1862,6 +1957,9 @@ type UpdateKnowledgeBaseInput struct {
 	ChunkOverlap *int `json:"chunkOverlap,omitempty"`
 	// batchSize为知识库做批量处理时的批次大小
 	BatchSize *int `json:"batchSize,omitempty"`
+	// splitter为知识库做文档拆分时的策略
+	// 规则: recursiveCharacter, token, markdown, page, sentence, semantic 之一，默认为 recursiveCharacter
+	Splitter *string `json:"splitter,omitempty"`
 }
 
 type UpdateLLMInput struct {
@@ -2162,6 +2260,10 @@ type Filedetail struct {
 	UpdateTimestamp *time.Time `json:"updateTimestamp,omitempty"`
 	// 最近一次成功的处理耗时
 	TimeCost int `json:"timeCost"`
+	// 已写入向量数据库的分段数，处理过程中更新
+	ProcessedChunks *int `json:"processedChunks,omitempty"`
+	// 文件的分段总数，文件全部加载后才可知
+	TotalChunks *int `json:"totalChunks,omitempty"`
 	// 文件处理的阶段
 	// 规则: enum { Pending , Processing , Succeeded, Failed, Skipped}
 	Phase string `json:"phase"`
@@ -65,6 +65,19 @@ func (r *applicationQueryResolver) ListApplicationMetadata(ctx context.Context,
 	return application.ListApplicationMeatadatas(ctx, c, input)
 }
 
+// GetApplicationMessageTrace is the resolver for the getApplicationMessageTrace field.
+func (r *applicationQueryResolver) GetApplicationMessageTrace(ctx context.Context, obj *generated.ApplicationQuery, input generated.ApplicationMessageTraceInput) ([]*generated.NodeTrace, error) {
+	c, err := getClientFromCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	systemCli, err := getAdminClient()
+	if err != nil {
+		return nil, err
+	}
+	return application.GetApplicationMessageTrace(ctx, c, systemCli, input)
+}
+
 // Application is the resolver for the Application field.
 func (r *mutationResolver) Application(ctx context.Context) (*generated.ApplicationMutation, error) {
 	return &generated.ApplicationMutation{}, nil
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/minio/minio-go/v7"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -42,9 +43,13 @@ import (
 	"github.com/kubeagi/arcadia/api/base/v1alpha1"
 	pkgconf "github.com/kubeagi/arcadia/apiserver/config"
 	"github.com/kubeagi/arcadia/apiserver/graph/generated"
+	"github.com/kubeagi/arcadia/apiserver/pkg/auth"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
 	"github.com/kubeagi/arcadia/apiserver/pkg/common"
 	"github.com/kubeagi/arcadia/apiserver/pkg/gpt"
 	"github.com/kubeagi/arcadia/apiserver/pkg/utils"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
 	"github.com/kubeagi/arcadia/pkg/config"
 	pkgutils "github.com/kubeagi/arcadia/pkg/utils"
 )
@@ -75,6 +80,17 @@ func addDefaultValue(gApp *generated.Application, app *v1alpha1.Application) {
 	gApp.ConversionWindowSize = pointer.Int(5)
 }
 
+// withSearchMode returns a copy of the retriever config with the search mode of the knowledgebase retriever,
+// the config of the last retriever is shown but only the knowledgebase retriever uses the search mode
+func withSearchMode(retriever *apiretriever.CommonRetrieverConfig, mode apiretriever.SearchMode) *apiretriever.CommonRetrieverConfig {
+	if retriever == nil {
+		return nil
+	}
+	res := *retriever
+	res.SearchMode = mode
+	return &res
+}
+
 func cr2app(prompt *apiprompt.Prompt, chainConfig *apichain.CommonChainConfig, retriever *apiretriever.CommonRetrieverConfig, app *v1alpha1.Application, agent *apiagent.Agent, doc *apidocumentloader.DocumentLoader, enableRerank, enableMultiQuery *bool, rerankModel *string) (*generated.Application, error) {
 	if app == nil {
 		return nil, errors.New("no app found")
@@ -109,6 +125,7 @@ func cr2app(prompt *apiprompt.Prompt, chainConfig *apichain.CommonChainConfig, r
 		DocNullReturn:     pointer.String(app.Spec.DocNullReturn),
 		ChatTimeout:       pointer.Float64(app.Spec.ChatTimeoutSecond),
 		EnableUploadFile:  app.Spec.EnableUploadFile,
+		InputVariables:    common.GetAppInputVariables(app),
 	}
 	if prompt != nil {
 		gApp.UserPrompt = pointer.String(prompt.Spec.UserMessage)
@@ -144,11 +161,13 @@ func cr2app(prompt *apiprompt.Prompt, chainConfig *apichain.CommonChainConfig, r
 	if retriever != nil {
 		gApp.ScoreThreshold = pointer.Float64(float64(pointer.Float32Deref(retriever.ScoreThreshold, 0.0)))
 		gApp.NumDocuments = pointer.Int(retriever.NumDocuments)
+		gApp.SearchMode = pointer.String(string(retriever.SearchMode))
 	}
 	if doc != nil && doc.ResourceVersion != "" {
 		gApp.BatchSize = pointer.Int(doc.Spec.BatchSize)
 		gApp.ChunkSize = pointer.Int(doc.Spec.ChunkSize)
 		gApp.ChunkOverlap = doc.Spec.ChunkOverlap
+		gApp.Splitter = pointer.String(string(doc.Spec.Splitter))
 	}
 	addDefaultValue(gApp, app)
 	gApp.EnableRerank = enableRerank
@@ -386,6 +405,9 @@ func GetApplication(ctx context.Context, c client.Client, name, namespace string
 				rerankModel = rerankRetriever.Spec.Model.Name
 			}
 		}
+		if kbRetriever.ResourceVersion != "" {
+			retriever = withSearchMode(retriever, kbRetriever.Spec.SearchMode)
+		}
 	} else {
 		llmchain := &apichain.LLMChain{}
 		if err := c.Get(ctx, key, llmchain); err != nil && !apierrors.IsNotFound(err) {
@@ -499,12 +521,16 @@ func UpdateApplicationConfig(ctx context.Context, c client.Client, input generat
 				ChunkSize:    pointer.IntDeref(input.ChunkSize, 1024),
 				ChunkOverlap: input.ChunkOverlap,
 				BatchSize:    pointer.IntDeref(input.BatchSize, 3),
+				Splitter:     v1alpha1.SplitterType(pointer.StringDeref(input.Splitter, string(v1alpha1.SplitterTypeRecursiveCharacter))),
 				LoaderConfig: apidocumentloader.LoaderConfig{},
 			},
 		}
 		if _, err := controllerutil.CreateOrUpdate(ctx, c, documentLoader, func() error {
 			documentLoader.Spec.ChunkSize = pointer.IntDeref(input.ChunkSize, documentLoader.Spec.ChunkSize)
 			documentLoader.Spec.BatchSize = pointer.IntDeref(input.BatchSize, documentLoader.Spec.BatchSize)
+			if input.Splitter != nil {
+				documentLoader.Spec.Splitter = v1alpha1.SplitterType(*input.Splitter)
+			}
 			if input.ChunkOverlap == nil {
 				documentLoader.Spec.ChunkOverlap = pointer.Int(50)
 			} else {
@@ -535,6 +561,7 @@ func UpdateApplicationConfig(ctx context.Context, c client.Client, input generat
 				CommonRetrieverConfig: apiretriever.CommonRetrieverConfig{
 					ScoreThreshold: pointer.Float32(float32(pointer.Float64Deref(input.ScoreThreshold, apiretriever.DefaultScoreThreshold))),
 					NumDocuments:   pointer.IntDeref(input.NumDocuments, apiretriever.DefaultNumDocuments),
+					SearchMode:     apiretriever.SearchMode(pointer.StringDeref(input.SearchMode, string(apiretriever.SearchModeVector))),
 				},
 			},
 		}
@@ -543,6 +570,9 @@ func UpdateApplicationConfig(ctx context.Context, c client.Client, input generat
 				conversationKnowledgebaseRetriever.Spec.ScoreThreshold = pointer.Float32(float32(*input.ScoreThreshold))
 			}
 			conversationKnowledgebaseRetriever.Spec.NumDocuments = pointer.IntDeref(input.NumDocuments, conversationKnowledgebaseRetriever.Spec.NumDocuments)
+			if input.SearchMode != nil {
+				conversationKnowledgebaseRetriever.Spec.SearchMode = apiretriever.SearchMode(*input.SearchMode)
+			}
 			return nil
 		}); err != nil {
 			return nil, err
@@ -677,6 +707,7 @@ func UpdateApplicationConfig(ctx context.Context, c client.Client, input generat
 					CommonRetrieverConfig: apiretriever.CommonRetrieverConfig{
 						ScoreThreshold: pointer.Float32(float32(pointer.Float64Deref(input.ScoreThreshold, apiretriever.DefaultScoreThreshold))),
 						NumDocuments:   pointer.IntDeref(input.NumDocuments, apiretriever.DefaultNumDocuments),
+						SearchMode:     apiretriever.SearchMode(pointer.StringDeref(input.SearchMode, string(apiretriever.SearchModeVector))),
 					},
 				},
 			}
@@ -685,6 +716,9 @@ func UpdateApplicationConfig(ctx context.Context, c client.Client, input generat
 					knowledgebaseRetriever.Spec.ScoreThreshold = pointer.Float32(float32(*input.ScoreThreshold))
 				}
 				knowledgebaseRetriever.Spec.NumDocuments = pointer.IntDeref(input.NumDocuments, knowledgebaseRetriever.Spec.NumDocuments)
+				if input.SearchMode != nil {
+					knowledgebaseRetriever.Spec.SearchMode = apiretriever.SearchMode(*input.SearchMode)
+				}
 				return nil
 			}); err != nil {
 				return nil, err
@@ -868,6 +902,9 @@ func UpdateApplicationConfig(ctx context.Context, c client.Client, input generat
 		}
 	}
 
+	if knowledgebaseRetriever != nil && knowledgebaseRetriever.ResourceVersion != "" {
+		retriever = withSearchMode(retriever, knowledgebaseRetriever.Spec.SearchMode)
+	}
 	return cr2app(prompt, chainConfig, retriever, app, agent, documentLoader, pointer.Bool(hasRerankRetriever), pointer.Bool(hasMultiQueryRetriever), pointer.String(rerankModel))
 }
 
@@ -1181,3 +1218,61 @@ func UploadIcon(ctx context.Context, client client.Client, icon, appName, namesp
 	}
 	return icon, nil
 }
+
+var (
+	chatStorageOnce sync.Once
+	chatStorage     storage.Storage
+)
+
+// GetApplicationMessageTrace returns the execution trace of every node for one message.
+// c is used to check whether the user can get the application, systemCli is used to init the chat storage.
+func GetApplicationMessageTrace(ctx context.Context, c, systemCli client.Client, input generated.ApplicationMessageTraceInput) ([]*generated.NodeTrace, error) {
+	app := &v1alpha1.Application{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: input.Namespace, Name: input.Name}, app); err != nil {
+		return nil, err
+	}
+	chatStorageOnce.Do(func() {
+		chatStorage = chat.NewChatServer(systemCli, false).Storage()
+	})
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	trace, err := chatStorage.FindMessageTrace(input.ConversationID, input.MessageID, storage.WithAppNamespace(input.Namespace), storage.WithAppName(input.Name), storage.WithUser(currentUser))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*generated.NodeTrace, 0, len(trace.Nodes))
+	for _, n := range trace.Nodes {
+		res = append(res, nodeTrace2model(n))
+	}
+	return res, nil
+}
+
+func nodeTrace2model(n base.NodeTrace) *generated.NodeTrace {
+	res := &generated.NodeTrace{
+		Name:       n.Name,
+		Group:      pointer.String(n.Group),
+		Kind:       n.Kind,
+		StartedAt:  n.StartedAt,
+		Latency:    int(n.Latency),
+		InputKeys:  n.InputKeys,
+		OutputKeys: n.OutputKeys,
+		Events:     make([]*generated.NodeTraceEvent, 0, len(n.Events)),
+		Skipped:    pointer.Bool(n.Skipped),
+	}
+	if n.Error != "" {
+		res.Error = pointer.String(n.Error)
+	}
+	if len(n.Outputs) > 0 {
+		res.Outputs = make(map[string]interface{}, len(n.Outputs))
+		for k, v := range n.Outputs {
+			res.Outputs[k] = v
+		}
+	}
+	for _, e := range n.Events {
+		res.Events = append(res.Events, &generated.NodeTraceEvent{
+			Type:    string(e.Type),
+			Time:    e.Time,
+			Content: pointer.String(e.Content),
+		})
+	}
+	return res
+}
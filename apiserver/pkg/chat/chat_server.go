@@ -110,6 +110,10 @@ func (cs *ChatServer) AppRun(ctx context.Context, req ChatReqBody, respStream ch
 		return nil, err
 	}
 	*timeout = app.Spec.ChatTimeoutSecond
+	// check the input variables before the conversation is saved
+	if _, err := appruntime.ResolveInputVariables(app.Spec.InputVariables, req.Inputs); err != nil {
+		return nil, err
+	}
 	var conversation *storage.Conversation
 	history := memory.NewChatMessageHistory()
 	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
@@ -127,6 +131,10 @@ func (cs *ChatServer) AppRun(ctx context.Context, req ChatReqBody, respStream ch
 			return nil, err
 		}
 		for _, v := range conversation.Messages {
+			// the blocked questions should not be sent to the llm again
+			if v.Blocked {
+				continue
+			}
 			_ = history.AddUserMessage(ctx, v.Query)
 			_ = history.AddAIMessage(ctx, v.Answer)
 		}
@@ -156,8 +164,16 @@ func (cs *ChatServer) AppRun(ctx context.Context, req ChatReqBody, respStream ch
 	if err != nil {
 		return nil, err
 	}
+	defer appRun.Release()
 	klog.FromContext(ctx).Info("begin to run application", "appName", req.APPName, "appNamespace", req.AppNamespace)
-	out, err := appRun.Run(ctx, cs.systemCli, respStream, appruntime.Input{Question: req.Query, Files: req.Files, NeedStream: req.ResponseMode.IsStreaming(), History: history, ConversationID: req.ConversationID})
+	summary := base.NewConversationSummary(conversation.Summary, conversation.SummaryMessages)
+	out, err := appRun.Run(ctx, cs.systemCli, respStream, appruntime.Input{Question: req.Query, Files: req.Files, NeedStream: req.ResponseMode.IsStreaming(), History: history, ConversationID: req.ConversationID, Summary: summary, Variables: req.Inputs})
+	// save the trace even if the run failed, so we can find out which node caused the error
+	if len(out.Trace) > 0 {
+		if traceErr := cs.Storage().UpdateMessageTrace(&storage.MessageTrace{MessageID: messageID, ConversationID: conversation.ID, Nodes: out.Trace}); traceErr != nil {
+			klog.FromContext(ctx).Error(traceErr, "failed to save message trace", "messageID", messageID)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -165,10 +181,16 @@ func (cs *ChatServer) AppRun(ctx context.Context, req ChatReqBody, respStream ch
 	conversation.UpdatedAt = req.StartTime
 	conversation.Messages[len(conversation.Messages)-1].Answer = out.Answer
 	conversation.Messages[len(conversation.Messages)-1].References = out.References
+	conversation.Messages[len(conversation.Messages)-1].Blocked = out.Blocked()
+	conversation.Messages[len(conversation.Messages)-1].Guardrails = out.Guardrails
 	conversation.Messages[len(conversation.Messages)-1].Latency = time.Since(req.StartTime).Milliseconds()
 	if req.Files != nil && len(req.Files) > 0 {
 		conversation.Messages[len(conversation.Messages)-1].RawFiles = strings.Join(req.Files, ",")
 	}
+	// the summary only covers the history messages, so it is valid in the next turn
+	if summary.Updated() {
+		conversation.Summary, conversation.SummaryMessages = summary.Get()
+	}
 
 	if err := cs.Storage().UpdateConversation(conversation); err != nil {
 		return nil, err
@@ -180,6 +202,7 @@ func (cs *ChatServer) AppRun(ctx context.Context, req ChatReqBody, respStream ch
 		Message:        out.Answer,
 		CreatedAt:      time.Now(),
 		References:     out.References,
+		Structured:     out.Structured,
 	}, nil
 }
 
@@ -236,6 +259,11 @@ func (cs *ChatServer) GetMessageReferences(ctx context.Context, req MessageReqBo
 	return nil, errors.New("conversation or message is not found")
 }
 
+func (cs *ChatServer) GetMessageTrace(ctx context.Context, req MessageReqBody) (*storage.MessageTrace, error) {
+	currentUser, _ := ctx.Value(auth.UserNameContextKey).(string)
+	return cs.Storage().FindMessageTrace(req.ConversationID, req.MessageID, storage.WithAppNamespace(req.AppNamespace), storage.WithAppName(req.APPName), storage.WithUser(currentUser))
+}
+
 // ListPromptStarters PromptStarter are examples for users to help them get up and running with the application quickly. We use same name with chatgpt
 func (cs *ChatServer) ListPromptStarters(ctx context.Context, req APPMetadata, limit int) (promptStarters []string, err error) {
 	app, err := cs.GetApp(ctx, req.APPName, req.AppNamespace)
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+)
+
+const (
+	// quotaStreamLease is how long a stream is counted if it is not released, like when the apiserver restarts
+	quotaStreamLease = 10 * time.Minute
+	// quotaStreamRetryAfter is the retry hint when there are too many streams, a stream usually ends in seconds
+	quotaStreamRetryAfter = 10 * time.Second
+)
+
+// QuotaExceededError is returned when a rate limit of the application is reached
+type QuotaExceededError struct {
+	Msg string
+	// RetryAfter is how long to wait before the request may be accepted
+	RetryAfter time.Duration
+}
+
+func (e *QuotaExceededError) Error() string { return e.Msg }
+
+// CheckQuota checks the rate limits of the application for the user before the application runs, user is the
+// logged in user or the client ip. The returned release func must be called after the run with the tokens used by it.
+func (cs *ChatServer) CheckQuota(ctx context.Context, req ChatReqBody, user, messageID string) (release func(tokens int64), err error) {
+	app, err := cs.GetApp(ctx, req.APPName, req.AppNamespace)
+	if err != nil {
+		return nil, err
+	}
+	limit := app.Spec.RateLimit
+	if limit == nil {
+		return func(int64) {}, nil
+	}
+	store := cs.Storage()
+	now := time.Now().UTC()
+	nextDay := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+	dayUsage := func(user string) storage.QuotaUsage {
+		return storage.QuotaUsage{AppNamespace: req.AppNamespace, AppName: req.APPName, User: user, Period: "day:" + now.Format("20060102"), ExpiresAt: nextDay}
+	}
+	// the app-wide usage has no user, the tokens are added after the run, so they are only read here
+	for u, max := range map[string]int64{"": limit.DailyTokens, user: limit.DailyTokensPerUser} {
+		if max <= 0 {
+			continue
+		}
+		usage, err := store.GetQuotaUsage(dayUsage(u))
+		if err != nil {
+			return nil, err
+		}
+		if usage.Tokens >= max {
+			return nil, &QuotaExceededError{Msg: "the daily tokens are used up", RetryAfter: nextDay.Sub(now)}
+		}
+	}
+	// the stream is acquired before the request is counted, so a rejected stream is not counted in the requests
+	streaming := limit.ConcurrentStreams > 0 && req.ResponseMode.IsStreaming()
+	if streaming {
+		acquired, err := store.AcquireQuotaStream(storage.QuotaStream{ID: messageID, AppNamespace: req.AppNamespace, AppName: req.APPName, User: user, ExpiresAt: now.Add(quotaStreamLease)}, limit.ConcurrentStreams)
+		if err != nil {
+			return nil, err
+		}
+		if !acquired {
+			return nil, &QuotaExceededError{Msg: "too many streaming chats at the same time", RetryAfter: quotaStreamRetryAfter}
+		}
+	}
+	if limit.RequestsPerMinute > 0 {
+		nextMinute := now.Truncate(time.Minute).Add(time.Minute)
+		usage, err := store.AddQuotaUsage(storage.QuotaUsage{AppNamespace: req.AppNamespace, AppName: req.APPName, User: user, Period: "minute:" + now.Format("200601021504"), Requests: 1, ExpiresAt: nextMinute})
+		if err == nil && usage.Requests > limit.RequestsPerMinute {
+			err = &QuotaExceededError{Msg: "too many requests", RetryAfter: nextMinute.Sub(now)}
+		}
+		if err != nil {
+			if streaming {
+				if releaseErr := store.ReleaseQuotaStream(messageID); releaseErr != nil {
+					klog.FromContext(ctx).Error(releaseErr, "failed to release the stream", "messageID", messageID)
+				}
+			}
+			return nil, err
+		}
+	}
+	return func(tokens int64) {
+		logger := klog.FromContext(ctx)
+		if streaming {
+			if err := store.ReleaseQuotaStream(messageID); err != nil {
+				logger.Error(err, "failed to release the stream", "messageID", messageID)
+			}
+		}
+		if tokens <= 0 {
+			return
+		}
+		for u, max := range map[string]int64{"": limit.DailyTokens, user: limit.DailyTokensPerUser} {
+			if max <= 0 {
+				continue
+			}
+			usage := dayUsage(u)
+			usage.Tokens = tokens
+			if _, err := store.AddQuotaUsage(usage); err != nil {
+				logger.Error(err, "failed to add the tokens to the usage", "messageID", messageID, "tokens", tokens)
+			}
+		}
+	}, nil
+}
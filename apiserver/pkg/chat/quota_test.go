@@ -0,0 +1,104 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
+)
+
+func TestCheckQuota(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Spec:       v1alpha1.ApplicationSpec{RateLimit: &v1alpha1.RateLimit{RequestsPerMinute: 3, ConcurrentStreams: 1, DailyTokensPerUser: 100}},
+		Status: v1alpha1.ApplicationStatus{ConditionedStatus: v1alpha1.ConditionedStatus{Conditions: []v1alpha1.Condition{
+			{Type: v1alpha1.TypeReady, Status: corev1.ConditionTrue},
+		}}},
+	}
+	cs := NewChatServer(fake.NewClientBuilder().WithScheme(scheme).WithObjects(app).Build(), false)
+	cs.storage = storage.NewMemoryStorage()
+	req := ChatReqBody{ResponseMode: Streaming}
+	req.APPName, req.AppNamespace = "app", "default"
+
+	// only one stream of a user at the same time
+	release, err := cs.CheckQuota(ctx, req, "alice", "message-1")
+	require.NoError(t, err)
+	_, err = cs.CheckQuota(ctx, req, "alice", "message-2")
+	var quotaErr *QuotaExceededError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, quotaStreamRetryAfter, quotaErr.RetryAfter)
+	release(60)
+
+	// the streams and the requests of other users are not counted
+	otherRelease, err := cs.CheckQuota(ctx, req, "bob", "message-3")
+	require.NoError(t, err)
+	otherRelease(0)
+
+	// the tokens of the last run are counted in the daily tokens
+	release, err = cs.CheckQuota(ctx, req, "alice", "message-4")
+	require.NoError(t, err)
+	release(60)
+	_, err = cs.CheckQuota(ctx, req, "alice", "message-5")
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, "the daily tokens are used up", quotaErr.Msg)
+	// the daily tokens are read without adding a usage
+	usage, err := cs.storage.GetQuotaUsage(storage.QuotaUsage{AppNamespace: "default", AppName: "app", User: "alice", Period: "day:" + time.Now().UTC().Format("20060102")})
+	require.NoError(t, err)
+	assert.Equal(t, int64(120), usage.Tokens)
+	assert.Zero(t, usage.Requests)
+
+	// the rejected streams are not counted in the requests
+	release, err = cs.CheckQuota(ctx, req, "carol", "message-9")
+	require.NoError(t, err)
+	for _, id := range []string{"message-10", "message-11", "message-12"} {
+		_, err = cs.CheckQuota(ctx, req, "carol", id)
+		require.ErrorAs(t, err, &quotaErr)
+		assert.Equal(t, quotaStreamRetryAfter, quotaErr.RetryAfter)
+	}
+	release(0)
+	release, err = cs.CheckQuota(ctx, req, "carol", "message-13")
+	require.NoError(t, err)
+	release(0)
+
+	// the requests per minute
+	req.ResponseMode = Blocking
+	app.Spec.RateLimit.DailyTokensPerUser = 0
+	require.NoError(t, cs.systemCli.Update(ctx, app))
+	_, err = cs.CheckQuota(ctx, req, "bob", "message-6")
+	require.NoError(t, err)
+	_, err = cs.CheckQuota(ctx, req, "bob", "message-7")
+	require.NoError(t, err)
+	_, err = cs.CheckQuota(ctx, req, "bob", "message-8")
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, "too many requests", quotaErr.Msg)
+	assert.LessOrEqual(t, quotaErr.RetryAfter.Seconds(), float64(60))
+}
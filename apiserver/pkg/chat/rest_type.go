@@ -66,6 +66,8 @@ type ChatReqBody struct {
 	Query string `json:"query" form:"query" binding:"required" example:"旷工最小计算单位为多少天？"`
 	// Files this conversation will use in the context
 	Files []string `json:"files" form:"files" example:"test.pdf,song.mp3"`
+	// Inputs are the values of the input variables of the application, by the names of the variables
+	Inputs map[string]any `json:"inputs,omitempty" form:"-"`
 	// ResponseMode:
 	// * Blocking - means the response is returned in a blocking manner
 	// * Streaming - means the response will use Server-Sent Events
@@ -87,6 +89,8 @@ type ChatRespBody struct {
 	CreatedAt time.Time `json:"created_at" example:"2023-12-21T10:21:06.389359092+08:00"`
 	// References is the list of references
 	References []retriever.Reference `json:"references,omitempty"`
+	// Structured is the answer parsed by the output schema of the application, only set if it has an output schema
+	Structured any `json:"structured,omitempty" swaggertype:"object"`
 	// Latency(ms) is how much time the server cost to process a certain request.
 	Latency int64 `json:"latency,omitempty" example:"1000"`
 	// Documents in this chat
@@ -101,6 +105,8 @@ type DocumentRespBody struct {
 
 type ErrorResp struct {
 	Err string `json:"error" example:"conversation is not found"`
+	// RetryAfter is the seconds to wait before retrying, only set when a rate limit of the application is reached
+	RetryAfter int64 `json:"retry_after,omitempty" example:"30"`
 }
 
 type SimpleResp struct {
@@ -23,11 +23,13 @@ import (
 
 	"gorm.io/gorm"
 
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
 	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
 )
 
 var (
 	ErrConversationNotFound = errors.New("conversation is not found")
+	ErrMessageTraceNotFound = errors.New("message trace is not found")
 )
 
 // Conversation represent a conversation in storage
@@ -41,6 +43,10 @@ type Conversation struct {
 	User         string         `gorm:"column:user;type:string;comment:the conversation chat user" json:"-"`
 	Debug        bool           `gorm:"column:debug;type:bool;comment:debug mode" json:"-"`
 	DeletedAt    gorm.DeletedAt `gorm:"column:deleted_at;type:time;comment:the time the conversation deleted at" json:"-"`
+	// Summary is the summary of the earlier messages made by the summary memory, SummaryMessages is the number of
+	// history messages in it
+	Summary         string `gorm:"column:summary;type:string;comment:the summary of the earlier messages" json:"-"`
+	SummaryMessages int    `gorm:"column:summary_messages;type:int;comment:the number of history messages in the summary" json:"-"`
 	// icon only valid in conversation list api
 	Icon string `gorm:"-" json:"icon"`
 }
@@ -62,6 +68,10 @@ type Message struct {
 	RawFiles   string     `gorm:"column:files;type:string;comment:input files" json:"-"`
 	Answer     string     `gorm:"column:answer;type:string;comment:ai response" json:"answer" example:"旷工最小计算单位为0.5天。"`
 	References References `gorm:"column:references;type:json;comment:references" json:"references,omitempty"`
+	// Blocked is true if the question or the answer is blocked by a guardrail
+	Blocked bool `gorm:"column:blocked;type:bool;comment:blocked by guardrails" json:"blocked,omitempty"`
+	// Guardrails are the failed checks of the guardrails, blocked, masked or flagged
+	Guardrails Guardrails `gorm:"column:guardrails;type:json;comment:failed guardrail checks" json:"guardrails,omitempty"`
 
 	// For Action Upload
 	Documents []Document `gorm:"foreignKey:MessageID" json:"documents"`
@@ -83,6 +93,41 @@ type Document struct {
 
 type References []retriever.Reference
 
+// MessageTrace is the execution trace of the app nodes for a chat message
+type MessageTrace struct {
+	MessageID      string     `gorm:"column:message_id;primaryKey;type:uuid;comment:message id" json:"message_id" example:"4f3546dd-5404-4bf8-a3bc-4fa3f9a7ba24"`
+	ConversationID string     `gorm:"column:conversation_id;type:uuid;comment:conversation id" json:"conversation_id" example:"5a41f3ca-763b-41ec-91c3-4bbbb00736d0"`
+	CreatedAt      time.Time  `gorm:"column:created_at;type:time;autoCreateTime;comment:the time the trace created at" json:"created_at" example:"2023-12-21T10:21:06.389359092+08:00"`
+	Nodes          NodeTraces `gorm:"column:nodes;type:json;comment:trace of every app node" json:"nodes"`
+}
+
+// QuotaUsage is the requests and tokens used in a period by a user of an application,
+// or by all the users of the application if User is empty
+type QuotaUsage struct {
+	AppNamespace string `gorm:"column:app_namespace;primaryKey;type:string;comment:app namespace"`
+	AppName      string `gorm:"column:app_name;primaryKey;type:string;comment:app name"`
+	User         string `gorm:"column:user;primaryKey;type:string;comment:the chat user, empty for all the users of the app"`
+	// Period is the time window of the usage, like minute:202401021021 or day:20240102
+	Period    string    `gorm:"column:period;primaryKey;type:string;comment:the time window of the usage"`
+	Requests  int64     `gorm:"column:requests;type:int;comment:the number of chat requests"`
+	Tokens    int64     `gorm:"column:tokens;type:int;comment:the number of llm tokens used"`
+	ExpiresAt time.Time `gorm:"column:expires_at;type:time;comment:the time the period ends, the usage is deleted after it"`
+}
+
+// QuotaStream is a streaming chat in progress, which is counted in the concurrent streams until it is released
+// or expired, so the stream of a restarted apiserver is not counted forever
+type QuotaStream struct {
+	ID           string    `gorm:"column:id;primaryKey;type:uuid;comment:message id"`
+	AppNamespace string    `gorm:"column:app_namespace;type:string;comment:app namespace"`
+	AppName      string    `gorm:"column:app_name;type:string;comment:app name"`
+	User         string    `gorm:"column:user;type:string;comment:the chat user"`
+	ExpiresAt    time.Time `gorm:"column:expires_at;type:time;comment:the time the stream is not counted after"`
+}
+
+type NodeTraces []base.NodeTrace
+
+type Guardrails []base.GuardrailEvent
+
 func (Conversation) TableName() string {
 	return "app_chat_conversation"
 }
@@ -95,10 +140,23 @@ func (Document) TableName() string {
 	return "app_chat_document"
 }
 
+func (MessageTrace) TableName() string {
+	return "app_chat_message_trace"
+}
+
+func (QuotaUsage) TableName() string {
+	return "app_chat_quota_usage"
+}
+
+func (QuotaStream) TableName() string {
+	return "app_chat_quota_stream"
+}
+
 type Storage interface {
 	ConversationStorage
 	MessageStorage
 	DocumentStorage
+	QuotaStorage
 }
 
 // ConversationStorage interface
@@ -130,8 +188,29 @@ type MessageStorage interface {
 	FindExistingMessage(conversationID, messageID string, opts ...SearchOption) (*Message, error)
 	// CountMessages count how many messages is about this app
 	CountMessages(appName, appNamespace string) (int64, error)
+	// UpdateMessageTrace creates or updates the execution trace of a message.
+	UpdateMessageTrace(*MessageTrace) error
+	// FindMessageTrace finds the execution trace of a message in the conversation.
+	//
+	// The conversation is searched with the options first, so only the user who can see the conversation can get the trace.
+	FindMessageTrace(conversationID, messageID string, opts ...SearchOption) (*MessageTrace, error)
 }
 
 type DocumentStorage interface {
 	// TO BE DEFINED
 }
+
+// QuotaStorage keeps the counters of the rate limits of the applications
+type QuotaStorage interface {
+	// AddQuotaUsage adds the requests and tokens of the usage to the counters of its period,
+	// and returns the counters after it. The expired usages of the user are deleted.
+	AddQuotaUsage(usage QuotaUsage) (*QuotaUsage, error)
+	// GetQuotaUsage returns the counters of the period of the usage, which are zero if there is no usage
+	// in the period or it is expired. The counters are not changed.
+	GetQuotaUsage(usage QuotaUsage) (*QuotaUsage, error)
+	// AcquireQuotaStream adds the stream if the user has less than max streams of the application not expired,
+	// and returns false if not.
+	AcquireQuotaStream(stream QuotaStream, max int64) (bool, error)
+	// ReleaseQuotaStream removes the stream, **not** return error if the stream is not found
+	ReleaseQuotaStream(id string) error
+}
@@ -18,7 +18,9 @@ package storage
 
 import (
 	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 var _ Storage = (*MemoryStorage)(nil)
@@ -26,6 +28,9 @@ var _ Storage = (*MemoryStorage)(nil)
 type MemoryStorage struct {
 	mu            sync.Mutex
 	conversations map[string]Conversation
+	traces        map[string]MessageTrace
+	quotaUsages   map[string]QuotaUsage
+	quotaStreams  map[string]QuotaStream
 }
 
 func (m *MemoryStorage) CountMessages(appName, appNamespace string) (res int64, err error) {
@@ -44,6 +49,9 @@ func (m *MemoryStorage) CountMessages(appName, appNamespace string) (res int64,
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
 		conversations: make(map[string]Conversation),
+		traces:        make(map[string]MessageTrace),
+		quotaUsages:   make(map[string]QuotaUsage),
+		quotaStreams:  make(map[string]QuotaStream),
 	}
 }
 
@@ -180,3 +188,88 @@ func (m *MemoryStorage) FindExistingDocument(conversationID string, messageID st
 	}
 	return nil, nil
 }
+
+// UpdateMessageTrace creates or updates the execution trace of a message in the MemoryStorage.
+func (m *MemoryStorage) UpdateMessageTrace(trace *MessageTrace) error {
+	m.mu.Lock()
+	m.traces[trace.MessageID] = *trace
+	m.mu.Unlock()
+	return nil
+}
+
+// FindMessageTrace finds the execution trace of a message in the conversation from MemoryStorage.
+func (m *MemoryStorage) FindMessageTrace(conversationID, messageID string, opts ...SearchOption) (*MessageTrace, error) {
+	if _, err := m.FindExistingConversation(conversationID, opts...); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	v, ok := m.traces[messageID]
+	m.mu.Unlock()
+	if !ok || v.ConversationID != conversationID {
+		return nil, ErrMessageTraceNotFound
+	}
+	return &v, nil
+}
+
+// AddQuotaUsage adds the usage to the counters of its period in the MemoryStorage.
+func (m *MemoryStorage) AddQuotaUsage(usage QuotaUsage) (*QuotaUsage, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k, v := range m.quotaUsages {
+		if v.ExpiresAt.Before(now) {
+			delete(m.quotaUsages, k)
+		}
+	}
+	key := strings.Join([]string{usage.AppNamespace, usage.AppName, usage.User, usage.Period}, "/")
+	if v, ok := m.quotaUsages[key]; ok {
+		usage.Requests += v.Requests
+		usage.Tokens += v.Tokens
+		usage.ExpiresAt = v.ExpiresAt
+	}
+	m.quotaUsages[key] = usage
+	return &usage, nil
+}
+
+// GetQuotaUsage returns the counters of the period of the usage in the MemoryStorage.
+func (m *MemoryStorage) GetQuotaUsage(usage QuotaUsage) (*QuotaUsage, error) {
+	key := strings.Join([]string{usage.AppNamespace, usage.AppName, usage.User, usage.Period}, "/")
+	m.mu.Lock()
+	v, ok := m.quotaUsages[key]
+	m.mu.Unlock()
+	usage.Requests, usage.Tokens = 0, 0
+	if ok && !v.ExpiresAt.Before(time.Now()) {
+		usage.Requests, usage.Tokens, usage.ExpiresAt = v.Requests, v.Tokens, v.ExpiresAt
+	}
+	return &usage, nil
+}
+
+// AcquireQuotaStream adds the stream to the MemoryStorage if the user has less than max streams.
+func (m *MemoryStorage) AcquireQuotaStream(stream QuotaStream, max int64) (bool, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var count int64
+	for k, v := range m.quotaStreams {
+		if v.ExpiresAt.Before(now) {
+			delete(m.quotaStreams, k)
+			continue
+		}
+		if v.AppNamespace == stream.AppNamespace && v.AppName == stream.AppName && v.User == stream.User {
+			count++
+		}
+	}
+	if count >= max {
+		return false, nil
+	}
+	m.quotaStreams[stream.ID] = stream
+	return true, nil
+}
+
+// ReleaseQuotaStream removes the stream from the MemoryStorage.
+func (m *MemoryStorage) ReleaseQuotaStream(id string) error {
+	m.mu.Lock()
+	delete(m.quotaStreams, id)
+	m.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+func TestMemoryStorageMessageTrace(t *testing.T) {
+	t.Parallel()
+	s := NewMemoryStorage()
+	require.NoError(t, s.UpdateConversation(&Conversation{ID: "c1", AppNamespace: "default", AppName: "app", User: "alice"}))
+	require.NoError(t, s.UpdateConversation(&Conversation{ID: "c2", AppNamespace: "default", AppName: "app", User: "alice"}))
+
+	trace := &MessageTrace{
+		MessageID:      "m1",
+		ConversationID: "c1",
+		CreatedAt:      time.Now(),
+		Nodes: NodeTraces{
+			{Name: "input", Kind: "input", InputKeys: []string{"question"}},
+			{Name: "chain", Group: "chain", Kind: "llmchain", Latency: 10, OutputKeys: []string{"_answer"}, Error: "failed"},
+		},
+	}
+	require.NoError(t, s.UpdateMessageTrace(trace))
+	got, err := s.FindMessageTrace("c1", "m1", WithUser("alice"))
+	require.NoError(t, err)
+	assert.Equal(t, trace, got)
+
+	// the trace is replaced by the update
+	trace.Nodes = append(trace.Nodes, base.NodeTrace{Name: "output", Kind: "output"})
+	require.NoError(t, s.UpdateMessageTrace(trace))
+	got, err = s.FindMessageTrace("c1", "m1")
+	require.NoError(t, err)
+	assert.Len(t, got.Nodes, 3)
+
+	_, err = s.FindMessageTrace("c1", "m2")
+	assert.ErrorIs(t, err, ErrMessageTraceNotFound)
+	// the trace of a message in another conversation
+	_, err = s.FindMessageTrace("c2", "m1")
+	assert.ErrorIs(t, err, ErrMessageTraceNotFound)
+	_, err = s.FindMessageTrace("c3", "m1")
+	assert.ErrorIs(t, err, ErrConversationNotFound)
+	_, err = s.FindMessageTrace("c1", "m1", WithUser("bob"))
+	assert.ErrorIs(t, err, ErrConversationNotFound)
+}
+
+func TestMemoryStorageQuotaUsage(t *testing.T) {
+	t.Parallel()
+	s := NewMemoryStorage()
+	day := QuotaUsage{AppNamespace: "default", AppName: "app", User: "alice", Period: "day:20240102", ExpiresAt: time.Now().Add(time.Hour)}
+	got, err := s.GetQuotaUsage(day)
+	require.NoError(t, err)
+	assert.Zero(t, got.Tokens)
+
+	add := day
+	add.Tokens = 10
+	_, err = s.AddQuotaUsage(add)
+	require.NoError(t, err)
+	_, err = s.AddQuotaUsage(add)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		got, err = s.GetQuotaUsage(day)
+		require.NoError(t, err)
+		assert.Equal(t, int64(20), got.Tokens)
+	}
+
+	// the expired usage is not returned
+	expired := QuotaUsage{AppNamespace: "default", AppName: "app", User: "alice", Period: "minute:202401021021", Requests: 1, ExpiresAt: time.Now().Add(-time.Minute)}
+	_, err = s.AddQuotaUsage(expired)
+	require.NoError(t, err)
+	got, err = s.GetQuotaUsage(expired)
+	require.NoError(t, err)
+	assert.Zero(t, got.Requests)
+}
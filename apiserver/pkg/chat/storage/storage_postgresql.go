@@ -19,6 +19,7 @@ package storage
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -31,6 +32,7 @@ import (
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
 	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
 )
 
@@ -57,6 +59,58 @@ func (r References) Value() (driver.Value, error) {
 	return json.Marshal(r)
 }
 
+func (n *NodeTraces) Scan(value interface{}) error {
+	if value == nil {
+		*n = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal JSONB value:%#v", value)
+	}
+
+	result := make([]base.NodeTrace, 0)
+	err := json.Unmarshal(bytes, &result)
+	if err != nil {
+		return err
+	}
+	*n = result
+	return nil
+}
+
+func (n NodeTraces) Value() (driver.Value, error) {
+	if n == nil || len([]base.NodeTrace(n)) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(n)
+}
+
+func (g *Guardrails) Scan(value interface{}) error {
+	if value == nil {
+		*g = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal JSONB value:%#v", value)
+	}
+
+	result := make([]base.GuardrailEvent, 0)
+	err := json.Unmarshal(bytes, &result)
+	if err != nil {
+		return err
+	}
+	*g = result
+	return nil
+}
+
+func (g Guardrails) Value() (driver.Value, error) {
+	if len(g) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(g)
+}
+
 var _ Storage = (*PostgreSQLStorage)(nil)
 
 type PostgreSQLStorage struct {
@@ -124,7 +178,7 @@ func NewPostgreSQLStorage(conn *pgx.Conn) (*PostgreSQLStorage, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := db.AutoMigrate(&Conversation{}, &Message{}, &Document{}); err != nil {
+	if err := db.AutoMigrate(&Conversation{}, &Message{}, &Document{}, &MessageTrace{}, &QuotaUsage{}, &QuotaStream{}); err != nil {
 		return nil, err
 	}
 	customLogger := logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
@@ -264,3 +318,100 @@ func (p *PostgreSQLStorage) FindExistingDocument(conversationID, messageID strin
 	}
 	return document, nil
 }
+
+func (p *PostgreSQLStorage) UpdateMessageTrace(trace *MessageTrace) error {
+	tx := p.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(trace)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	return nil
+}
+
+func (p *PostgreSQLStorage) FindMessageTrace(conversationID, messageID string, opts ...SearchOption) (*MessageTrace, error) {
+	searchOpt := applyOptions(&conversationID, opts...)
+	conversationQuery := Conversation{ID: conversationID}
+	if searchOpt.User != nil {
+		conversationQuery.User = *searchOpt.User
+	}
+	if searchOpt.AppName != nil {
+		conversationQuery.AppName = *searchOpt.AppName
+	}
+	if searchOpt.AppNamespace != nil {
+		conversationQuery.AppNamespace = *searchOpt.AppNamespace
+	}
+	conversationQuery.DeletedAt.Valid = false
+	conversation := &Conversation{}
+	tx := p.db.First(conversation, conversationQuery)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	trace := &MessageTrace{}
+	tx = p.db.First(trace, MessageTrace{MessageID: messageID, ConversationID: conversationID})
+	if tx.Error != nil {
+		if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageTraceNotFound
+		}
+		return nil, tx.Error
+	}
+	return trace, nil
+}
+
+func (p *PostgreSQLStorage) AddQuotaUsage(usage QuotaUsage) (*QuotaUsage, error) {
+	tx := p.db.Where("app_namespace = ? AND app_name = ? AND \"user\" = ? AND expires_at < ?", usage.AppNamespace, usage.AppName, usage.User, time.Now()).Delete(&QuotaUsage{})
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	// the counters are added in the database, so the requests of all the apiserver replicas are counted
+	tx = p.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "app_namespace"}, {Name: "app_name"}, {Name: "user"}, {Name: "period"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"requests": gorm.Expr("app_chat_quota_usage.requests + ?", usage.Requests),
+			"tokens":   gorm.Expr("app_chat_quota_usage.tokens + ?", usage.Tokens),
+		}),
+	}, clause.Returning{}).Create(&usage)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return &usage, nil
+}
+
+func (p *PostgreSQLStorage) GetQuotaUsage(usage QuotaUsage) (*QuotaUsage, error) {
+	res := QuotaUsage{}
+	tx := p.db.Where("app_namespace = ? AND app_name = ? AND \"user\" = ? AND period = ? AND expires_at >= ?", usage.AppNamespace, usage.AppName, usage.User, usage.Period, time.Now()).Limit(1).Find(&res)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	usage.Requests, usage.Tokens = 0, 0
+	if tx.RowsAffected > 0 {
+		usage.Requests, usage.Tokens, usage.ExpiresAt = res.Requests, res.Tokens, res.ExpiresAt
+	}
+	return &usage, nil
+}
+
+func (p *PostgreSQLStorage) AcquireQuotaStream(stream QuotaStream, max int64) (acquired bool, err error) {
+	err = p.db.Transaction(func(tx *gorm.DB) error {
+		// only one replica counts the streams of the user at the same time
+		key := fmt.Sprintf("%s/%s/%s", stream.AppNamespace, stream.AppName, stream.User)
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+			return err
+		}
+		query := tx.Where("app_namespace = ? AND app_name = ? AND \"user\" = ?", stream.AppNamespace, stream.AppName, stream.User).Session(&gorm.Session{})
+		if err := query.Where("expires_at < ?", time.Now()).Delete(&QuotaStream{}).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := query.Model(&QuotaStream{}).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= max {
+			return nil
+		}
+		acquired = true
+		return tx.Create(&stream).Error
+	})
+	return acquired, err
+}
+
+func (p *PostgreSQLStorage) ReleaseQuotaStream(id string) error {
+	return p.db.Delete(&QuotaStream{ID: id}).Error
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+func TestNodeTracesValueScan(t *testing.T) {
+	t.Parallel()
+	startedAt := time.Date(2024, 1, 2, 10, 21, 6, 0, time.UTC)
+	for name, nodes := range map[string]NodeTraces{
+		"nil":   nil,
+		"empty": {},
+		"nodes": {
+			{Name: "input", Kind: "input", StartedAt: startedAt, InputKeys: []string{"question"}},
+			{Name: "chain", Group: "chain", Kind: "llmchain", StartedAt: startedAt, Latency: 10, Events: []base.TraceEvent{{Type: base.TraceEventLLMStart, Time: startedAt, Content: "Human: hi"}}},
+		},
+	} {
+		value, err := nodes.Value()
+		require.NoError(t, err, name)
+		got := NodeTraces{{Name: "stale"}}
+		require.NoError(t, got.Scan(value), name)
+		if len(nodes) == 0 {
+			// an empty trace is written as NULL, and read back as nil
+			assert.Nil(t, value, name)
+			assert.Nil(t, got, name)
+			continue
+		}
+		assert.Equal(t, nodes, got, name)
+	}
+}
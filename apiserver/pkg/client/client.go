@@ -27,13 +27,16 @@ import (
 	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/utils/env"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	agentv1alpha1 "github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
 	apichain "github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
 	documentloaderv1alpha1 "github.com/kubeagi/arcadia/api/app-node/documentloader/v1alpha1"
+	apiguardrail "github.com/kubeagi/arcadia/api/app-node/guardrail/v1alpha1"
 	apiprompt "github.com/kubeagi/arcadia/api/app-node/prompt/v1alpha1"
 	apiretriever "github.com/kubeagi/arcadia/api/app-node/retriever/v1alpha1"
+	apirouter "github.com/kubeagi/arcadia/api/app-node/router/v1alpha1"
 	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
 	evaluationarcadiav1alpha1 "github.com/kubeagi/arcadia/api/evaluation/v1alpha1"
 	"github.com/kubeagi/arcadia/apiserver/pkg/oidc"
@@ -68,6 +71,17 @@ func GetClient(idtoken *string) (client.Client, error) {
 	return cli, nil
 }
 
+// NewCache creates the informer cache with the system config, it should be started by the caller
+func NewCache() (cache.Cache, error) {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(cfg, cache.Options{
+		Scheme: Scheme,
+	})
+}
+
 var (
 	Scheme = runtime.NewScheme()
 )
@@ -85,4 +99,6 @@ func init() {
 	utilruntime.Must(batchv1.AddToScheme(Scheme))
 	utilruntime.Must(agentv1alpha1.AddToScheme(Scheme))
 	utilruntime.Must(documentloaderv1alpha1.AddToScheme(Scheme))
+	utilruntime.Must(apirouter.AddToScheme(Scheme))
+	utilruntime.Must(apiguardrail.AddToScheme(Scheme))
 }
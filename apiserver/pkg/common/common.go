@@ -211,6 +211,28 @@ func GetAppCategory(app *v1alpha1.Application) []*string {
 	return category
 }
 
+// GetAppInputVariables returns the input variables of the app, so the front-ends can render a form for them
+func GetAppInputVariables(app *v1alpha1.Application) []*generated.ApplicationInputVariable {
+	variables := make([]*generated.ApplicationInputVariable, 0, len(app.Spec.InputVariables))
+	for _, v := range app.Spec.InputVariables {
+		variableType := v.Type
+		if variableType == "" {
+			variableType = v1alpha1.InputVariableTypeText
+		}
+		variables = append(variables, &generated.ApplicationInputVariable{
+			Name:        v.Name,
+			DisplayName: pointer.String(v.DisplayName),
+			Description: pointer.String(v.Description),
+			Type:        string(variableType),
+			Required:    pointer.Bool(v.Required),
+			Default:     pointer.String(v.Default),
+			Options:     v.Options,
+			MaxLength:   pointer.Int(v.MaxLength),
+		})
+	}
+	return variables
+}
+
 func DeleteAllOptions(input *generated.DeleteCommonInput) ([]client.DeleteAllOfOption, error) {
 	if input.Namespace == "" {
 		return nil, errors.New("namespace is empty, please check your request args")
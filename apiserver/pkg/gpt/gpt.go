@@ -62,6 +62,7 @@ func app2gpt(ctx context.Context, app *v1alpha1.Application, c client.Client) (*
 		ShowNextGuide:      pointer.Bool(app.Spec.ShowNextGuide),
 		EnableUploadFile:   app.Spec.EnableUploadFile,
 		NotReadyReasonCode: pointer.String(string(GetGPTNotReadyReasonCode(app))),
+		InputVariables:     common.GetAppInputVariables(app),
 	}
 	return gpt, nil
 }
@@ -136,6 +136,8 @@ func knowledgebase2model(ctx context.Context, c client.Client, knowledgebase *v1
 						Path:            detail.Path,
 						Phase:           string(detail.Phase),
 						TimeCost:        int(detail.TimeCost),
+						ProcessedChunks: pointer.Int(int(detail.ProcessedChunks)),
+						TotalChunks:     pointer.Int(int(detail.TotalChunks)),
 						UpdateTimestamp: new(time.Time),
 						Version:         detail.Version,
 						LatestVersion:   detailStat.VersionID,
@@ -192,6 +194,7 @@ func knowledgebase2model(ctx context.Context, c client.Client, knowledgebase *v1
 		ChunkSize:        &embeddingOptions.ChunkSize,
 		ChunkOverlap:     embeddingOptions.ChunkOverlap,
 		BatchSize:        &embeddingOptions.BatchSize,
+		Splitter:         pointer.String(string(embeddingOptions.Splitter)),
 
 		// Status info
 		Status:  &status,
@@ -285,6 +288,7 @@ func CreateKnowledgeBase(ctx context.Context, c client.Client, input generated.C
 				ChunkSize:    chunkSize,
 				ChunkOverlap: chunkOverlap,
 				BatchSize:    batchSize,
+				Splitter:     v1alpha1.SplitterType(pointer.StringDeref(input.Splitter, string(v1alpha1.SplitterTypeRecursiveCharacter))),
 			},
 		},
 	}
@@ -372,6 +376,9 @@ func UpdateKnowledgeBase(ctx context.Context, c client.Client, input *generated.
 	if input.BatchSize != nil {
 		kb.Spec.BatchSize = *input.BatchSize
 	}
+	if input.Splitter != nil {
+		kb.Spec.Splitter = v1alpha1.SplitterType(*input.Splitter)
+	}
 
 	err = c.Update(ctx, kb)
 	if err != nil {
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
@@ -35,9 +36,12 @@ import (
 	"github.com/kubeagi/arcadia/apiserver/config"
 	"github.com/kubeagi/arcadia/apiserver/pkg/auth"
 	"github.com/kubeagi/arcadia/apiserver/pkg/chat"
+	"github.com/kubeagi/arcadia/apiserver/pkg/chat/storage"
 	"github.com/kubeagi/arcadia/apiserver/pkg/client"
 	"github.com/kubeagi/arcadia/apiserver/pkg/oidc"
 	"github.com/kubeagi/arcadia/apiserver/pkg/requestid"
+	"github.com/kubeagi/arcadia/pkg/appruntime"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
 )
 
 const (
@@ -66,6 +70,8 @@ func NewChatService(cli runtimeclient.Client, isGpts bool) (*ChatService, error)
 // @Param			request		body		chat.ChatReqBody	true	"query params"
 // @Success		200			{object}	chat.ChatRespBody	"blocking mode, will return all field; streaming mode, only conversation_id, message and created_at will be returned"
 // @Failure		400			{object}	chat.ErrorResp
+// @Failure		422			{object}	chat.ErrorResp
+// @Failure		429			{object}	chat.ErrorResp	"a rate limit of the application is reached, retry after retry_after seconds"
 // @Failure		500			{object}	chat.ErrorResp
 // @Router			/chat [post]
 func (cs *ChatService) ChatHandler() gin.HandlerFunc {
@@ -87,6 +93,33 @@ func (cs *ChatService) ChatHandler() gin.HandlerFunc {
 		logger := klog.FromContext(c.Request.Context())
 		chatTimeoutSecond := pointer.Float64(WaitTimeoutForChatStreaming)
 
+		// check the rate limits of the application before it runs
+		user, _ := c.Request.Context().Value(auth.UserNameContextKey).(string)
+		if user == "" {
+			user = "ip:" + c.ClientIP()
+		}
+		release, err := cs.server.CheckQuota(c.Request.Context(), req, user, messageID)
+		if err != nil {
+			var quotaErr *chat.QuotaExceededError
+			if errors.As(err, &quotaErr) {
+				retryAfter := int64(quotaErr.RetryAfter.Seconds() + 0.5)
+				c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+				c.JSON(http.StatusTooManyRequests, chat.ErrorResp{Err: err.Error(), RetryAfter: retryAfter})
+				logger.Info("rate limit exceeded", "user", user, "retryAfter", retryAfter, "reason", err.Error())
+				return
+			}
+			c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
+			logger.Error(err, "failed to check the rate limits")
+			return
+		}
+		// the llm calls in the run add their tokens to usage, which are counted in the daily tokens after the run
+		usage := &base.TokenUsage{}
+		runCtx := base.WithTokenUsage(c.Request.Context(), usage)
+		appRun := func(respStream chan string) (*chat.ChatRespBody, error) {
+			defer func() { release(usage.Total()) }()
+			return cs.server.AppRun(runCtx, req, respStream, messageID, chatTimeoutSecond)
+		}
+
 		if req.ResponseMode.IsStreaming() {
 			buf := strings.Builder{}
 			// handle chat streaming mode
@@ -103,7 +136,7 @@ func (cs *ChatService) ChatHandler() gin.HandlerFunc {
 						}
 					}
 				}()
-				response, err = cs.server.AppRun(c.Request.Context(), req, respStream, messageID, chatTimeoutSecond)
+				response, err = appRun(respStream)
 				if err != nil {
 					c.SSEvent("error", chat.ChatRespBody{
 						MessageID:      messageID,
@@ -174,8 +207,20 @@ func (cs *ChatService) ChatHandler() gin.HandlerFunc {
 			logger.Info("end to receive messages")
 		} else {
 			// handle chat blocking mode
-			response, err = cs.server.AppRun(c.Request.Context(), req, nil, messageID, chatTimeoutSecond)
+			response, err = appRun(nil)
 			if err != nil {
+				var inputErr *appruntime.InputVariableError
+				if errors.As(err, &inputErr) {
+					c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: err.Error()})
+					logger.Error(err, "invalid input variables")
+					return
+				}
+				var structuredErr *base.StructuredOutputError
+				if errors.As(err, &structuredErr) {
+					c.JSON(http.StatusUnprocessableEntity, chat.ErrorResp{Err: err.Error()})
+					logger.Error(err, "the answer is not valid by the output schema")
+					return
+				}
 				c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
 				logger.Error(err, "error resp")
 				return
@@ -394,6 +439,54 @@ func (cs *ChatService) ReferenceHandler() gin.HandlerFunc {
 	}
 }
 
+// @Summary	get one message execution trace
+// @Schemes
+// @Description	get the execution trace of every app node for one message
+// @Tags			application
+// @Accept			json
+// @Produce		json
+// @Param			namespace	header		string				true	"namespace this request is in"
+// @Param			messageID	path		string				true	"messageID"
+// @Param			request		body		chat.MessageReqBody	true	"query params"
+// @Success		200			{object}	storage.MessageTrace
+// @Failure		400			{object}	chat.ErrorResp
+// @Failure		404			{object}	chat.ErrorResp
+// @Failure		500			{object}	chat.ErrorResp
+// @Router			/chat/messages/{messageID}/trace [post]
+func (cs *ChatService) TraceHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		messageID := c.Param("messageID")
+		if messageID == "" {
+			err := errors.New("messageID is required")
+			klog.FromContext(c.Request.Context()).Error(err, "messageID is required")
+			c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		req := chat.MessageReqBody{
+			MessageID: messageID,
+		}
+		req.AppNamespace = NamespaceInHeader(c)
+		if err := c.ShouldBindJSON(&req); err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "traceHandler: error binding json")
+			c.JSON(http.StatusBadRequest, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		req.MessageID = messageID
+		resp, err := cs.server.GetMessageTrace(c.Request.Context(), req)
+		if err != nil {
+			klog.FromContext(c.Request.Context()).Error(err, "error get message trace")
+			if errors.Is(err, storage.ErrMessageTraceNotFound) || errors.Is(err, storage.ErrConversationNotFound) || errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, chat.ErrorResp{Err: err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, chat.ErrorResp{Err: err.Error()})
+			return
+		}
+		klog.FromContext(c.Request.Context()).V(3).Info("get message trace done", "req", req)
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
 // @Summary	get app's prompt starters
 // @Schemes
 // @Description	get app's prompt starters
@@ -463,6 +556,7 @@ func registerChat(g *gin.RouterGroup, conf config.ServerConfig) {
 
 	g.POST("/messages", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.HistoryHandler())                         // messages history
 	g.POST("/messages/:messageID/references", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.ReferenceHandler()) // messages reference
+	g.POST("/messages/:messageID/trace", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.TraceHandler())          // messages execution trace
 
 	g.POST("/prompt-starter", auth.AuthInterceptor(conf.EnableOIDC, oidc.Verifier, v1alpha1.GroupVersion, "get", "applications"), requestid.RequestIDInterceptor(), chatService.PromptStartersHandler())
 }
@@ -29,8 +29,12 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/minio/minio-go/v7"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kubeagi/arcadia/api/base/v1alpha1"
@@ -41,7 +45,6 @@ import (
 	"github.com/kubeagi/arcadia/apiserver/pkg/common"
 	apiserverds "github.com/kubeagi/arcadia/apiserver/pkg/datasource"
 	"github.com/kubeagi/arcadia/apiserver/pkg/oidc"
-	"github.com/kubeagi/arcadia/apiserver/pkg/versioneddataset"
 	"github.com/kubeagi/arcadia/pkg/cache"
 	pkgconfig "github.com/kubeagi/arcadia/pkg/config"
 	"github.com/kubeagi/arcadia/pkg/datasource"
@@ -812,7 +815,10 @@ func (m *minioAPI) GetDownloadLink(ctx *gin.Context) {
 
 // @Summary	Create web cralwer file
 // @Schemes
-// @Description	Create a web crawler file which contains crawer params
+// @Description	Create a web crawler file which contains crawer params, and add it to the versioneddataset, whose controller crawls the website.
+// @Description	The crawled pages are stored as html files under the directory named after the datasource, with their url and title in the object tags.
+// @Description	The result of the crawl is the status of the file in the versioneddataset, and 409 is returned if the website is being crawled.
+// @Description	Calling it again crawls the website again, and the pages which are not found any more are removed.
 // @Tags			MinioAPI
 // @Accept			json
 // @Produce		json
@@ -820,6 +826,7 @@ func (m *minioAPI) GetDownloadLink(ctx *gin.Context) {
 // @Param			namespace	header		string				true	"Name of the bucket"
 // @Success		200			{object}	string
 // @Failure		400			{object}	map[string]string
+// @Failure		409			{object}	map[string]string
 // @Failure		500			{object}	map[string]string
 // @Router			/bff/versioneddataset/files/webcrawler [post]
 func (m *minioAPI) CreateWebCrawlerFile(ctx *gin.Context) {
@@ -835,8 +842,8 @@ func (m *minioAPI) CreateWebCrawlerFile(ctx *gin.Context) {
 	namespace := NamespaceInHeader(ctx)
 
 	// read versioneddataset
-	vds, err := versioneddataset.GetVersionedDataset(ctx.Request.Context(), m.client, body.VersionedDataset, namespace)
-	if err != nil {
+	vds := &v1alpha1.VersionedDataset{}
+	if err := m.client.Get(ctx.Request.Context(), types.NamespacedName{Namespace: namespace, Name: body.VersionedDataset}, vds); err != nil {
 		klog.Errorf("failed to get versioneddataset error %s", err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 			"message": fmt.Sprintf("failed to get versioneddataset error %s", err.Error()),
@@ -858,6 +865,13 @@ func (m *minioAPI) CreateWebCrawlerFile(ctx *gin.Context) {
 		})
 		return
 	}
+	path := fmt.Sprintf("%s-%s%s", ds.Namespace, ds.Name, datasource.WebCrawlerFileSuffix)
+	if webCrawling(vds, ds.Namespace, ds.Name, path) {
+		ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"message": "the website is being crawled, try again after it is done",
+		})
+		return
+	}
 
 	if body.Params.URL == nil {
 		body.Params.URL = ds.Endpoint.URL
@@ -865,6 +879,12 @@ func (m *minioAPI) CreateWebCrawlerFile(ctx *gin.Context) {
 	if body.Params.IntervalTime == nil {
 		body.Params.IntervalTime = ds.Web.RecommendIntervalTime
 	}
+	if _, err := datasource.NewWeb(ctx.Request.Context(), pointer.StringDeref(body.Params.URL, "")); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"message": fmt.Sprintf("invalid web crawler params: %s", err.Error()),
+		})
+		return
+	}
 	content, err := json.Marshal(body.Params)
 	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
@@ -882,7 +902,7 @@ func (m *minioAPI) CreateWebCrawlerFile(ctx *gin.Context) {
 		return
 	}
 
-	object := fmt.Sprintf("dataset/%s/%s/%s-%s.web", vds.Dataset.Name, vds.Version, ds.Namespace, ds.Name)
+	object := fmt.Sprintf("dataset/%s/%s/%s", vds.Spec.Dataset.Name, vds.Spec.Version, path)
 	_, err = source.Client.PutObject(ctx.Request.Context(), namespace, object, bytes.NewReader(content), int64(len(content)), minio.PutObjectOptions{})
 	if err != nil {
 		klog.Errorf("failed to put webcrawler file error %s", err)
@@ -892,7 +912,86 @@ func (m *minioAPI) CreateWebCrawlerFile(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"bucket": namespace, "object": object})
+	// the versioneddataset controller crawls the pages, and records the result in the status of the file
+	if err := requestWebCrawl(ctx.Request.Context(), m.client, vds, ds.Namespace, ds.Name, path); err != nil {
+		klog.Errorf("failed to request the web crawl error %s", err)
+		status := http.StatusInternalServerError
+		if apierrors.IsConflict(err) {
+			status = http.StatusConflict
+		}
+		ctx.AbortWithStatusJSON(status, gin.H{
+			"message": fmt.Sprintf("failed to request the web crawl error %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"bucket": namespace, "object": object, "pages": datasource.WebPagesPrefix(object)})
+}
+
+// webCrawling returns whether the web crawler file of the datasource is being crawled
+func webCrawling(vds *v1alpha1.VersionedDataset, namespace, name, path string) bool {
+	for _, fs := range vds.Status.Files {
+		if fs.Kind != "Datasource" || fs.Name != name || pointer.StringDeref(fs.Namespace, vds.Namespace) != namespace {
+			continue
+		}
+		for _, f := range fs.Status {
+			if f.Path == path {
+				return f.Phase == v1alpha1.FileProcessPhaseProcessing
+			}
+		}
+	}
+	return false
+}
+
+// requestWebCrawl adds the web crawler file to the file groups of the versioneddataset, so the versioneddataset
+// controller crawls the pages. If the file is added before, it is set to processing to crawl the pages again.
+func requestWebCrawl(ctx context.Context, c client.Client, vds *v1alpha1.VersionedDataset, namespace, name, path string) error {
+	for i, fs := range vds.Status.Files {
+		if fs.Kind != "Datasource" || fs.Name != name || pointer.StringDeref(fs.Namespace, vds.Namespace) != namespace {
+			continue
+		}
+		for j, f := range fs.Status {
+			if f.Path != path {
+				continue
+			}
+			updated := vds.DeepCopy()
+			updated.Status.Files[i].Status[j].Phase = v1alpha1.FileProcessPhaseProcessing
+			updated.Status.Files[i].Status[j].ErrMessage = ""
+			updated.Status.SetConditions(v1alpha1.Condition{
+				Type:               v1alpha1.TypeReady,
+				Status:             corev1.ConditionFalse,
+				Reason:             v1alpha1.ReasonFileSyncing,
+				Message:            "crawl web pages.",
+				LastTransitionTime: metav1.Now(),
+			})
+			// two requests of the same file conflict, so only one of them crawls
+			return c.Status().Patch(ctx, updated, client.MergeFromWithOptions(vds, client.MergeFromWithOptimisticLock{}))
+		}
+	}
+
+	for i, fg := range vds.Spec.FileGroups {
+		if fg.Source == nil || fg.Source.Kind != "Datasource" || fg.Source.Name != name || pointer.StringDeref(fg.Source.Namespace, vds.Namespace) != namespace {
+			continue
+		}
+		for _, f := range fg.Files {
+			if f.Path == path {
+				// the file is not synced to the status yet, it will be crawled
+				return nil
+			}
+		}
+		vds.Spec.FileGroups[i].Files = append(vds.Spec.FileGroups[i].Files, v1alpha1.FileWithVersion{Path: path})
+		return c.Update(ctx, vds)
+	}
+	vds.Spec.FileGroups = append(vds.Spec.FileGroups, v1alpha1.FileGroup{
+		Source: &v1alpha1.TypedObjectReference{
+			APIGroup:  pointer.String(v1alpha1.GroupVersion.String()),
+			Kind:      "Datasource",
+			Name:      name,
+			Namespace: pointer.String(namespace),
+		},
+		Files: []v1alpha1.FileWithVersion{{Path: path}},
+	})
+	return c.Update(ctx, vds)
 }
 
 // @Summary	edit csv file online
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+func TestRequestWebCrawl(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	vds := &v1alpha1.VersionedDataset{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "dataset-v1"},
+		Spec:       v1alpha1.VersionedDatasetSpec{Dataset: &v1alpha1.TypedObjectReference{Name: "dataset"}, Version: "v1"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(vds).Build()
+	get := func() *v1alpha1.VersionedDataset {
+		res := &v1alpha1.VersionedDataset{}
+		require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(vds), res))
+		return res
+	}
+	const path = "default-web.web"
+
+	// the file is added to the file groups
+	require.NoError(t, requestWebCrawl(ctx, c, get(), "default", "web", path))
+	got := get()
+	require.Len(t, got.Spec.FileGroups, 1)
+	assert.Equal(t, "web", got.Spec.FileGroups[0].Source.Name)
+	assert.Equal(t, []v1alpha1.FileWithVersion{{Path: path}}, got.Spec.FileGroups[0].Files)
+	// it is not added twice before it is synced to the status
+	require.NoError(t, requestWebCrawl(ctx, c, get(), "default", "web", path))
+	assert.Len(t, get().Spec.FileGroups[0].Files, 1)
+
+	// the crawl is done
+	got.Status.Files = []v1alpha1.FileStatus{{
+		TypedObjectReference: v1alpha1.TypedObjectReference{Kind: "Datasource", Name: "web", Namespace: pointer.String("default")},
+		Status:               []v1alpha1.FileDetails{{Path: path, Phase: v1alpha1.FileProcessPhaseFailed, ErrMessage: "timeout"}},
+	}}
+	require.NoError(t, c.Status().Update(ctx, got))
+	got = get()
+	assert.False(t, webCrawling(got, "default", "web", path))
+
+	// crawling again sets the file to processing, and only one of two requests succeeds
+	stale := got.DeepCopy()
+	require.NoError(t, requestWebCrawl(ctx, c, got, "default", "web", path))
+	got = get()
+	assert.True(t, webCrawling(got, "default", "web", path))
+	assert.Empty(t, got.Status.Files[0].Status[0].ErrMessage)
+	assert.Equal(t, v1alpha1.ReasonFileSyncing, got.Status.GetCondition(v1alpha1.TypeReady).Reason)
+	err := requestWebCrawl(ctx, c, stale, "default", "web", path)
+	assert.True(t, apierrors.IsConflict(err))
+}
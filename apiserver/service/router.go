@@ -16,17 +16,22 @@ limitations under the License.
 package service
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/kubeagi/arcadia/apiserver/config"
 	"github.com/kubeagi/arcadia/apiserver/docs"
 	"github.com/kubeagi/arcadia/apiserver/pkg/client"
 	"github.com/kubeagi/arcadia/apiserver/pkg/oidc"
+	"github.com/kubeagi/arcadia/pkg/appruntime"
 	pkgconfig "github.com/kubeagi/arcadia/pkg/config"
 )
 
@@ -52,6 +57,7 @@ func NewServerAndRun(conf config.ServerConfig) {
 	r.GET("/healthz", func(c *gin.Context) {
 		c.String(http.StatusOK, "ok")
 	})
+	r.GET("/metrics", gin.WrapH(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})))
 
 	// enable oidc authentication
 	if conf.EnableOIDC {
@@ -72,6 +78,10 @@ func NewServerAndRun(conf config.ServerConfig) {
 		ragGroup := r.Group("/rags")
 		registerRAG(ragGroup, conf)
 
+		if conf.EnableAppCache {
+			enableAppCache()
+		}
+
 		// for admin chat server with Restful apis
 		chatGroup := r.Group("/chat")
 		registerChat(chatGroup, conf)
@@ -92,3 +102,26 @@ func NewServerAndRun(conf config.ServerConfig) {
 
 	_ = r.Run(fmt.Sprintf("%s:%d", conf.Host, conf.Port))
 }
+
+// enableAppCache starts the informers and enables the application cache after they are synced in the background,
+// chats work without the cache until then or if it fails
+func enableAppCache() {
+	informers, err := client.NewCache()
+	if err != nil {
+		klog.Errorf("failed to create the informer cache, the application cache is disabled: %s", err)
+		return
+	}
+	ctx := context.Background()
+	go func() {
+		if err := informers.Start(ctx); err != nil {
+			klog.Errorf("failed to start the informer cache: %s", err)
+		}
+	}()
+	go func() {
+		if !informers.WaitForCacheSync(ctx) {
+			klog.Errorf("failed to sync the informer cache, the application cache is disabled")
+			return
+		}
+		appruntime.EnableAppCache(informers, client.Scheme)
+	}()
+}
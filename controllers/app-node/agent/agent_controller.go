@@ -0,0 +1,199 @@
+/*
+Copyright 2023 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	api "github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	appnode "github.com/kubeagi/arcadia/controllers/app-node"
+	"github.com/kubeagi/arcadia/pkg/appruntime/tools"
+)
+
+// AgentReconciler reconciles an Agent object
+type AgentReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=agents,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=agents/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=agents/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.12.2/pkg/reconcile
+func (r *AgentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(5).Info("Start Agent Reconcile")
+	instance := &api.Agent{}
+	if err := r.Get(ctx, req.NamespacedName, instance); err != nil {
+		// There's no need to requeue if the resource no longer exists.
+		// Otherwise, we'll be requeued implicitly because we return an error.
+		log.V(1).Info("Failed to get Agent")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	log = log.WithValues("Generation", instance.GetGeneration(), "ObservedGeneration", instance.Status.ObservedGeneration, "creator", instance.Spec.Creator)
+	log.V(5).Info("Get Agent instance")
+
+	// Add a finalizer.Then, we can define some operations which should
+	// occur before the Agent to be deleted.
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/finalizers
+	if newAdded := controllerutil.AddFinalizer(instance, arcadiav1alpha1.Finalizer); newAdded {
+		log.Info("Try to add Finalizer for Agent")
+		if err := r.Update(ctx, instance); err != nil {
+			log.Error(err, "Failed to update Agent to add finalizer, will try again later")
+			return ctrl.Result{}, err
+		}
+		log.Info("Adding Finalizer for Agent done")
+		return ctrl.Result{}, nil
+	}
+
+	// Check if the Agent instance is marked to be deleted, which is
+	// indicated by the deletion timestamp being set.
+	if instance.GetDeletionTimestamp() != nil && controllerutil.ContainsFinalizer(instance, arcadiav1alpha1.Finalizer) {
+		log.Info("Performing Finalizer Operations for Agent before delete CR")
+		log.Info("Removing Finalizer for Agent after successfully performing the operations")
+		controllerutil.RemoveFinalizer(instance, arcadiav1alpha1.Finalizer)
+		if err := r.Update(ctx, instance); err != nil {
+			log.Error(err, "Failed to remove the finalizer for Agent")
+			return ctrl.Result{}, err
+		}
+		log.Info("Remove Agent done")
+		return ctrl.Result{}, nil
+	}
+
+	if migrateType(instance) {
+		log.Info("Migrate the type of Agent to conversational, which is the agent it used before the type takes effect")
+		if err := r.Update(ctx, instance); err != nil {
+			log.Error(err, "Failed to migrate the type of Agent, will try again later")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	instance, result, err := r.reconcile(ctx, log, instance)
+
+	// Update status after reconciliation.
+	if updateStatusErr := r.patchStatus(ctx, instance); updateStatusErr != nil {
+		log.Error(updateStatusErr, "unable to update status after reconciliation")
+		return ctrl.Result{Requeue: true}, updateStatusErr
+	}
+
+	return result, err
+}
+
+func (r *AgentReconciler) reconcile(ctx context.Context, log logr.Logger, instance *api.Agent) (*api.Agent, ctrl.Result, error) {
+	// Observe generation change
+	if instance.Status.ObservedGeneration != instance.Generation {
+		instance.Status.ObservedGeneration = instance.Generation
+		r.setCondition(instance, instance.Status.WaitingCompleteCondition()...)
+		if updateStatusErr := r.patchStatus(ctx, instance); updateStatusErr != nil {
+			log.Error(updateStatusErr, "unable to update status after generation update")
+			return instance, ctrl.Result{Requeue: true}, updateStatusErr
+		}
+	}
+
+	if instance.Status.IsReady() {
+		return instance, ctrl.Result{}, nil
+	}
+	instance.Status.Type = instance.Spec.GetType()
+	if err := checkTools(instance.Spec.AllowedTools); err != nil {
+		instance.Status.SetConditions(instance.Status.ErrorCondition(err.Error())...)
+		return instance, ctrl.Result{}, nil
+	}
+	if err := appnode.CheckAndUpdateAnnotation(ctx, log, r.Client, instance); err != nil {
+		instance.Status.SetConditions(instance.Status.ErrorCondition(err.Error())...)
+	} else {
+		instance.Status.SetConditions(instance.Status.ReadyCondition()...)
+	}
+	return instance, ctrl.Result{}, nil
+}
+
+// migrateType changes the type of the agents created before the type takes effect to conversational.
+// Their type is the zeroShot defaulted by the old crd, but they run as conversational agents. A defaulted field is
+// not owned by any manager, so the agents with zeroShot set explicitly are kept. The status type is set after the
+// first reconciliation, so an agent is only checked once.
+func migrateType(instance *api.Agent) bool {
+	if instance.Spec.Type != api.AgentTypeZeroShot || instance.Status.Type != "" {
+		return false
+	}
+	for _, f := range instance.GetManagedFields() {
+		if f.FieldsV1 == nil {
+			continue
+		}
+		fields := make(map[string]map[string]any)
+		if err := json.Unmarshal(f.FieldsV1.Raw, &fields); err != nil {
+			return false
+		}
+		if _, ok := fields["f:spec"]["f:type"]; ok {
+			return false
+		}
+	}
+	instance.Spec.Type = api.AgentTypeConversational
+	return true
+}
+
+// checkTools checks whether the tools are registered and their params are valid
+func checkTools(specTools []api.Tool) error {
+	for _, toolSpec := range specTools {
+		d, ok := tools.Lookup(toolSpec.Name)
+		if !ok {
+			return fmt.Errorf("no tool found with name: %s", toolSpec.Name)
+		}
+		if _, err := d.ParseParams(toolSpec.Params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *AgentReconciler) patchStatus(ctx context.Context, instance *api.Agent) error {
+	latest := &api.Agent{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(instance), latest); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(instance.Status, latest.Status) {
+		return nil
+	}
+	patch := client.MergeFrom(latest.DeepCopy())
+	latest.Status = instance.Status
+	return r.Client.Status().Patch(ctx, latest, patch, client.FieldOwner("Agent-controller"))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&api.Agent{}).
+		Complete(r)
+}
+
+func (r *AgentReconciler) setCondition(instance *api.Agent, condition ...arcadiav1alpha1.Condition) *api.Agent {
+	instance.Status.SetConditions(condition...)
+	return instance
+}
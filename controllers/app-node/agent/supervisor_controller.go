@@ -0,0 +1,177 @@
+/*
+Copyright 2023 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	api "github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	appnode "github.com/kubeagi/arcadia/controllers/app-node"
+)
+
+// SupervisorReconciler reconciles a Supervisor object
+type SupervisorReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=supervisors,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=supervisors/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=supervisors/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.12.2/pkg/reconcile
+func (r *SupervisorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(5).Info("Start Supervisor Reconcile")
+	instance := &api.Supervisor{}
+	if err := r.Get(ctx, req.NamespacedName, instance); err != nil {
+		// There's no need to requeue if the resource no longer exists.
+		// Otherwise, we'll be requeued implicitly because we return an error.
+		log.V(1).Info("Failed to get Supervisor")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	log = log.WithValues("Generation", instance.GetGeneration(), "ObservedGeneration", instance.Status.ObservedGeneration, "creator", instance.Spec.Creator)
+	log.V(5).Info("Get Supervisor instance")
+
+	// Add a finalizer.Then, we can define some operations which should
+	// occur before the Supervisor to be deleted.
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/finalizers
+	if newAdded := controllerutil.AddFinalizer(instance, arcadiav1alpha1.Finalizer); newAdded {
+		log.Info("Try to add Finalizer for Supervisor")
+		if err := r.Update(ctx, instance); err != nil {
+			log.Error(err, "Failed to update Supervisor to add finalizer, will try again later")
+			return ctrl.Result{}, err
+		}
+		log.Info("Adding Finalizer for Supervisor done")
+		return ctrl.Result{}, nil
+	}
+
+	// Check if the Supervisor instance is marked to be deleted, which is
+	// indicated by the deletion timestamp being set.
+	if instance.GetDeletionTimestamp() != nil && controllerutil.ContainsFinalizer(instance, arcadiav1alpha1.Finalizer) {
+		log.Info("Performing Finalizer Operations for Supervisor before delete CR")
+		log.Info("Removing Finalizer for Supervisor after successfully performing the operations")
+		controllerutil.RemoveFinalizer(instance, arcadiav1alpha1.Finalizer)
+		if err := r.Update(ctx, instance); err != nil {
+			log.Error(err, "Failed to remove the finalizer for Supervisor")
+			return ctrl.Result{}, err
+		}
+		log.Info("Remove Supervisor done")
+		return ctrl.Result{}, nil
+	}
+
+	instance, result, err := r.reconcile(ctx, log, instance)
+
+	// Update status after reconciliation.
+	if updateStatusErr := r.patchStatus(ctx, instance); updateStatusErr != nil {
+		log.Error(updateStatusErr, "unable to update status after reconciliation")
+		return ctrl.Result{Requeue: true}, updateStatusErr
+	}
+
+	return result, err
+}
+
+func (r *SupervisorReconciler) reconcile(ctx context.Context, log logr.Logger, instance *api.Supervisor) (*api.Supervisor, ctrl.Result, error) {
+	// Observe generation change
+	if instance.Status.ObservedGeneration != instance.Generation {
+		instance.Status.ObservedGeneration = instance.Generation
+		r.setCondition(instance, instance.Status.WaitingCompleteCondition()...)
+		if updateStatusErr := r.patchStatus(ctx, instance); updateStatusErr != nil {
+			log.Error(updateStatusErr, "unable to update status after generation update")
+			return instance, ctrl.Result{Requeue: true}, updateStatusErr
+		}
+	}
+
+	if instance.Status.IsReady() {
+		return instance, ctrl.Result{}, nil
+	}
+	if err := r.checkAgents(ctx, instance); err != nil {
+		instance.Status.SetConditions(instance.Status.ErrorCondition(err.Error())...)
+		// the agents may be created or become ready later
+		return instance, ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	if err := appnode.CheckAndUpdateAnnotation(ctx, log, r.Client, instance); err != nil {
+		instance.Status.SetConditions(instance.Status.ErrorCondition(err.Error())...)
+	} else {
+		instance.Status.SetConditions(instance.Status.ReadyCondition()...)
+	}
+	return instance, ctrl.Result{}, nil
+}
+
+// checkAgents checks whether the agents are unique and ready
+func (r *SupervisorReconciler) checkAgents(ctx context.Context, instance *api.Supervisor) error {
+	if len(instance.Spec.Agents) == 0 {
+		return errors.New("supervisor needs one or more agents")
+	}
+	names := make(map[string]bool, len(instance.Spec.Agents))
+	for _, a := range instance.Spec.Agents {
+		if names[a.Name] {
+			return fmt.Errorf("agent %s should be unique", a.Name)
+		}
+		names[a.Name] = true
+		if a.Description == "" {
+			return fmt.Errorf("agent %s needs a description for the planner", a.Name)
+		}
+		agent := &api.Agent{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: instance.Namespace, Name: a.Name}, agent); err != nil {
+			return fmt.Errorf("can't find the agent %s: %w", a.Name, err)
+		}
+		if ready, msg := agent.Status.IsReadyOrGetReadyMessage(); !ready {
+			return fmt.Errorf("agent %s is not ready: %s", a.Name, msg)
+		}
+	}
+	return nil
+}
+
+func (r *SupervisorReconciler) patchStatus(ctx context.Context, instance *api.Supervisor) error {
+	latest := &api.Supervisor{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(instance), latest); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(instance.Status, latest.Status) {
+		return nil
+	}
+	patch := client.MergeFrom(latest.DeepCopy())
+	latest.Status = instance.Status
+	return r.Client.Status().Patch(ctx, latest, patch, client.FieldOwner("Supervisor-controller"))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SupervisorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&api.Supervisor{}).
+		Complete(r)
+}
+
+func (r *SupervisorReconciler) setCondition(instance *api.Supervisor, condition ...arcadiav1alpha1.Condition) *api.Supervisor {
+	instance.Status.SetConditions(condition...)
+	return instance
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	api "github.com/kubeagi/arcadia/api/app-node/guardrail/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	appnode "github.com/kubeagi/arcadia/controllers/app-node"
+)
+
+// GuardrailReconciler reconciles a Guardrail object
+type GuardrailReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=guardrail.arcadia.kubeagi.k8s.com.cn,resources=guardrails,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=guardrail.arcadia.kubeagi.k8s.com.cn,resources=guardrails/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=guardrail.arcadia.kubeagi.k8s.com.cn,resources=guardrails/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.12.2/pkg/reconcile
+func (r *GuardrailReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.V(5).Info("Start Guardrail Reconcile")
+	instance := &api.Guardrail{}
+	if err := r.Get(ctx, req.NamespacedName, instance); err != nil {
+		// There's no need to requeue if the resource no longer exists.
+		// Otherwise, we'll be requeued implicitly because we return an error.
+		log.V(1).Info("Failed to get Guardrail")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	log = log.WithValues("Generation", instance.GetGeneration(), "ObservedGeneration", instance.Status.ObservedGeneration, "creator", instance.Spec.Creator)
+	log.V(5).Info("Get Guardrail instance")
+
+	// Add a finalizer.Then, we can define some operations which should
+	// occur before the Guardrail to be deleted.
+	// More info: https://kubernetes.io/docs/concepts/overview/working-with-objects/finalizers
+	if newAdded := controllerutil.AddFinalizer(instance, arcadiav1alpha1.Finalizer); newAdded {
+		log.Info("Try to add Finalizer for Guardrail")
+		if err := r.Update(ctx, instance); err != nil {
+			log.Error(err, "Failed to update Guardrail to add finalizer, will try again later")
+			return ctrl.Result{}, err
+		}
+		log.Info("Adding Finalizer for Guardrail done")
+		return ctrl.Result{}, nil
+	}
+
+	// Check if the Guardrail instance is marked to be deleted, which is
+	// indicated by the deletion timestamp being set.
+	if instance.GetDeletionTimestamp() != nil && controllerutil.ContainsFinalizer(instance, arcadiav1alpha1.Finalizer) {
+		log.Info("Performing Finalizer Operations for Guardrail before delete CR")
+		log.Info("Removing Finalizer for Guardrail after successfully performing the operations")
+		controllerutil.RemoveFinalizer(instance, arcadiav1alpha1.Finalizer)
+		if err := r.Update(ctx, instance); err != nil {
+			log.Error(err, "Failed to remove the finalizer for Guardrail")
+			return ctrl.Result{}, err
+		}
+		log.Info("Remove Guardrail done")
+		return ctrl.Result{}, nil
+	}
+
+	instance, result, err := r.reconcile(ctx, log, instance)
+
+	// Update status after reconciliation.
+	if updateStatusErr := r.patchStatus(ctx, instance); updateStatusErr != nil {
+		log.Error(updateStatusErr, "unable to update status after reconciliation")
+		return ctrl.Result{Requeue: true}, updateStatusErr
+	}
+
+	return result, err
+}
+
+func (r *GuardrailReconciler) reconcile(ctx context.Context, log logr.Logger, instance *api.Guardrail) (*api.Guardrail, ctrl.Result, error) {
+	// Observe generation change
+	if instance.Status.ObservedGeneration != instance.Generation {
+		instance.Status.ObservedGeneration = instance.Generation
+		r.setCondition(instance, instance.Status.WaitingCompleteCondition()...)
+		if updateStatusErr := r.patchStatus(ctx, instance); updateStatusErr != nil {
+			log.Error(updateStatusErr, "unable to update status after generation update")
+			return instance, ctrl.Result{Requeue: true}, updateStatusErr
+		}
+	}
+
+	if instance.Status.IsReady() {
+		return instance, ctrl.Result{}, nil
+	}
+	if err := checkGuardrail(instance.Spec.GuardrailConfig); err != nil {
+		instance.Status.SetConditions(instance.Status.ErrorCondition(err.Error())...)
+		return instance, ctrl.Result{}, nil
+	}
+	if err := appnode.CheckAndUpdateAnnotation(ctx, log, r.Client, instance); err != nil {
+		instance.Status.SetConditions(instance.Status.ErrorCondition(err.Error())...)
+	} else {
+		instance.Status.SetConditions(instance.Status.ReadyCondition()...)
+	}
+	return instance, ctrl.Result{}, nil
+}
+
+// checkGuardrail checks whether the guardrail has checks and the checks are valid
+func checkGuardrail(config api.GuardrailConfig) error {
+	if config.SensitiveWords == nil && len(config.PII) == 0 && config.Classifier == nil {
+		return errors.New("guardrail needs sensitive words, pii detectors or a classifier")
+	}
+	if words := config.SensitiveWords; words != nil && words.From != nil {
+		switch words.From.Kind {
+		case "ConfigMap", "VersionedDataset":
+		default:
+			return fmt.Errorf("sensitive words can't be read from %s, only ConfigMap and VersionedDataset are supported", words.From.Kind)
+		}
+		if words.Key == "" {
+			return fmt.Errorf("the key of sensitive words in %s %s is required", words.From.Kind, words.From.Name)
+		}
+	}
+	for _, pii := range config.PII {
+		expr := pii.GetRegexp()
+		if expr == "" {
+			return fmt.Errorf("pii detector %s needs a regexp", pii.Name)
+		}
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("pii detector %s has an invalid regexp %s: %w", pii.Name, expr, err)
+		}
+	}
+	return nil
+}
+
+func (r *GuardrailReconciler) patchStatus(ctx context.Context, instance *api.Guardrail) error {
+	latest := &api.Guardrail{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(instance), latest); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(instance.Status, latest.Status) {
+		return nil
+	}
+	patch := client.MergeFrom(latest.DeepCopy())
+	latest.Status = instance.Status
+	return r.Client.Status().Patch(ctx, latest, patch, client.FieldOwner("Guardrail-controller"))
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GuardrailReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&api.Guardrail{}).
+		Complete(r)
+}
+
+func (r *GuardrailReconciler) setCondition(instance *api.Guardrail, condition ...arcadiav1alpha1.Condition) *api.Guardrail {
+	instance.Status.SetConditions(condition...)
+	return instance
+}
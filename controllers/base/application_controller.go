@@ -17,8 +17,8 @@ limitations under the License.
 package controllers
 
 import (
-	"container/list"
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 
@@ -38,11 +38,12 @@ import (
 	agentv1alpha1 "github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
 	chainv1alpha1 "github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
 	documentloaderv1alpha1 "github.com/kubeagi/arcadia/api/app-node/documentloader/v1alpha1"
+	guardrailv1alpha1 "github.com/kubeagi/arcadia/api/app-node/guardrail/v1alpha1"
 	promptv1alpha1 "github.com/kubeagi/arcadia/api/app-node/prompt/v1alpha1"
 	retrieveralpha1 "github.com/kubeagi/arcadia/api/app-node/retriever/v1alpha1"
+	routerv1alpha1 "github.com/kubeagi/arcadia/api/app-node/router/v1alpha1"
 	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
 	"github.com/kubeagi/arcadia/pkg/appruntime"
-	"github.com/kubeagi/arcadia/pkg/appruntime/base"
 )
 
 const (
@@ -56,8 +57,13 @@ const (
 	RerankRetrieverIndexKey        = "metadata.rerankretriever"
 	MultiQueryRetrieverIndexKey    = "metadata.multiqueryretriever"
 	MergerRetrieverIndexKey        = "metadata.mergerretriever"
+	QueryTransformIndexKey         = "metadata.querytransform"
 	AgentIndexKey                  = "metadata.agent"
+	SupervisorIndexKey             = "metadata.supervisor"
 	DocumentLoaderIndexKey         = "metadata.documentloader"
+	RouterIndexKey                 = "metadata.router"
+	GuardrailIndexKey              = "metadata.guardrail"
+	SubApplicationIndexKey         = "metadata.application"
 )
 
 // ApplicationReconciler reconciles an Application object
@@ -93,9 +99,20 @@ type ApplicationReconciler struct {
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=agents,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=agents/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=agents/finalizers,verbs=update
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=apitools,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=apitools/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=supervisors,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=supervisors/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=supervisors/finalizers,verbs=update
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=documentloaders,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=documentloaders/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=arcadia.kubeagi.k8s.com.cn,resources=documentloaders/finalizers,verbs=update
+//+kubebuilder:rbac:groups=router.arcadia.kubeagi.k8s.com.cn,resources=routers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=router.arcadia.kubeagi.k8s.com.cn,resources=routers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=router.arcadia.kubeagi.k8s.com.cn,resources=routers/finalizers,verbs=update
+//+kubebuilder:rbac:groups=guardrail.arcadia.kubeagi.k8s.com.cn,resources=guardrails,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=guardrail.arcadia.kubeagi.k8s.com.cn,resources=guardrails/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=guardrail.arcadia.kubeagi.k8s.com.cn,resources=guardrails/finalizers,verbs=update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -135,7 +152,9 @@ func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	// indicated by the deletion timestamp being set.
 	if app.GetDeletionTimestamp() != nil && controllerutil.ContainsFinalizer(app, arcadiav1alpha1.Finalizer) {
 		log.Info("Performing Finalizer Operations for Application before delete CR")
-		// TODO perform the finalizer operations here, for example: remove vectorstore data?
+		if app.Status.AnswerCacheVersion != "" {
+			r.removeAnswerCache(ctx, log, app)
+		}
 		log.Info("Removing Finalizer for Application after successfully performing the operations")
 		controllerutil.RemoveFinalizer(app, arcadiav1alpha1.Finalizer)
 		if err := r.Update(ctx, app); err != nil {
@@ -178,10 +197,13 @@ func MigrateAppCategory(app *arcadiav1alpha1.Application) bool {
 // 2. output node must not have next node
 // 3. input node must only have one
 // 4. input node must only have one
-// 5. only one node connected to output, and this node type should be chain or agent
+// 5. only one node connected to output, and this node type should be chain, agent, supervisor or application,
+// several nodes can be connected to output if they are on different branches of routers
 // 6. when this node points to output, it can only point to output
-// 7. should not have cycle TODO
+// 7. should not have cycle
 // 8. nodeName should be unique
+// 9. applications called by application nodes should not call each other in a cycle or too deep
+// 10. input variables should have unique names which are not used by the application, and valid default values
 func (r *ApplicationReconciler) validateNodes(ctx context.Context, log logr.Logger, app *arcadiav1alpha1.Application) (*arcadiav1alpha1.Application, ctrl.Result, error) {
 	log.V(5).Info("Start validate nodes...")
 	defer log.V(5).Info("Validate nodes Done")
@@ -219,32 +241,62 @@ func (r *ApplicationReconciler) validateNodes(ctx context.Context, log logr.Logg
 		return app, ctrl.Result{RequeueAfter: waitMedium}, nil
 	}
 
-	var toOutput int
-	var toOutputNodeNext int
+	ending := make([]string, 0, 1)
 	for _, node := range app.Spec.Nodes {
 		for _, n := range node.NextNodeName {
 			if n == outputNodeName {
-				toOutput++
+				ending = append(ending, node.Name)
 				group := node.Ref.APIGroup
 				if group == nil {
 					r.setCondition(app, app.Status.ErrorCondition("node should have ref.group setting")...)
 					return app, ctrl.Result{RequeueAfter: waitMedium}, nil
 				}
-				// Only allow chain group or agent node as the ending node
+				// Only allow chain group, agent or application node as the ending node
 				if *group != chainv1alpha1.Group && (*group != agentv1alpha1.Group && node.Ref.Kind != "agent") {
 					r.setCondition(app, app.Status.ErrorCondition("ending node should be a chain or agent")...)
 					return app, ctrl.Result{RequeueAfter: waitMedium}, nil
 				}
+				if len(node.NextNodeName) != 1 {
+					r.setCondition(app, app.Status.ErrorCondition("when this node points to output, it can only point to output")...)
+					return app, ctrl.Result{RequeueAfter: waitMedium}, nil
+				}
 			}
-			toOutputNodeNext = len(node.NextNodeName)
 		}
 	}
-	if toOutput != 1 {
+	if len(ending) == 0 {
 		r.setCondition(app, app.Status.ErrorCondition("only one node can output")...)
 		return app, ctrl.Result{RequeueAfter: waitMedium}, nil
 	}
-	if toOutputNodeNext != 1 {
-		r.setCondition(app, app.Status.ErrorCondition("when this node points to output, it can only point to output")...)
+	if len(ending) > 1 {
+		routes, err := r.routerRoutes(ctx, app)
+		if err != nil {
+			r.setCondition(app, app.Status.ErrorCondition(err.Error())...)
+			return app, ctrl.Result{RequeueAfter: waitMedium}, nil
+		}
+		if !appruntime.ExclusiveNodes(app.Spec.Nodes, routes, ending...) {
+			r.setCondition(app, app.Status.ErrorCondition("only one node can output, unless the nodes are on different routes of a router")...)
+			return app, ctrl.Result{RequeueAfter: waitMedium}, nil
+		}
+	}
+
+	if _, err := appruntime.SortNodes(app.Spec.Nodes); err != nil {
+		var cycleErr *appruntime.CycleError
+		if errors.As(err, &cycleErr) {
+			log.Info("application nodes have a cycle", "cycle", cycleErr.Path)
+		}
+		r.setCondition(app, app.Status.ErrorCondition(err.Error())...)
+		return app, ctrl.Result{RequeueAfter: waitMedium}, nil
+	}
+	if _, err := appruntime.CheckAppCalls(ctx, r.Client, app, nil); err != nil {
+		var cycleErr *appruntime.AppCycleError
+		if errors.As(err, &cycleErr) {
+			log.Info("applications call each other in a cycle", "cycle", cycleErr.Path)
+		}
+		r.setCondition(app, app.Status.ErrorCondition(err.Error())...)
+		return app, ctrl.Result{RequeueAfter: waitMedium}, nil
+	}
+	if err := appruntime.CheckInputVariables(app.Spec.InputVariables); err != nil {
+		r.setCondition(app, app.Status.ErrorCondition(err.Error())...)
 		return app, ctrl.Result{RequeueAfter: waitMedium}, nil
 	}
 
@@ -255,43 +307,78 @@ func (r *ApplicationReconciler) validateNodes(ctx context.Context, log logr.Logg
 		return app, ctrl.Result{RequeueAfter: waitMedium}, nil
 	}
 
-	visited := make(map[string]bool)
-	waitRunningNodes := list.New()
-	for _, v := range runtimeApp.StartingNodes {
-		waitRunningNodes.PushBack(v)
-	}
-	for e := waitRunningNodes.Front(); e != nil; e = e.Next() {
-		e := e.Value.(base.Node)
-		if !visited[e.Name()] {
-			reWait := false
-			for _, n := range e.GetPrevNode() {
-				if !visited[n.Name()] {
-					reWait = true
-					break
-				}
-			}
-			if reWait {
-				waitRunningNodes.PushBack(e)
-				continue
-			}
-			log.V(5).Info("runtimeApp try to check node...", "node", e.Name())
-			if isReady, errMsg := e.Ready(); !isReady {
-				r.setCondition(app, app.Status.ErrorCondition(fmt.Sprintf("%s:%s || node %s get failed status: %s", e.Group(), e.Kind(), e.Name(), errMsg))...)
-				return app, ctrl.Result{RequeueAfter: waitMedium}, nil
-			}
-			log.V(5).Info("runtimeApp check node done", "node", e.Name())
-			visited[e.Name()] = true
-		}
-		for _, n := range e.GetNextNode() {
-			waitRunningNodes.PushBack(n)
+	for _, e := range runtimeApp.SortedNodes {
+		log.V(5).Info("runtimeApp try to check node...", "node", e.Name())
+		if isReady, errMsg := e.Ready(); !isReady {
+			r.setCondition(app, app.Status.ErrorCondition(fmt.Sprintf("%s:%s || node %s get failed status: %s", e.Group(), e.Kind(), e.Name(), errMsg))...)
+			return app, ctrl.Result{RequeueAfter: waitMedium}, nil
 		}
+		log.V(5).Info("runtimeApp check node done", "node", e.Name())
 	}
 
 	log.V(5).Info("runtimeApp check Done")
+	r.reconcileAnswerCache(ctx, log, app)
 	r.setCondition(app, app.Status.ReadyCondition()...)
 	return app, ctrl.Result{}, nil
 }
 
+// reconcileAnswerCache removes the cached answers when the files of the knowledgebase change or the answer cache
+// is disabled, and records the files version of the cached answers in status.
+// The answers of other versions are not used anyway, so the removal is only to free the storage.
+func (r *ApplicationReconciler) reconcileAnswerCache(ctx context.Context, log logr.Logger, app *arcadiav1alpha1.Application) {
+	if app.Spec.AnswerCache == nil {
+		if app.Status.AnswerCacheVersion != "" {
+			r.removeAnswerCache(ctx, log, app)
+			app.Status.AnswerCacheVersion = ""
+		}
+		return
+	}
+	kb, err := appruntime.AnswerCacheKnowledgebase(ctx, r.Client, app.Namespace, app.Spec)
+	if err != nil {
+		log.Error(err, "failed to get the knowledgebase of the answer cache")
+		return
+	}
+	version := kb.FilesVersion()
+	if app.Status.AnswerCacheVersion == version {
+		return
+	}
+	if app.Status.AnswerCacheVersion != "" {
+		log.Info("knowledgebase files changed, remove the answer cache", "knowledgebase", kb.Name, "oldVersion", app.Status.AnswerCacheVersion, "newVersion", version)
+		if err := appruntime.RemoveAnswerCache(ctx, log, r.Client, app.Namespace, app.Name, kb); err != nil {
+			log.Error(err, "failed to remove the answer cache")
+		}
+	}
+	app.Status.AnswerCacheVersion = version
+}
+
+// removeAnswerCache removes the cached answers of the application, failures are only logged
+func (r *ApplicationReconciler) removeAnswerCache(ctx context.Context, log logr.Logger, app *arcadiav1alpha1.Application) {
+	kb, err := appruntime.AnswerCacheKnowledgebase(ctx, r.Client, app.Namespace, app.Spec)
+	if err != nil {
+		log.Error(err, "failed to get the knowledgebase of the answer cache")
+		return
+	}
+	if err := appruntime.RemoveAnswerCache(ctx, log, r.Client, app.Namespace, app.Name, kb); err != nil {
+		log.Error(err, "failed to remove the answer cache")
+	}
+}
+
+// routerRoutes returns the next nodes of each route of the routers in the application by the router node name
+func (r *ApplicationReconciler) routerRoutes(ctx context.Context, app *arcadiav1alpha1.Application) (map[string][][]string, error) {
+	routes := make(map[string][][]string)
+	for _, node := range app.Spec.Nodes {
+		if node.Ref == nil || node.Ref.APIGroup == nil || *node.Ref.APIGroup != routerv1alpha1.Group {
+			continue
+		}
+		router := &routerv1alpha1.Router{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: node.Ref.GetNamespace(app.Namespace), Name: node.Ref.Name}, router); err != nil {
+			return nil, fmt.Errorf("can't find the router of node %s: %w", node.Name, err)
+		}
+		routes[node.Name] = router.Spec.RouteNextNodeNames()
+	}
+	return routes, nil
+}
+
 func (r *ApplicationReconciler) reconcile(ctx context.Context, log logr.Logger, app *arcadiav1alpha1.Application) (*arcadiav1alpha1.Application, ctrl.Result, error) {
 	// Observe generation change
 	if app.Status.ObservedGeneration != app.Generation {
@@ -370,8 +457,13 @@ func (r *ApplicationReconciler) SetupWithManager(ctx context.Context, mgr ctrl.M
 		{RerankRetrieverIndexKey, "retriever", "rerankretriever"},
 		{MultiQueryRetrieverIndexKey, "retriever", "multiqueryretriever"},
 		{MergerRetrieverIndexKey, "retriever", "mergerretriever"},
+		{QueryTransformIndexKey, "retriever", "querytransform"},
 		{AgentIndexKey, "", "agent"},
+		{SupervisorIndexKey, "", "supervisor"},
 		{DocumentLoaderIndexKey, "", "documentloader"},
+		{RouterIndexKey, "router", "router"},
+		{GuardrailIndexKey, "guardrail", "guardrail"},
+		{SubApplicationIndexKey, "", "application"},
 	}
 	for _, d := range dependencies {
 		d := d
@@ -427,8 +519,14 @@ func (r *ApplicationReconciler) SetupWithManager(ctx context.Context, mgr ctrl.M
 		Watches(&source.Kind{Type: &retrieveralpha1.RerankRetriever{}}, getEventHandler(RerankRetrieverIndexKey)).
 		Watches(&source.Kind{Type: &retrieveralpha1.MultiQueryRetriever{}}, getEventHandler(MultiQueryRetrieverIndexKey)).
 		Watches(&source.Kind{Type: &retrieveralpha1.MergerRetriever{}}, getEventHandler(MergerRetrieverIndexKey)).
+		Watches(&source.Kind{Type: &retrieveralpha1.QueryTransform{}}, getEventHandler(QueryTransformIndexKey)).
 		Watches(&source.Kind{Type: &agentv1alpha1.Agent{}}, getEventHandler(AgentIndexKey)).
+		Watches(&source.Kind{Type: &agentv1alpha1.Supervisor{}}, getEventHandler(SupervisorIndexKey)).
 		Watches(&source.Kind{Type: &documentloaderv1alpha1.DocumentLoader{}}, getEventHandler(DocumentLoaderIndexKey)).
+		Watches(&source.Kind{Type: &routerv1alpha1.Router{}}, getEventHandler(RouterIndexKey)).
+		Watches(&source.Kind{Type: &guardrailv1alpha1.Guardrail{}}, getEventHandler(GuardrailIndexKey)).
+		// the applications calling an application are reconciled when its status changes
+		Watches(&source.Kind{Type: &arcadiav1alpha1.Application{}}, getEventHandler(SubApplicationIndexKey)).
 		Complete(r)
 }
 
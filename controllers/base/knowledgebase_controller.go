@@ -17,7 +17,6 @@ limitations under the License.
 package controllers
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -30,16 +29,15 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/minio/minio-go/v7"
-	"github.com/tmc/langchaingo/documentloaders"
 	"github.com/tmc/langchaingo/embeddings"
 	"github.com/tmc/langchaingo/schema"
-	"github.com/tmc/langchaingo/textsplitter"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
+	"k8s.io/utils/strings/slices"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -52,6 +50,7 @@ import (
 	"github.com/kubeagi/arcadia/pkg/datasource"
 	pkgdocumentloaders "github.com/kubeagi/arcadia/pkg/documentloaders"
 	"github.com/kubeagi/arcadia/pkg/langchainwrap"
+	"github.com/kubeagi/arcadia/pkg/splitter"
 	"github.com/kubeagi/arcadia/pkg/utils"
 	"github.com/kubeagi/arcadia/pkg/vectorstore"
 )
@@ -64,7 +63,12 @@ const (
 	waitSmaller = time.Second * 3
 	waitMedium  = time.Minute
 
+	// progressPatchInterval is the min interval to patch the progress of a processing file into the status
+	progressPatchInterval = time.Second * 10
+
 	retryForFailed = "for-failed"
+	// refreshTables loads the new rows of the tables from PostgreSQL datasources by their watermark columns
+	refreshTables = "refresh-tables"
 )
 
 var (
@@ -163,7 +167,7 @@ func (r *KnowledgeBaseReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	if v := kb.Annotations[arcadiav1alpha1.UpdateSourceFileAnnotationKey]; v != "" {
 		log.Info("Manual update")
 		kbNew := kb.DeepCopy()
-		if v != retryForFailed && len(kb.Status.FileGroupDetail) != 0 {
+		if v != retryForFailed && v != refreshTables && len(kb.Status.FileGroupDetail) != 0 {
 			log.Info("set FileGroupDetail to nil to redo embedder...")
 			kbNew.Status.FileGroupDetail = nil
 			kbNew = r.setCondition(log, kbNew, kbNew.InitCondition())
@@ -187,6 +191,24 @@ func (r *KnowledgeBaseReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 				return reconcile.Result{}, r.patchStatus(ctx, log, kbNew)
 			}
 		}
+		if v == refreshTables {
+			found := false
+			for out, fg := range kbNew.Status.FileGroupDetail {
+				for in, f := range fg.FileDetails {
+					if findTableSource(kbNew, fg.Source, f.Path) != nil && f.Phase != arcadiav1alpha1.FileProcessPhaseProcessing {
+						found = true
+						kbNew.Status.FileGroupDetail[out].FileDetails[in].Phase = arcadiav1alpha1.FileProcessPhaseProcessing
+						kbNew.Status.FileGroupDetail[out].FileDetails[in].LastUpdateTime = metav1.Now()
+						kbNew.Status.FileGroupDetail[out].FileDetails[in].ErrMessage = ""
+					}
+				}
+			}
+			if found {
+				log.Info("refresh the tables by their watermarks.")
+				kbNew = r.setCondition(log, kbNew, kbNew.InitCondition())
+				return reconcile.Result{}, r.patchStatus(ctx, log, kbNew)
+			}
+		}
 		delete(kbNew.Annotations, arcadiav1alpha1.UpdateSourceFileAnnotationKey)
 		return reconcile.Result{}, r.Patch(ctx, kbNew, client.MergeFrom(kb))
 	}
@@ -464,6 +486,9 @@ func (r *KnowledgeBaseReconciler) reconcileFileGroup(
 		if !dsObj.Status.IsReady() {
 			return errDataSourceNotReady
 		}
+		if dsObj.Spec.Type() == arcadiav1alpha1.DatasourceTypePostgreSQL {
+			return r.reconcileTable(ctx, log, kb, dsObj, vectorStore, embedder, groupIndex, fileIndex)
+		}
 		// set endpoint's auth secret namespace to current datasource if not set
 		endpoint := dsObj.Spec.Endpoint.DeepCopy()
 		if endpoint != nil && endpoint.AuthSecret != nil {
@@ -528,7 +553,20 @@ func (r *KnowledgeBaseReconciler) reconcileFileGroup(
 	}
 	defer file.Close()
 	startTime := time.Now()
-	if err = r.handleFile(ctx, log, file, info.Object, tags, kb, vectorStore, embedder); err != nil {
+	lastPatch := startTime
+	progress := func(processed int64) {
+		kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].ProcessedChunks = processed
+		if time.Since(lastPatch) < progressPatchInterval {
+			return
+		}
+		lastPatch = time.Now()
+		if err := r.patchStatus(ctx, log, kb); err != nil {
+			log.Error(err, "failed to patch the progress of the file", "processedChunks", processed)
+		}
+	}
+	kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].ProcessedChunks = 0
+	kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].TotalChunks = 0
+	if err = r.handleFile(ctx, log, file, info.Object, tags, kb, vectorStore, embedder, progress); err != nil {
 		if errors.Is(err, errFileSkipped) {
 			kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].UpdateErr(err, arcadiav1alpha1.FileProcessPhaseSkipped)
 		} else {
@@ -539,12 +577,171 @@ func (r *KnowledgeBaseReconciler) reconcileFileGroup(
 	cost := int64(time.Since(startTime).Milliseconds())
 
 	kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].TimeCost = cost
+	kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].TotalChunks = kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].ProcessedChunks
 	log.Info("handle FileGroup succeeded", "timecost(milliseconds)", cost)
 	kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex].UpdateErr(err, arcadiav1alpha1.FileProcessPhaseSucceeded)
 	return nil
 }
 
-func (r *KnowledgeBaseReconciler) handleFile(ctx context.Context, log logr.Logger, file io.ReadCloser, fileName string, tags map[string]string, kb *arcadiav1alpha1.KnowledgeBase, store *arcadiav1alpha1.VectorStore, embedder *arcadiav1alpha1.Embedder) (err error) {
+// findTableSource returns the table source in the spec with the name, or nil if the file is not a table
+func findTableSource(kb *arcadiav1alpha1.KnowledgeBase, source *arcadiav1alpha1.TypedObjectReference, name string) *arcadiav1alpha1.TableSource {
+	if source == nil {
+		return nil
+	}
+	for _, fg := range kb.Spec.FileGroups {
+		if fg.Source == nil || fg.Source.Kind != source.Kind || fg.Source.Name != source.Name ||
+			fg.Source.GetNamespace(kb.Namespace) != source.GetNamespace(kb.Namespace) {
+			continue
+		}
+		for i := range fg.Tables {
+			if fg.Tables[i].SourceName() == name {
+				return &fg.Tables[i]
+			}
+		}
+	}
+	return nil
+}
+
+// reconcileTable embeds the rows of a table from a PostgreSQL datasource.
+// Only the rows after the watermark in the status are loaded if the table has a watermark column.
+func (r *KnowledgeBaseReconciler) reconcileTable(
+	ctx context.Context,
+	log logr.Logger,
+	kb *arcadiav1alpha1.KnowledgeBase,
+	dsObj *arcadiav1alpha1.Datasource,
+	vectorStore *arcadiav1alpha1.VectorStore,
+	embedder *arcadiav1alpha1.Embedder,
+	groupIndex, fileIndex int,
+) (err error) {
+	fileDetail := &kb.Status.FileGroupDetail[groupIndex].FileDetails[fileIndex]
+	defer func() {
+		if err != nil {
+			fileDetail.UpdateErr(err, arcadiav1alpha1.FileProcessPhaseFailed)
+		}
+	}()
+	table := findTableSource(kb, kb.Status.FileGroupDetail[groupIndex].Source, fileDetail.Path)
+	if table == nil {
+		return fmt.Errorf("no table %s found in the spec", fileDetail.Path)
+	}
+	if table.Query != "" && table.Name == "" {
+		return fmt.Errorf("name is required for the query of datasource %s", dsObj.Name)
+	}
+	if table.WatermarkColumn != "" && table.KeyColumn == "" {
+		return fmt.Errorf("keyColumn is required with watermarkColumn %s of table %s, to replace the documents of the updated rows", table.WatermarkColumn, fileDetail.Path)
+	}
+	if table.WatermarkColumn != "" && vectorStore.Spec.Type() != arcadiav1alpha1.VectorStoreTypePGVector {
+		return fmt.Errorf("the rows of table %s can't be replaced by the key column in vectorstore %s, only pgvector supports it", fileDetail.Path, vectorStore.Name)
+	}
+	if !embedder.Status.IsReady() {
+		return errEmbedderNotReady
+	}
+	if !vectorStore.Status.IsReady() {
+		return errVectorStoreNotReady
+	}
+	pg, err := datasource.GetPostgreSQLPool(ctx, r.Client, dsObj)
+	if err != nil {
+		return err
+	}
+	startTime := time.Now()
+	log = log.WithValues("table", fileDetail.Path, "watermark", fileDetail.Watermark)
+	rows, err := pg.ReadRows(ctx, table, fileDetail.Watermark)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	embeddingOptions := kb.EmbeddingOptions()
+	em, err := langchainwrap.GetLangchainEmbedder(ctx, embedder, r.Client, "", embeddings.WithBatchSize(embeddingOptions.BatchSize))
+	if err != nil {
+		return err
+	}
+	split, err := splitter.New(ctx, splitter.Options{
+		Type:         embeddingOptions.Splitter,
+		ChunkSize:    embeddingOptions.ChunkSize,
+		ChunkOverlap: pointer.IntDeref(embeddingOptions.ChunkOverlap, arcadiav1alpha1.DefaultChunkOverlap),
+		Embedder:     em,
+	})
+	if err != nil {
+		return err
+	}
+	// the rows loaded again by the watermark are updated, their old documents are replaced batch by batch,
+	// the keys of the rows without content are removed too
+	replace := table.WatermarkColumn != "" && fileDetail.Watermark != ""
+	keyIndex := slices.Index(rows.Columns, table.KeyColumn)
+	var keys []string
+	next := func() ([]string, error) {
+		row, err := rows.Next()
+		if err == nil && replace && keyIndex >= 0 {
+			keys = append(keys, row[keyIndex])
+		}
+		return row, err
+	}
+	removeRows := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		err := vectorstore.RemoveTableRows(ctx, log, vectorStore, kb.VectorStoreCollectionName(), r.Client, fileDetail.Path, keys)
+		keys = keys[:0]
+		return err
+	}
+	loader := pkgdocumentloaders.NewTableReader(fileDetail.Path, rows.Columns, next, pkgdocumentloaders.TableColumns{
+		Content:  table.ContentColumns,
+		Metadata: table.MetadataColumns,
+		Question: table.QuestionColumn,
+		Answer:   table.AnswerColumn,
+		Key:      table.KeyColumn,
+	})
+	fileDetail.ProcessedChunks = 0
+	fileDetail.TotalChunks = 0
+	lastPatch := startTime
+	err = vectorstore.AddDocumentsInBatches(ctx, log, vectorStore, em, kb.VectorStoreCollectionName(), r.Client, func(add func([]schema.Document) error) error {
+		err := loader.Stream(ctx, pkgdocumentloaders.DefaultStreamBatchSize, func(documents []schema.Document) (err error) {
+			// q/a pairs are not split like a QA csv file
+			if !table.IsQA() {
+				if documents, err = split.SplitDocuments(documents); err != nil {
+					return err
+				}
+			}
+			if err = removeRows(); err != nil {
+				return err
+			}
+			if err = add(documents); err != nil {
+				return err
+			}
+			fileDetail.ProcessedChunks += int64(len(documents))
+			if time.Since(lastPatch) >= progressPatchInterval {
+				lastPatch = time.Now()
+				if err := r.patchStatus(ctx, log, kb); err != nil {
+					log.Error(err, "failed to patch the progress of the table", "processedChunks", fileDetail.ProcessedChunks)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return removeRows()
+	})
+	if err != nil {
+		return err
+	}
+	log.V(3).Info("load rows from table", "chunks", fileDetail.ProcessedChunks)
+	fileDetail.TotalChunks = fileDetail.ProcessedChunks
+	tags, err := pg.GetTags(ctx, table)
+	if err != nil {
+		return err
+	}
+	fileDetail.Type = tags[arcadiav1alpha1.ObjectTypeTag]
+	fileDetail.Count = tags[arcadiav1alpha1.ObjectCountTag]
+	fileDetail.Watermark = rows.Watermark
+	fileDetail.TimeCost = time.Since(startTime).Milliseconds()
+	log.Info("handle table succeeded", "timecost(milliseconds)", fileDetail.TimeCost)
+	fileDetail.UpdateErr(nil, arcadiav1alpha1.FileProcessPhaseSucceeded)
+	return nil
+}
+
+// handleFile loads, splits and embeds the file in batches, so a large file never needs to fit in memory.
+// progress is called with the number of chunks added to the vectorstore after each batch.
+func (r *KnowledgeBaseReconciler) handleFile(ctx context.Context, log logr.Logger, file io.ReadCloser, fileName string, tags map[string]string, kb *arcadiav1alpha1.KnowledgeBase, store *arcadiav1alpha1.VectorStore, embedder *arcadiav1alpha1.Embedder, progress func(processed int64)) (err error) {
 	log = log.WithValues("fileName", fileName, "tags", tags)
 	if !embedder.Status.IsReady() {
 		return errEmbedderNotReady
@@ -557,64 +754,62 @@ func (r *KnowledgeBaseReconciler) handleFile(ctx context.Context, log logr.Logge
 	if err != nil {
 		return err
 	}
-	data, err := io.ReadAll(file) // TODO Load large files in pieces to save memory
 	// TODO Line or single line byte exceeds embedder limit
+	loader, err := pkgdocumentloaders.NewLoader(file, fileName, pkgdocumentloaders.LoaderOptions{
+		QA: tags[arcadiav1alpha1.ObjectTypeTag] == arcadiav1alpha1.ObjectTypeQA,
+	})
 	if err != nil {
 		return err
 	}
-	dataReader := bytes.NewReader(data)
-	var documents []schema.Document
-	var loader documentloaders.Loader
-	switch filepath.Ext(fileName) {
-	case ".txt":
-		loader = documentloaders.NewText(dataReader)
-	case ".csv":
-		v, ok := tags[arcadiav1alpha1.ObjectTypeTag]
-		if ok && v == arcadiav1alpha1.ObjectTypeQA {
-			// for qa csv,we skip the text splitter
-			loader = pkgdocumentloaders.NewQACSV(dataReader, fileName)
-		} else {
-			loader = documentloaders.NewCSV(dataReader)
-		}
-	case ".html", ".htm":
-		loader = documentloaders.NewHTML(dataReader)
-	case ".pdf":
-		loader = pkgdocumentloaders.NewPDF(dataReader, fileName)
-	// TODO: support .mp3,.wav
-	default:
-		loader = documentloaders.NewText(dataReader)
-	}
-
-	// initialize text splitter
-	// var split textsplitter.TextSplitter
-	split := textsplitter.NewRecursiveCharacter(
-		textsplitter.WithChunkSize(embeddingOptions.ChunkSize),
-		textsplitter.WithChunkOverlap(pointer.IntDeref(embeddingOptions.ChunkOverlap, arcadiav1alpha1.DefaultChunkOverlap)),
-	)
-	// switch {
-	// case "token":
-	//	split = textsplitter.NewTokenSplitter(
-	//		textsplitter.WithChunkSize(chunkSize),
-	//		textsplitter.WithChunkOverlap(chunkOverlap),
-	//	)
-	// case "markdown":
-	//	split = textsplitter.NewMarkdownTextSplitter(
-	//		textsplitter.WithChunkSize(chunkSize),
-	//		textsplitter.WithChunkOverlap(chunkOverlap),
-	//	)
-	//default:
-	//	split = textsplitter.NewRecursiveCharacter(
-	//		textsplitter.WithChunkSize(chunkSize),
-	//		textsplitter.WithChunkOverlap(chunkOverlap),
-	//	)
-	//}
-
-	documents, err = loader.LoadAndSplit(ctx, split)
+	// for qa csv,we skip the text splitter
+	_, skipSplit := loader.(pkgdocumentloaders.QACSV)
+
+	split, err := splitter.New(ctx, splitter.Options{
+		Type:         embeddingOptions.Splitter,
+		ChunkSize:    embeddingOptions.ChunkSize,
+		ChunkOverlap: pointer.IntDeref(embeddingOptions.ChunkOverlap, arcadiav1alpha1.DefaultChunkOverlap),
+		Embedder:     em,
+	})
 	if err != nil {
 		return err
 	}
+	// pages crawled from a web datasource carry their url and title in the tags
+	pageURL, title := datasource.ParseWebPageTags(tags)
 
-	return vectorstore.AddDocuments(ctx, log, store, em, kb.VectorStoreCollectionName(), r.Client, documents)
+	return vectorstore.AddDocumentsInBatches(ctx, log, store, em, kb.VectorStoreCollectionName(), r.Client, func(add func([]schema.Document) error) error {
+		var processed int64
+		handle := func(documents []schema.Document) (err error) {
+			if !skipSplit {
+				if documents, err = split.SplitDocuments(documents); err != nil {
+					return err
+				}
+			}
+			if pageURL != "" {
+				for i := range documents {
+					if documents[i].Metadata == nil {
+						documents[i].Metadata = make(map[string]any)
+					}
+					documents[i].Metadata[pkgdocumentloaders.URLCol] = pageURL
+					documents[i].Metadata[pkgdocumentloaders.TitleCol] = title
+					documents[i].Metadata[pkgdocumentloaders.ChunkContentCol] = documents[i].PageContent
+				}
+			}
+			if err = add(documents); err != nil {
+				return err
+			}
+			processed += int64(len(documents))
+			progress(processed)
+			return nil
+		}
+		if streamer, ok := loader.(pkgdocumentloaders.Streamer); ok {
+			return streamer.Stream(ctx, pkgdocumentloaders.DefaultStreamBatchSize, handle)
+		}
+		documents, err := loader.Load(ctx)
+		if err != nil {
+			return err
+		}
+		return handle(documents)
+	})
 }
 
 func (r *KnowledgeBaseReconciler) reconcileDelete(ctx context.Context, log logr.Logger, kb *arcadiav1alpha1.KnowledgeBase) {
@@ -690,7 +885,8 @@ func isFileDetailDiff(a, b arcadiav1alpha1.FileDetails) bool {
 		a.TimeCost != b.TimeCost ||
 		a.Phase != b.Phase ||
 		a.ErrMessage != b.ErrMessage ||
-		a.Version != b.Version
+		a.Version != b.Version ||
+		a.Watermark != b.Watermark
 }
 
 func (r *KnowledgeBaseReconciler) syncStatus(ctx context.Context, kb *arcadiav1alpha1.KnowledgeBase) bool {
@@ -724,6 +920,14 @@ func (r *KnowledgeBaseReconciler) syncStatus(ctx context.Context, kb *arcadiav1a
 			})
 			specSource[key][f.Path] = [2]int{index, i}
 		}
+		for _, t := range fg.Tables {
+			newStatus[index].FileDetails = append(newStatus[index].FileDetails, arcadiav1alpha1.FileDetails{
+				Path:           t.SourceName(),
+				Phase:          arcadiav1alpha1.FileProcessPhasePending,
+				LastUpdateTime: now,
+			})
+			specSource[key][t.SourceName()] = [2]int{index, len(newStatus[index].FileDetails) - 1}
+		}
 	}
 
 	for _, fgd := range kb.Status.FileGroupDetail {
@@ -40,13 +40,18 @@ import (
 	agentv1alpha1 "github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
 	apichain "github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
 	documentloaderv1alpha1 "github.com/kubeagi/arcadia/api/app-node/documentloader/v1alpha1"
+	apiguardrail "github.com/kubeagi/arcadia/api/app-node/guardrail/v1alpha1"
 	apiprompt "github.com/kubeagi/arcadia/api/app-node/prompt/v1alpha1"
 	apiretriever "github.com/kubeagi/arcadia/api/app-node/retriever/v1alpha1"
+	apirouter "github.com/kubeagi/arcadia/api/app-node/router/v1alpha1"
 	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
 	evaluationarcadiav1alpha1 "github.com/kubeagi/arcadia/api/evaluation/v1alpha1"
+	agentcontrollers "github.com/kubeagi/arcadia/controllers/app-node/agent"
 	chaincontrollers "github.com/kubeagi/arcadia/controllers/app-node/chain"
+	guardrailcontrollers "github.com/kubeagi/arcadia/controllers/app-node/guardrail"
 	promptcontrollers "github.com/kubeagi/arcadia/controllers/app-node/prompt"
 	retrievertrollers "github.com/kubeagi/arcadia/controllers/app-node/retriever"
+	routercontrollers "github.com/kubeagi/arcadia/controllers/app-node/router"
 	basecontrollers "github.com/kubeagi/arcadia/controllers/base"
 	evaluationcontrollers "github.com/kubeagi/arcadia/controllers/evaluation"
 	"github.com/kubeagi/arcadia/pkg/config"
@@ -75,6 +80,8 @@ func init() {
 	utilruntime.Must(agentv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(rbacv1.AddToScheme(scheme))
 	utilruntime.Must(documentloaderv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(apirouter.AddToScheme(scheme))
+	utilruntime.Must(apiguardrail.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -278,6 +285,13 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "MultiQueryRetriever")
 		os.Exit(1)
 	}
+	if err = (&retrievertrollers.QueryTransformReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "QueryTransform")
+		os.Exit(1)
+	}
 	if err = (&retrievertrollers.MergerRetrieverReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -306,6 +320,34 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "RAG")
 		os.Exit(1)
 	}
+	if err = (&agentcontrollers.AgentReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Agent")
+		os.Exit(1)
+	}
+	if err = (&agentcontrollers.SupervisorReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Supervisor")
+		os.Exit(1)
+	}
+	if err = (&routercontrollers.RouterReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Router")
+		os.Exit(1)
+	}
+	if err = (&guardrailcontrollers.GuardrailReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Guardrail")
+		os.Exit(1)
+	}
 	if err = (&chaincontrollers.APIChainReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -20,9 +20,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/tmc/langchaingo/agents"
-	"github.com/tmc/langchaingo/callbacks"
 	"github.com/tmc/langchaingo/chains"
 	"github.com/tmc/langchaingo/llms"
 	langchaingoschema "github.com/tmc/langchaingo/schema"
@@ -34,6 +34,7 @@ import (
 	"github.com/kubeagi/arcadia/pkg/appruntime/base"
 	"github.com/kubeagi/arcadia/pkg/appruntime/chain"
 	"github.com/kubeagi/arcadia/pkg/appruntime/log"
+	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
 	"github.com/kubeagi/arcadia/pkg/appruntime/tools"
 )
 
@@ -47,6 +48,16 @@ func NewExecutor(baseNode base.BaseNode) *Executor {
 	}
 }
 
+// isEndingNode returns true if the next node is the output node, so the answer of the node is the answer of the application
+func isEndingNode(node base.Node) bool {
+	for _, n := range node.GetNextNode() {
+		if n.Kind() == "output" {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Executor) Run(ctx context.Context, cli client.Client, args map[string]any) (map[string]any, error) {
 	v1, ok := args[base.LangchaingoLLMKeyInArg]
 	if !ok {
@@ -60,7 +71,10 @@ func (p *Executor) Run(ctx context.Context, cli client.Client, args map[string]a
 	if err := cli.Get(ctx, types.NamespacedName{Namespace: p.RefNamespace(), Name: p.Ref.Name}, instance); err != nil {
 		return args, fmt.Errorf("can't find the agent in cluster: %w", err)
 	}
-	allowedTools := tools.InitTools(ctx, instance.Spec.AllowedTools)
+	allowedTools, err := tools.InitTools(ctx, cli, p.RefNamespace(), instance.Spec.AllowedTools)
+	if err != nil {
+		return args, fmt.Errorf("failed to init tools of the agent: %w", err)
+	}
 
 	var history langchaingoschema.ChatMessageHistory
 	if v3, ok := args[base.LangchaingoChatMessageHistoryKeyInArg]; ok && v3 != nil {
@@ -69,30 +83,63 @@ func (p *Executor) Run(ctx context.Context, cli client.Client, args map[string]a
 			return args, errors.New("history not memory.ChatMessageHistory")
 		}
 	}
+	// Only show tool action in the streaming output if configured, and the final answer is streamed
+	// if the agent gives the answer of the application
+	var streamHandler *StreamHandler
+	if needStream, ok := args[base.InputIsNeedStreamKeyInArg].(bool); ok && needStream {
+		showToolAction, streamAnswer := instance.Spec.Options.ShowToolAction, isEndingNode(p)
+		if showToolAction || streamAnswer {
+			streamHandler = NewStreamHandler(args, instance.Spec.GetType(), showToolAction, streamAnswer)
+		}
+		if showToolAction {
+			for _, t := range allowedTools {
+				if actionTool, ok := t.(tools.ActionTool); ok {
+					actionTool.SetActionHandler(*streamHandler)
+				}
+			}
+		}
+	}
 	// Initialize executor using langchaingo
 	executorOptions := func(o *agents.CreationOptions) {
 		agents.WithCallbacksHandler(log.KLogHandler{LogLevel: 3})(o)
 		agents.WithMaxIterations(instance.Spec.Options.MaxIterations)(o)
-		// Only show tool action in the streaming output if configured
-		if instance.Spec.Options.ShowToolAction {
-			if needStream, ok := args[base.InputIsNeedStreamKeyInArg].(bool); ok && needStream {
-				streamHandler := StreamHandler{callbacks.SimpleHandler{}, args}
-				agents.WithCallbacksHandler(streamHandler)(o)
-			}
+		if streamHandler != nil {
+			agents.WithCallbacksHandler(*streamHandler)(o)
 		}
-		agents.WithMemory(chain.GetMemory(llm, instance.Spec.AgentConfig.Options.Memory, history, "input", ""))(o)
-	}
-	executor, err := agents.Initialize(llm, allowedTools, agents.ConversationalReactDescription, executorOptions)
-	if err != nil {
-		return args, fmt.Errorf("failed to initialize executor: %w", err)
+		agents.WithMemory(chain.GetMemory(llm, instance.Spec.AgentConfig.Options.Memory, history, base.GetConversationSummaryFromArg(args), "input", ""))(o)
 	}
-	executor.CallbacksHandler = log.KLogHandler{LogLevel: 3}
 	input := make(map[string]any)
-	if instance.Spec.Prompt != "" {
-		input["input"] = fmt.Sprintf("%s, %s", instance.Spec.Prompt, args["question"])
-	} else {
+	var executor agents.Executor
+	switch agentType := instance.Spec.GetType(); agentType {
+	case v1alpha1.AgentTypeFunctionCalling:
+		// the prompt is used as the system prompt, so the question is sent as it is
 		input["input"] = args["question"]
+		agent := NewFunctionCallingAgent(llm, allowedTools, instance.Spec.Prompt)
+		if streamHandler != nil && isEndingNode(p) {
+			agent.StreamingFunc = func(ctx context.Context, chunk []byte) error {
+				streamHandler.HandleStreamingFunc(ctx, chunk)
+				return nil
+			}
+		}
+		executor = agents.NewExecutor(agent, allowedTools, executorOptions)
+	case v1alpha1.AgentTypeZeroShot, v1alpha1.AgentTypeConversational:
+		if instance.Spec.Prompt != "" {
+			input["input"] = fmt.Sprintf("%s, %s", instance.Spec.Prompt, args["question"])
+		} else {
+			input["input"] = args["question"]
+		}
+		langchaingoAgentType := agents.ZeroShotReactDescription
+		if agentType == v1alpha1.AgentTypeConversational {
+			langchaingoAgentType = agents.ConversationalReactDescription
+		}
+		executor, err = agents.Initialize(llm, allowedTools, langchaingoAgentType, executorOptions)
+		if err != nil {
+			return args, fmt.Errorf("failed to initialize executor: %w", err)
+		}
+	default:
+		return args, fmt.Errorf("unknown agent type %s", agentType)
 	}
+	executor.CallbacksHandler = log.KLogHandler{LogLevel: 3}
 	// chains.Call will add history to args
 	response, err := chains.Call(ctx, executor, input)
 	if err != nil {
@@ -101,5 +148,18 @@ func (p *Executor) Run(ctx context.Context, cli client.Client, args map[string]a
 	}
 	klog.FromContext(ctx).V(5).Info("use agent, blocking out:", response["output"])
 	args[base.AgentOutputInArg] = response["output"]
+	if isEndingNode(p) {
+		answer, _ := response["output"].(string)
+		answer = strings.TrimSpace(answer)
+		args[base.OutputAnswerKeyInArg] = answer
+		if streamHandler != nil {
+			streamHandler.StreamFinalAnswer(ctx, answer)
+		}
+	}
+	for _, t := range allowedTools {
+		if referenceTool, ok := t.(tools.ReferenceTool); ok {
+			args = retriever.AddReferencesToArgs(args, referenceTool.References())
+		}
+	}
 	return args, nil
 }
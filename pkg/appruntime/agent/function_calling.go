@@ -0,0 +1,161 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/agents"
+	"github.com/tmc/langchaingo/llms"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	langchaingotools "github.com/tmc/langchaingo/tools"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/tools"
+	"github.com/kubeagi/arcadia/pkg/langchainwrap"
+)
+
+const (
+	// stringInputArg is the only argument of the tools which do not describe their input with a json schema,
+	// it is the argument read by agents.OpenAIFunctionsAgent
+	stringInputArg = "__arg1"
+	// historyKey is the key of the chat history loaded by the memory of the executor
+	historyKey = "history"
+
+	defaultFunctionCallingSystemPrompt = "You are a helpful assistant. Use the provided functions to get the information you need, then answer the question of the user."
+)
+
+// FunctionCallingAgent is agents.OpenAIFunctionsAgent with the json schemas of the tools as the parameters of the
+// functions, and the chat history in the system message.
+// The openai llm of langchaingo returns an error for the function messages in the scratchpad of OpenAIFunctionsAgent,
+// so the function calls and their results are encoded by langchainwrap, and converted to the function or tool messages
+// by the http clients of the OpenAI compatible llms.
+type FunctionCallingAgent struct {
+	*agents.OpenAIFunctionsAgent
+	// SystemPrompt is the instruction of the agent
+	SystemPrompt string
+	// StreamingFunc streams the answer of the llm, the function calls are not streamed
+	StreamingFunc func(ctx context.Context, chunk []byte) error
+}
+
+var _ agents.Agent = (*FunctionCallingAgent)(nil)
+
+func NewFunctionCallingAgent(llm llms.Model, tools []langchaingotools.Tool, systemPrompt string) *FunctionCallingAgent {
+	if systemPrompt == "" {
+		systemPrompt = defaultFunctionCallingSystemPrompt
+	}
+	return &FunctionCallingAgent{
+		OpenAIFunctionsAgent: agents.NewOpenAIFunctionsAgent(llm, tools, agents.NewOpenAIOption().WithSystemMessage(systemPrompt)),
+		SystemPrompt:         systemPrompt,
+	}
+}
+
+func (a *FunctionCallingAgent) Plan(ctx context.Context, intermediateSteps []langchaingoschema.AgentStep, inputs map[string]string) ([]langchaingoschema.AgentAction, *langchaingoschema.AgentFinish, error) {
+	system := a.SystemPrompt
+	if history := inputs[historyKey]; history != "" {
+		system = fmt.Sprintf("%s\n\nThe conversation history:\n%s", system, history)
+	}
+	messages := []llms.MessageContent{
+		llms.TextParts(langchaingoschema.ChatMessageTypeSystem, system),
+		llms.TextParts(langchaingoschema.ChatMessageTypeHuman, inputs["input"]),
+	}
+	for i, step := range intermediateSteps {
+		id := fmt.Sprintf("call_%d", i)
+		messages = append(messages,
+			langchainwrap.FunctionCallMessage(id, step.Action.Tool, a.arguments(step.Action)),
+			langchainwrap.FunctionResultMessage(id, step.Action.Tool, step.Observation),
+		)
+	}
+	options := []llms.CallOption{llms.WithFunctions(a.functions())}
+	if a.StreamingFunc != nil {
+		options = append(options, llms.WithStreamingFunc(a.streamAnswer()))
+	}
+	resp, err := a.LLM.GenerateContent(ctx, messages, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, nil, errors.New("no choices in the llm response")
+	}
+	actions, finish, err := a.ParseOutput(resp)
+	for i := range actions {
+		actions[i].Tool = strings.TrimSpace(actions[i].Tool)
+	}
+	return actions, finish, err
+}
+
+// GetInputKeys returns the input of the question, the history is optional
+func (a *FunctionCallingAgent) GetInputKeys() []string {
+	return []string{"input"}
+}
+
+// arguments returns the arguments of the function call in json, the string input of the tools without parameters
+// is unwrapped by OpenAIFunctionsAgent.ParseOutput
+func (a *FunctionCallingAgent) arguments(action langchaingoschema.AgentAction) string {
+	for _, tool := range a.Tools {
+		if _, ok := tool.(tools.ParametersTool); ok && tool.Name() == action.Tool {
+			return action.ToolInput
+		}
+	}
+	data, _ := json.Marshal(map[string]string{stringInputArg: action.ToolInput}) //nolint:errchkjson
+	return string(data)
+}
+
+// streamAnswer returns the streaming func of a llm call, which calls StreamingFunc with the content,
+// and skips the rest of the output after the llm starts to call a function.
+// The streaming chunk of a function call is the function call in json, with the name and the arguments so far.
+func (a *FunctionCallingAgent) streamAnswer() func(ctx context.Context, chunk []byte) error {
+	calling := false
+	return func(ctx context.Context, chunk []byte) error {
+		if calling || len(chunk) == 0 {
+			return nil
+		}
+		var call langchaingoschema.FunctionCall
+		if json.Unmarshal(chunk, &call) == nil && call.Name != "" {
+			calling = true
+			return nil
+		}
+		return a.StreamingFunc(ctx, chunk)
+	}
+}
+
+func (a *FunctionCallingAgent) functions() []llms.FunctionDefinition {
+	res := make([]llms.FunctionDefinition, 0, len(a.Tools))
+	for _, tool := range a.Tools {
+		var parameters map[string]any
+		if t, ok := tool.(tools.ParametersTool); ok {
+			parameters = t.Parameters()
+		} else {
+			parameters = map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					stringInputArg: map[string]any{"type": "string", "description": "the input of the function"},
+				},
+				"required": []string{stringInputArg},
+			}
+		}
+		res = append(res, llms.FunctionDefinition{
+			Name:        tool.Name(),
+			Description: tool.Description(),
+			Parameters:  parameters,
+		})
+	}
+	return res
+}
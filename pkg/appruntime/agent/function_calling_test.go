@@ -0,0 +1,153 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	langchaingotools "github.com/tmc/langchaingo/tools"
+
+	"github.com/kubeagi/arcadia/pkg/langchainwrap"
+)
+
+// fakeFunctionLLM returns the responses in order, and records the messages and functions of the last call.
+// The responses are streamed like the openai llm of langchaingo if the streaming func is set.
+type fakeFunctionLLM struct {
+	responses []*llms.ContentChoice
+	messages  []llms.MessageContent
+	functions []llms.FunctionDefinition
+}
+
+func (f *fakeFunctionLLM) GenerateContent(_ context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	opts := llms.CallOptions{}
+	for _, o := range options {
+		o(&opts)
+	}
+	f.messages, f.functions = messages, opts.Functions
+	choice := f.responses[0]
+	f.responses = f.responses[1:]
+	if opts.StreamingFunc != nil {
+		chunks := []string{"", choice.Content}
+		if choice.FuncCall != nil {
+			data, _ := json.Marshal(choice.FuncCall)
+			chunks = append(chunks, string(data))
+		}
+		for _, chunk := range chunks {
+			if err := opts.StreamingFunc(context.Background(), []byte(chunk)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{choice}}, nil
+}
+
+func (f *fakeFunctionLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+type fakeTool struct {
+	name       string
+	parameters map[string]any
+}
+
+func (t fakeTool) Name() string        { return t.name }
+func (t fakeTool) Description() string { return "the " + t.name }
+func (t fakeTool) Call(_ context.Context, input string) (string, error) {
+	return t.name + " of " + input, nil
+}
+
+type fakeParametersTool struct {
+	fakeTool
+}
+
+func (t fakeParametersTool) Parameters() map[string]any { return t.parameters }
+
+func TestFunctionCallingAgent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	llm := &fakeFunctionLLM{responses: []*llms.ContentChoice{
+		{FuncCall: &langchaingoschema.FunctionCall{Name: " weather ", Arguments: `{"__arg1":"Beijing"}`}},
+		{FuncCall: &langchaingoschema.FunctionCall{Name: "pets", Arguments: `{"limit":2}`}},
+		{Content: "It is sunny, and there are 2 pets."},
+	}}
+	petsParameters := map[string]any{"type": "object", "properties": map[string]any{"limit": map[string]any{"type": "integer"}}}
+	agent := NewFunctionCallingAgent(llm, []langchaingotools.Tool{
+		fakeTool{name: "weather"},
+		fakeParametersTool{fakeTool{name: "pets", parameters: petsParameters}},
+	}, "")
+	inputs := map[string]string{"input": "how is the weather in Beijing", historyKey: "Human: hi\nAI: hello"}
+
+	actions, finish, err := agent.Plan(ctx, nil, inputs)
+	require.NoError(t, err)
+	require.Nil(t, finish)
+	require.Len(t, actions, 1)
+	// the string input of the tool is unwrapped
+	assert.Equal(t, "weather", actions[0].Tool)
+	assert.Equal(t, "Beijing", actions[0].ToolInput)
+	require.Len(t, llm.functions, 2)
+	assert.Contains(t, llm.functions[0].Parameters.(map[string]any)["properties"], stringInputArg)
+	assert.Equal(t, petsParameters, llm.functions[1].Parameters)
+	assert.Contains(t, llm.messages[0].Parts[0].(llms.TextContent).Text, "Human: hi\nAI: hello")
+
+	steps := []langchaingoschema.AgentStep{{Action: actions[0], Observation: "sunny"}}
+	actions, _, err = agent.Plan(ctx, steps, inputs)
+	require.NoError(t, err)
+	// the arguments are passed as they are to the tools with parameters
+	assert.Equal(t, `{"limit":2}`, actions[0].ToolInput)
+	require.Len(t, llm.messages, 4)
+	// the string input is wrapped again in the function call
+	assert.Equal(t, langchainwrap.FunctionCallMessage("call_0", "weather", `{"__arg1":"Beijing"}`), llm.messages[2])
+	assert.Equal(t, langchainwrap.FunctionResultMessage("call_0", "weather", "sunny"), llm.messages[3])
+
+	_, finish, err = agent.Plan(ctx, steps, inputs)
+	require.NoError(t, err)
+	require.NotNil(t, finish)
+	assert.Equal(t, "It is sunny, and there are 2 pets.", finish.ReturnValues["output"])
+}
+
+func TestFunctionCallingAgentStreaming(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	llm := &fakeFunctionLLM{responses: []*llms.ContentChoice{
+		{Content: "Let me check.", FuncCall: &langchaingoschema.FunctionCall{Name: "weather", Arguments: `{"__arg1":"Beijing"}`}},
+		{Content: "It is sunny."},
+	}}
+	agent := NewFunctionCallingAgent(llm, []langchaingotools.Tool{fakeTool{name: "weather"}}, "")
+	var streamed []string
+	agent.StreamingFunc = func(_ context.Context, chunk []byte) error {
+		streamed = append(streamed, string(chunk))
+		return nil
+	}
+	inputs := map[string]string{"input": "how is the weather in Beijing"}
+
+	actions, _, err := agent.Plan(ctx, nil, inputs)
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	// the content before the function call is streamed, but the function call is not
+	assert.Equal(t, []string{"Let me check."}, streamed)
+
+	_, finish, err := agent.Plan(ctx, []langchaingoschema.AgentStep{{Action: actions[0], Observation: "sunny"}}, inputs)
+	require.NoError(t, err)
+	require.NotNil(t, finish)
+	assert.Equal(t, []string{"Let me check.", "It is sunny."}, streamed)
+}
@@ -19,22 +19,97 @@ package agent
 import (
 	"context"
 	"fmt"
+	"strings"
+	"unicode"
 
 	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/schema"
 	"k8s.io/klog/v2"
 
+	"github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
 	"github.com/kubeagi/arcadia/pkg/appruntime/base"
 )
 
+const (
+	// finalAnswerPrefix is the prefix of the final answer of the zero shot ReAct agent
+	finalAnswerPrefix = "Final Answer:"
+	// conversationalFinalAnswerPrefix is the prefix of the final answer of the conversational ReAct agent
+	conversationalFinalAnswerPrefix = "AI:"
+)
+
 // StreamHandler is a callback handler that prints to the standard output streaming.
 type StreamHandler struct {
 	callbacks.SimpleHandler
-	args map[string]any
+	args      map[string]any
+	agentType v1alpha1.AgentType
+	// showToolAction streams the tools called by the agent
+	showToolAction bool
+	// answer streams the final answer, nil if the final answer is not streamed
+	answer *finalAnswerStream
 }
 
 var _ callbacks.Handler = StreamHandler{}
 
+// NewStreamHandler returns a handler which streams the tool actions if showToolAction is true,
+// and the final answer if streamAnswer is true
+func NewStreamHandler(args map[string]any, agentType v1alpha1.AgentType, showToolAction, streamAnswer bool) *StreamHandler {
+	handler := &StreamHandler{args: args, agentType: agentType, showToolAction: showToolAction}
+	if streamAnswer {
+		prefix := finalAnswerPrefix
+		switch agentType {
+		case v1alpha1.AgentTypeConversational:
+			prefix = conversationalFinalAnswerPrefix
+		case v1alpha1.AgentTypeFunctionCalling:
+			// the function calls are skipped by the agent, so the streamed content is the answer
+			prefix = ""
+		}
+		handler.answer = &finalAnswerStream{prefix: prefix}
+	}
+	return handler
+}
+
+// HandleChainStart is called before every llm call of the ReAct agents, which is a new output to look for the final answer
+func (handler StreamHandler) HandleChainStart(_ context.Context, _ map[string]any) {
+	if handler.answer != nil {
+		handler.answer.reset()
+	}
+}
+
+// HandleStreamingFunc streams the final answer in the output of the llm, the thoughts and the actions are never streamed
 func (handler StreamHandler) HandleStreamingFunc(ctx context.Context, chunk []byte) {
+	if handler.answer == nil {
+		return
+	}
+	if answer := handler.answer.write(string(chunk)); answer != "" {
+		handler.stream(ctx, answer)
+	}
+}
+
+// HandleAgentAction streams the tool the agent is going to call, with the agent type
+func (handler StreamHandler) HandleAgentAction(ctx context.Context, action schema.AgentAction) {
+	if handler.showToolAction {
+		handler.stream(ctx, fmt.Sprintf("[%s] Calling tool %s with input: %s\n", handler.agentType, action.Tool, action.ToolInput))
+	}
+}
+
+// HandleText streams the actions reported by the tools, like the executed sql
+func (handler StreamHandler) HandleText(ctx context.Context, text string) {
+	if handler.showToolAction {
+		handler.stream(ctx, fmt.Sprintf("[%s] %s\n", handler.agentType, text))
+	}
+}
+
+// StreamFinalAnswer streams the whole final answer if it is not streamed by the llm,
+// like the llm doesn't support streaming or the answer of the function calling agent
+func (handler StreamHandler) StreamFinalAnswer(ctx context.Context, answer string) {
+	if handler.answer == nil || handler.answer.streamed || answer == "" {
+		return
+	}
+	handler.answer.streamed = true
+	handler.stream(ctx, answer)
+}
+
+func (handler StreamHandler) stream(ctx context.Context, chunk string) {
 	if _, ok := handler.args[base.OutputAnswerStreamChanKeyInArg]; ok {
 		logger := klog.FromContext(ctx)
 		streamChan, ok := handler.args[base.OutputAnswerStreamChanKeyInArg].(chan string)
@@ -43,7 +118,47 @@ func (handler StreamHandler) HandleStreamingFunc(ctx context.Context, chunk []by
 			logger.Error(err, "answer_stream is not chan string")
 			return
 		}
-		logger.V(5).Info("stream out:" + string(chunk))
-		streamChan <- string(chunk)
+		logger.V(5).Info("stream out:" + chunk)
+		streamChan <- chunk
+	}
+}
+
+// finalAnswerStream finds the prefix of the final answer in the streamed output of the llm,
+// and returns the text after it. The text before the prefix is kept until the prefix is found,
+// because the prefix may be split into several chunks.
+type finalAnswerStream struct {
+	prefix string
+	buffer strings.Builder
+	// found is true after the prefix is found in the current output
+	found bool
+	// streamed is true if any text of the final answer is streamed
+	streamed bool
+}
+
+func (s *finalAnswerStream) reset() {
+	s.buffer.Reset()
+	s.found = false
+}
+
+// write adds a chunk of the output, and returns the text of the final answer to stream
+func (s *finalAnswerStream) write(chunk string) string {
+	if !s.found {
+		s.buffer.WriteString(chunk)
+		output := s.buffer.String()
+		i := strings.Index(output, s.prefix)
+		if i < 0 {
+			return ""
+		}
+		s.found = true
+		chunk = output[i+len(s.prefix):]
+	}
+	// the spaces between the prefix and the answer
+	if !s.streamed {
+		chunk = strings.TrimLeftFunc(chunk, unicode.IsSpace)
+		if chunk == "" {
+			return ""
+		}
+		s.streamed = true
 	}
+	return chunk
 }
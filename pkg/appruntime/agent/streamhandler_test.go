@@ -0,0 +1,80 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+func TestStreamHandler(t *testing.T) {
+	ctx := context.Background()
+	run := func(handler *StreamHandler, outputs ...[]string) string {
+		streamChan := make(chan string, 100)
+		handler.args = map[string]any{base.OutputAnswerStreamChanKeyInArg: streamChan}
+		for _, chunks := range outputs {
+			handler.HandleChainStart(ctx, nil)
+			for _, chunk := range chunks {
+				handler.HandleStreamingFunc(ctx, []byte(chunk))
+			}
+		}
+		close(streamChan)
+		var streamed strings.Builder
+		for chunk := range streamChan {
+			streamed.WriteString(chunk)
+		}
+		return streamed.String()
+	}
+
+	// the thoughts and the actions are not streamed, and the prefix may be split into chunks
+	handler := NewStreamHandler(nil, v1alpha1.AgentTypeZeroShot, false, true)
+	streamed := run(handler,
+		[]string{"Thought: I need the weather.\nAction: weather\nAction Input: Beijing"},
+		[]string{"Thought: I know the answer.\nFinal ", "Answer", ":", " ", "It is ", "sunny."},
+	)
+	assert.Equal(t, "It is sunny.", streamed)
+
+	// the conversational agent answers after "AI:"
+	handler = NewStreamHandler(nil, v1alpha1.AgentTypeConversational, false, true)
+	assert.Equal(t, "Hello!", run(handler, []string{"Thought: Do I need to use a tool? No\nA", "I: Hello", "!"}))
+	// the answer is streamed only once
+	handler.StreamFinalAnswer(ctx, "Hello!")
+	assert.Len(t, handler.args[base.OutputAnswerStreamChanKeyInArg], 0)
+
+	// the answer is streamed at last if the llm doesn't stream
+	handler = NewStreamHandler(nil, v1alpha1.AgentTypeFunctionCalling, false, true)
+	streamChan := make(chan string, 1)
+	handler.args = map[string]any{base.OutputAnswerStreamChanKeyInArg: streamChan}
+	handler.StreamFinalAnswer(ctx, "It is sunny.")
+	assert.Equal(t, "It is sunny.", <-streamChan)
+
+	// only the tool actions are streamed if the answer is given by the next nodes
+	handler = NewStreamHandler(nil, v1alpha1.AgentTypeZeroShot, true, false)
+	streamChan = make(chan string, 10)
+	handler.args = map[string]any{base.OutputAnswerStreamChanKeyInArg: streamChan}
+	handler.HandleStreamingFunc(ctx, []byte("Final Answer: It is sunny."))
+	handler.HandleAgentAction(ctx, schema.AgentAction{Tool: "weather", ToolInput: "Beijing"})
+	assert.Equal(t, "[zeroShot] Calling tool weather with input: Beijing\n", <-streamChan)
+	assert.Len(t, streamChan, 0)
+}
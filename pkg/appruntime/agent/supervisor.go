@@ -0,0 +1,309 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/memory"
+	"github.com/tmc/langchaingo/prompts"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+)
+
+const (
+	defaultSupervisorInstruction = "You are a supervisor who completes the task of the user by handing sub-tasks to the agents below."
+
+	supervisorPlanPrompt = `{{.instruction}}
+
+Agents:
+{{.agents}}
+
+Hand one sub-task to one of the agents at a time, the sub-task should include all the information the agent needs.
+Finish when the results of the agents are enough to answer the task.
+Answer with a JSON object only, {"agent": "<agent name>", "task": "<the sub-task>"} to hand off a sub-task, or {"finish": true} to finish.
+
+Conversation history:
+{{.history}}
+
+Task: {{.question}}
+
+Results of the agents so far:
+{{.results}}`
+
+	supervisorAnswerPrompt = `{{.instruction}}
+
+Answer the task with the results of the agents.
+
+Conversation history:
+{{.history}}
+
+Task: {{.question}}
+
+Results of the agents:
+{{.results}}
+
+Answer:`
+
+	// supervisorStreamName is the name in the streamed hand-offs, like the agent type of the tool actions of an agent
+	supervisorStreamName v1alpha1.AgentType = "supervisor"
+)
+
+// Supervisor plans the task with the llm, hands the sub-tasks to the worker agents one by one,
+// and answers with their results when the planner finishes or the max turns are reached
+type Supervisor struct {
+	base.BaseNode
+	Instance *v1alpha1.Supervisor
+}
+
+func NewSupervisor(baseNode base.BaseNode) *Supervisor {
+	return &Supervisor{
+		BaseNode: baseNode,
+	}
+}
+
+// supervisorStep is the decision of the planner in a turn
+type supervisorStep struct {
+	Agent  string `json:"agent"`
+	Task   string `json:"task"`
+	Finish bool   `json:"finish"`
+}
+
+// supervisorResult is the result of a sub-task, or a wrong decision of the planner
+type supervisorResult struct {
+	agent  string
+	task   string
+	output string
+}
+
+func (s *Supervisor) Init(ctx context.Context, cli client.Client, _ map[string]any) error {
+	instance := &v1alpha1.Supervisor{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: s.RefNamespace(), Name: s.Ref.Name}, instance); err != nil {
+		return fmt.Errorf("can't find the supervisor in cluster: %w", err)
+	}
+	s.Instance = instance
+	return nil
+}
+
+func (s *Supervisor) Run(ctx context.Context, cli client.Client, args map[string]any) (map[string]any, error) {
+	v1, ok := args[base.LangchaingoLLMKeyInArg]
+	if !ok {
+		return args, errors.New("no llm")
+	}
+	llm, ok := v1.(llms.Model)
+	if !ok {
+		return args, errors.New("llm not llms.Model")
+	}
+	question, err := base.GetInputQuestionFromArg(args)
+	if err != nil {
+		return args, err
+	}
+	history := ""
+	if h, ok := args[base.LangchaingoChatMessageHistoryKeyInArg].(langchaingoschema.ChatMessageHistory); ok && h != nil {
+		messages, err := h.Messages(ctx)
+		if err != nil {
+			return args, fmt.Errorf("failed to load the history: %w", err)
+		}
+		if history, err = langchaingoschema.GetBufferString(messages, "Human", "AI"); err != nil {
+			return args, err
+		}
+	}
+	ending := isEndingNode(s)
+	var streamHandler *StreamHandler
+	if needStream, ok := args[base.InputIsNeedStreamKeyInArg].(bool); ok && needStream {
+		streamHandler = NewStreamHandler(args, supervisorStreamName, s.Instance.Spec.GetShowHandoff(), false)
+	}
+
+	values := map[string]any{
+		"instruction": s.instruction(),
+		"agents":      s.agentList(),
+		"history":     history,
+		"question":    question,
+	}
+	logger := klog.FromContext(ctx).WithValues("supervisor", s.Name())
+	tracer := base.NodeTracerFromContext(ctx)
+	var results []supervisorResult
+	for turn := 0; turn < s.Instance.Spec.GetMaxTurns(); turn++ {
+		values["results"] = formatSupervisorResults(results)
+		answer, err := s.generate(ctx, llm, supervisorPlanPrompt, values, llms.WithTemperature(0))
+		if err != nil {
+			return args, fmt.Errorf("failed to plan the task: %w", err)
+		}
+		step, err := parseSupervisorStep(answer)
+		if err != nil {
+			// the wrong answer is shown to the planner in the next turn
+			results = append(results, supervisorResult{output: err.Error()})
+			continue
+		}
+		if step.Finish {
+			break
+		}
+		if !s.hasAgent(step.Agent) {
+			results = append(results, supervisorResult{agent: step.Agent, task: step.Task, output: fmt.Sprintf("there is no agent named %s", step.Agent)})
+			continue
+		}
+		logger.V(3).Info("hand off a sub-task", "turn", turn, "agent", step.Agent, "task", step.Task)
+		tracer.AddEvent(base.TraceEventAgentAction, fmt.Sprintf("hand off to %s: %s", step.Agent, step.Task))
+		if streamHandler != nil {
+			streamHandler.HandleAgentAction(ctx, langchaingoschema.AgentAction{Tool: step.Agent, ToolInput: step.Task})
+		}
+		output, err := s.handoff(ctx, cli, args, step.Agent, step.Task)
+		if err != nil {
+			return args, fmt.Errorf("agent %s failed: %w", step.Agent, err)
+		}
+		results = append(results, supervisorResult{agent: step.Agent, task: step.Task, output: output})
+	}
+
+	values["results"] = formatSupervisorResults(results)
+	var options []llms.CallOption
+	if ending && streamHandler != nil {
+		options = append(options, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+			streamHandler.stream(ctx, string(chunk))
+			return nil
+		}))
+	}
+	answer, err := s.generate(ctx, llm, supervisorAnswerPrompt, values, options...)
+	if err != nil {
+		return args, fmt.Errorf("failed to answer the task: %w", err)
+	}
+	answer = strings.TrimSpace(answer)
+	tracer.AddEvent(base.TraceEventAgentFinish, answer)
+	args[base.AgentOutputInArg] = answer
+	if ending {
+		args[base.OutputAnswerKeyInArg] = answer
+	}
+	return args, nil
+}
+
+// handoff runs the worker agent with the sub-task as the question, and returns the output of the agent.
+// The agent has its own history, so the sub-tasks are not saved in the conversation.
+func (s *Supervisor) handoff(ctx context.Context, cli client.Client, args map[string]any, agentName, task string) (string, error) {
+	workerArgs := make(map[string]any, len(args))
+	for k, v := range args {
+		workerArgs[k] = v
+	}
+	workerArgs[base.InputQuestionKeyInArg] = task
+	workerArgs[base.LangchaingoChatMessageHistoryKeyInArg] = memory.NewChatMessageHistory()
+	ref := arcadiav1alpha1.TypedObjectReference{
+		APIGroup:  pointer.String(v1alpha1.Group),
+		Kind:      "Agent",
+		Name:      agentName,
+		Namespace: pointer.String(s.RefNamespace()),
+	}
+	worker := NewExecutor(base.NewBaseNode(s.Namespace(), s.Name()+"/"+agentName, ref))
+	out, err := worker.Run(ctx, cli, workerArgs)
+	if err != nil {
+		return "", err
+	}
+	// the references found by the tools of the agent are the references of the answer
+	if references, _ := out[base.RuntimeRetrieverReferencesKeyInArg].([]retriever.Reference); len(references) > 0 {
+		args[base.RuntimeRetrieverReferencesKeyInArg] = append([]retriever.Reference(nil), references...)
+	}
+	output, _ := out[base.AgentOutputInArg].(string)
+	if output == "" {
+		output = "the agent gave no result"
+	}
+	return strings.TrimSpace(output), nil
+}
+
+func (s *Supervisor) generate(ctx context.Context, llm llms.Model, template string, values map[string]any, options ...llms.CallOption) (string, error) {
+	prompt, err := prompts.NewPromptTemplate(template, []string{"instruction", "agents", "history", "question", "results"}).Format(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to format the supervisor prompt: %w", err)
+	}
+	tracer := base.NodeTracerFromContext(ctx)
+	tracer.AddEvent(base.TraceEventLLMStart, prompt)
+	answer, err := llms.GenerateFromSinglePrompt(ctx, llm, prompt, options...)
+	if err != nil {
+		tracer.AddEvent(base.TraceEventLLMError, err.Error())
+		return "", err
+	}
+	tracer.AddEvent(base.TraceEventLLMEnd, answer)
+	return answer, nil
+}
+
+func (s *Supervisor) instruction() string {
+	if s.Instance.Spec.Prompt != "" {
+		return s.Instance.Spec.Prompt + "\n" + defaultSupervisorInstruction
+	}
+	return defaultSupervisorInstruction
+}
+
+func (s *Supervisor) agentList() string {
+	agents := make([]string, 0, len(s.Instance.Spec.Agents))
+	for _, a := range s.Instance.Spec.Agents {
+		agents = append(agents, fmt.Sprintf("- %s: %s", a.Name, a.Description))
+	}
+	return strings.Join(agents, "\n")
+}
+
+func (s *Supervisor) hasAgent(name string) bool {
+	for _, a := range s.Instance.Spec.Agents {
+		if a.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSupervisorStep parses the JSON object in the answer of the planner
+func parseSupervisorStep(answer string) (supervisorStep, error) {
+	var step supervisorStep
+	start, end := strings.Index(answer, "{"), strings.LastIndex(answer, "}")
+	if start < 0 || end < start {
+		return step, fmt.Errorf("the answer %q is not a JSON object", answer)
+	}
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &step); err != nil {
+		return step, fmt.Errorf("the answer %q is not a valid JSON object: %w", answer, err)
+	}
+	if !step.Finish && (step.Agent == "" || step.Task == "") {
+		return step, fmt.Errorf("the answer %q has no agent and task, and does not finish", answer)
+	}
+	return step, nil
+}
+
+func formatSupervisorResults(results []supervisorResult) string {
+	if len(results) == 0 {
+		return "none"
+	}
+	var b strings.Builder
+	for i, r := range results {
+		if r.agent == "" {
+			fmt.Fprintf(&b, "%d. Wrong answer: %s\n", i+1, r.output)
+			continue
+		}
+		fmt.Fprintf(&b, "%d. Agent %s was asked: %s\nResult: %s\n", i+1, r.agent, r.task, r.output)
+	}
+	return b.String()
+}
+
+func (s *Supervisor) Ready() (isReady bool, msg string) {
+	return s.Instance.Status.IsReadyOrGetReadyMessage()
+}
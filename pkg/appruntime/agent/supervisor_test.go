@@ -0,0 +1,149 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/memory"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+// fakeAnswersLLM returns the answers in order and streams them, and records the prompts
+type fakeAnswersLLM struct {
+	answers []string
+	prompts []string
+}
+
+func (f *fakeAnswersLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	opts := llms.CallOptions{}
+	for _, o := range options {
+		o(&opts)
+	}
+	f.prompts = append(f.prompts, messages[0].Parts[0].(llms.TextContent).Text)
+	answer := f.answers[0]
+	f.answers = f.answers[1:]
+	if opts.StreamingFunc != nil {
+		if err := opts.StreamingFunc(ctx, []byte(answer)); err != nil {
+			return nil, err
+		}
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: answer}}}, nil
+}
+
+func (f *fakeAnswersLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+func TestSupervisor(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	supervisor := &v1alpha1.Supervisor{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "supervisor"},
+		Spec: v1alpha1.SupervisorSpec{SupervisorConfig: v1alpha1.SupervisorConfig{
+			Agents:   []v1alpha1.SupervisedAgent{{Name: "researcher", Description: "finds the prices of products"}},
+			MaxTurns: 3,
+		}},
+	}
+	researcher := &v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "researcher"},
+		Spec:       v1alpha1.AgentSpec{AgentConfig: v1alpha1.AgentConfig{Type: v1alpha1.AgentTypeZeroShot, Options: v1alpha1.Options{MaxIterations: 3}}},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(supervisor, researcher).Build()
+	newSupervisor := func() *Supervisor {
+		s := NewSupervisor(base.NewBaseNode("default", "supervisor-node", arcadiav1alpha1.TypedObjectReference{APIGroup: pointer.String(v1alpha1.Group), Kind: "Supervisor", Name: "supervisor"}))
+		s.SetNextNode(base.NewOutput(base.NewBaseNode("default", "Output", arcadiav1alpha1.TypedObjectReference{Kind: "Output", Name: "Output"})))
+		require.NoError(t, s.Init(ctx, cli, nil))
+		return s
+	}
+	run := func(s *Supervisor, llm *fakeAnswersLLM) (map[string]any, string) {
+		streamChan := make(chan string, 10)
+		args, err := s.Run(ctx, cli, map[string]any{
+			base.InputQuestionKeyInArg:                 "how much is the cat food?",
+			base.LangchaingoLLMKeyInArg:                llm,
+			base.LangchaingoChatMessageHistoryKeyInArg: memory.NewChatMessageHistory(),
+			base.InputIsNeedStreamKeyInArg:             true,
+			base.OutputAnswerStreamChanKeyInArg:        streamChan,
+		})
+		require.NoError(t, err)
+		close(streamChan)
+		var streamed strings.Builder
+		for chunk := range streamChan {
+			streamed.WriteString(chunk)
+		}
+		return args, streamed.String()
+	}
+
+	// the planner hands a sub-task to the agent, gets a wrong agent name back, then finishes
+	llm := &fakeAnswersLLM{answers: []string{
+		`{"agent": "researcher", "task": "find the price of the cat food"}`,
+		"Thought: I know the price.\nFinal Answer: 42 dollars",
+		`I will ask {"agent": "writer", "task": "write an email"}`,
+		`{"finish": true}`,
+		"The cat food is 42 dollars.",
+	}}
+	args, streamed := run(newSupervisor(), llm)
+	assert.Equal(t, "The cat food is 42 dollars.", args[base.OutputAnswerKeyInArg])
+	assert.Equal(t, "The cat food is 42 dollars.", args[base.AgentOutputInArg])
+	assert.Equal(t, "[supervisor] Calling tool researcher with input: find the price of the cat food\nThe cat food is 42 dollars.", streamed)
+	require.Len(t, llm.prompts, 5)
+	assert.Contains(t, llm.prompts[0], "- researcher: finds the prices of products")
+	assert.Contains(t, llm.prompts[1], "Question: find the price of the cat food")
+	assert.Contains(t, llm.prompts[3], "1. Agent researcher was asked: find the price of the cat food\nResult: 42 dollars\n2. Agent writer was asked: write an email\nResult: there is no agent named writer")
+	assert.Contains(t, llm.prompts[4], "Task: how much is the cat food?")
+
+	// the supervisor answers with the results so far after the max turns
+	llm = &fakeAnswersLLM{answers: []string{
+		`{"agent": "researcher", "task": "find the price"}`,
+		"Final Answer: 42 dollars",
+		"not a step",
+		`{"agent": "researcher", "task": "find the price again"}`,
+		"Final Answer: 42 dollars",
+		"The cat food is 42 dollars.",
+	}}
+	args, _ = run(newSupervisor(), llm)
+	assert.Equal(t, "The cat food is 42 dollars.", args[base.OutputAnswerKeyInArg])
+	require.Len(t, llm.prompts, 6)
+	assert.Contains(t, llm.prompts[5], `2. Wrong answer: the answer "not a step" is not a JSON object`)
+}
+
+func TestParseSupervisorStep(t *testing.T) {
+	step, err := parseSupervisorStep("```json\n{\"agent\": \"researcher\", \"task\": \"find the price\"}\n```")
+	require.NoError(t, err)
+	assert.Equal(t, supervisorStep{Agent: "researcher", Task: "find the price"}, step)
+	step, err = parseSupervisorStep(`{"finish": true}`)
+	require.NoError(t, err)
+	assert.True(t, step.Finish)
+	_, err = parseSupervisorStep(`{"agent": "researcher"}`)
+	assert.ErrorContains(t, err, "has no agent and task")
+	_, err = parseSupervisorStep(`{"agent": researcher}`)
+	assert.ErrorContains(t, err, "not a valid JSON object")
+}
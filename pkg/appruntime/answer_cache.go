@@ -0,0 +1,266 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/tmc/langchaingo/embeddings"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appnode "github.com/kubeagi/arcadia/api/app-node"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	"github.com/kubeagi/arcadia/pkg/langchainwrap"
+	pkgvectorstore "github.com/kubeagi/arcadia/pkg/vectorstore"
+)
+
+// metadata keys of the cached answers, the page content is the question
+const (
+	answerCacheVersionKey    = "kb_version"
+	answerCacheCreatedAtKey  = "created_at"
+	answerCacheAnswerKey     = "answer"
+	answerCacheReferencesKey = "references"
+)
+
+// answerCacheTraceName is the name of the node trace of a cache hit, as no node of the application is run
+const answerCacheTraceName = "answer_cache"
+
+// answerCacheCandidates is the number of similar questions to check, the most similar one may be expired
+const answerCacheCandidates = 4
+
+// answerCache finds the answers of similar questions asked before, see arcadiav1alpha1.AnswerCache
+type answerCache struct {
+	config      arcadiav1alpha1.AnswerCache
+	collection  string
+	vectorStore *arcadiav1alpha1.VectorStore
+	embedder    embeddings.Embedder
+	// version is the files version of the knowledgebase, answers of other versions are not used
+	version string
+}
+
+// AnswerCacheKnowledgebase returns the knowledgebase whose embedder and vectorstore are used by the answer cache,
+// which is the first knowledgebase node of the application except the conversation knowledgebase
+func AnswerCacheKnowledgebase(ctx context.Context, cli client.Client, namespace string, spec arcadiav1alpha1.ApplicationSpec) (*arcadiav1alpha1.KnowledgeBase, error) {
+	for _, node := range spec.Nodes {
+		if node.Ref == nil || !strings.EqualFold(node.Ref.Kind, "knowledgebase") || appnode.IsPlaceholderConversationKnowledgebase(node.Ref.Name) {
+			continue
+		}
+		kb := &arcadiav1alpha1.KnowledgeBase{}
+		if err := cli.Get(ctx, types.NamespacedName{Namespace: node.Ref.GetNamespace(namespace), Name: node.Ref.Name}, kb); err != nil {
+			return nil, fmt.Errorf("can't find the knowledgebase of the answer cache: %w", err)
+		}
+		return kb, nil
+	}
+	return nil, errors.New("answer cache needs a knowledgebase node in the application")
+}
+
+// RemoveAnswerCache removes all cached answers of the application
+func RemoveAnswerCache(ctx context.Context, log logr.Logger, cli client.Client, namespace, name string, kb *arcadiav1alpha1.KnowledgeBase) error {
+	if kb.Spec.VectorStore == nil {
+		return nil
+	}
+	vectorStore := &arcadiav1alpha1.VectorStore{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: kb.Spec.VectorStore.GetNamespace(kb.Namespace), Name: kb.Spec.VectorStore.Name}, vectorStore); err != nil {
+		return err
+	}
+	return pkgvectorstore.RemoveCollection(ctx, log, vectorStore, arcadiav1alpha1.AnswerCacheCollectionName(namespace, name), cli)
+}
+
+func newAnswerCache(ctx context.Context, cli client.Client, a *Application) (*answerCache, error) {
+	kb, err := AnswerCacheKnowledgebase(ctx, cli, a.Namespace, a.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if kb.Spec.Embedder == nil || kb.Spec.VectorStore == nil {
+		return nil, fmt.Errorf("knowledgebase %s of the answer cache has no embedder or vectorstore", kb.Name)
+	}
+	embedder := &arcadiav1alpha1.Embedder{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: kb.Spec.Embedder.GetNamespace(kb.Namespace), Name: kb.Spec.Embedder.Name}, embedder); err != nil {
+		return nil, fmt.Errorf("can't find the embedder of the answer cache: %w", err)
+	}
+	em, err := langchainwrap.GetLangchainEmbedder(ctx, embedder, cli, "")
+	if err != nil {
+		return nil, fmt.Errorf("can't convert to langchain embedder: %w", err)
+	}
+	vectorStore := &arcadiav1alpha1.VectorStore{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: kb.Spec.VectorStore.GetNamespace(kb.Namespace), Name: kb.Spec.VectorStore.Name}, vectorStore); err != nil {
+		return nil, fmt.Errorf("can't find the vectorstore of the answer cache: %w", err)
+	}
+	return &answerCache{
+		config:      *a.Spec.AnswerCache,
+		collection:  arcadiav1alpha1.AnswerCacheCollectionName(a.Namespace, a.Name),
+		vectorStore: vectorStore,
+		embedder:    em,
+		version:     kb.FilesVersion(),
+	}, nil
+}
+
+// Get returns the cached answer of the most similar question, false if there is none.
+// The trace of the answer records the score of the cached question and the files version of the knowledgebase.
+func (c *answerCache) Get(ctx context.Context, cli client.Client, question string) (Output, bool) {
+	logger := klog.FromContext(ctx)
+	startedAt := time.Now()
+	s, finish, err := pkgvectorstore.NewVectorStore(ctx, c.vectorStore.DeepCopy(), c.embedder, c.collection, cli)
+	if finish != nil {
+		defer finish()
+	}
+	if err != nil {
+		logger.Error(err, "failed to connect the vectorstore of the answer cache")
+		return Output{}, false
+	}
+	docs, err := s.SimilaritySearch(ctx, question, answerCacheCandidates)
+	if err != nil {
+		logger.Error(err, "failed to search the answer cache")
+		return Output{}, false
+	}
+	output, ok := c.pick(ctx, question, docs)
+	if ok {
+		output.Trace[0].StartedAt = startedAt
+		output.Trace[0].Latency = time.Since(startedAt).Milliseconds()
+	}
+	return output, ok
+}
+
+// pick returns the answer of the first similar question which is similar enough, not expired and answered by
+// the same files version of the knowledgebase
+func (c *answerCache) pick(ctx context.Context, question string, docs []langchaingoschema.Document) (Output, bool) {
+	logger := klog.FromContext(ctx)
+	threshold := c.config.GetScoreThreshold()
+	ttl := time.Duration(c.config.GetTTLSeconds()) * time.Second
+	for _, doc := range docs {
+		// pgvector get score means vector distance, chroma get score means similarity
+		score := doc.Score
+		if c.vectorStore.Spec.Type() == arcadiav1alpha1.VectorStoreTypePGVector {
+			score = 1 - score
+		}
+		if score < threshold || metadataString(doc.Metadata[answerCacheVersionKey]) != c.version {
+			continue
+		}
+		createdAt, err := strconv.ParseInt(metadataString(doc.Metadata[answerCacheCreatedAtKey]), 10, 64)
+		if err != nil || time.Since(time.Unix(createdAt, 0)) > ttl {
+			continue
+		}
+		output := Output{Answer: metadataString(doc.Metadata[answerCacheAnswerKey])}
+		if output.Answer == "" {
+			continue
+		}
+		if refs := metadataString(doc.Metadata[answerCacheReferencesKey]); refs != "" {
+			if err := json.Unmarshal([]byte(refs), &output.References); err != nil {
+				logger.Error(err, "failed to parse the references of the cached answer")
+				continue
+			}
+		}
+		logger.V(3).Info("answer cache hit", "question", question, "cachedQuestion", doc.PageContent, "score", score)
+		output.Trace = []base.NodeTrace{{
+			Name:       answerCacheTraceName,
+			Kind:       answerCacheTraceName,
+			StartedAt:  time.Now(),
+			OutputKeys: []string{base.OutputAnswerKeyInArg},
+			Events: []base.TraceEvent{{
+				Type:    base.TraceEventAnswerCacheHit,
+				Time:    time.Now(),
+				Content: base.TruncateTraceContent(fmt.Sprintf("score: %.4f, kb_version: %s, cached question: %s", score, c.version, doc.PageContent)),
+			}},
+		}}
+		return output, true
+	}
+	return Output{}, false
+}
+
+// Put saves the answer of the question
+func (c *answerCache) Put(ctx context.Context, cli client.Client, question string, output Output) {
+	logger := klog.FromContext(ctx)
+	metadata := map[string]any{
+		answerCacheVersionKey:   c.version,
+		answerCacheCreatedAtKey: strconv.FormatInt(time.Now().Unix(), 10),
+		answerCacheAnswerKey:    output.Answer,
+	}
+	if len(output.References) > 0 {
+		refs, err := json.Marshal(output.References)
+		if err != nil {
+			logger.Error(err, "failed to save the references of the answer to cache")
+			return
+		}
+		metadata[answerCacheReferencesKey] = string(refs)
+	}
+	s, finish, err := pkgvectorstore.NewVectorStore(ctx, c.vectorStore.DeepCopy(), c.embedder, c.collection, cli)
+	if finish != nil {
+		defer finish()
+	}
+	if err != nil {
+		logger.Error(err, "failed to connect the vectorstore of the answer cache")
+		return
+	}
+	if _, err := s.AddDocuments(ctx, []langchaingoschema.Document{{PageContent: question, Metadata: metadata}}); err != nil {
+		logger.Error(err, "failed to save the answer to cache")
+	}
+}
+
+// metadataString returns the metadata value as a string, chroma may get []byte with quotes
+func metadataString(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []byte:
+		return strings.TrimPrefix(strings.TrimSuffix(string(value), "\""), "\"")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// questionChecker is implemented by guard nodes, the answer cache is not used if the question fails their checks,
+// so the guard nodes can handle the question in the run
+type questionChecker interface {
+	CheckQuestion(ctx context.Context, question string) bool
+}
+
+// useAnswerCache checks if the answer cache can be used by the input, only the first question without files
+// of a conversation is cached
+func (a *Application) useAnswerCache(ctx context.Context, input Input) bool {
+	if a.answerCache == nil || len(input.Files) > 0 || input.Question == "" {
+		return false
+	}
+	// the answers depend on the values of the input variables
+	if len(a.Spec.InputVariables) > 0 {
+		return false
+	}
+	if input.History != nil {
+		messages, err := input.History.Messages(ctx)
+		if err != nil || len(messages) > 0 {
+			return false
+		}
+	}
+	for _, n := range a.SortedNodes {
+		if checker, ok := n.(questionChecker); ok && !checker.CheckQuestion(ctx, input.Question) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/memory"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/utils/pointer"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+func TestAnswerCachePick(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c := &answerCache{
+		config:      arcadiav1alpha1.AnswerCache{ScoreThreshold: pointer.Float32(0.9), TTLSeconds: 60},
+		vectorStore: &arcadiav1alpha1.VectorStore{Spec: arcadiav1alpha1.VectorStoreSpec{PGVector: &arcadiav1alpha1.PGVector{}}},
+		version:     "v2",
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	cached := func(distance float32, version, createdAt, answer string) langchaingoschema.Document {
+		return langchaingoschema.Document{
+			PageContent: "what is arcadia",
+			Score:       distance,
+			Metadata: map[string]any{
+				answerCacheVersionKey:    version,
+				answerCacheCreatedAtKey:  createdAt,
+				answerCacheAnswerKey:     answer,
+				answerCacheReferencesKey: `[{"question":"q","answer":"a","score":0.5}]`,
+			},
+		}
+	}
+	expired := strconv.FormatInt(time.Now().Add(-2*time.Minute).Unix(), 10)
+
+	_, ok := c.pick(ctx, "what is arcadia", []langchaingoschema.Document{
+		// pgvector returns the distances
+		cached(0.2, "v2", now, "not similar enough"),
+		cached(0.05, "v1", now, "answered with the old files"),
+		cached(0.01, "v2", expired, "expired"),
+		cached(0.01, "v2", "", "no created time"),
+	})
+	assert.False(t, ok)
+
+	output, ok := c.pick(ctx, "what is arcadia", []langchaingoschema.Document{
+		cached(0.05, "v1", now, "answered with the old files"),
+		cached(0.08, "v2", now, "a platform"),
+	})
+	assert.True(t, ok)
+	assert.Equal(t, "a platform", output.Answer)
+	assert.Len(t, output.References, 1)
+	// the cache hit is recorded in the trace with the score and the files version
+	require.Len(t, output.Trace, 1)
+	require.Len(t, output.Trace[0].Events, 1)
+	assert.Equal(t, base.TraceEventAnswerCacheHit, output.Trace[0].Events[0].Type)
+	assert.Equal(t, "score: 0.9200, kb_version: v2, cached question: what is arcadia", output.Trace[0].Events[0].Content)
+}
+
+// fakeChecker is a node which checks the questions like the guardrail
+type fakeChecker struct {
+	base.BaseNode
+	pass bool
+}
+
+func (f *fakeChecker) CheckQuestion(_ context.Context, _ string) bool {
+	return f.pass
+}
+
+func TestUseAnswerCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	checker := &fakeChecker{pass: true}
+	newApp := func() *Application {
+		return &Application{answerCache: &answerCache{}, SortedNodes: []base.Node{checker}}
+	}
+	input := Input{Question: "what is arcadia", History: memory.NewChatMessageHistory()}
+	assert.True(t, newApp().useAnswerCache(ctx, input))
+
+	assert.False(t, (&Application{}).useAnswerCache(ctx, input), "answer cache is not enabled")
+
+	withFiles := input
+	withFiles.Files = []string{"a.pdf"}
+	assert.False(t, newApp().useAnswerCache(ctx, withFiles), "files are uploaded")
+
+	withHistory := input
+	withHistory.History = memory.NewChatMessageHistory(memory.WithPreviousMessages([]langchaingoschema.ChatMessage{
+		langchaingoschema.HumanChatMessage{Content: "hi"},
+	}))
+	assert.False(t, newApp().useAnswerCache(ctx, withHistory), "not the first question")
+
+	withVariables := newApp()
+	withVariables.Spec.InputVariables = []arcadiav1alpha1.InputVariable{{Name: "language"}}
+	assert.False(t, withVariables.useAnswerCache(ctx, input), "the application has input variables")
+
+	failed := &Application{answerCache: &answerCache{}, SortedNodes: []base.Node{&fakeChecker{pass: false}}}
+	assert.False(t, failed.useAnswerCache(ctx, input), "the question fails the check")
+}
@@ -17,7 +17,6 @@ limitations under the License.
 package appruntime
 
 import (
-	"container/list"
 	"context"
 	"errors"
 	"fmt"
@@ -34,10 +33,12 @@ import (
 	"github.com/kubeagi/arcadia/pkg/appruntime/base"
 	"github.com/kubeagi/arcadia/pkg/appruntime/chain"
 	"github.com/kubeagi/arcadia/pkg/appruntime/documentloader"
+	"github.com/kubeagi/arcadia/pkg/appruntime/guardrail"
 	"github.com/kubeagi/arcadia/pkg/appruntime/knowledgebase"
 	"github.com/kubeagi/arcadia/pkg/appruntime/llm"
 	"github.com/kubeagi/arcadia/pkg/appruntime/prompt"
 	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+	"github.com/kubeagi/arcadia/pkg/appruntime/router"
 )
 
 type Input struct {
@@ -50,10 +51,31 @@ type Input struct {
 	NeedStream     bool
 	History        langchaingoschema.ChatMessageHistory
 	ConversationID string
+	// Summary is the summary of the earlier history used by the summary memory, it is updated in the run
+	// and should be saved with the conversation if updated
+	Summary *base.ConversationSummary
+	// Variables are the values of the input variables of the application
+	Variables map[string]any
 }
 type Output struct {
 	Answer     string
 	References []retriever.Reference
+	// Trace is the execution record of every node in this run
+	Trace []base.NodeTrace
+	// Guardrails are the failed checks of the guardrail nodes in this run
+	Guardrails []base.GuardrailEvent
+	// Structured is the answer parsed by the output schema of the llm chain, nil if there is no output schema
+	Structured any
+}
+
+// Blocked checks if the question or the answer is blocked by a guardrail
+func (o Output) Blocked() bool {
+	for _, e := range o.Guardrails {
+		if e.Action == "block" {
+			return true
+		}
+	}
+	return false
 }
 
 type Application struct {
@@ -64,26 +86,53 @@ type Application struct {
 	Nodes         map[string]base.Node
 	StartingNodes []base.Node
 	EndingNode    base.Node
+	// SortedNodes are all nodes in topological order
+	SortedNodes []base.Node
+	// release returns the application to the cache, nil if it is not from the cache
+	release func()
+	// answerCache is nil if the answer cache is not enabled
+	answerCache *answerCache
 }
 
+// NewAppOrGetFromCache returns an initialized application, which is reused from the cache if EnableAppCache is called.
+// Call Release when the application is no longer used, so it can be used by the next run.
 func NewAppOrGetFromCache(ctx context.Context, cli client.Client, app *arcadiav1alpha1.Application) (*Application, error) {
 	if app == nil || app.Name == "" || app.Namespace == "" {
 		return nil, errors.New("app has no name or namespace")
 	}
-	a := &Application{
+	if c := appCache.Load(); c != nil {
+		return c.Get(ctx, cli, app)
+	}
+	a := newApp(app)
+	return a, a.Init(ctx, cli)
+}
+
+func newApp(app *arcadiav1alpha1.Application) *Application {
+	return &Application{
 		Namespace: app.GetNamespace(),
 		Name:      app.Name,
 		Spec:      app.Spec,
 		Inited:    false,
 	}
-	return a, a.Init(ctx, cli)
 }
 
-// TODO: 防止无限循环，需要找一下是不是成环
+// Release returns the application to the cache, the application must not be used after it.
+func (a *Application) Release() {
+	if a.release != nil {
+		release := a.release
+		a.release = nil
+		release()
+	}
+}
+
 func (a *Application) Init(ctx context.Context, cli client.Client) (err error) {
 	if a.Inited {
 		return
 	}
+	sorted, err := SortNodes(a.Spec.Nodes)
+	if err != nil {
+		return err
+	}
 	a.Nodes = make(map[string]base.Node)
 
 	var inputNodeName, outputNodeName string
@@ -135,11 +184,24 @@ func (a *Application) Init(ctx context.Context, cli client.Client) (err error) {
 			a.StartingNodes = append(a.StartingNodes, current)
 		}
 	}
+	a.SortedNodes = make([]base.Node, 0, len(sorted))
+	for _, name := range sorted {
+		a.SortedNodes = append(a.SortedNodes, a.Nodes[name])
+	}
+	if a.Spec.AnswerCache != nil {
+		if a.answerCache, err = newAnswerCache(ctx, cli, a); err != nil {
+			return err
+		}
+	}
 	klog.FromContext(ctx).V(5).Info(fmt.Sprintf("init application success starting nodes: %#v\n", a.StartingNodes))
 	return nil
 }
 
 func (a *Application) Run(ctx context.Context, cli client.Client, respStream chan string, input Input) (output Output, err error) {
+	variables, err := ResolveInputVariables(a.Spec.InputVariables, input.Variables)
+	if err != nil {
+		return output, err
+	}
 	out := map[string]any{
 		base.InputQuestionKeyInArg:                 input.Question,
 		"files":                                    input.Files,
@@ -153,73 +215,293 @@ func (a *Application) Run(ctx context.Context, cli client.Client, respStream cha
 	if a.Spec.DocNullReturn != "" {
 		out[base.APPDocNullReturn] = a.Spec.DocNullReturn
 	}
-	visited := make(map[string]bool)
-	waitRunningNodes := list.New()
-	for _, v := range a.StartingNodes {
-		waitRunningNodes.PushBack(v)
-	}
-	for e := waitRunningNodes.Front(); e != nil; e = e.Next() {
-		e := e.Value.(base.Node)
-		if !visited[e.Name()] {
-			reWait := false
-			for _, n := range e.GetPrevNode() {
-				if !visited[n.Name()] {
-					reWait = true
-					break
-				}
+	if input.Summary != nil {
+		out[base.ConversationSummaryKeyInArg] = input.Summary
+	}
+	if len(variables) > 0 {
+		out[base.InputVariablesKeyInArg] = variables
+	}
+	useAnswerCache := a.useAnswerCache(ctx, input)
+	if useAnswerCache {
+		if cached, ok := a.answerCache.Get(ctx, cli, input.Question); ok {
+			if input.NeedStream && respStream != nil {
+				go func() {
+					respStream <- cached.Answer
+				}()
 			}
-			if reWait {
-				waitRunningNodes.PushBack(e)
-				continue
+			return cached, nil
+		}
+	}
+	tracer := base.NewTracer()
+	out, err = a.execute(withApp(ctx, a.Namespace, a.Name), cli, out, tracer)
+	output.Trace = tracer.Traces()
+	if err != nil {
+		var er *base.RetrieverGetNullDocError
+		var blocked *base.GuardrailBlockedError
+		switch {
+		case errors.As(err, &blocked):
+			output.Answer = blocked.Msg
+		case errors.As(err, &er):
+			output.Answer = er.Msg
+		default:
+			_, output.Guardrails = a.guard(ctx, "")
+			return output, err
+		}
+		output.Answer, output.Guardrails = a.guard(ctx, output.Answer)
+		if input.NeedStream && respStream != nil {
+			answer := output.Answer
+			go func() {
+				respStream <- answer
+			}()
+		}
+		return output, nil
+	}
+	answer, _ := out[base.OutputAnswerKeyInArg].(string)
+	guarded, guardrails := a.guard(ctx, answer)
+	if output.Guardrails = guardrails; len(guarded) > 0 {
+		output.Answer = guarded
+	}
+	// the parsed answer is dropped if the answer is changed by the guardrails
+	if structured, ok := out[base.OutputStructuredKeyInArg]; ok && guarded == answer {
+		output.Structured = structured
+	}
+	if a, ok := out[base.RuntimeRetrieverReferencesKeyInArg]; ok {
+		if references, ok := a.([]retriever.Reference); ok && len(references) > 0 {
+			output.References = references
+		}
+	}
+	if output.Answer == "" && respStream == nil {
+		return Output{Trace: output.Trace}, errors.New("no answer")
+	}
+	if useAnswerCache && output.Answer != "" && len(output.Guardrails) == 0 && output.Structured == nil {
+		cached := Output{Answer: output.Answer, References: output.References}
+		go a.answerCache.Put(context.WithoutCancel(ctx), cli, input.Question, cached)
+	}
+	return output, nil
+}
+
+// guard waits for the streamed answer and checks the answer by the guard nodes, it returns the answer to reply
+// and the failed checks of this run
+func (a *Application) guard(ctx context.Context, answer string) (string, []base.GuardrailEvent) {
+	var events []base.GuardrailEvent
+	for _, n := range a.SortedNodes {
+		g, ok := n.(base.Guard)
+		if !ok {
+			continue
+		}
+		var e []base.GuardrailEvent
+		answer, e = g.GuardAnswer(ctx, answer)
+		events = append(events, e...)
+	}
+	return answer, events
+}
+
+type nodeResult struct {
+	node base.Node
+	args *branchArgs
+	err  error
+}
+
+// execute runs the nodes in topological order.
+// A node starts as soon as all its prev nodes are done, so independent branches run at the same time.
+// Each node gets its own copy of args, which are merged by mergeBranches when branches join.
+// A router only goes on with the next nodes it picks. A node with prev nodes after routers is skipped if none of
+// the edges from them are taken, other prev nodes like llms and knowledgebases are shared by all branches.
+// When a node fails, no more nodes will be started and the args of the failed node are returned with the error.
+// Every node run is recorded by the tracer.
+func (a *Application) execute(ctx context.Context, cli client.Client, args map[string]any, tracer *base.Tracer) (map[string]any, error) {
+	logger := klog.FromContext(ctx)
+	index := make(map[string]int, len(a.SortedNodes))
+	pending := make(map[string]int, len(a.SortedNodes))
+	for i, n := range a.SortedNodes {
+		index[n.Name()] = i
+		pending[n.Name()] = len(n.GetPrevNode())
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	input := newBranchArgs(args)
+	outputs := make(map[string]*branchArgs, len(a.SortedNodes))
+	results := make(chan nodeResult, len(a.SortedNodes))
+	started := make([]base.Node, 0, len(a.SortedNodes))
+	defer func() {
+		for _, n := range started {
+			n.Cleanup()
+		}
+	}()
+	// taken are the edges to go on with, an edge from a skipped node or to a next node not picked by a router is not taken
+	taken := make(map[edge]bool)
+	routed := routedNodes(a.SortedNodes)
+	start := func(n base.Node) {
+		in := input
+		if prev := n.GetPrevNode(); len(prev) > 0 {
+			branches := make([]*branchArgs, 0, len(prev))
+			for _, p := range prev {
+				if taken[edge{from: p.Name(), to: n.Name()}] {
+					branches = append(branches, outputs[p.Name()])
+				}
 			}
+			in = mergeBranches(branches)
+		}
+		started = append(started, n)
+		nodeTracer := tracer.StartNode(n, in.args)
+		go func() {
+			res := nodeResult{node: n}
 			defer func() {
 				if r := recover(); r != nil {
-					klog.FromContext(ctx).Info(fmt.Sprintf("Recovered from node:%s error:%s stack:%s", e.Name(), r, string(debug.Stack())))
+					logger.Info(fmt.Sprintf("Recovered from node:%s error:%s stack:%s", n.Name(), r, string(debug.Stack())))
+					res.args = in
+					res.err = fmt.Errorf("panic: %v", r)
 				}
-			}()
-			defer e.Cleanup()
-			if out, err = e.Run(ctx, cli, out); err != nil {
-				var er *base.RetrieverGetNullDocError
-				if errors.As(err, &er) {
-					agentReturnNothing := true
-					v, ok := out[base.OutputAnswerKeyInArg]
-					if ok {
-						if answer, ok := v.(string); ok && len(answer) > 0 {
-							agentReturnNothing = false
+				var changed []string
+				if res.args != in {
+					changed = res.args.changedBy(index[n.Name()])
+					for _, k := range changed {
+						if brief, ok := briefArg(res.args.args[k]); ok {
+							nodeTracer.SetOutput(k, brief)
 						}
 					}
-					if agentReturnNothing {
-						if input.NeedStream && respStream != nil {
-							go func() {
-								respStream <- er.Msg
-							}()
-						}
-						return Output{Answer: er.Msg}, nil
-					}
-				} else {
-					return Output{}, fmt.Errorf("run node %s: %w", e.Name(), err)
 				}
+				nodeTracer.Finish(changed, res.err)
+				results <- res
+			}()
+			out, err := n.Run(base.WithNodeTracer(ctx, nodeTracer), cli, copyArgs(in.args))
+			if out == nil {
+				out = in.args
 			}
-			visited[e.Name()] = true
+			res.args = in.written(out, index[n.Name()])
+			res.err = err
+		}()
+	}
+	// done starts or skips the next nodes whose prev nodes are all done, and returns the number of started nodes.
+	// out is nil if the node is skipped.
+	var done func(n base.Node, out *branchArgs) int
+	done = func(n base.Node, out *branchArgs) int {
+		count := 0
+		picked := pickedNextNodes(out, index[n.Name()])
+		for _, next := range n.GetNextNode() {
+			if out != nil && (picked == nil || picked[next.Name()]) {
+				taken[edge{from: n.Name(), to: next.Name()}] = true
+			}
+			pending[next.Name()]--
+			if pending[next.Name()] > 0 {
+				continue
+			}
+			if shouldRun(next, routed, taken) {
+				start(next)
+				count++
+				continue
+			}
+			logger.V(3).Info("skip node which is not on the picked branches", "node", next.Name())
+			tracer.SkipNode(next)
+			count += done(next, nil)
+		}
+		return count
+	}
+	for _, n := range a.SortedNodes {
+		if pending[n.Name()] == 0 {
+			start(n)
+		}
+	}
+
+	var failed *nodeResult
+	for running := len(started); running > 0; running-- {
+		res := <-results
+		if failed != nil {
+			continue
 		}
-		for _, n := range e.GetNextNode() {
-			waitRunningNodes.PushBack(n)
+		if res.err != nil && !hasAnswer(res) {
+			res := res
+			failed = &res
+			cancel()
+			continue
 		}
+		outputs[res.node.Name()] = res.args
+		running += done(res.node, res.args)
 	}
-	if a, ok := out[base.OutputAnswerKeyInArg]; ok {
-		if answer, ok := a.(string); ok && len(answer) > 0 {
-			output = Output{Answer: answer}
+	if failed != nil {
+		var er *base.RetrieverGetNullDocError
+		if errors.As(failed.err, &er) {
+			return failed.args.args, failed.err
 		}
+		return failed.args.args, fmt.Errorf("run node %s: %w", failed.node.Name(), failed.err)
 	}
-	if a, ok := out[base.RuntimeRetrieverReferencesKeyInArg]; ok {
-		if references, ok := a.([]retriever.Reference); ok && len(references) > 0 {
-			output.References = references
+
+	// the final args are merged from all nodes without next nodes, normally only the output node
+	ending := make([]*branchArgs, 0, 1)
+	for _, n := range a.SortedNodes {
+		if out, ok := outputs[n.Name()]; ok && len(n.GetNextNode()) == 0 {
+			ending = append(ending, out)
 		}
 	}
-	if output.Answer == "" && respStream == nil {
-		return Output{}, errors.New("no answer")
+	if len(ending) == 0 {
+		return args, nil
 	}
-	return output, nil
+	return mergeBranches(ending).args, nil
+}
+
+type edge struct {
+	from, to string
+}
+
+// pickedNextNodes returns the next nodes picked by the node if it is a router, or nil if all next nodes should go on
+func pickedNextNodes(out *branchArgs, index int) map[string]bool {
+	if out == nil {
+		return nil
+	}
+	if writer, ok := out.writers[base.RouterNextNodesKeyInArg]; !ok || writer != index {
+		return nil
+	}
+	names, _ := out.args[base.RouterNextNodesKeyInArg].([]string)
+	picked := make(map[string]bool, len(names))
+	for _, name := range names {
+		picked[name] = true
+	}
+	return picked
+}
+
+// routedNodes returns the routers and the nodes after them, which may be skipped
+func routedNodes(sorted []base.Node) map[string]bool {
+	routed := make(map[string]bool, len(sorted))
+	for _, n := range sorted {
+		if n.Group() == "router" && n.Kind() == "router" {
+			routed[n.Name()] = true
+			continue
+		}
+		for _, p := range n.GetPrevNode() {
+			if routed[p.Name()] {
+				routed[n.Name()] = true
+				break
+			}
+		}
+	}
+	return routed
+}
+
+// shouldRun returns false if the node has routed prev nodes but none of the edges from them are taken
+func shouldRun(n base.Node, routed map[string]bool, taken map[edge]bool) bool {
+	hasRouted := false
+	for _, p := range n.GetPrevNode() {
+		if !routed[p.Name()] {
+			continue
+		}
+		hasRouted = true
+		if taken[edge{from: p.Name(), to: n.Name()}] {
+			return true
+		}
+	}
+	return !hasRouted
+}
+
+// hasAnswer checks if the node got an answer even if the retriever returns no document,
+// for example the agent answers the question, then the application should go on.
+func hasAnswer(res nodeResult) bool {
+	var er *base.RetrieverGetNullDocError
+	if !errors.As(res.err, &er) || res.args == nil {
+		return false
+	}
+	answer, ok := res.args.args[base.OutputAnswerKeyInArg].(string)
+	return ok && len(answer) > 0
 }
 
 func InitNode(ctx context.Context, appNamespace, name string, ref arcadiav1alpha1.TypedObjectReference) (n base.Node, err error) {
@@ -260,6 +542,9 @@ func InitNode(ctx context.Context, appNamespace, name string, ref arcadiav1alpha
 		case "mergerretriever":
 			logger.V(3).Info("initnode mergerretriever")
 			return retriever.NewMergerRetriever(baseNode), nil
+		case "querytransform":
+			logger.V(3).Info("initnode querytransform")
+			return retriever.NewQueryTransform(baseNode), nil
 		default:
 			return nil, err
 		}
@@ -278,9 +563,31 @@ func InitNode(ctx context.Context, appNamespace, name string, ref arcadiav1alpha
 		case "agent":
 			logger.V(3).Info("initnode agent - executor")
 			return agent.NewExecutor(baseNode), nil
+		case "supervisor":
+			logger.V(3).Info("initnode supervisor")
+			return agent.NewSupervisor(baseNode), nil
 		case "documentloader":
 			logger.V(3).Info("initnode agent - documentloader")
 			return documentloader.NewDocumentLoader(baseNode), nil
+		case "application":
+			logger.V(3).Info("initnode application")
+			return NewSubApplication(baseNode), nil
+		default:
+			return nil, err
+		}
+	case "router":
+		switch baseNode.Kind() {
+		case "router":
+			logger.V(3).Info("initnode router")
+			return router.NewRouter(baseNode), nil
+		default:
+			return nil, err
+		}
+	case "guardrail":
+		switch baseNode.Kind() {
+		case "guardrail":
+			logger.V(3).Info("initnode guardrail")
+			return guardrail.NewGuardrail(baseNode), nil
 		default:
 			return nil, err
 		}
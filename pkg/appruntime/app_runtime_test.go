@@ -0,0 +1,100 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+type fakeNode struct {
+	base.BaseNode
+	run func(args map[string]any)
+}
+
+func (f *fakeNode) Run(_ context.Context, _ client.Client, args map[string]any) (map[string]any, error) {
+	if f.run != nil {
+		f.run(args)
+	}
+	return args, nil
+}
+
+func TestExecuteSkipsBranchesNotPicked(t *testing.T) {
+	nodes := make(map[string]*fakeNode)
+	add := func(name string, run func(args map[string]any)) {
+		ref := arcadiav1alpha1.TypedObjectReference{Kind: name}
+		if name == "router" {
+			ref.APIGroup = pointer.String("router.arcadia.kubeagi.k8s.com.cn")
+		}
+		nodes[name] = &fakeNode{BaseNode: base.NewBaseNode("default", name, ref), run: run}
+	}
+	answer := func(v string) func(args map[string]any) {
+		return func(args map[string]any) {
+			args[base.OutputAnswerKeyInArg] = v
+		}
+	}
+	add("input", nil)
+	add("llm", nil)
+	add("router", func(args map[string]any) {
+		args[base.RouterNextNodesKeyInArg] = []string{"b"}
+	})
+	add("a", answer("a"))
+	add("b", answer("b"))
+	add("after-a", answer("after a"))
+	add("output", nil)
+	link := func(from string, to ...string) {
+		for _, n := range to {
+			nodes[from].SetNextNode(nodes[n])
+			nodes[n].SetPrevNode(nodes[from])
+		}
+	}
+	link("input", "router")
+	// the llm is shared by both branches
+	link("llm", "a", "b")
+	link("router", "a", "b")
+	link("a", "after-a")
+	link("after-a", "output")
+	link("b", "output")
+
+	app := &Application{}
+	for _, name := range []string{"input", "llm", "router", "a", "b", "after-a", "output"} {
+		app.SortedNodes = append(app.SortedNodes, nodes[name])
+	}
+	tracer := base.NewTracer()
+	out, err := app.execute(context.Background(), nil, map[string]any{base.InputQuestionKeyInArg: "q"}, tracer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out[base.OutputAnswerKeyInArg] != "b" {
+		t.Fatalf("expected answer from b, got %v", out[base.OutputAnswerKeyInArg])
+	}
+	skipped := make([]string, 0)
+	for _, trace := range tracer.Traces() {
+		if trace.Skipped {
+			skipped = append(skipped, trace.Name)
+		}
+	}
+	if len(skipped) != 2 || skipped[0] != "a" || skipped[1] != "after-a" {
+		t.Fatalf("expected a and after-a to be skipped, got %v", skipped)
+	}
+}
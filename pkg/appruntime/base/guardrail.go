@@ -0,0 +1,44 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package base
+
+import "context"
+
+// GuardrailEvent is a failed check of a guardrail node in one application run
+type GuardrailEvent struct {
+	Node string `json:"node" example:"guardrail-node"`
+	// Stage is input or output
+	Stage string `json:"stage" example:"input"`
+	// Action is what is done with the text, block, mask or flag
+	Action string `json:"action" example:"block"`
+	// Reasons are the failed checks, like sensitive_word:xx, pii:phone or classifier
+	Reasons []string `json:"reasons" example:"pii:phone"`
+}
+
+// GuardrailBlockedError stops the application run, and Msg is replied instead
+type GuardrailBlockedError struct {
+	Msg string
+}
+
+func (e *GuardrailBlockedError) Error() string { return e.Msg }
+
+// Guard is implemented by nodes which check the answer after all nodes are done
+type Guard interface {
+	// GuardAnswer waits until the streamed answer is sent, then returns the answer to reply and the failed checks
+	// of this run. The answer is the streamed text if it is streamed by the node, or the checked answer if not.
+	GuardAnswer(ctx context.Context, answer string) (string, []GuardrailEvent)
+}
@@ -37,6 +37,14 @@ const (
 	APPDocNullReturn                      = "_app_doc_null_return"
 	ConversationKnowledgeBaseInArg        = "_conversation_knowledgebase" // the conversation Knowledgebase cr in args, status has ready
 	ConversationIDInArg                   = "_conversation_id"
+	ConversationSummaryKeyInArg           = "_conversation_summary"
+	InputVariablesKeyInArg                = "_input_variables"   // the values of the input variables of the application, map[string]string
+	RouterRouteKeyInArg                   = "_route"             // the name of the route picked by the router
+	RouterNextNodesKeyInArg               = "_router_next_nodes" // the next nodes picked by the router, other next nodes are skipped
+	OutputStructuredKeyInArg              = "_structured_answer" // the answer parsed by the output schema of the llm chain
+	// RetrievalQueryKeyInArg is the query rewritten by the query transform node, retrievers search with it instead of
+	// the question, and the question is kept for the final prompt
+	RetrievalQueryKeyInArg = "_retrieval_query"
 )
 
 var (
@@ -56,6 +64,14 @@ func GetInputQuestionFromArg(args map[string]any) (string, error) {
 	return query, nil
 }
 
+// GetRetrievalQueryFromArg returns the query for retrievers, which is the rewritten query if exists, or the question
+func GetRetrievalQueryFromArg(args map[string]any) (string, error) {
+	if query, ok := args[RetrievalQueryKeyInArg].(string); ok && len(query) != 0 {
+		return query, nil
+	}
+	return GetInputQuestionFromArg(args)
+}
+
 func GetRetrieversFromArg(args map[string]any) ([]langchainschema.Retriever, error) {
 	v, ok := args[LangchaingoRetrieversKeyInArg]
 	if !ok {
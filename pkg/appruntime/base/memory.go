@@ -0,0 +1,61 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package base
+
+import "sync"
+
+// ConversationSummary is the rolling summary of the earlier messages in the history of a conversation,
+// which is updated by the summary memory. It is loaded from the conversation and saved back after the run,
+// so only the messages not summarized yet are sent to the llm in a turn.
+type ConversationSummary struct {
+	mu       sync.Mutex
+	summary  string
+	messages int
+	updated  bool
+}
+
+// NewConversationSummary returns the summary of the first messages in the history
+func NewConversationSummary(summary string, messages int) *ConversationSummary {
+	return &ConversationSummary{summary: summary, messages: messages}
+}
+
+// Get returns the summary and the number of messages summarized in it
+func (s *ConversationSummary) Get() (summary string, messages int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.summary, s.messages
+}
+
+// Set updates the summary, messages is the number of messages summarized in it
+func (s *ConversationSummary) Set(summary string, messages int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summary, s.messages, s.updated = summary, messages, true
+}
+
+// Updated checks if the summary is changed by Set, so it should be saved
+func (s *ConversationSummary) Updated() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updated
+}
+
+// GetConversationSummaryFromArg returns the summary of the conversation in args, nil if not exists
+func GetConversationSummaryFromArg(args map[string]any) *ConversationSummary {
+	summary, _ := args[ConversationSummaryKeyInArg].(*ConversationSummary)
+	return summary
+}
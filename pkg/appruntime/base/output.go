@@ -18,6 +18,7 @@ package base
 
 import (
 	"context"
+	"fmt"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -35,3 +36,17 @@ func NewOutput(baseNode BaseNode) *Output {
 func (c *Output) Run(_ context.Context, _ client.Client, args map[string]any) (map[string]any, error) {
 	return args, nil
 }
+
+// StructuredOutputError is returned when the answer is not valid by the output schema after all attempts
+type StructuredOutputError struct {
+	// Answer is the last answer of the llm
+	Answer   string
+	Attempts int
+	Err      error
+}
+
+func (e *StructuredOutputError) Error() string {
+	return fmt.Sprintf("the answer is not valid by the output schema after %d attempts: %s", e.Attempts, e.Err)
+}
+
+func (e *StructuredOutputError) Unwrap() error { return e.Err }
@@ -0,0 +1,237 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package base
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TraceEventType is the type of the event happened when a node is running
+type TraceEventType string
+
+const (
+	TraceEventLLMStart     TraceEventType = "llm_start"
+	TraceEventLLMEnd       TraceEventType = "llm_end"
+	TraceEventLLMError     TraceEventType = "llm_error"
+	TraceEventChainEnd     TraceEventType = "chain_end"
+	TraceEventRetrieverEnd TraceEventType = "retriever_end"
+	TraceEventToolStart    TraceEventType = "tool_start"
+	TraceEventToolEnd      TraceEventType = "tool_end"
+	TraceEventToolError    TraceEventType = "tool_error"
+	TraceEventAgentAction  TraceEventType = "agent_action"
+	TraceEventAgentFinish  TraceEventType = "agent_finish"
+	// TraceEventContextTrim records the history and the documents dropped to fit the context window of the model
+	TraceEventContextTrim TraceEventType = "context_trim"
+	// TraceEventAnswerCacheHit records the cached answer used instead of running the application
+	TraceEventAnswerCacheHit TraceEventType = "answer_cache_hit"
+)
+
+// maxTraceContentLength is the max length of the content recorded in the trace, longer content will be truncated
+const maxTraceContentLength = 2000
+
+// TraceEvent is something happened when a node is running, like the rendered prompt sent to llm or a tool call of an agent
+type TraceEvent struct {
+	Type    TraceEventType `json:"type" example:"llm_start"`
+	Time    time.Time      `json:"time" example:"2024-01-02T10:21:06.389359092+08:00"`
+	Content string         `json:"content,omitempty" example:"Human: 旷工最小计算单位为多少天？"`
+}
+
+// NodeTrace is the execution record of a node in one application run
+type NodeTrace struct {
+	Name      string    `json:"name" example:"Chain"`
+	Group     string    `json:"group,omitempty" example:"chain"`
+	Kind      string    `json:"kind" example:"retrievalqachain"`
+	StartedAt time.Time `json:"started_at" example:"2024-01-02T10:21:06.389359092+08:00"`
+	// Latency is the time cost of this node, in ms
+	Latency int64 `json:"latency" example:"1000"`
+	// InputKeys are the keys in args when the node starts
+	InputKeys []string `json:"input_keys,omitempty"`
+	// OutputKeys are the keys added or changed by this node
+	OutputKeys []string `json:"output_keys,omitempty"`
+	// Outputs are the brief of the values added or changed by this node
+	Outputs map[string]string `json:"outputs,omitempty"`
+	Events  []TraceEvent      `json:"events,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	// Skipped is true if the node is not run because it is not on the branches picked by routers
+	Skipped bool `json:"skipped,omitempty"`
+	// Children are the traces of the nodes of the application called by this node
+	Children []NodeTrace `json:"children,omitempty"`
+}
+
+// Tracer records the NodeTrace of all nodes in one application run, it is safe for concurrent use
+type Tracer struct {
+	mu    sync.Mutex
+	nodes []*NodeTracer
+}
+
+func NewTracer() *Tracer {
+	return &Tracer{}
+}
+
+// StartNode starts to record the trace of a node
+func (t *Tracer) StartNode(node Node, args map[string]any) *NodeTracer {
+	n := &NodeTracer{
+		trace: NodeTrace{
+			Name:      node.Name(),
+			Group:     node.Group(),
+			Kind:      node.Kind(),
+			StartedAt: time.Now(),
+			InputKeys: sortedKeys(args),
+		},
+	}
+	t.mu.Lock()
+	t.nodes = append(t.nodes, n)
+	t.mu.Unlock()
+	return n
+}
+
+// SkipNode records a node which is skipped in this run
+func (t *Tracer) SkipNode(node Node) {
+	n := &NodeTracer{
+		trace: NodeTrace{
+			Name:      node.Name(),
+			Group:     node.Group(),
+			Kind:      node.Kind(),
+			StartedAt: time.Now(),
+			Skipped:   true,
+		},
+	}
+	t.mu.Lock()
+	t.nodes = append(t.nodes, n)
+	t.mu.Unlock()
+}
+
+// Traces returns the NodeTrace of all nodes, sorted by the start time
+func (t *Tracer) Traces() []NodeTrace {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	res := make([]NodeTrace, 0, len(t.nodes))
+	for _, n := range t.nodes {
+		res = append(res, n.Trace())
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].StartedAt.Before(res[j].StartedAt)
+	})
+	return res
+}
+
+// NodeTracer records the trace of one running node
+type NodeTracer struct {
+	mu    sync.Mutex
+	trace NodeTrace
+}
+
+// AddEvent adds an event to the node trace, it does nothing if the NodeTracer is nil
+func (n *NodeTracer) AddEvent(eventType TraceEventType, content string) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.trace.Events = append(n.trace.Events, TraceEvent{Type: eventType, Time: time.Now(), Content: TruncateTraceContent(content)})
+}
+
+// SetOutput records the brief of a value added or changed by the node
+func (n *NodeTracer) SetOutput(key, brief string) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.trace.Outputs == nil {
+		n.trace.Outputs = make(map[string]string)
+	}
+	n.trace.Outputs[key] = TruncateTraceContent(brief)
+}
+
+// SetChildren records the traces of the application called by the node as nested spans
+func (n *NodeTracer) SetChildren(children []NodeTrace) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.trace.Children = children
+}
+
+// Finish marks the node as done, with the keys it changed and the error it returned
+func (n *NodeTracer) Finish(outputKeys []string, err error) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.trace.Latency = time.Since(n.trace.StartedAt).Milliseconds()
+	sort.Strings(outputKeys)
+	n.trace.OutputKeys = outputKeys
+	if err != nil {
+		n.trace.Error = err.Error()
+	}
+}
+
+// Trace returns a copy of the recorded NodeTrace
+func (n *NodeTracer) Trace() NodeTrace {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	res := n.trace
+	res.Events = append([]TraceEvent(nil), n.trace.Events...)
+	res.Children = append([]NodeTrace(nil), n.trace.Children...)
+	if n.trace.Outputs != nil {
+		res.Outputs = make(map[string]string, len(n.trace.Outputs))
+		for k, v := range n.trace.Outputs {
+			res.Outputs[k] = v
+		}
+	}
+	return res
+}
+
+type nodeTracerContextKey struct{}
+
+// WithNodeTracer returns a context with the NodeTracer, so callbacks handlers can record events into the trace of the running node
+func WithNodeTracer(ctx context.Context, n *NodeTracer) context.Context {
+	return context.WithValue(ctx, nodeTracerContextKey{}, n)
+}
+
+// NodeTracerFromContext returns the NodeTracer in the context, or nil if there is none
+func NodeTracerFromContext(ctx context.Context) *NodeTracer {
+	n, _ := ctx.Value(nodeTracerContextKey{}).(*NodeTracer)
+	return n
+}
+
+// TruncateTraceContent cuts the content to a length suitable to be stored in the trace
+func TruncateTraceContent(content string) string {
+	r := []rune(content)
+	if len(r) <= maxTraceContentLength {
+		return content
+	}
+	return string(r[:maxTraceContentLength]) + "..."
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
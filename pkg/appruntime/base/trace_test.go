@@ -0,0 +1,130 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+func newTraceNode(name, group, kind string) *BaseNode {
+	n := NewBaseNode("default", name, arcadiav1alpha1.TypedObjectReference{APIGroup: pointer.String(group), Kind: kind, Name: name})
+	return &n
+}
+
+func TestNodeTracer(t *testing.T) {
+	t.Parallel()
+	tracer := NewTracer()
+	node := newTraceNode("chain", "chain.arcadia.kubeagi.k8s.com.cn", "LLMChain")
+	args := map[string]any{InputQuestionKeyInArg: "hi", "_history": nil}
+	n := tracer.StartNode(node, args)
+	ctx := WithNodeTracer(context.Background(), n)
+	assert.Same(t, n, NodeTracerFromContext(ctx))
+	NodeTracerFromContext(ctx).AddEvent(TraceEventLLMStart, strings.Repeat("字", maxTraceContentLength+1))
+	n.SetOutput(OutputAnswerKeyInArg, "hello")
+	time.Sleep(5 * time.Millisecond)
+	n.Finish([]string{"_answer_stream", OutputAnswerKeyInArg}, nil)
+
+	traces := tracer.Traces()
+	require.Len(t, traces, 1)
+	trace := traces[0]
+	assert.Equal(t, "chain", trace.Name)
+	assert.Equal(t, "chain", trace.Group)
+	assert.Equal(t, "llmchain", trace.Kind)
+	assert.False(t, trace.StartedAt.IsZero())
+	assert.GreaterOrEqual(t, trace.Latency, int64(5))
+	assert.Equal(t, []string{"_history", InputQuestionKeyInArg}, trace.InputKeys)
+	assert.Equal(t, []string{OutputAnswerKeyInArg, "_answer_stream"}, trace.OutputKeys)
+	assert.Equal(t, map[string]string{OutputAnswerKeyInArg: "hello"}, trace.Outputs)
+	require.Len(t, trace.Events, 1)
+	assert.Equal(t, TraceEventLLMStart, trace.Events[0].Type)
+	assert.Equal(t, maxTraceContentLength+len("..."), len([]rune(trace.Events[0].Content)))
+	assert.Empty(t, trace.Error)
+
+	// the returned trace is a copy
+	trace.Outputs[OutputAnswerKeyInArg] = "changed"
+	assert.Equal(t, "hello", tracer.Traces()[0].Outputs[OutputAnswerKeyInArg])
+
+	// nothing is recorded without a tracer in the context
+	var none *NodeTracer
+	assert.Nil(t, NodeTracerFromContext(context.Background()))
+	none.AddEvent(TraceEventLLMEnd, "ignored")
+	none.SetOutput("key", "ignored")
+	none.Finish(nil, errors.New("ignored"))
+	var noTracer *Tracer
+	assert.Nil(t, noTracer.Traces())
+}
+
+func TestTracerConcurrentNodes(t *testing.T) {
+	t.Parallel()
+	tracer := NewTracer()
+	tracer.SkipNode(newTraceNode("skipped", "arcadia.kubeagi.k8s.com.cn", "Output"))
+	const branches = 10
+	var wg sync.WaitGroup
+	for i := 0; i < branches; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			n := tracer.StartNode(newTraceNode(fmt.Sprintf("branch-%d", i), "chain.arcadia.kubeagi.k8s.com.cn", "LLMChain"), map[string]any{})
+			for j := 0; j < 5; j++ {
+				n.AddEvent(TraceEventToolStart, fmt.Sprintf("call %d", j))
+			}
+			var err error
+			if i%2 == 1 {
+				err = fmt.Errorf("branch %d failed", i)
+			}
+			n.Finish([]string{fmt.Sprintf("out-%d", i)}, err)
+		}(i)
+	}
+	wg.Wait()
+
+	traces := tracer.Traces()
+	require.Len(t, traces, branches+1)
+	seen := make(map[string]bool)
+	for i, trace := range traces {
+		if i > 0 {
+			assert.False(t, trace.StartedAt.Before(traces[i-1].StartedAt), "the traces are sorted by the start time")
+		}
+		seen[trace.Name] = true
+		if trace.Skipped {
+			assert.Equal(t, "skipped", trace.Name)
+			continue
+		}
+		var index int
+		_, err := fmt.Sscanf(trace.Name, "branch-%d", &index)
+		require.NoError(t, err)
+		assert.Len(t, trace.Events, 5)
+		assert.Equal(t, []string{fmt.Sprintf("out-%d", index)}, trace.OutputKeys)
+		if index%2 == 1 {
+			assert.Equal(t, fmt.Sprintf("branch %d failed", index), trace.Error)
+		} else {
+			assert.Empty(t, trace.Error)
+		}
+	}
+	assert.Len(t, seen, branches+1)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package base
+
+import (
+	"context"
+	"sync/atomic"
+	"unicode"
+)
+
+// TokenUsage counts the llm tokens used in one application run, including the runs of the sub applications.
+// It is safe for concurrent use.
+type TokenUsage struct {
+	tokens atomic.Int64
+}
+
+// Add adds the tokens of a llm call
+func (u *TokenUsage) Add(tokens int64) {
+	if u != nil {
+		u.tokens.Add(tokens)
+	}
+}
+
+// Total returns the tokens used so far
+func (u *TokenUsage) Total() int64 {
+	if u == nil {
+		return 0
+	}
+	return u.tokens.Load()
+}
+
+type tokenUsageContextKey struct{}
+
+// WithTokenUsage returns a context in which the llm calls add their tokens to u
+func WithTokenUsage(ctx context.Context, u *TokenUsage) context.Context {
+	return context.WithValue(ctx, tokenUsageContextKey{}, u)
+}
+
+// TokenUsageFromContext returns the TokenUsage in ctx, nil if not exists, and Add of a nil TokenUsage does nothing
+func TokenUsageFromContext(ctx context.Context) *TokenUsage {
+	u, _ := ctx.Value(tokenUsageContextKey{}).(*TokenUsage)
+	return u
+}
+
+// ContextWindow is implemented by the llms which know the max context length of their models
+type ContextWindow interface {
+	// MaxContextLength returns the max number of tokens in the context of the model, 0 if unknown.
+	// model is the model set in the chain, empty for the default model of the llm.
+	MaxContextLength(model string) int
+}
+
+// EstimateTokens estimates the tokens of the text without the tokenizer of the model,
+// a CJK character is counted as a token, and 4 other characters as a token
+func EstimateTokens(text string) int {
+	var cjk, others int
+	for _, r := range text {
+		if isCJK(r) {
+			cjk++
+		} else {
+			others++
+		}
+	}
+	return cjk + (others+3)/4
+}
+
+// TruncateTokens cuts the text to at most maxTokens tokens estimated by EstimateTokens
+func TruncateTokens(text string, maxTokens int) string {
+	var cjk, others int
+	for i, r := range text {
+		if isCJK(r) {
+			cjk++
+		} else {
+			others++
+		}
+		if cjk+(others+3)/4 > maxTokens {
+			return text[:i]
+		}
+	}
+	return text
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
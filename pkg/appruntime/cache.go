@@ -0,0 +1,303 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// maxIdleApps is the max number of idle applications kept for one application
+const maxIdleApps = 8
+
+var (
+	appCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "arcadia_app_cache_requests_total",
+		Help: "Number of runtime applications requested from the application cache, by result hit or miss",
+	}, []string{"result"})
+	appCacheInvalidations = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "arcadia_app_cache_invalidations_total",
+		Help: "Number of cached runtime applications dropped because the application or one of its resources changed",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(appCacheRequests, appCacheInvalidations)
+}
+
+// appCache is empty until EnableAppCache is called, then NewAppOrGetFromCache reuses the initialized applications.
+// It is enabled after the informers are synced, while chats are running.
+var appCache atomic.Pointer[AppCache]
+
+// EnableAppCache enables the process-wide application cache, and the informers are used to watch the changes of
+// applications and the resources read by their nodes. The informers should be started by the caller.
+func EnableAppCache(informers cache.Cache, scheme *runtime.Scheme) {
+	appCache.Store(NewAppCache(informers, scheme))
+}
+
+// AppCache caches the initialized runtime applications.
+// Nodes keep their own state during a run, so an application is checked out by one chat at a time,
+// and it goes back to the cache by Application.Release. More applications are initialized when chats
+// of the same application run at the same time.
+// The cached applications of an application are dropped when the application or any resource read by
+// Init changes, which is found by the informers.
+type AppCache struct {
+	informers cache.Cache
+	scheme    *runtime.Scheme
+
+	mu      sync.Mutex
+	entries map[types.NamespacedName]*appCacheEntry
+	// watched are the kinds which already have event handlers
+	watched map[schema.GroupVersionKind]bool
+	// events counts the events handled by invalidate, put skips caching if an event comes during its check
+	events uint64
+}
+
+type appCacheEntry struct {
+	// dependencies are the objects read by Init, including the application itself, with their resource versions
+	dependencies map[dependency]string
+	idle         []*Application
+}
+
+type dependency struct {
+	gvk schema.GroupVersionKind
+	key types.NamespacedName
+}
+
+func NewAppCache(informers cache.Cache, scheme *runtime.Scheme) *AppCache {
+	return &AppCache{
+		informers: informers,
+		scheme:    scheme,
+		entries:   make(map[types.NamespacedName]*appCacheEntry),
+		watched:   make(map[schema.GroupVersionKind]bool),
+	}
+}
+
+// Get returns an idle application from the cache or initializes a new one.
+// The application must be released by Application.Release after the run.
+func (c *AppCache) Get(ctx context.Context, cli client.Client, app *arcadiav1alpha1.Application) (*Application, error) {
+	key := types.NamespacedName{Namespace: app.Namespace, Name: app.Name}
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok {
+		if entry.dependencies[dependency{gvk: arcadiav1alpha1.GroupVersion.WithKind("Application"), key: key}] != app.ResourceVersion {
+			delete(c.entries, key)
+			appCacheInvalidations.Inc()
+		} else if n := len(entry.idle); n > 0 {
+			a := entry.idle[n-1]
+			entry.idle = entry.idle[:n-1]
+			a.release = c.releaser(key, entry.dependencies, a)
+			c.mu.Unlock()
+			appCacheRequests.WithLabelValues("hit").Inc()
+			return a, nil
+		}
+	}
+	c.mu.Unlock()
+	appCacheRequests.WithLabelValues("miss").Inc()
+
+	recorder := &recordingClient{Client: cli, dependencies: make(map[dependency]string)}
+	recorder.dependencies[dependency{gvk: arcadiav1alpha1.GroupVersion.WithKind("Application"), key: key}] = app.ResourceVersion
+	a := newApp(app)
+	if err := a.Init(ctx, recorder); err != nil {
+		return nil, err
+	}
+	dependencies := recorder.Dependencies()
+	if err := c.watch(ctx, dependencies); err != nil {
+		// the application still works, but it can't be cached without watching its resources
+		klog.FromContext(ctx).Error(err, "failed to watch the resources of application, skip caching it", "app", key)
+		return a, nil
+	}
+	a.release = c.releaser(key, dependencies, a)
+	return a, nil
+}
+
+func (c *AppCache) releaser(key types.NamespacedName, dependencies map[dependency]string, a *Application) func() {
+	return func() {
+		c.put(key, dependencies, a)
+	}
+}
+
+// put returns the application to the cache if its resources are not changed
+func (c *AppCache) put(key types.NamespacedName, dependencies map[dependency]string, a *Application) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && sameDependencies(entry.dependencies, dependencies) {
+		if len(entry.idle) < maxIdleApps {
+			entry.idle = append(entry.idle, a)
+		}
+		c.mu.Unlock()
+		return
+	}
+	events := c.events
+	c.mu.Unlock()
+
+	// only the latest versions are kept, an older application can't get in since its resources
+	// are checked against the informer stores
+	if !c.upToDate(dependencies) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.events != events {
+		// an event handler may have missed the application during the check, the next release tries again
+		return
+	}
+	entry, ok = c.entries[key]
+	if !ok || !sameDependencies(entry.dependencies, dependencies) {
+		entry = &appCacheEntry{dependencies: dependencies}
+		c.entries[key] = entry
+	}
+	if len(entry.idle) < maxIdleApps {
+		entry.idle = append(entry.idle, a)
+	}
+}
+
+// upToDate checks the resource versions against the informer stores, so no change is missed between Init and the
+// event handlers. It is called without the lock, the stores are updated before the event handlers count the events.
+func (c *AppCache) upToDate(dependencies map[dependency]string) bool {
+	for d, version := range dependencies {
+		obj, err := c.scheme.New(d.gvk)
+		if err != nil {
+			return false
+		}
+		o, ok := obj.(client.Object)
+		if !ok {
+			return false
+		}
+		if err := c.informers.Get(context.Background(), d.key, o); err != nil || o.GetResourceVersion() != version {
+			return false
+		}
+	}
+	return true
+}
+
+// watch adds the event handlers to the informers of the kinds of dependencies
+func (c *AppCache) watch(ctx context.Context, dependencies map[dependency]string) error {
+	for d := range dependencies {
+		c.mu.Lock()
+		watched := c.watched[d.gvk]
+		c.mu.Unlock()
+		if watched {
+			continue
+		}
+		informer, err := c.informers.GetInformerForKind(ctx, d.gvk)
+		if err != nil {
+			return fmt.Errorf("get informer of %s: %w", d.gvk, err)
+		}
+		c.mu.Lock()
+		if !c.watched[d.gvk] {
+			c.watched[d.gvk] = true
+			gvk := d.gvk
+			informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+				UpdateFunc: func(_, obj interface{}) {
+					c.invalidate(gvk, obj, false)
+				},
+				DeleteFunc: func(obj interface{}) {
+					c.invalidate(gvk, obj, true)
+				},
+			})
+		}
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// invalidate drops the cached applications which read an older version of the object or the deleted object
+func (c *AppCache) invalidate(gvk schema.GroupVersionKind, obj interface{}, deleted bool) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	o, ok := obj.(client.Object)
+	if !ok {
+		return
+	}
+	d := dependency{gvk: gvk, key: client.ObjectKeyFromObject(o)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events++
+	for key, entry := range c.entries {
+		if version, ok := entry.dependencies[d]; ok && (deleted || o.GetDeletionTimestamp() != nil || version != o.GetResourceVersion()) {
+			delete(c.entries, key)
+			appCacheInvalidations.Inc()
+		}
+	}
+}
+
+func sameDependencies(a, b map[dependency]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for d, version := range a {
+		if b[d] != version {
+			return false
+		}
+	}
+	return true
+}
+
+// recordingClient records the objects got by Init and their resource versions.
+// Objects of the core group, like the auth secrets of llms and embedders, are not recorded, so the apiserver
+// doesn't watch and keep all secrets of the cluster. A rotated secret is used by the applications initialized
+// after the cached ones are invalidated, for example by a change of the llm or the embedder.
+type recordingClient struct {
+	client.Client
+
+	mu           sync.Mutex
+	dependencies map[dependency]string
+}
+
+func (r *recordingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if err := r.Client.Get(ctx, key, obj); err != nil {
+		return err
+	}
+	gvk, err := apiutil.GVKForObject(obj, r.Scheme())
+	if err != nil {
+		return err
+	}
+	if gvk.Group == corev1.GroupName {
+		return nil
+	}
+	r.mu.Lock()
+	r.dependencies[dependency{gvk: gvk, key: key}] = obj.GetResourceVersion()
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *recordingClient) Dependencies() map[dependency]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res := make(map[dependency]string, len(r.dependencies))
+	for d, version := range r.dependencies {
+		res[d] = version
+	}
+	return res
+}
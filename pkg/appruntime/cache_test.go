@@ -0,0 +1,123 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiprompt "github.com/kubeagi/arcadia/api/app-node/prompt/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// fakeInformers reads objects from the client like the informer stores
+type fakeInformers struct {
+	*informertest.FakeInformers
+	reader client.Reader
+}
+
+func (f *fakeInformers) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return f.reader.Get(ctx, key, obj)
+}
+
+func TestAppCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(arcadiav1alpha1.AddToScheme(scheme))
+	utilruntime.Must(apiprompt.AddToScheme(scheme))
+
+	ref := func(group, kind, name string) *arcadiav1alpha1.TypedObjectReference {
+		return &arcadiav1alpha1.TypedObjectReference{APIGroup: pointer.String(group), Kind: kind, Name: name}
+	}
+	app := &arcadiav1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Spec: arcadiav1alpha1.ApplicationSpec{Nodes: []arcadiav1alpha1.Node{
+			{NodeConfig: arcadiav1alpha1.NodeConfig{Name: "input", Ref: ref("arcadia.kubeagi.k8s.com.cn", "Input", "input")}, NextNodeName: []string{"prompt"}},
+			{NodeConfig: arcadiav1alpha1.NodeConfig{Name: "prompt", Ref: ref("prompt.arcadia.kubeagi.k8s.com.cn", "Prompt", "prompt")}, NextNodeName: []string{"output"}},
+			{NodeConfig: arcadiav1alpha1.NodeConfig{Name: "output", Ref: ref("arcadia.kubeagi.k8s.com.cn", "Output", "output")}},
+		}},
+	}
+	p := &apiprompt.Prompt{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prompt"}}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(app, p).Build()
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(app), app))
+
+	informers := &informertest.FakeInformers{Scheme: scheme}
+	c := NewAppCache(&fakeInformers{FakeInformers: informers, reader: cli}, scheme)
+
+	first, err := c.Get(ctx, cli, app)
+	require.NoError(t, err)
+	// the running application is not shared
+	second, err := c.Get(ctx, cli, app)
+	require.NoError(t, err)
+	assert.NotSame(t, first, second)
+	first.Release()
+	got, err := c.Get(ctx, cli, app)
+	require.NoError(t, err)
+	assert.Same(t, first, got)
+	got.Release()
+
+	// the prompt changes
+	old := p.DeepCopy()
+	require.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(p), p))
+	p.Spec.UserMessage = "{{.question}}"
+	require.NoError(t, cli.Update(ctx, p))
+	informer, err := informers.FakeInformerForKind(ctx, apiprompt.GroupVersion.WithKind("Prompt"))
+	require.NoError(t, err)
+	informer.Update(old, p)
+	got, err = c.Get(ctx, cli, app)
+	require.NoError(t, err)
+	assert.NotSame(t, first, got)
+	got.Release()
+	// the application initialized before the change can't get in again
+	second.Release()
+	again, err := c.Get(ctx, cli, app)
+	require.NoError(t, err)
+	assert.Same(t, got, again)
+}
+
+func TestRecordingClient(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(apiprompt.AddToScheme(scheme))
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "auth"}}
+	p := &apiprompt.Prompt{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prompt"}}
+	recorder := &recordingClient{
+		Client:       fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret, p).Build(),
+		dependencies: make(map[dependency]string),
+	}
+	require.NoError(t, recorder.Get(ctx, client.ObjectKeyFromObject(secret), &corev1.Secret{}))
+	require.NoError(t, recorder.Get(ctx, client.ObjectKeyFromObject(p), &apiprompt.Prompt{}))
+	// the secrets are not watched
+	dependencies := recorder.Dependencies()
+	assert.Len(t, dependencies, 1)
+	assert.Contains(t, dependencies, dependency{gvk: apiprompt.GroupVersion.WithKind("Prompt"), key: client.ObjectKeyFromObject(p)})
+}
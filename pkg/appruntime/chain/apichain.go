@@ -88,8 +88,8 @@ func (l *APIChain) Run(ctx context.Context, _ client.Client, args map[string]any
 	options := GetChainOptions(instance.Spec.CommonChainConfig)
 
 	chain := chains.NewAPIChain(llm, http.DefaultClient)
-	chain.RequestChain.Memory = GetMemory(llm, instance.Spec.Memory, history, "", "")
-	chain.AnswerChain.Memory = GetMemory(llm, instance.Spec.Memory, history, "input", "")
+	chain.RequestChain.Memory = GetMemory(llm, instance.Spec.Memory, history, base.GetConversationSummaryFromArg(args), "", "")
+	chain.AnswerChain.Memory = GetMemory(llm, instance.Spec.Memory, history, base.GetConversationSummaryFromArg(args), "input", "")
 	l.APIChain = chain
 	apiDoc := instance.Spec.APIDoc
 	if apiDoc == "" {
@@ -83,13 +83,21 @@ func GetChainOptions(config v1alpha1.CommonChainConfig) []chains.ChainCallOption
 	return options
 }
 
-func GetMemory(llm llms.Model, config v1alpha1.Memory, history langchaingoschema.ChatMessageHistory, inputKey, outputKey string) langchaingoschema.Memory {
+// GetMemory returns the memory by the config, summary is the summary of the conversation used by the summary memory, which may be nil
+func GetMemory(llm llms.Model, config v1alpha1.Memory, history langchaingoschema.ChatMessageHistory, summary *base.ConversationSummary, inputKey, outputKey string) langchaingoschema.Memory {
 	if inputKey == "" {
 		inputKey = "question"
 	}
 	if outputKey == "" {
 		outputKey = "text"
 	}
+	if config.Summary != nil {
+		windowSize := 0
+		if config.ConversionWindowSize != nil {
+			windowSize = *config.ConversionWindowSize
+		}
+		return NewSummaryBuffer(llm, windowSize, config.Summary.Prompt, summary, memory.WithInputKey(inputKey), memory.WithOutputKey(outputKey), memory.WithChatHistory(history))
+	}
 	if config.MaxTokenLimit > 0 {
 		return memory.NewConversationTokenBuffer(llm, config.MaxTokenLimit, memory.WithInputKey(inputKey), memory.WithOutputKey(outputKey), memory.WithChatHistory(history))
 	}
@@ -0,0 +1,246 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/memory"
+	"github.com/tmc/langchaingo/prompts"
+	langchainschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	appruntimeretriever "github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+)
+
+// contextWindow fits the history and the documents into the tokens left in the context window of the model by
+// the prompt and the answer. The history is trimmed first from the oldest message, then the documents with the
+// lowest scores are dropped, and at last the remaining documents are truncated.
+type contextWindow struct {
+	// available is the number of tokens for the history and the documents
+	available int
+	history   []langchainschema.ChatMessage
+
+	// fitted is true after the documents are retrieved, and the fields below are set
+	fitted bool
+	// keptHistory is the history in the prompt
+	keptHistory []langchainschema.ChatMessage
+	// keptDocs are the documents in the prompt before they are truncated, which are returned as the references
+	keptDocs []langchainschema.Document
+}
+
+// newContextWindow returns nil if the max context length of the model is unknown.
+// maxTokens is the tokens kept for the answer, which is at most a half of the context.
+func newContextWindow(ctx context.Context, llm llms.Model, model string, maxTokens int, prompt prompts.FormatPrompter, question string, history []langchainschema.ChatMessage) *contextWindow {
+	w, ok := llm.(base.ContextWindow)
+	if !ok {
+		return nil
+	}
+	maxContext := w.MaxContextLength(model)
+	if maxContext <= 0 {
+		return nil
+	}
+	if maxTokens <= 0 || maxTokens > maxContext/2 {
+		maxTokens = maxContext / 2
+	}
+	// the prompt without the history and the documents
+	values := map[string]any{"question": question}
+	for _, v := range prompt.GetInputVariables() {
+		if _, ok := values[v]; !ok {
+			values[v] = ""
+		}
+	}
+	value, err := prompt.FormatPrompt(values)
+	if err != nil {
+		klog.FromContext(ctx).Error(err, "failed to format the prompt, the prompt is not fitted to the context window")
+		return nil
+	}
+	return &contextWindow{
+		available: maxContext - maxTokens - base.EstimateTokens(value.String()),
+		history:   history,
+	}
+}
+
+// fit keeps the history and the documents in the available tokens, and returns the documents for the prompt
+func (w *contextWindow) fit(ctx context.Context, docs []langchainschema.Document) []langchainschema.Document {
+	w.fitted = true
+	w.keptHistory, w.keptDocs = w.history, docs
+	historyTokens := make([]int, len(w.history))
+	over := -w.available
+	for i, m := range w.history {
+		historyTokens[i] = messageTokens(m)
+		over += historyTokens[i]
+	}
+	docTokens := make([]int, len(docs))
+	for i, d := range docs {
+		docTokens[i] = base.EstimateTokens(d.PageContent) + 1
+		over += docTokens[i]
+	}
+	if over <= 0 {
+		return docs
+	}
+
+	var trimmed []string
+	dropped := 0
+	for over > 0 && dropped < len(w.history) {
+		over -= historyTokens[dropped]
+		dropped++
+	}
+	w.keptHistory = w.history[dropped:]
+	if dropped > 0 {
+		trimmed = append(trimmed, fmt.Sprintf("dropped %d history messages", dropped))
+	}
+
+	// the documents from the lowest score, at least one document is kept before truncating
+	order := make([]int, len(docs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return documentScore(docs[order[i]]) < documentScore(docs[order[j]]) })
+	droppedDocs := make(map[int]bool)
+	for _, i := range order {
+		if over <= 0 || len(droppedDocs) == len(docs)-1 {
+			break
+		}
+		droppedDocs[i] = true
+		over -= docTokens[i]
+		trimmed = append(trimmed, fmt.Sprintf("dropped document %s", documentBrief(docs[i])))
+	}
+	truncated := make(map[int]string)
+	for _, i := range order {
+		if over <= 0 {
+			break
+		}
+		if droppedDocs[i] {
+			continue
+		}
+		keep := docTokens[i] - 1 - over
+		if keep <= 0 {
+			droppedDocs[i] = true
+			over -= docTokens[i]
+			trimmed = append(trimmed, fmt.Sprintf("dropped document %s", documentBrief(docs[i])))
+			continue
+		}
+		truncated[i] = base.TruncateTokens(docs[i].PageContent, keep)
+		over -= docTokens[i] - 1 - keep
+		trimmed = append(trimmed, fmt.Sprintf("truncated document %s to %d tokens", documentBrief(docs[i]), keep))
+	}
+
+	w.keptDocs = make([]langchainschema.Document, 0, len(docs)-len(droppedDocs))
+	promptDocs := make([]langchainschema.Document, 0, len(docs)-len(droppedDocs))
+	for i, d := range docs {
+		if droppedDocs[i] {
+			continue
+		}
+		w.keptDocs = append(w.keptDocs, d)
+		if content, ok := truncated[i]; ok {
+			d.PageContent = content
+		}
+		promptDocs = append(promptDocs, d)
+	}
+	msg := strings.Join(trimmed, "; ")
+	klog.FromContext(ctx).V(3).Info("fit the prompt to the context window", "available", w.available, "trimmed", msg)
+	base.NodeTracerFromContext(ctx).AddEvent(base.TraceEventContextTrim, base.TruncateTraceContent(msg))
+	return promptDocs
+}
+
+func messageTokens(m langchainschema.ChatMessage) int {
+	// the prefix like "Human: " and the new line
+	return base.EstimateTokens(m.GetContent()) + 3
+}
+
+// documentScore is the rerank score if the documents are reranked, or the score of the search
+func documentScore(doc langchainschema.Document) float32 {
+	switch score := doc.Metadata[appruntimeretriever.RerankScoreCol].(type) {
+	case float32:
+		return score
+	case float64:
+		return float32(score)
+	}
+	return doc.Score
+}
+
+func documentBrief(doc langchainschema.Document) string {
+	return fmt.Sprintf("(score %.4f) %q", documentScore(doc), base.TruncateTokens(doc.PageContent, 20))
+}
+
+// contextWindowRetriever fits the documents of the retriever into the context window
+type contextWindowRetriever struct {
+	langchainschema.Retriever
+	window *contextWindow
+}
+
+func (r *contextWindowRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]langchainschema.Document, error) {
+	docs, err := r.Retriever.GetRelevantDocuments(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return r.window.fit(ctx, docs), nil
+}
+
+// contextWindowMemory returns the history loaded before the run, or the history kept in the context window after
+// the documents are fitted. The context is saved by the memory of the chain.
+type contextWindowMemory struct {
+	langchainschema.Memory
+	window *contextWindow
+}
+
+func (m *contextWindowMemory) LoadMemoryVariables(ctx context.Context, _ map[string]any) (map[string]any, error) {
+	key := m.GetMemoryKey(ctx)
+	if key == "" {
+		return map[string]any{}, nil
+	}
+	history := m.window.history
+	if m.window.fitted {
+		history = m.window.keptHistory
+	}
+	bufferString, err := langchainschema.GetBufferString(history, "Human", "AI")
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{key: bufferString}, nil
+}
+
+// loadHistoryMessages loads the history messages by the memory, like the last rounds in the window
+func loadHistoryMessages(ctx context.Context, mem langchainschema.Memory) ([]langchainschema.ChatMessage, error) {
+	var buffer *memory.ConversationBuffer
+	switch m := mem.(type) {
+	case *SummaryBuffer:
+		buffer = &m.ConversationBuffer
+	case *memory.ConversationWindowBuffer:
+		buffer = &m.ConversationBuffer
+	case *memory.ConversationTokenBuffer:
+		buffer = &m.ConversationBuffer
+	case *memory.ConversationBuffer:
+		buffer = m
+	default:
+		return nil, nil
+	}
+	buffer.ReturnMessages = true
+	defer func() { buffer.ReturnMessages = false }()
+	vars, err := mem.LoadMemoryVariables(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	messages, _ := vars[mem.GetMemoryKey(ctx)].([]langchainschema.ChatMessage)
+	return messages, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chain
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/prompts"
+	langchainschema "github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	appruntimeretriever "github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+)
+
+// fakeContextWindowLLM is a llm with a small context window
+type fakeContextWindowLLM struct {
+	fakeAnswersLLM
+	maxContext int
+}
+
+func (f *fakeContextWindowLLM) MaxContextLength(string) int { return f.maxContext }
+
+func TestContextWindowFit(t *testing.T) {
+	ctx := context.Background()
+	prompt := prompts.NewPromptTemplate("{{.context}}\n{{.history}}\nQuestion: {{.question}}", []string{"context", "history", "question"})
+	// 40 characters are 10 tokens
+	text := func(c string) string { return strings.Repeat(c, 40) }
+	require.Equal(t, 10, base.EstimateTokens(text("a")))
+	history := []langchainschema.ChatMessage{
+		langchainschema.HumanChatMessage{Content: text("a")},
+		langchainschema.AIChatMessage{Content: text("b")},
+		langchainschema.HumanChatMessage{Content: text("c")},
+		langchainschema.AIChatMessage{Content: text("d")},
+	}
+	docs := []langchainschema.Document{
+		{PageContent: text("1"), Score: 0.9},
+		{PageContent: text("2"), Score: 0.5, Metadata: map[string]any{appruntimeretriever.RerankScoreCol: float32(0.1)}},
+		{PageContent: text("3"), Score: 0.3},
+	}
+
+	assert.Nil(t, newContextWindow(ctx, &fakeAnswersLLM{}, "", 0, prompt, "why?", history), "the context length is unknown")
+
+	// everything fits
+	w := newContextWindow(ctx, &fakeContextWindowLLM{maxContext: 1000}, "", 100, prompt, "why?", history)
+	require.NotNil(t, w)
+	assert.Equal(t, docs, w.fit(ctx, docs))
+	assert.Equal(t, history, w.keptHistory)
+
+	// the history is trimmed first
+	w = &contextWindow{available: 2*13 + 3*11, history: history}
+	assert.Equal(t, docs, w.fit(ctx, docs))
+	assert.Equal(t, history[2:], w.keptHistory)
+
+	// then the documents with the lowest scores are dropped, the rerank score is used if exists
+	w = &contextWindow{available: 2 * 11, history: history}
+	assert.Equal(t, []langchainschema.Document{docs[0], docs[2]}, w.fit(ctx, docs))
+	assert.Empty(t, w.keptHistory)
+
+	// at last the documents are truncated, and the references are not truncated
+	w = &contextWindow{available: 6, history: history}
+	fitted := w.fit(ctx, docs)
+	require.Len(t, fitted, 1)
+	assert.Equal(t, strings.Repeat("1", 20), fitted[0].PageContent)
+	assert.Equal(t, docs[:1], w.keptDocs)
+
+	// the memory returns the kept history after the documents are fitted
+	w = &contextWindow{available: 2*13 + 3*11, history: history}
+	windowSize := 5
+	mem := &contextWindowMemory{Memory: GetMemory(&fakeAnswersLLM{}, v1alpha1.Memory{ConversionWindowSize: &windowSize}, nil, nil, "", ""), window: w}
+	vars, err := mem.LoadMemoryVariables(ctx, nil)
+	require.NoError(t, err)
+	assert.Contains(t, vars["history"], text("a"))
+	w.fit(ctx, docs)
+	vars, err = mem.LoadMemoryVariables(ctx, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, vars["history"], text("a"))
+	assert.Contains(t, vars["history"], "Human: "+text("c"))
+}
@@ -31,12 +31,15 @@ import (
 
 	"github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
 	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	"github.com/kubeagi/arcadia/pkg/appruntime/log"
 )
 
 type LLMChain struct {
 	chains.LLMChain
 	base.BaseNode
 	Instance *v1alpha1.LLMChain
+	// outputParser is set if the chain has an output schema
+	outputParser *OutputSchemaParser
 }
 
 func NewLLMChain(baseNode base.BaseNode) *LLMChain {
@@ -52,6 +55,13 @@ func (l *LLMChain) Init(ctx context.Context, cli client.Client, args map[string]
 		return fmt.Errorf("can't find the chain in cluster: %w", err)
 	}
 	l.Instance = instance
+	if instance.Spec.OutputSchema != nil {
+		parser, err := NewOutputSchemaParser(instance.Spec.OutputSchema.Schema)
+		if err != nil {
+			return err
+		}
+		l.outputParser = parser
+	}
 	return nil
 }
 
@@ -117,14 +127,18 @@ func (l *LLMChain) Run(ctx context.Context, cli client.Client, args map[string]a
 	}
 
 	chain := chains.NewLLMChain(llm, prompt)
+	chain.CallbacksHandler = log.KLogHandler{LogLevel: 3}
 	if history != nil {
-		chain.Memory = GetMemory(llm, instance.Spec.Memory, history, "", "")
+		chain.Memory = GetMemory(llm, instance.Spec.Memory, history, base.GetConversationSummaryFromArg(args), "", "")
 	}
 	l.LLMChain = *chain
 
 	var out string
 	needStream := false
 	needStream, ok = args[base.InputIsNeedStreamKeyInArg].(bool)
+	if l.outputParser != nil {
+		return l.runStructured(ctx, args, options, ok && needStream)
+	}
 	if ok && needStream {
 		options = append(options, chains.WithStreamingFunc(stream(args)))
 		out, err = chains.Predict(ctx, l.LLMChain, args, options...)
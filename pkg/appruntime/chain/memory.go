@@ -0,0 +1,137 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chain
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/memory"
+	"github.com/tmc/langchaingo/prompts"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+// DefaultSummaryPrompt is the prompt to update the summary of the conversation
+const DefaultSummaryPrompt = `Progressively summarize the lines of conversation provided, adding onto the previous summary and returning a new summary.
+Keep the facts, names, numbers and decisions which may be needed later in the conversation. Only return the new summary.
+
+Current summary:
+{{.summary}}
+
+New lines of conversation:
+{{.new_lines}}
+
+New summary:`
+
+const (
+	// defaultSummaryWindowSize is the number of the last rounds kept as they are, if the window size is not set
+	defaultSummaryWindowSize = 5
+	// summaryBatchMessages is the max number of messages added to the summary by one llm call
+	summaryBatchMessages = 20
+)
+
+// SummaryBuffer is a memory with a summary of the earlier rounds and the last rounds of the conversation.
+// When rounds move out of the window, the llm adds them to the summary, which is kept in a base.ConversationSummary
+// and saved with the conversation, so the rounds are summarized only once.
+type SummaryBuffer struct {
+	memory.ConversationBuffer
+	LLM        llms.Model
+	Prompt     prompts.PromptTemplate
+	WindowSize int
+	Summary    *base.ConversationSummary
+}
+
+var _ langchaingoschema.Memory = &SummaryBuffer{}
+
+// NewSummaryBuffer creates a summary memory. If summary is nil, the summary is made from the start in every run.
+func NewSummaryBuffer(llm llms.Model, windowSize int, prompt string, summary *base.ConversationSummary, options ...memory.ConversationBufferOption) *SummaryBuffer {
+	if windowSize <= 0 {
+		windowSize = defaultSummaryWindowSize
+	}
+	if prompt == "" {
+		prompt = DefaultSummaryPrompt
+	}
+	if summary == nil {
+		summary = base.NewConversationSummary("", 0)
+	}
+	return &SummaryBuffer{
+		ConversationBuffer: *memory.NewConversationBuffer(options...),
+		LLM:                llm,
+		Prompt:             prompts.NewPromptTemplate(prompt, []string{"summary", "new_lines"}),
+		WindowSize:         windowSize,
+		Summary:            summary,
+	}
+}
+
+// LoadMemoryVariables returns the summary as a system message before the last rounds
+func (s *SummaryBuffer) LoadMemoryVariables(ctx context.Context, _ map[string]any) (map[string]any, error) {
+	messages, err := s.ChatHistory.Messages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	older, recent := []langchaingoschema.ChatMessage(nil), messages
+	if n := s.WindowSize * 2; len(messages) > n {
+		older, recent = messages[:len(messages)-n], messages[len(messages)-n:]
+	}
+	summary, err := s.summarize(ctx, older)
+	if err != nil {
+		// the answer is still useful without the latest rounds in the summary, they are summarized in the next turn
+		klog.FromContext(ctx).Error(err, "failed to update the conversation summary")
+	}
+	if summary != "" {
+		summaryMessage := langchaingoschema.SystemChatMessage{Content: "Summary of the earlier conversation: " + summary}
+		recent = append([]langchaingoschema.ChatMessage{summaryMessage}, recent...)
+	}
+	if s.ReturnMessages {
+		return map[string]any{s.MemoryKey: recent}, nil
+	}
+	bufferString, err := langchaingoschema.GetBufferString(recent, s.HumanPrefix, s.AIPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{s.MemoryKey: bufferString}, nil
+}
+
+// summarize adds the messages not in the summary yet to it, and returns the summary of all the messages
+func (s *SummaryBuffer) summarize(ctx context.Context, messages []langchaingoschema.ChatMessage) (string, error) {
+	summary, summarized := s.Summary.Get()
+	for summarized < len(messages) {
+		end := summarized + summaryBatchMessages
+		if end > len(messages) {
+			end = len(messages)
+		}
+		lines, err := langchaingoschema.GetBufferString(messages[summarized:end], s.HumanPrefix, s.AIPrefix)
+		if err != nil {
+			return summary, err
+		}
+		prompt, err := s.Prompt.Format(map[string]any{"summary": summary, "new_lines": lines})
+		if err != nil {
+			return summary, err
+		}
+		next, err := llms.GenerateFromSinglePrompt(ctx, s.LLM, prompt)
+		if err != nil {
+			return summary, err
+		}
+		summary, summarized = strings.TrimSpace(next), end
+		s.Summary.Set(summary, summarized)
+	}
+	return summary, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/memory"
+
+	"github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+// fakeSummaryLLM returns the new lines of the prompt as the summary, and records the prompts
+type fakeSummaryLLM struct {
+	prompts []string
+}
+
+func (f *fakeSummaryLLM) GenerateContent(_ context.Context, messages []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	prompt := messages[0].Parts[0].(llms.TextContent).Text
+	f.prompts = append(f.prompts, prompt)
+	lines := prompt[strings.Index(prompt, "New lines of conversation:\n")+len("New lines of conversation:\n") : strings.Index(prompt, "\n\nNew summary:")]
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: fmt.Sprintf("summary of %d lines", len(strings.Split(lines, "\n")))}}}, nil
+}
+
+func (f *fakeSummaryLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+func TestSummaryBuffer(t *testing.T) {
+	ctx := context.Background()
+	history := memory.NewChatMessageHistory()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, history.AddUserMessage(ctx, fmt.Sprintf("question %d", i)))
+		require.NoError(t, history.AddAIMessage(ctx, fmt.Sprintf("answer %d", i)))
+	}
+	llm := &fakeSummaryLLM{}
+	summary := base.NewConversationSummary("", 0)
+	windowSize := 2
+	config := v1alpha1.Memory{ConversionWindowSize: &windowSize, Summary: &v1alpha1.SummaryMemory{}}
+	mem := GetMemory(llm, config, history, summary, "", "")
+	require.IsType(t, &SummaryBuffer{}, mem)
+
+	vars, err := mem.LoadMemoryVariables(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "System: Summary of the earlier conversation: summary of 4 lines\nHuman: question 2\nAI: answer 2\nHuman: question 3\nAI: answer 3", vars["history"])
+	require.Len(t, llm.prompts, 1)
+	assert.Contains(t, llm.prompts[0], "Human: question 0\nAI: answer 0\nHuman: question 1\nAI: answer 1")
+	assert.True(t, summary.Updated())
+	s, n := summary.Get()
+	assert.Equal(t, "summary of 4 lines", s)
+	assert.Equal(t, 4, n)
+
+	// the summarized rounds are not sent to the llm again
+	require.NoError(t, mem.SaveContext(ctx, map[string]any{"question": "question 4"}, map[string]any{"text": "answer 4"}))
+	_, err = mem.LoadMemoryVariables(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, llm.prompts, 2)
+	assert.Contains(t, llm.prompts[1], "Current summary:\nsummary of 4 lines\n")
+	assert.Contains(t, llm.prompts[1], "New lines of conversation:\nHuman: question 2\nAI: answer 2\n\n")
+	_, n = summary.Get()
+	assert.Equal(t, 6, n)
+
+	// no llm call if all the rounds are in the window
+	summary = base.NewConversationSummary("", 0)
+	windowSize = 5
+	_, err = GetMemory(llm, config, history, summary, "", "").LoadMemoryVariables(ctx, nil)
+	require.NoError(t, err)
+	assert.Len(t, llm.prompts, 2)
+	assert.False(t, summary.Updated())
+}
@@ -119,7 +119,11 @@ func (l *RetrievalQAChain) Run(ctx context.Context, cli client.Client, args map[
 		}
 	}
 
-	docs, err := retriever.GetRelevantDocuments(ctx, args["question"].(string))
+	query, err := base.GetRetrievalQueryFromArg(args)
+	if err != nil {
+		return args, err
+	}
+	docs, err := retriever.GetRelevantDocuments(ctx, query)
 	if err != nil {
 		return args, fmt.Errorf("can't get doc from retriever: %w", err)
 	}
@@ -155,13 +159,28 @@ func (l *RetrievalQAChain) Run(ctx context.Context, cli client.Client, args map[
 	}
 
 	llmChain := chains.NewLLMChain(llm, prompt)
+	chainMemory := GetMemory(llm, instance.Spec.Memory, history, base.GetConversationSummaryFromArg(args), "", "")
+	// fit the history and the documents into the context window of the model, the history is loaded before the run
+	// and the documents are fitted after they are retrieved
+	var historyMessages []langchainschema.ChatMessage
+	if history != nil {
+		if historyMessages, err = loadHistoryMessages(ctx, chainMemory); err != nil {
+			return args, fmt.Errorf("failed to load the history: %w", err)
+		}
+	}
+	question, _ := args["question"].(string)
+	window := newContextWindow(ctx, llm, instance.Spec.Model, instance.Spec.MaxTokens, prompt, question, historyMessages)
+	if window != nil {
+		chainMemory = &contextWindowMemory{Memory: chainMemory, window: window}
+		retriever = &contextWindowRetriever{Retriever: retriever, window: window}
+	}
 	if history != nil {
-		llmChain.Memory = GetMemory(llm, instance.Spec.Memory, history, "", "")
+		llmChain.Memory = chainMemory
 	}
 	llmChain.CallbacksHandler = log.KLogHandler{LogLevel: 3}
 	condenseQustionGenerator := chains.LoadCondenseQuestionGenerator(llm)
 	condenseQustionGenerator.CallbacksHandler = log.KLogHandler{LogLevel: 3}
-	chain := chains.NewConversationalRetrievalQA(chains.NewStuffDocuments(llmChain), condenseQustionGenerator, retriever, GetMemory(llm, instance.Spec.Memory, history, "", ""))
+	chain := chains.NewConversationalRetrievalQA(chains.NewStuffDocuments(llmChain), condenseQustionGenerator, retriever, chainMemory)
 	chain.RephraseQuestion = false
 	chain.ReturnSourceDocuments = true
 	l.ConversationalRetrievalQA = chain
@@ -191,6 +210,10 @@ func (l *RetrievalQAChain) Run(ctx context.Context, cli client.Client, args map[
 		args[base.OutputAnswerKeyInArg] = out
 		// _conversationalRetrievalQADefaultSourceDocumentKey
 		doc, ok := outputValues["source_documents"].([]langchainschema.Document)
+		if window != nil && window.fitted {
+			// the references are the documents in the prompt, but not truncated
+			doc, ok = window.keptDocs, true
+		}
 		if ok {
 			_, refs := appruntimeretriever.ConvertDocuments(ctx, doc, "retrievalqachain")
 			// note: the references in args will be replaced, not append
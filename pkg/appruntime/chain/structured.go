@@ -0,0 +1,177 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tmc/langchaingo/chains"
+	"github.com/tmc/langchaingo/prompts"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/klog/v2"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+const defaultOutputSchemaMaxRetries = 2
+
+var jsonCodeBlock = regexp.MustCompile("(?s)```(?:json)?\\s*(.*?)```")
+
+// OutputSchemaParser parses the answer of the llm as a JSON value and validates it by the JSON Schema
+type OutputSchemaParser struct {
+	schema *spec.Schema
+	raw    string
+}
+
+// NewOutputSchemaParser creates a parser by the JSON Schema in JSON
+func NewOutputSchemaParser(schema string) (*OutputSchemaParser, error) {
+	s := &spec.Schema{}
+	if err := json.Unmarshal([]byte(schema), s); err != nil {
+		return nil, fmt.Errorf("invalid output schema: %w", err)
+	}
+	return &OutputSchemaParser{schema: s, raw: strings.TrimSpace(schema)}, nil
+}
+
+// Instructions returns the text to tell the llm how to answer
+func (p *OutputSchemaParser) Instructions() string {
+	return "Answer with a JSON value which follows the JSON Schema below, without any other text.\n```json\n" + p.raw + "\n```"
+}
+
+// Parse returns the JSON text in the answer and the parsed value, or the error to tell the llm why the answer is not valid
+func (p *OutputSchemaParser) Parse(answer string) (text string, value any, err error) {
+	text = extractJSON(answer)
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return "", nil, fmt.Errorf("the answer is not valid JSON: %w", err)
+	}
+	if err := validate.AgainstSchema(p.schema, value, strfmt.Default); err != nil {
+		return "", nil, err
+	}
+	return text, value, nil
+}
+
+// extractJSON returns the JSON in the answer, the llm may put it in a code block or add some words around it
+func extractJSON(answer string) string {
+	s := strings.TrimSpace(answer)
+	if m := jsonCodeBlock.FindStringSubmatch(s); m != nil {
+		s = strings.TrimSpace(m[1])
+	}
+	if json.Valid([]byte(s)) {
+		return s
+	}
+	start := strings.IndexAny(s, "{[")
+	end := strings.LastIndexAny(s, "}]")
+	if start < 0 || end < start {
+		return s
+	}
+	return s[start : end+1]
+}
+
+// structuredPrompt adds the instructions of the output schema to the prompt, and the failed answers with the
+// validation errors after it, so the llm can fix the answer
+type structuredPrompt struct {
+	prompts.FormatPrompter
+	instructions string
+	feedback     []langchaingoschema.ChatMessage
+}
+
+func (p *structuredPrompt) FormatPrompt(values map[string]any) (langchaingoschema.PromptValue, error) {
+	value, err := p.FormatPrompter.FormatPrompt(values)
+	if err != nil {
+		return nil, err
+	}
+	messages := append([]langchaingoschema.ChatMessage(nil), value.Messages()...)
+	instructed := false
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].GetType() == langchaingoschema.ChatMessageTypeHuman {
+			messages[i] = langchaingoschema.HumanChatMessage{Content: messages[i].GetContent() + "\n\n" + p.instructions}
+			instructed = true
+			break
+		}
+	}
+	if !instructed {
+		messages = append(messages, langchaingoschema.HumanChatMessage{Content: p.instructions})
+	}
+	return prompts.ChatPromptValue(append(messages, p.feedback...)), nil
+}
+
+// noSaveMemory loads the history but doesn't save the failed attempts to it
+type noSaveMemory struct {
+	langchaingoschema.Memory
+}
+
+func (noSaveMemory) SaveContext(context.Context, map[string]any, map[string]any) error { return nil }
+
+func (l *LLMChain) outputSchemaMaxRetries() int {
+	if l.Instance.Spec.OutputSchema.MaxRetries != nil {
+		return *l.Instance.Spec.OutputSchema.MaxRetries
+	}
+	return defaultOutputSchemaMaxRetries
+}
+
+// predictStructured asks the llm for an answer which is valid by the output schema, the validation error is sent
+// back to the llm to fix the answer up to MaxRetries times. Only the valid answer is saved to the memory.
+func (l *LLMChain) predictStructured(ctx context.Context, args map[string]any, options []chains.ChainCallOption) (text string, value any, err error) {
+	prompt := &structuredPrompt{FormatPrompter: l.Prompt, instructions: l.outputParser.Instructions()}
+	chain := l.LLMChain
+	chain.Prompt = prompt
+	chain.Memory = noSaveMemory{Memory: l.Memory}
+	attempts := l.outputSchemaMaxRetries() + 1
+	var answer string
+	for i := 1; i <= attempts; i++ {
+		answer, err = chains.Predict(ctx, chain, args, options...)
+		if err != nil {
+			return "", nil, err
+		}
+		text, value, err = l.outputParser.Parse(answer)
+		if err == nil {
+			if err := l.Memory.SaveContext(ctx, args, map[string]any{"text": text}); err != nil {
+				return "", nil, err
+			}
+			return text, value, nil
+		}
+		klog.FromContext(ctx).V(3).Info("the answer is not valid by the output schema", "attempt", i, "error", err.Error())
+		prompt.feedback = append(prompt.feedback,
+			langchaingoschema.AIChatMessage{Content: answer},
+			langchaingoschema.HumanChatMessage{Content: fmt.Sprintf("The answer is not valid: %s\nFix the answer, only return the JSON.", err)})
+	}
+	return "", nil, &base.StructuredOutputError{Answer: answer, Attempts: attempts, Err: err}
+}
+
+// runStructured runs the chain with the output schema, the answer is streamed after it is valid,
+// because a part of the JSON is not useful and the answer may be replaced by the retries
+func (l *LLMChain) runStructured(ctx context.Context, args map[string]any, options []chains.ChainCallOption, needStream bool) (map[string]any, error) {
+	text, value, err := l.predictStructured(ctx, args, options)
+	if err != nil {
+		return args, fmt.Errorf("llmchain run error: %w", err)
+	}
+	klog.FromContext(ctx).V(5).Info("use llmchain, structured out:" + text)
+	args[base.OutputAnswerKeyInArg] = text
+	args[base.OutputStructuredKeyInArg] = value
+	if needStream {
+		if err := stream(args)(ctx, []byte(text)); err != nil {
+			return args, err
+		}
+	}
+	return args, nil
+}
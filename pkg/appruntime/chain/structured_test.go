@@ -0,0 +1,116 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/chains"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/memory"
+	"github.com/tmc/langchaingo/prompts"
+
+	"github.com/kubeagi/arcadia/api/app-node/chain/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+const testOutputSchema = `{"type": "object", "properties": {"name": {"type": "string"}, "age": {"type": "integer"}}, "required": ["name"]}`
+
+// fakeAnswersLLM returns the answers in order, and records the prompts
+type fakeAnswersLLM struct {
+	answers []string
+	prompts []string
+}
+
+func (f *fakeAnswersLLM) GenerateContent(_ context.Context, messages []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	f.prompts = append(f.prompts, messages[0].Parts[0].(llms.TextContent).Text)
+	answer := f.answers[0]
+	f.answers = f.answers[1:]
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: answer}}}, nil
+}
+
+func (f *fakeAnswersLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+func TestOutputSchemaParser(t *testing.T) {
+	_, err := NewOutputSchemaParser("not json")
+	assert.ErrorContains(t, err, "invalid output schema")
+
+	parser, err := NewOutputSchemaParser(testOutputSchema)
+	require.NoError(t, err)
+	for answer, expected := range map[string]string{
+		`{"name": "Tom", "age": 3}`:                               `{"name": "Tom", "age": 3}`,
+		"```json\n{\"name\": \"Tom\"}\n```":                       `{"name": "Tom"}`,
+		`Sure, here is the answer: {"name": "Tom"} Hope it helps`: `{"name": "Tom"}`,
+	} {
+		text, value, err := parser.Parse(answer)
+		require.NoError(t, err, answer)
+		assert.Equal(t, expected, text)
+		assert.Equal(t, "Tom", value.(map[string]any)["name"])
+	}
+	_, _, err = parser.Parse("I don't know")
+	assert.ErrorContains(t, err, "not valid JSON")
+	_, _, err = parser.Parse(`{"age": 3}`)
+	assert.ErrorContains(t, err, "name")
+	_, _, err = parser.Parse(`{"name": "Tom", "age": "three"}`)
+	assert.ErrorContains(t, err, "age")
+}
+
+func TestRunStructured(t *testing.T) {
+	ctx := context.Background()
+	newChain := func(llm llms.Model, maxRetries int, history *memory.ChatMessageHistory) *LLMChain {
+		parser, err := NewOutputSchemaParser(testOutputSchema)
+		require.NoError(t, err)
+		l := &LLMChain{
+			Instance:     &v1alpha1.LLMChain{Spec: v1alpha1.LLMChainSpec{OutputSchema: &v1alpha1.OutputSchema{Schema: testOutputSchema, MaxRetries: &maxRetries}}},
+			outputParser: parser,
+		}
+		l.LLMChain = *chains.NewLLMChain(llm, prompts.NewChatPromptTemplate([]prompts.MessageFormatter{
+			prompts.NewHumanMessagePromptTemplate("{{.question}}", []string{"question"}),
+		}))
+		windowSize := 5
+		l.Memory = GetMemory(llm, v1alpha1.Memory{ConversionWindowSize: &windowSize}, history, nil, "", "")
+		return l
+	}
+
+	// the validation error is sent back to the llm, and only the valid answer is saved
+	llm := &fakeAnswersLLM{answers: []string{`{"age": 3}`, `{"name": "Tom", "age": 3}`}}
+	history := memory.NewChatMessageHistory()
+	args, err := newChain(llm, 2, history).runStructured(ctx, map[string]any{"question": "who is the cat?"}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name": "Tom", "age": 3}`, args[base.OutputAnswerKeyInArg])
+	assert.Equal(t, map[string]any{"name": "Tom", "age": float64(3)}, args[base.OutputStructuredKeyInArg])
+	require.Len(t, llm.prompts, 2)
+	assert.Contains(t, llm.prompts[0], "who is the cat?\n\nAnswer with a JSON value which follows the JSON Schema below")
+	assert.Contains(t, llm.prompts[1], "AI: {\"age\": 3}\nHuman: The answer is not valid: ")
+	messages, err := history.Messages(ctx)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, `{"name": "Tom", "age": 3}`, messages[1].GetContent())
+
+	// the run fails after all the attempts
+	llm = &fakeAnswersLLM{answers: []string{"no", "still no"}}
+	_, err = newChain(llm, 1, memory.NewChatMessageHistory()).runStructured(ctx, map[string]any{"question": "who is the cat?"}, nil, false)
+	var structuredErr *base.StructuredOutputError
+	require.ErrorAs(t, err, &structuredErr)
+	assert.Equal(t, 2, structuredErr.Attempts)
+	assert.Equal(t, "still no", structuredErr.Answer)
+}
@@ -17,16 +17,11 @@ limitations under the License.
 package documentloader
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"io"
-	"path/filepath"
 	"strings"
 
-	"github.com/tmc/langchaingo/documentloaders"
 	"github.com/tmc/langchaingo/schema"
-	"github.com/tmc/langchaingo/textsplitter"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
@@ -38,6 +33,7 @@ import (
 	"github.com/kubeagi/arcadia/pkg/config"
 	"github.com/kubeagi/arcadia/pkg/datasource"
 	arcadiadocumentloaders "github.com/kubeagi/arcadia/pkg/documentloaders"
+	"github.com/kubeagi/arcadia/pkg/splitter"
 )
 
 type DocumentLoader struct {
@@ -104,43 +100,30 @@ func (dl *DocumentLoader) Run(ctx context.Context, cli client.Client, args map[s
 		defer fileHandler.Close()
 		// TODO: cache the content if the hash does not change, and use the content directly without read and load it again
 
-		data, err := io.ReadAll(fileHandler)
+		// Use ext name in the spec first, and use real file ext name if it does not exist
+		loader, err := arcadiadocumentloaders.NewLoader(fileHandler, file, arcadiadocumentloaders.LoaderOptions{
+			ExtName: dl.Instance.Spec.FileExtName,
+		})
 		if err != nil {
 			klog.Errorln("failed to read file content", err)
 			continue
 		}
 
-		var loader documentloaders.Loader
-		// Use ext name in the spec first, and use real file ext name if it does not exist
-		extName := dl.Instance.Spec.FileExtName
-		if extName == "" {
-			extName = filepath.Ext(file)
-		}
-		switch extName {
-		case ".mp3", ".wav":
-			loader = arcadiadocumentloaders.NewAudoWithWhisper(data, file, "", "", false)
-		case ".csv":
-			dataReader := bytes.NewReader(data)
-			loader = documentloaders.NewCSV(dataReader)
-		case ".html", ".htm":
-			dataReader := bytes.NewReader(data)
-			loader = documentloaders.NewHTML(dataReader)
-		case ".pdf":
-			dataReader := bytes.NewReader(data)
-			loader = arcadiadocumentloaders.NewPDF(dataReader, file)
-			// loader = documentloaders.NewPDF(dataReader, int64(len(data)))
-		default:
-			dataReader := bytes.NewReader(data)
-			loader = documentloaders.NewText(dataReader)
+		split, err := splitter.New(ctx, splitter.Options{
+			Type:         dl.Instance.Spec.Splitter,
+			ChunkSize:    dl.Instance.Spec.ChunkSize,
+			ChunkOverlap: pointer.IntDeref(dl.Instance.Spec.ChunkOverlap, arcadiav1alpha1.DefaultChunkOverlap),
+		})
+		if err != nil {
+			return nil, err
 		}
-
-		split := textsplitter.NewRecursiveCharacter(
-			textsplitter.WithChunkSize(dl.Instance.Spec.ChunkSize),
-			textsplitter.WithChunkOverlap(pointer.IntDeref(dl.Instance.Spec.ChunkOverlap, arcadiav1alpha1.DefaultChunkOverlap)),
-		)
-		docs, err := loader.LoadAndSplit(ctx, split)
+		docs, err := loader.Load(ctx)
 		if err != nil {
-			klog.Errorln("failed to load and split content", err)
+			klog.Errorln("failed to load content", err)
+			return nil, err
+		}
+		if docs, err = split.SplitDocuments(docs); err != nil {
+			klog.Errorln("failed to split content", err)
 			return nil, err
 		}
 
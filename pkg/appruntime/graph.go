@@ -0,0 +1,330 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/utils/strings/slices"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+// CycleError is returned when the nodes of an application form a cycle
+type CycleError struct {
+	// Path is the node names on the cycle, the first node is repeated at the end
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("application nodes should not have a cycle: %s", strings.Join(e.Path, " -> "))
+}
+
+// SortNodes returns the node names in topological order.
+// Nodes without dependencies between each other keep the order of the spec, so the result is stable.
+// It returns a *CycleError if the nodes have a cycle.
+func SortNodes(nodes []arcadiav1alpha1.Node) ([]string, error) {
+	inDegree := make(map[string]int, len(nodes))
+	for _, node := range nodes {
+		inDegree[node.Name] = 0
+	}
+	next := make(map[string][]string, len(nodes))
+	for _, node := range nodes {
+		for _, n := range node.NextNodeName {
+			if _, ok := inDegree[n]; !ok {
+				return nil, fmt.Errorf("node %s not found", n)
+			}
+			next[node.Name] = append(next[node.Name], n)
+			inDegree[n]++
+		}
+	}
+	sorted := make([]string, 0, len(nodes))
+	queue := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if inDegree[node.Name] == 0 {
+			queue = append(queue, node.Name)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		sorted = append(sorted, current)
+		for _, n := range next[current] {
+			inDegree[n]--
+			if inDegree[n] == 0 {
+				queue = append(queue, n)
+			}
+		}
+	}
+	if len(sorted) != len(nodes) {
+		return nil, &CycleError{Path: findCycle(nodes, next, inDegree)}
+	}
+	return sorted, nil
+}
+
+// findCycle walks the nodes left by Kahn's algorithm to report one cycle
+func findCycle(nodes []arcadiav1alpha1.Node, next map[string][]string, inDegree map[string]int) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(nodes))
+	path := make([]string, 0)
+	var walk func(name string) []string
+	walk = func(name string) []string {
+		state[name] = visiting
+		path = append(path, name)
+		for _, n := range next[name] {
+			switch state[n] {
+			case visiting:
+				for i := range path {
+					if path[i] == n {
+						return append(append([]string{}, path[i:]...), n)
+					}
+				}
+			case unvisited:
+				if cycle := walk(n); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		return nil
+	}
+	for _, node := range nodes {
+		if inDegree[node.Name] > 0 && state[node.Name] == unvisited {
+			if cycle := walk(node.Name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// ExclusiveNodes checks if the nodes never run in the same application run.
+// routes are the next nodes of each route by the router node name, and a router takes only one route in a run.
+// Two nodes are exclusive if every path from the input to one of them goes through a next node of a router, every path
+// to the other goes through another next node of the same router, and no route of the router takes both next nodes.
+func ExclusiveNodes(nodes []arcadiav1alpha1.Node, routes map[string][][]string, names ...string) bool {
+	type edge struct {
+		from, to string
+	}
+	starts := make([]string, 0, 1)
+	inDegree := make(map[string]int, len(nodes))
+	next := make(map[string][]string, len(nodes))
+	for _, node := range nodes {
+		next[node.Name] = node.NextNodeName
+		for _, n := range node.NextNodeName {
+			inDegree[n]++
+		}
+	}
+	// paths start from the input node, nodes like llms and knowledgebases are shared by all branches
+	for _, node := range nodes {
+		if node.Ref != nil && node.Ref.Kind == arcadiav1alpha1.InputNode {
+			starts = append(starts, node.Name)
+		}
+	}
+	if len(starts) == 0 {
+		for _, node := range nodes {
+			if inDegree[node.Name] == 0 {
+				starts = append(starts, node.Name)
+			}
+		}
+	}
+	// reachable returns the nodes reachable from the starting nodes without the excluded edge
+	reachable := func(excluded edge) map[string]bool {
+		visited := make(map[string]bool, len(nodes))
+		queue := append([]string(nil), starts...)
+		for _, s := range starts {
+			visited[s] = true
+		}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, n := range next[current] {
+				if visited[n] || (edge{from: current, to: n}) == excluded {
+					continue
+				}
+				visited[n] = true
+				queue = append(queue, n)
+			}
+		}
+		return visited
+	}
+	// behind returns the next node of the router which every path to the node goes through, or "" if there is none
+	behind := func(router, node string) string {
+		for _, n := range next[router] {
+			if !reachable(edge{from: router, to: n})[node] {
+				return n
+			}
+		}
+		return ""
+	}
+	sameRoute := func(router, a, b string) bool {
+		for _, route := range routes[router] {
+			if slices.Contains(route, a) && slices.Contains(route, b) {
+				return true
+			}
+		}
+		return false
+	}
+	exclusive := func(a, b string) bool {
+		for router := range routes {
+			x, y := behind(router, a), behind(router, b)
+			if x != "" && y != "" && x != y && !sameRoute(router, x, y) {
+				return true
+			}
+		}
+		return false
+	}
+	for i := range names {
+		for j := i + 1; j < len(names); j++ {
+			if !exclusive(names[i], names[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// branchArgs is the args of one branch of the application graph.
+// writers records, for each key, the topological index of the node which wrote the value last,
+// -1 means the value comes from the application input.
+type branchArgs struct {
+	args    map[string]any
+	writers map[string]int
+}
+
+func newBranchArgs(args map[string]any) *branchArgs {
+	writers := make(map[string]int, len(args))
+	for k := range args {
+		writers[k] = -1
+	}
+	return &branchArgs{args: args, writers: writers}
+}
+
+// listKeysInArg are the keys whose values are collected from all branches when they join
+var listKeysInArg = []string{
+	base.LangchaingoRetrieversKeyInArg,
+	base.RuntimeRetrieverReferencesKeyInArg,
+}
+
+// mergeBranches merges the args of the prev nodes when the branches join:
+//  1. retrievers and references are concatenated in the order of the branches, duplicates are removed.
+//  2. for other keys, the value written by the latest node in topological order wins,
+//     so a value which is not changed in one branch will not override the value changed in another branch.
+func mergeBranches(branches []*branchArgs) *branchArgs {
+	if len(branches) == 1 {
+		return branches[0]
+	}
+	merged := &branchArgs{args: make(map[string]any), writers: make(map[string]int)}
+	for _, b := range branches {
+		for k, v := range b.args {
+			w, exist := merged.writers[k]
+			if !exist || b.writers[k] > w {
+				merged.args[k] = v
+				merged.writers[k] = b.writers[k]
+			}
+		}
+	}
+	for _, key := range listKeysInArg {
+		var (
+			list   reflect.Value
+			writer = -1
+		)
+		for _, b := range branches {
+			v, ok := b.args[key]
+			if !ok || v == nil {
+				continue
+			}
+			rv := reflect.ValueOf(v)
+			if rv.Kind() != reflect.Slice {
+				continue
+			}
+			if !list.IsValid() {
+				list = reflect.MakeSlice(rv.Type(), 0, rv.Len())
+			} else if list.Type() != rv.Type() {
+				continue
+			}
+			for i := 0; i < rv.Len(); i++ {
+				if !containsValue(list, rv.Index(i)) {
+					list = reflect.Append(list, rv.Index(i))
+				}
+			}
+			if b.writers[key] > writer {
+				writer = b.writers[key]
+			}
+		}
+		if list.IsValid() {
+			merged.args[key] = list.Interface()
+			merged.writers[key] = writer
+		}
+	}
+	return merged
+}
+
+func containsValue(list reflect.Value, v reflect.Value) bool {
+	for i := 0; i < list.Len(); i++ {
+		if reflect.DeepEqual(list.Index(i).Interface(), v.Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// copyArgs copies the args for a node, slices are copied too,
+// so appending to them in one branch will not affect another branch.
+func copyArgs(args map[string]any) map[string]any {
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		if rv := reflect.ValueOf(v); v != nil && rv.Kind() == reflect.Slice && !rv.IsNil() {
+			v = reflect.AppendSlice(reflect.MakeSlice(rv.Type(), 0, rv.Len()), rv).Interface()
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// written returns the args after a node run, and marks the keys changed by this node with its topological index
+func (b *branchArgs) written(out map[string]any, index int) *branchArgs {
+	res := &branchArgs{args: out, writers: make(map[string]int, len(out))}
+	for k, v := range out {
+		old, exist := b.args[k]
+		if exist && reflect.DeepEqual(old, v) {
+			res.writers[k] = b.writers[k]
+		} else {
+			res.writers[k] = index
+		}
+	}
+	return res
+}
+
+// changedBy returns the keys added or changed by the node with the topological index
+func (b *branchArgs) changedBy(index int) []string {
+	keys := make([]string, 0)
+	for k, w := range b.writers {
+		if w == index {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+func node(name string, next ...string) arcadiav1alpha1.Node {
+	return arcadiav1alpha1.Node{NodeConfig: arcadiav1alpha1.NodeConfig{Name: name}, NextNodeName: next}
+}
+
+func TestSortNodes(t *testing.T) {
+	nodes := []arcadiav1alpha1.Node{
+		node("input", "retriever-a", "retriever-b"),
+		node("merger", "chain"),
+		node("retriever-a", "merger"),
+		node("retriever-b", "merger"),
+		node("chain", "output"),
+		node("output"),
+	}
+	sorted, err := SortNodes(nodes)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"input", "retriever-a", "retriever-b", "merger", "chain", "output"}
+	if !reflect.DeepEqual(sorted, expected) {
+		t.Fatalf("expected %v, got %v", expected, sorted)
+	}
+
+	nodes = []arcadiav1alpha1.Node{
+		node("input", "a"),
+		node("a", "b"),
+		node("b", "c"),
+		node("c", "a", "output"),
+		node("output"),
+	}
+	_, err = SortNodes(nodes)
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	expected = []string{"a", "b", "c", "a"}
+	if !reflect.DeepEqual(cycleErr.Path, expected) {
+		t.Fatalf("expected cycle %v, got %v", expected, cycleErr.Path)
+	}
+
+	if _, err = SortNodes([]arcadiav1alpha1.Node{node("input", "missing")}); err == nil {
+		t.Fatalf("expected error for missing node")
+	}
+}
+
+func TestMergeBranches(t *testing.T) {
+	input := newBranchArgs(map[string]any{
+		base.InputQuestionKeyInArg:              "q",
+		"context":                               "",
+		base.RuntimeRetrieverReferencesKeyInArg: []string{"shared"},
+	})
+
+	a := copyArgs(input.args)
+	a["context"] = "from a"
+	a[base.RuntimeRetrieverReferencesKeyInArg] = append(a[base.RuntimeRetrieverReferencesKeyInArg].([]string), "a")
+	branchA := input.written(a, 1)
+
+	b := copyArgs(input.args)
+	b[base.RuntimeRetrieverReferencesKeyInArg] = append(b[base.RuntimeRetrieverReferencesKeyInArg].([]string), "b")
+	b["llm"] = "llm"
+	branchB := input.written(b, 2)
+
+	merged := mergeBranches([]*branchArgs{branchA, branchB})
+	expected := map[string]any{
+		base.InputQuestionKeyInArg:              "q",
+		"context":                               "from a",
+		"llm":                                   "llm",
+		base.RuntimeRetrieverReferencesKeyInArg: []string{"shared", "a", "b"},
+	}
+	if !reflect.DeepEqual(merged.args, expected) {
+		t.Fatalf("expected %v, got %v", expected, merged.args)
+	}
+	if !reflect.DeepEqual(input.args[base.RuntimeRetrieverReferencesKeyInArg], []string{"shared"}) {
+		t.Fatalf("input args should not be changed by branches, got %v", input.args)
+	}
+}
+
+func TestExclusiveNodes(t *testing.T) {
+	input := node("input", "router")
+	input.Ref = &arcadiav1alpha1.TypedObjectReference{Kind: arcadiav1alpha1.InputNode}
+	nodes := []arcadiav1alpha1.Node{
+		input,
+		// the llm is shared by all branches
+		node("llm", "chat", "qa", "policy"),
+		node("router", "chat", "retriever", "policy"),
+		node("retriever", "qa"),
+		node("chat", "output"),
+		node("qa", "output"),
+		node("policy", "output"),
+		node("output"),
+	}
+	routes := map[string][][]string{"router": {{"chat"}, {"retriever"}, {"policy"}}}
+	if !ExclusiveNodes(nodes, routes, "chat", "qa", "policy") {
+		t.Fatalf("nodes on different routes should be exclusive")
+	}
+	routes = map[string][][]string{"router": {{"chat", "retriever"}, {"policy"}}}
+	if ExclusiveNodes(nodes, routes, "chat", "qa") {
+		t.Fatalf("nodes on the same route should not be exclusive")
+	}
+	if ExclusiveNodes(nodes, nil, "chat", "qa") {
+		t.Fatalf("nodes without routers should not be exclusive")
+	}
+	// qa can also be reached without the router
+	nodes[0].NextNodeName = []string{"router", "retriever"}
+	routes = map[string][][]string{"router": {{"chat"}, {"retriever"}, {"policy"}}}
+	if ExclusiveNodes(nodes, routes, "chat", "qa") {
+		t.Fatalf("nodes reachable without the router should not be exclusive")
+	}
+}
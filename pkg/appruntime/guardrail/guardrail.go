@@ -0,0 +1,422 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/prompts"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeagi/arcadia/api/app-node/guardrail/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	"github.com/kubeagi/arcadia/pkg/config"
+)
+
+const defaultClassifierPrompt = `Check whether the following text is safe for a public assistant.
+Unsafe text includes violence, pornography, hate speech, illegal activities, self-harm and politically sensitive content.
+Answer with safe or unsafe only.
+
+Text: {{.text}}
+Answer:`
+
+// Guardrail checks the question before the next nodes, and checks the answer of the application after all nodes are done.
+// The streamed answer is checked sentence by sentence, only the checked sentences are sent.
+type Guardrail struct {
+	base.BaseNode
+	Instance *v1alpha1.Guardrail
+	words    *regexp.Regexp
+	pii      []pii
+
+	// state of one run, reset by GuardAnswer
+	mu         sync.Mutex
+	ran        bool
+	llm        llms.Model
+	events     []base.GuardrailEvent
+	streamIn   chan string
+	streamDone chan struct{}
+	streamed   strings.Builder
+}
+
+type pii struct {
+	name        string
+	re          *regexp.Regexp
+	replacement string
+}
+
+var _ base.Guard = (*Guardrail)(nil)
+
+func NewGuardrail(baseNode base.BaseNode) *Guardrail {
+	return &Guardrail{
+		BaseNode: baseNode,
+	}
+}
+
+func (g *Guardrail) Init(ctx context.Context, cli client.Client, _ map[string]any) error {
+	instance := &v1alpha1.Guardrail{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: g.RefNamespace(), Name: g.Ref.Name}, instance); err != nil {
+		return fmt.Errorf("can't find the guardrail in cluster: %w", err)
+	}
+	g.Instance = instance
+	words, err := g.loadWords(ctx, cli)
+	if err != nil {
+		return err
+	}
+	g.words = nil
+	if len(words) > 0 {
+		quoted := make([]string, 0, len(words))
+		for _, w := range words {
+			quoted = append(quoted, regexp.QuoteMeta(w))
+		}
+		g.words = regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+	}
+	g.pii = make([]pii, 0, len(instance.Spec.PII))
+	for _, detector := range instance.Spec.PII {
+		re, err := regexp.Compile(detector.GetRegexp())
+		if err != nil {
+			return fmt.Errorf("pii detector %s has an invalid regexp: %w", detector.Name, err)
+		}
+		replacement := detector.Replacement
+		if replacement == "" {
+			replacement = "***"
+		}
+		g.pii = append(g.pii, pii{name: detector.Name, re: re, replacement: replacement})
+	}
+	return nil
+}
+
+// loadWords returns the sensitive words in the spec and in the ConfigMap or the file of the VersionedDataset
+func (g *Guardrail) loadWords(ctx context.Context, cli client.Client) ([]string, error) {
+	spec := g.Instance.Spec.SensitiveWords
+	if spec == nil {
+		return nil, nil
+	}
+	words := make([]string, 0, len(spec.Words))
+	words = append(words, spec.Words...)
+	if spec.From == nil {
+		return cleanWords(words), nil
+	}
+	namespace := spec.From.GetNamespace(g.RefNamespace())
+	switch spec.From.Kind {
+	case "ConfigMap":
+		cm := &corev1.ConfigMap{}
+		if err := cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: spec.From.Name}, cm); err != nil {
+			return nil, fmt.Errorf("can't find the configmap of sensitive words: %w", err)
+		}
+		data, ok := cm.Data[spec.Key]
+		if !ok {
+			return nil, fmt.Errorf("no key %s in configmap %s", spec.Key, spec.From.Name)
+		}
+		words = append(words, strings.Split(data, "\n")...)
+	case "VersionedDataset":
+		vds := &arcadiav1alpha1.VersionedDataset{}
+		if err := cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: spec.From.Name}, vds); err != nil {
+			return nil, fmt.Errorf("can't find the versioneddataset of sensitive words: %w", err)
+		}
+		if vds.Spec.Dataset == nil {
+			return nil, fmt.Errorf("versioneddataset %s has no dataset", spec.From.Name)
+		}
+		oss, err := config.GetSystemDatasourceOSS(ctx)
+		if err != nil {
+			return nil, err
+		}
+		file, err := oss.ReadFile(ctx, &arcadiav1alpha1.OSS{
+			Bucket: namespace,
+			Object: filepath.Join("dataset", vds.Spec.Dataset.Name, vds.Spec.Version, spec.Key),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sensitive words from versioneddataset %s: %w", spec.From.Name, err)
+		}
+		defer file.Close()
+		lines, err := readLines(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sensitive words from versioneddataset %s: %w", spec.From.Name, err)
+		}
+		words = append(words, lines...)
+	default:
+		return nil, fmt.Errorf("sensitive words can't be read from %s", spec.From.Kind)
+	}
+	return cleanWords(words), nil
+}
+
+func readLines(r io.Reader) ([]string, error) {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// cleanWords drops the empty lines and comments starting with #
+func cleanWords(words []string) []string {
+	res := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.TrimSpace(w)
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		res = append(res, w)
+	}
+	return res
+}
+
+func (g *Guardrail) Run(ctx context.Context, _ client.Client, args map[string]any) (map[string]any, error) {
+	spec := g.Instance.Spec
+	g.mu.Lock()
+	g.ran = true
+	g.llm, _ = args[base.LangchaingoLLMKeyInArg].(llms.Model)
+	g.mu.Unlock()
+	if spec.Classifier != nil && g.llm == nil {
+		return args, errors.New("no llm for the classifier of guardrail")
+	}
+
+	if spec.HasStage(v1alpha1.GuardrailStageInput) {
+		question, err := base.GetInputQuestionFromArg(args)
+		if err != nil {
+			return args, err
+		}
+		checked, blocked := g.check(ctx, v1alpha1.GuardrailStageInput, question)
+		if blocked {
+			return args, &base.GuardrailBlockedError{Msg: spec.GetBlockedReply()}
+		}
+		args[base.InputQuestionKeyInArg] = checked
+	}
+
+	if spec.HasStage(v1alpha1.GuardrailStageOutput) {
+		needStream, _ := args[base.InputIsNeedStreamKeyInArg].(bool)
+		out, _ := args[base.OutputAnswerStreamChanKeyInArg].(chan string)
+		if needStream && out != nil {
+			args[base.OutputAnswerStreamChanKeyInArg] = g.guardStream(context.WithoutCancel(ctx), out)
+		}
+	}
+	return args, nil
+}
+
+// guardStream returns a channel for the next nodes to stream the answer, the answer is sent to out
+// sentence by sentence after checked, and nothing more is sent after the blocked reply.
+func (g *Guardrail) guardStream(ctx context.Context, out chan string) chan string {
+	in := make(chan string)
+	done := make(chan struct{})
+	g.mu.Lock()
+	g.streamIn, g.streamDone = in, done
+	g.streamed.Reset()
+	g.mu.Unlock()
+	go func() {
+		defer close(done)
+		blocked := false
+		send := func(text string) {
+			if blocked || text == "" {
+				return
+			}
+			text, blocked = g.check(ctx, v1alpha1.GuardrailStageOutput, text)
+			if blocked {
+				text = g.Instance.Spec.GetBlockedReply()
+			}
+			g.mu.Lock()
+			g.streamed.WriteString(text)
+			g.mu.Unlock()
+			out <- text
+		}
+		var pending strings.Builder
+		for chunk := range in {
+			pending.WriteString(chunk)
+			s := pending.String()
+			if i := lastSentenceEnd(s); i > 0 {
+				pending.Reset()
+				pending.WriteString(s[i:])
+				send(s[:i])
+			}
+		}
+		send(pending.String())
+	}()
+	return in
+}
+
+// lastSentenceEnd returns the index after the last sentence end in s, 0 if there is none.
+// A dot ends a sentence only if a space follows, so numbers like 0.5 are not split.
+func lastSentenceEnd(s string) int {
+	for i := len(s); i > 0; {
+		r, size := utf8.DecodeLastRuneInString(s[:i])
+		switch r {
+		case '。', '！', '？', '；', '!', '?', ';', '\n':
+			return i
+		case '.':
+			if next, _ := utf8.DecodeRuneInString(s[i:]); i < len(s) && unicode.IsSpace(next) {
+				return i
+			}
+		}
+		i -= size
+	}
+	return 0
+}
+
+// check checks the text of the stage, and returns the text after the action and whether it is blocked
+func (g *Guardrail) check(ctx context.Context, stage v1alpha1.GuardrailStage, text string) (string, bool) {
+	action := g.Instance.Spec.GetAction()
+	reasons := make([]string, 0)
+	masked := text
+	if g.words != nil {
+		seen := make(map[string]bool)
+		for _, w := range g.words.FindAllString(text, -1) {
+			if w = strings.ToLower(w); !seen[w] {
+				seen[w] = true
+				reasons = append(reasons, "sensitive_word:"+w)
+			}
+		}
+		masked = g.words.ReplaceAllStringFunc(masked, func(s string) string {
+			return strings.Repeat("*", utf8.RuneCountInString(s))
+		})
+	}
+	for _, p := range g.pii {
+		if p.re.MatchString(masked) {
+			reasons = append(reasons, "pii:"+p.name)
+			masked = p.re.ReplaceAllLiteralString(masked, p.replacement)
+		}
+	}
+	blocked := action == v1alpha1.GuardrailActionBlock && len(reasons) > 0
+	if !blocked && g.Instance.Spec.Classifier != nil && !g.classify(ctx, masked) {
+		reasons = append(reasons, "classifier")
+		// the unsafe text can't be masked
+		blocked = action != v1alpha1.GuardrailActionFlag
+	}
+	if len(reasons) == 0 {
+		return text, false
+	}
+	taken := action
+	if blocked {
+		taken = v1alpha1.GuardrailActionBlock
+	}
+	klog.FromContext(ctx).V(3).Info("guardrail check failed", "node", g.Name(), "stage", stage, "action", taken, "reasons", reasons)
+	g.mu.Lock()
+	g.events = append(g.events, base.GuardrailEvent{Node: g.Name(), Stage: string(stage), Action: string(taken), Reasons: reasons})
+	g.mu.Unlock()
+	if action == v1alpha1.GuardrailActionMask && !blocked {
+		return masked, false
+	}
+	return text, blocked
+}
+
+// classify asks the llm whether the text is safe, the text is taken as safe if the llm fails
+func (g *Guardrail) classify(ctx context.Context, text string) bool {
+	template := g.Instance.Spec.Classifier.Prompt
+	if template == "" {
+		template = defaultClassifierPrompt
+	}
+	logger := klog.FromContext(ctx)
+	prompt, err := prompts.NewPromptTemplate(template, []string{"text"}).Format(map[string]any{"text": text})
+	if err != nil {
+		logger.Error(err, "failed to format the guardrail classifier prompt")
+		return true
+	}
+	tracer := base.NodeTracerFromContext(ctx)
+	tracer.AddEvent(base.TraceEventLLMStart, prompt)
+	answer, err := llms.GenerateFromSinglePrompt(ctx, g.llm, prompt, llms.WithTemperature(0))
+	if err != nil {
+		tracer.AddEvent(base.TraceEventLLMError, err.Error())
+		logger.Error(err, "guardrail classifier failed, take the text as safe")
+		return true
+	}
+	tracer.AddEvent(base.TraceEventLLMEnd, answer)
+	return !strings.Contains(strings.ToLower(answer), "unsafe")
+}
+
+// CheckQuestion checks the question by the sensitive words and PII detectors without taking any action,
+// a question can't pass if it needs the classifier, which is only available in the run
+func (g *Guardrail) CheckQuestion(_ context.Context, question string) bool {
+	spec := g.Instance.Spec
+	if !spec.HasStage(v1alpha1.GuardrailStageInput) {
+		return true
+	}
+	if spec.Classifier != nil {
+		return false
+	}
+	if g.words != nil && g.words.MatchString(question) {
+		return false
+	}
+	for _, p := range g.pii {
+		if p.re.MatchString(question) {
+			return false
+		}
+	}
+	return true
+}
+
+// GuardAnswer implements base.Guard
+func (g *Guardrail) GuardAnswer(ctx context.Context, answer string) (string, []base.GuardrailEvent) {
+	g.mu.Lock()
+	ran, in, done := g.ran, g.streamIn, g.streamDone
+	g.mu.Unlock()
+	if !ran {
+		return answer, nil
+	}
+	if in != nil {
+		close(in)
+		<-done
+	}
+	g.mu.Lock()
+	streamed := g.streamed.String()
+	g.mu.Unlock()
+	switch {
+	case streamed != "":
+		answer = streamed
+	case answer != "" && g.Instance.Spec.HasStage(v1alpha1.GuardrailStageOutput):
+		checked, blocked := g.check(ctx, v1alpha1.GuardrailStageOutput, answer)
+		if blocked {
+			checked = g.Instance.Spec.GetBlockedReply()
+		}
+		answer = checked
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	events := g.events
+	g.ran, g.llm, g.events, g.streamIn, g.streamDone = false, nil, nil, nil, nil
+	g.streamed.Reset()
+	return answer, events
+}
+
+func (g *Guardrail) Ready() (isReady bool, msg string) {
+	isReady, msg = g.Instance.Status.IsReadyOrGetReadyMessage()
+	if !isReady {
+		return isReady, msg
+	}
+	if g.Instance.Spec.Classifier == nil {
+		return true, ""
+	}
+	for _, n := range g.GetPrevNode() {
+		if n.Kind() == "llm" {
+			return true, ""
+		}
+	}
+	return false, "the guardrail's prev node should have one llm for the classifier"
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeagi/arcadia/api/app-node/guardrail/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+func newGuardrail(t *testing.T, config v1alpha1.GuardrailConfig) *Guardrail {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	instance := &v1alpha1.Guardrail{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "guardrail"},
+		Spec:       v1alpha1.GuardrailSpec{GuardrailConfig: config},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(instance).Build()
+	g := NewGuardrail(base.NewBaseNode("default", "guardrail-node", arcadiav1alpha1.TypedObjectReference{
+		APIGroup: pointer.String(v1alpha1.Group), Kind: "Guardrail", Name: "guardrail",
+	}))
+	require.NoError(t, g.Init(context.Background(), cli, nil))
+	return g
+}
+
+func TestGuardrailInput(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	g := newGuardrail(t, v1alpha1.GuardrailConfig{
+		Stages:         []v1alpha1.GuardrailStage{v1alpha1.GuardrailStageInput},
+		Action:         v1alpha1.GuardrailActionMask,
+		SensitiveWords: &v1alpha1.SensitiveWords{Words: []string{"Secret", " ", "# comment"}},
+		PII:            []v1alpha1.PIIDetector{{Name: "phone"}},
+	})
+	args, err := g.Run(ctx, nil, map[string]any{base.InputQuestionKeyInArg: "my secret phone is 13800138000"})
+	require.NoError(t, err)
+	assert.Equal(t, "my ****** phone is ***", args[base.InputQuestionKeyInArg])
+	_, events := g.GuardAnswer(ctx, "")
+	require.Len(t, events, 1)
+	assert.Equal(t, "mask", events[0].Action)
+	assert.Equal(t, []string{"sensitive_word:secret", "pii:phone"}, events[0].Reasons)
+
+	g.Instance.Spec.Action = v1alpha1.GuardrailActionBlock
+	_, err = g.Run(ctx, nil, map[string]any{base.InputQuestionKeyInArg: "tell me the SECRET"})
+	var blocked *base.GuardrailBlockedError
+	require.True(t, errors.As(err, &blocked))
+	assert.Equal(t, v1alpha1.DefaultBlockedReply, blocked.Msg)
+	// the state is reset for the next run
+	g.GuardAnswer(ctx, "")
+	_, events = g.GuardAnswer(ctx, "")
+	assert.Empty(t, events)
+
+	assert.True(t, g.CheckQuestion(ctx, "what is the weather"))
+	assert.False(t, g.CheckQuestion(ctx, "call 13800138000"))
+}
+
+func TestGuardrailStream(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	g := newGuardrail(t, v1alpha1.GuardrailConfig{
+		Stages:         []v1alpha1.GuardrailStage{v1alpha1.GuardrailStageOutput},
+		BlockedReply:   "blocked",
+		SensitiveWords: &v1alpha1.SensitiveWords{Words: []string{"secret"}},
+	})
+	out := make(chan string, 10)
+	args, err := g.Run(ctx, nil, map[string]any{
+		base.InputIsNeedStreamKeyInArg:      true,
+		base.OutputAnswerStreamChanKeyInArg: out,
+	})
+	require.NoError(t, err)
+	in := args[base.OutputAnswerStreamChanKeyInArg].(chan string)
+	for _, chunk := range []string{"It costs 0.", "5 yuan. The ", "sec", "ret is 42。", "Bye"} {
+		in <- chunk
+	}
+	answer, events := g.GuardAnswer(ctx, "It costs 0.5 yuan. The secret is 42。Bye")
+	close(out)
+	sent := make([]string, 0)
+	for s := range out {
+		sent = append(sent, s)
+	}
+	// the sentence with the sensitive word is replaced, and nothing is sent after it
+	assert.Equal(t, []string{"It costs 0.5 yuan.", "blocked"}, sent)
+	assert.Equal(t, strings.Join(sent, ""), answer)
+	require.Len(t, events, 1)
+	assert.Equal(t, "output", events[0].Stage)
+}
+
+func TestLastSentenceEnd(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, 0, lastSentenceEnd("0.5"))
+	assert.Equal(t, 0, lastSentenceEnd("ends with a dot."))
+	assert.Equal(t, len("a."), lastSentenceEnd("a. b"))
+	assert.Equal(t, len("你好。"), lastSentenceEnd("你好。世界"))
+}
@@ -28,12 +28,15 @@ import (
 	"github.com/kubeagi/arcadia/api/base/v1alpha1"
 	"github.com/kubeagi/arcadia/pkg/appruntime/base"
 	"github.com/kubeagi/arcadia/pkg/langchainwrap"
+	"github.com/kubeagi/arcadia/pkg/llms"
 )
 
 type LLM struct {
 	base.BaseNode
 	langchainllms.Model
 	Instance *v1alpha1.LLM
+	// maxContextLength is the max context length of the default model, 0 if unknown
+	maxContextLength int
 }
 
 func NewLLM(baseNode base.BaseNode) *LLM {
@@ -53,6 +56,7 @@ func (z *LLM) Init(ctx context.Context, cli client.Client, _ map[string]any) err
 	}
 	z.Model = llm
 	z.Instance = instance
+	z.maxContextLength = getMaxContextLength(ctx, cli, instance)
 	return nil
 }
 
@@ -66,3 +70,94 @@ func (z *LLM) Run(ctx context.Context, _ client.Client, args map[string]any) (ma
 func (z *LLM) Ready() (isReady bool, msg string) {
 	return z.Instance.Status.IsReadyOrGetReadyMessage()
 }
+
+// MaxContextLength returns the max context length of the model, the one of the default model if model is empty
+func (z *LLM) MaxContextLength(model string) int {
+	if length, ok := llms.ModelContextLengths[model]; ok {
+		return length
+	}
+	return z.maxContextLength
+}
+
+// getMaxContextLength returns the max context length of the default model of the llm, which is in the spec of the
+// Model served by the worker, or known by the model name for the 3rd party llms
+func getMaxContextLength(ctx context.Context, cli client.Client, instance *v1alpha1.LLM) int {
+	if instance.Spec.Provider.GetType() != v1alpha1.ProviderTypeWorker {
+		if models := instance.GetModelList(); len(models) > 0 {
+			return llms.ModelContextLengths[models[0]]
+		}
+		return 0
+	}
+	if instance.Spec.Worker == nil {
+		return 0
+	}
+	logger := klog.FromContext(ctx)
+	worker := &v1alpha1.Worker{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: instance.Spec.Worker.GetNamespace(instance.GetNamespace()), Name: instance.Spec.Worker.Name}, worker); err != nil {
+		logger.Error(err, "failed to get the worker of the llm, the max context length is unknown")
+		return 0
+	}
+	if worker.Spec.Model == nil {
+		return 0
+	}
+	model := &v1alpha1.Model{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: worker.Spec.Model.GetNamespace(worker.GetNamespace()), Name: worker.Spec.Model.Name}, model); err != nil {
+		logger.Error(err, "failed to get the model of the worker, the max context length is unknown")
+		return 0
+	}
+	return model.Spec.MaxContextLength
+}
+
+// GenerateContent calls the llm and adds the tokens used to the TokenUsage in ctx
+func (z *LLM) GenerateContent(ctx context.Context, messages []langchainllms.MessageContent, options ...langchainllms.CallOption) (*langchainllms.ContentResponse, error) {
+	resp, err := z.Model.GenerateContent(ctx, messages, options...)
+	if err == nil {
+		base.TokenUsageFromContext(ctx).Add(usedTokens(messages, resp))
+	}
+	return resp, err
+}
+
+// Call goes through GenerateContent, so the tokens are counted too
+func (z *LLM) Call(ctx context.Context, prompt string, options ...langchainllms.CallOption) (string, error) {
+	return langchainllms.GenerateFromSinglePrompt(ctx, z, prompt, options...)
+}
+
+// usedTokens returns the tokens reported by the llm, or an estimate of the prompt and the answer if not reported,
+// like in the streaming mode of openai
+func usedTokens(messages []langchainllms.MessageContent, resp *langchainllms.ContentResponse) int64 {
+	if len(resp.Choices) > 0 {
+		info := resp.Choices[0].GenerationInfo
+		if total := toInt64(info["TotalTokens"]); total > 0 {
+			return total
+		}
+		if total := toInt64(info["PromptTokens"]) + toInt64(info["CompletionTokens"]); total > 0 {
+			return total
+		}
+	}
+	var tokens int64
+	for _, m := range messages {
+		for _, p := range m.Parts {
+			if t, ok := p.(langchainllms.TextContent); ok {
+				tokens += int64(base.EstimateTokens(t.Text))
+			}
+		}
+	}
+	for _, c := range resp.Choices {
+		tokens += int64(base.EstimateTokens(c.Content))
+	}
+	return tokens
+}
+
+func toInt64(v any) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
@@ -25,9 +25,12 @@ import (
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/schema"
 	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
 )
 
-// KLogHandler is a callback handler that prints to klog v3
+// KLogHandler is a callback handler that prints to klog v3,
+// and records the events into the trace of the running node if there is a NodeTracer in the context
 type KLogHandler struct {
 	LogLevel int
 }
@@ -51,6 +54,19 @@ func (l KLogHandler) HandleLLMGenerateContentStart(ctx context.Context, ms []llm
 	}
 	logger.WithValues("logger", "arcadia")
 	logger.V(l.LogLevel).Info(buf.String())
+	if tracer := base.NodeTracerFromContext(ctx); tracer != nil {
+		prompt := strings.Builder{}
+		for _, m := range ms {
+			prompt.WriteString(string(m.Role) + ": ")
+			for _, t := range m.Parts {
+				if t, ok := t.(llms.TextContent); ok {
+					prompt.WriteString(t.Text)
+				}
+			}
+			prompt.WriteString("\n")
+		}
+		tracer.AddEvent(base.TraceEventLLMStart, prompt.String())
+	}
 }
 
 func (l KLogHandler) HandleLLMGenerateContentEnd(ctx context.Context, res *llms.ContentResponse) {
@@ -76,6 +92,20 @@ func (l KLogHandler) HandleLLMGenerateContentEnd(ctx context.Context, res *llms.
 	}
 	logger.WithValues("logger", "arcadia")
 	logger.V(l.LogLevel).Info(buf.String())
+	if tracer := base.NodeTracerFromContext(ctx); tracer != nil {
+		out := strings.Builder{}
+		for _, c := range res.Choices {
+			out.WriteString(c.Content)
+			if c.FuncCall != nil {
+				out.WriteString("\nFuncCall: " + c.FuncCall.Name + " " + c.FuncCall.Arguments)
+			}
+			// token usage is in the generation info, like CompletionTokens, PromptTokens and TotalTokens
+			for k, v := range c.GenerationInfo {
+				out.WriteString(fmt.Sprintf("\n%s: %v", k, v))
+			}
+		}
+		tracer.AddEvent(base.TraceEventLLMEnd, out.String())
+	}
 }
 
 func (l KLogHandler) HandleStreamingFunc(ctx context.Context, chunk []byte) {
@@ -108,6 +138,7 @@ func (l KLogHandler) HandleLLMError(ctx context.Context, err error) {
 	logger := klog.FromContext(ctx)
 	logger.WithValues("logger", "arcadia")
 	logger.V(l.LogLevel).Error(err, "Exiting LLM with error")
+	base.NodeTracerFromContext(ctx).AddEvent(base.TraceEventLLMError, err.Error())
 }
 
 func (l KLogHandler) HandleChainStart(ctx context.Context, inputs map[string]any) {
@@ -120,6 +151,17 @@ func (l KLogHandler) HandleChainEnd(ctx context.Context, outputs map[string]any)
 	logger := klog.FromContext(ctx)
 	logger.WithValues("logger", "arcadia")
 	logger.V(l.LogLevel).Info(fmt.Sprintf("Exiting chain with outputs: %#v", outputs))
+	if tracer := base.NodeTracerFromContext(ctx); tracer != nil {
+		out := strings.Builder{}
+		for k, v := range outputs {
+			// source documents are recorded by the retriever
+			if _, isDocs := v.([]schema.Document); isDocs {
+				continue
+			}
+			out.WriteString(fmt.Sprintf("%s: %v\n", k, v))
+		}
+		tracer.AddEvent(base.TraceEventChainEnd, out.String())
+	}
 }
 
 func (l KLogHandler) HandleChainError(ctx context.Context, err error) {
@@ -132,30 +174,35 @@ func (l KLogHandler) HandleToolStart(ctx context.Context, input string) {
 	logger := klog.FromContext(ctx)
 	logger.WithValues("logger", "arcadia")
 	logger.V(l.LogLevel).Info("Entering tool with input: " + removeNewLines(input))
+	base.NodeTracerFromContext(ctx).AddEvent(base.TraceEventToolStart, input)
 }
 
 func (l KLogHandler) HandleToolEnd(ctx context.Context, output string) {
 	logger := klog.FromContext(ctx)
 	logger.WithValues("logger", "arcadia")
 	logger.V(l.LogLevel).Info("Exiting tool with output: " + removeNewLines(output))
+	base.NodeTracerFromContext(ctx).AddEvent(base.TraceEventToolEnd, output)
 }
 
 func (l KLogHandler) HandleToolError(ctx context.Context, err error) {
 	logger := klog.FromContext(ctx)
 	logger.WithValues("logger", "arcadia")
 	logger.V(l.LogLevel).Error(err, "Exiting tool with error")
+	base.NodeTracerFromContext(ctx).AddEvent(base.TraceEventToolError, err.Error())
 }
 
 func (l KLogHandler) HandleAgentAction(ctx context.Context, action schema.AgentAction) {
 	logger := klog.FromContext(ctx)
 	logger.WithValues("logger", "arcadia")
 	logger.V(l.LogLevel).Info("Agent selected action: " + formatAgentAction(action))
+	base.NodeTracerFromContext(ctx).AddEvent(base.TraceEventAgentAction, formatAgentAction(action))
 }
 
 func (l KLogHandler) HandleAgentFinish(ctx context.Context, finish schema.AgentFinish) {
 	logger := klog.FromContext(ctx)
 	logger.WithValues("logger", "arcadia")
 	logger.V(l.LogLevel).Info("Agent finish: " + formatAgentFinish(finish))
+	base.NodeTracerFromContext(ctx).AddEvent(base.TraceEventAgentFinish, formatAgentFinish(finish))
 }
 
 func (l KLogHandler) HandleRetrieverStart(ctx context.Context, query string) {
@@ -170,6 +217,14 @@ func (l KLogHandler) HandleRetrieverEnd(ctx context.Context, query string, docum
 	// TODO need format
 	// logger.V(l.LogLevel).Info(fmt.Sprintf("Exiting retriever with documents for query:%s documents: %#v", query, documents))
 	logger.V(l.LogLevel).Info(fmt.Sprintf("Exiting retriever with documents for query: %s", query))
+	if tracer := base.NodeTracerFromContext(ctx); tracer != nil {
+		out := strings.Builder{}
+		out.WriteString(fmt.Sprintf("query: %s\n", query))
+		for i, doc := range documents {
+			out.WriteString(fmt.Sprintf("[%d] score: %.4f %s\n", i, doc.Score, doc.PageContent))
+		}
+		tracer.AddEvent(base.TraceEventRetrieverEnd, out.String())
+	}
 }
 
 func formatAgentAction(action schema.AgentAction) string {
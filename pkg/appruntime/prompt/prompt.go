@@ -79,6 +79,12 @@ func (p *Prompt) Run(ctx context.Context, cli client.Client, args map[string]any
 			},
 		},
 	}
+	// the input variables of the application can be used in the prompt by their names
+	if variables, ok := args[base.InputVariablesKeyInArg].(map[string]string); ok {
+		for k, v := range variables {
+			template.PartialVariables[k] = v
+		}
+	}
 	// todo format
 	p.ChatPromptTemplate = template
 	args["prompt"] = p
@@ -51,6 +51,14 @@ type Reference struct {
 	Title string `json:"title,omitempty" example:"开始使用 Microsoft 帐户 – Microsoft"`
 	// URL of the webpage
 	URL string `json:"url,omitempty" example:"https://www.microsoft.com/zh-cn/welcome"`
+	// Heading is the header path of the markdown chunk
+	Heading string `json:"heading,omitempty" example:"员工手册 > 考勤管理"`
+	// Sheet is the sheet name of the excel row
+	Sheet string `json:"sheet,omitempty" example:"Sheet1"`
+	// SlideNumber is the slide number of the powerpoint slide
+	SlideNumber int `json:"slide_number,omitempty" example:"3"`
+	// SearchMode is the search modes which found the chunk, like `vector,keyword`
+	SearchMode string `json:"search_mode,omitempty" example:"vector,keyword"`
 	// RerankScore
 	RerankScore float32        `json:"rerank_score,omitempty" example:"0.58124"`
 	Metadata    map[string]any `json:"-"`
@@ -148,6 +156,38 @@ func ConvertDocuments(ctx context.Context, docs []langchaingoschema.Document, re
 				content = strings.TrimPrefix(strings.TrimSuffix(string(a), "\""), "\"")
 			}
 		}
+		title, ok := doc.Metadata[documentloaders.TitleCol].(string)
+		if !ok {
+			if a, ok := doc.Metadata[documentloaders.TitleCol].([]byte); ok {
+				title = strings.TrimPrefix(strings.TrimSuffix(string(a), "\""), "\"")
+			}
+		}
+		pageURL, ok := doc.Metadata[documentloaders.URLCol].(string)
+		if !ok {
+			if a, ok := doc.Metadata[documentloaders.URLCol].([]byte); ok {
+				pageURL = strings.TrimPrefix(strings.TrimSuffix(string(a), "\""), "\"")
+			}
+		}
+		heading, ok := doc.Metadata[documentloaders.HeadingCol].(string)
+		if !ok {
+			if a, ok := doc.Metadata[documentloaders.HeadingCol].([]byte); ok {
+				heading = strings.TrimPrefix(strings.TrimSuffix(string(a), "\""), "\"")
+			}
+		}
+		sheet, ok := doc.Metadata[documentloaders.SheetCol].(string)
+		if !ok {
+			if a, ok := doc.Metadata[documentloaders.SheetCol].([]byte); ok {
+				sheet = strings.TrimPrefix(strings.TrimSuffix(string(a), "\""), "\"")
+			}
+		}
+		slideNumber, ok := doc.Metadata[documentloaders.SlideNumberCol].(string)
+		if !ok {
+			if a, ok := doc.Metadata[documentloaders.SlideNumberCol].([]byte); ok {
+				slideNumber = strings.TrimPrefix(strings.TrimSuffix(string(a), "\""), "\"")
+			}
+		}
+		slide, _ := strconv.Atoi(slideNumber)
+		searchMode, _ := doc.Metadata[SearchModeCol].(string)
 		rerankScore, _ := doc.Metadata[RerankScoreCol].(float32)
 		refs = append(refs, Reference{
 			Question:     pageContent,
@@ -158,6 +198,12 @@ func ConvertDocuments(ctx context.Context, docs []langchaingoschema.Document, re
 			FileName:     filename,
 			PageNumber:   page,
 			Content:      content,
+			Title:        title,
+			URL:          pageURL,
+			Heading:      heading,
+			Sheet:        sheet,
+			SlideNumber:  slide,
+			SearchMode:   searchMode,
 			Metadata:     doc.Metadata,
 			RerankScore:  rerankScore,
 		})
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retriever
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores"
+	"k8s.io/klog/v2"
+
+	apiretriever "github.com/kubeagi/arcadia/api/app-node/retriever/v1alpha1"
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/log"
+	pkgvectorstore "github.com/kubeagi/arcadia/pkg/vectorstore"
+)
+
+// SearchModeCol is the metadata of the search modes which found the document, like `vector,keyword`
+const SearchModeCol = "search_mode"
+
+// rrfK is the constant of reciprocal rank fusion, which lowers the weight of the top ranks
+const rrfK = 60
+
+func withSearchMode(docs []schema.Document, mode apiretriever.SearchMode) []schema.Document {
+	for i := range docs {
+		if docs[i].Metadata == nil {
+			docs[i].Metadata = make(map[string]any)
+		}
+		docs[i].Metadata[SearchModeCol] = string(mode)
+	}
+	return docs
+}
+
+// searchKnowledgebase searches the knowledgebase by the search mode of the config, the scores of the documents are similarities in [0, 1]
+func searchKnowledgebase(ctx context.Context, s vectorstores.VectorStore, vectorStore *v1alpha1.VectorStore, kb *v1alpha1.KnowledgeBase, config apiretriever.CommonRetrieverConfig, query string) ([]schema.Document, error) {
+	logger := klog.FromContext(ctx)
+	vectorSearch := func(num int) ([]schema.Document, error) {
+		var retriever vectorstores.Retriever
+		if config.ScoreThreshold != nil {
+			retriever = vectorstores.ToRetriever(s, num, vectorstores.WithScoreThreshold(*config.ScoreThreshold))
+		} else {
+			retriever = vectorstores.ToRetriever(s, num)
+		}
+		retriever.CallbacksHandler = log.KLogHandler{LogLevel: 3}
+		docs, err := retriever.GetRelevantDocuments(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("can't get relevant documents: %w", err)
+		}
+		// pgvector get score means vector distance, similarity = 1 - vector distance
+		// chroma get score means similarity
+		// we want similarity finally.
+		if vectorStore.Spec.Type() == v1alpha1.VectorStoreTypePGVector {
+			for i := range docs {
+				docs[i].Score = 1 - docs[i].Score
+			}
+		}
+		return withSearchMode(docs, apiretriever.SearchModeVector), nil
+	}
+	keywordSearch := func(num int) ([]schema.Document, error) {
+		key := fmt.Sprintf("%s/%s/%s", vectorStore.Namespace, vectorStore.Name, kb.VectorStoreCollectionName())
+		index, err := pkgvectorstore.GetKeywordIndex(ctx, s, key, kb.FilesVersion())
+		if err != nil {
+			return nil, err
+		}
+		docs := normalizeKeywordScores(index.Search(query, num), config.ScoreThreshold)
+		return withSearchMode(docs, apiretriever.SearchModeKeyword), nil
+	}
+
+	switch config.SearchMode {
+	case apiretriever.SearchModeVector, "":
+		return vectorSearch(config.NumDocuments)
+	case apiretriever.SearchModeKeyword:
+		return keywordSearch(config.NumDocuments)
+	case apiretriever.SearchModeHybrid:
+		// more candidates from each search make the fusion meaningful
+		vectorDocs, err := vectorSearch(config.NumDocuments * 2)
+		if err != nil {
+			return nil, err
+		}
+		keywordDocs, err := keywordSearch(config.NumDocuments * 2)
+		if errors.Is(err, pkgvectorstore.ErrKeywordSearchNotSupported) || errors.Is(err, pkgvectorstore.ErrKeywordIndexTooLarge) {
+			logger.Info("keyword search is not available, use vector search only", "vectorstore", vectorStore.Name, "reason", err.Error())
+			if len(vectorDocs) > config.NumDocuments {
+				vectorDocs = vectorDocs[:config.NumDocuments]
+			}
+			return vectorDocs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return fuseByRank(config.NumDocuments, vectorDocs, keywordDocs), nil
+	default:
+		return nil, fmt.Errorf("unknown search mode %s", config.SearchMode)
+	}
+}
+
+// normalizeKeywordScores divides the bm25 scores, which are not bounded, by the score of the best match,
+// so the scores are in (0, 1] like the similarities, and drops the documents below the threshold
+func normalizeKeywordScores(docs []schema.Document, threshold *float32) []schema.Document {
+	if len(docs) == 0 {
+		return docs
+	}
+	best := docs[0].Score
+	res := make([]schema.Document, 0, len(docs))
+	for _, doc := range docs {
+		if best > 0 {
+			doc.Score /= best
+		} else {
+			doc.Score = 1
+		}
+		if threshold != nil && doc.Score < *threshold {
+			continue
+		}
+		res = append(res, doc)
+	}
+	return res
+}
+
+// fuseByRank merges the results by reciprocal rank fusion, the documents with the same content are the same one.
+// The score of a document is its fused score divided by the max possible one, and its search modes are joined by `,`.
+func fuseByRank(num int, results ...[]schema.Document) []schema.Document {
+	type fused struct {
+		doc   schema.Document
+		score float64
+		modes []string
+	}
+	byContent := make(map[string]*fused)
+	order := make([]*fused, 0)
+	for _, docs := range results {
+		for rank, doc := range docs {
+			f, ok := byContent[doc.PageContent]
+			if !ok {
+				f = &fused{doc: doc}
+				byContent[doc.PageContent] = f
+				order = append(order, f)
+			}
+			f.score += 1 / float64(rrfK+rank+1)
+			if mode, ok := doc.Metadata[SearchModeCol].(string); ok {
+				f.modes = append(f.modes, mode)
+			}
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].score > order[j].score
+	})
+	if len(order) > num {
+		order = order[:num]
+	}
+	best := float64(len(results)) / float64(rrfK+1)
+	res := make([]schema.Document, 0, len(order))
+	for _, f := range order {
+		f.doc.Score = float32(f.score / best)
+		f.doc.Metadata[SearchModeCol] = strings.Join(f.modes, ",")
+		res = append(res, f.doc)
+	}
+	return res
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retriever
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/schema"
+
+	pkgvectorstore "github.com/kubeagi/arcadia/pkg/vectorstore"
+)
+
+func TestNormalizeKeywordScores(t *testing.T) {
+	t.Parallel()
+	index := pkgvectorstore.NewKeywordIndex([]schema.Document{
+		{PageContent: "The warranty of product X-100 is two years"},
+		{PageContent: "Product X-200 has no warranty, the product is cheap"},
+		{PageContent: "The warranty covers the battery"},
+		{PageContent: "员工请假需要提前审批"},
+	})
+	raw := index.Search("x-100 warranty", 5)
+	require.Len(t, raw, 3)
+	require.Greater(t, raw[1].Score, float32(1), "bm25 scores are not bounded")
+
+	docs := normalizeKeywordScores(raw, nil)
+	require.Len(t, docs, 3)
+	assert.Equal(t, float32(1), docs[0].Score)
+	for i, doc := range docs {
+		assert.Greater(t, doc.Score, float32(0))
+		assert.LessOrEqual(t, doc.Score, float32(1))
+		if i > 0 {
+			assert.LessOrEqual(t, doc.Score, docs[i-1].Score)
+		}
+	}
+
+	threshold := (docs[1].Score + docs[2].Score) / 2
+	assert.Len(t, normalizeKeywordScores(index.Search("x-100 warranty", 5), &threshold), 2)
+
+	// zero scores are not divided by
+	docs = normalizeKeywordScores([]schema.Document{{Score: 0}, {Score: 0}}, nil)
+	assert.Equal(t, []float32{1, 1}, []float32{docs[0].Score, docs[1].Score})
+	assert.Empty(t, normalizeKeywordScores(nil, nil))
+}
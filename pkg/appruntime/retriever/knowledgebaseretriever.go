@@ -31,7 +31,6 @@ import (
 	apiretriever "github.com/kubeagi/arcadia/api/app-node/retriever/v1alpha1"
 	"github.com/kubeagi/arcadia/api/base/v1alpha1"
 	"github.com/kubeagi/arcadia/pkg/appruntime/base"
-	"github.com/kubeagi/arcadia/pkg/appruntime/log"
 	"github.com/kubeagi/arcadia/pkg/langchainwrap"
 	pkgvectorstore "github.com/kubeagi/arcadia/pkg/vectorstore"
 )
@@ -96,6 +95,8 @@ func (l *KnowledgeBaseRetriever) Ready() (isReady bool, msg string) {
 func (l *KnowledgeBaseRetriever) Cleanup() {
 	if l.Finish != nil {
 		l.Finish()
+		// the node may be run again when the application is cached
+		l.Finish = nil
 	}
 }
 
@@ -143,30 +144,15 @@ func GenerateKnowledgebaseRetriever(ctx context.Context, cli client.Client, know
 		return nil, finish, err
 	}
 	logger := klog.FromContext(ctx)
-	logger.V(3).Info(fmt.Sprintf("retriever created[scorethreshold: %f][num: %d]", pointer.Float32Deref(retrieverConfig.ScoreThreshold, 0.0), retrieverConfig.NumDocuments))
-	var retriever vectorstores.Retriever
-	if retrieverConfig.ScoreThreshold != nil {
-		retriever = vectorstores.ToRetriever(s, retrieverConfig.NumDocuments, vectorstores.WithScoreThreshold(*retrieverConfig.ScoreThreshold))
-	} else {
-		retriever = vectorstores.ToRetriever(s, retrieverConfig.NumDocuments)
-	}
-	retriever.CallbacksHandler = log.KLogHandler{LogLevel: 3}
+	logger.V(3).Info(fmt.Sprintf("retriever created[scorethreshold: %f][num: %d][mode: %s]", pointer.Float32Deref(retrieverConfig.ScoreThreshold, 0.0), retrieverConfig.NumDocuments, retrieverConfig.SearchMode))
 
-	query, err := base.GetInputQuestionFromArg(args)
+	query, err := base.GetRetrievalQueryFromArg(args)
 	if err != nil {
 		return nil, finish, err
 	}
-	docs, err := retriever.GetRelevantDocuments(ctx, query)
+	docs, err := searchKnowledgebase(ctx, s, vectorStore, knowledgebase, retrieverConfig, query)
 	if err != nil {
-		return nil, finish, fmt.Errorf("can't get relevant documents: %w", err)
-	}
-	// pgvector get score means vector distance, similarity = 1 - vector distance
-	// chroma get score means similarity
-	// we want similarity finally.
-	if vectorStore.Spec.Type() == v1alpha1.VectorStoreTypePGVector {
-		for i := range docs {
-			docs[i].Score = 1 - docs[i].Score
-		}
+		return nil, finish, err
 	}
 	docs, refs := ConvertDocuments(ctx, docs, "knowledgebase")
 	args = AddReferencesToArgs(args, refs)
@@ -60,13 +60,9 @@ func (l *MultiQueryRetriever) Init(ctx context.Context, cli client.Client, _ map
 }
 
 func (l *MultiQueryRetriever) Run(ctx context.Context, cli client.Client, args map[string]any) (map[string]any, error) {
-	q, ok := args[base.InputQuestionKeyInArg]
-	if !ok {
-		return args, errors.New("no question in args")
-	}
-	query, ok := q.(string)
-	if !ok || len(query) == 0 {
-		return args, errors.New("empty question")
+	query, err := base.GetRetrievalQueryFromArg(args)
+	if err != nil {
+		return args, err
 	}
 
 	retrieversInArg, err := base.GetRetrieversFromArg(args)
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retriever
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/prompts"
+	langchainschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiretriever "github.com/kubeagi/arcadia/api/app-node/retriever/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+const (
+	_defaultCondenseTemplate = `Given the following conversation and a follow up question, rephrase the follow up question to be a standalone question, in its original language.
+
+Chat History:
+{{.history}}
+Follow Up Input: {{.question}}
+Standalone question:`
+
+	_defaultHyDETemplate = `Please write a short passage to answer the question, in the original language of the question.
+Question: {{.question}}
+Passage:`
+
+	_defaultKeywordsTemplate = `Extract the keywords of the question for searching documents, in the original language of the question.
+Answer with the keywords only, separated by spaces.
+Question: {{.question}}
+Keywords:`
+)
+
+// QueryTransform rewrites the query for the next retrievers by the llm, the result is saved in args
+// with the key base.RetrievalQueryKeyInArg, and the question is not changed for the final prompt.
+// Query transforms can be chained, a later one rewrites the query from the former one.
+type QueryTransform struct {
+	base.BaseNode
+	Instance *apiretriever.QueryTransform
+}
+
+func NewQueryTransform(baseNode base.BaseNode) *QueryTransform {
+	return &QueryTransform{
+		BaseNode: baseNode,
+	}
+}
+
+func (l *QueryTransform) Init(ctx context.Context, cli client.Client, _ map[string]any) error {
+	instance := &apiretriever.QueryTransform{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: l.RefNamespace(), Name: l.BaseNode.Ref.Name}, instance); err != nil {
+		return fmt.Errorf("can't find the query transform in cluster: %w", err)
+	}
+	l.Instance = instance
+	return nil
+}
+
+func (l *QueryTransform) Run(ctx context.Context, _ client.Client, args map[string]any) (map[string]any, error) {
+	query, err := base.GetRetrievalQueryFromArg(args)
+	if err != nil {
+		return args, err
+	}
+	v, ok := args[base.LangchaingoLLMKeyInArg]
+	if !ok {
+		return args, errors.New("no llm")
+	}
+	llm, ok := v.(llms.Model)
+	if !ok {
+		return args, errors.New("llm not llms.Model")
+	}
+
+	spec := l.Instance.Spec
+	var template, history string
+	switch spec.GetType() {
+	case apiretriever.QueryTransformTypeCondense:
+		template = _defaultCondenseTemplate
+		history, err = historyString(ctx, args, spec.GetConversionWindowSize())
+		if err != nil {
+			return args, err
+		}
+		if history == "" {
+			// the first question of the conversation is standalone already
+			klog.FromContext(ctx).V(3).Info("no chat history, skip condensing the query")
+			return args, nil
+		}
+	case apiretriever.QueryTransformTypeHyDE:
+		template = _defaultHyDETemplate
+	case apiretriever.QueryTransformTypeKeywords:
+		template = _defaultKeywordsTemplate
+	default:
+		return args, fmt.Errorf("unknown query transform type %s", spec.Type)
+	}
+	if spec.Prompt != "" {
+		template = spec.Prompt
+	}
+	prompt, err := prompts.NewPromptTemplate(template, []string{"question", "history"}).Format(map[string]any{
+		"question": query,
+		"history":  history,
+	})
+	if err != nil {
+		return args, fmt.Errorf("failed to format the query transform prompt: %w", err)
+	}
+	tracer := base.NodeTracerFromContext(ctx)
+	tracer.AddEvent(base.TraceEventLLMStart, prompt)
+	answer, err := llms.GenerateFromSinglePrompt(ctx, llm, prompt, llms.WithTemperature(0))
+	if err != nil {
+		tracer.AddEvent(base.TraceEventLLMError, err.Error())
+		return args, fmt.Errorf("failed to transform the query: %w", err)
+	}
+	tracer.AddEvent(base.TraceEventLLMEnd, answer)
+
+	answer = strings.TrimSpace(answer)
+	if spec.GetType() == apiretriever.QueryTransformTypeKeywords {
+		answer = strings.Join(strings.FieldsFunc(answer, func(r rune) bool {
+			return r == ',' || r == '，' || r == '、' || r == ';' || r == '\n' || r == ' '
+		}), " ")
+	}
+	if answer == "" {
+		klog.FromContext(ctx).V(3).Info("query transform gets an empty query, keep the query", "query", query)
+		return args, nil
+	}
+	klog.FromContext(ctx).V(3).Info("query transformed", "type", spec.GetType(), "query", query, "transformed", answer)
+	args[base.RetrievalQueryKeyInArg] = answer
+	return args, nil
+}
+
+// historyString formats the last rounds of the chat history, empty if there is no history
+func historyString(ctx context.Context, args map[string]any, rounds int) (string, error) {
+	v, ok := args[base.LangchaingoChatMessageHistoryKeyInArg]
+	if !ok || v == nil || rounds == 0 {
+		return "", nil
+	}
+	history, ok := v.(langchainschema.ChatMessageHistory)
+	if !ok {
+		return "", errors.New("history not memory.ChatMessageHistory")
+	}
+	messages, err := history.Messages(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the chat history: %w", err)
+	}
+	// one round has a question and an answer
+	if len(messages) > rounds*2 {
+		messages = messages[len(messages)-rounds*2:]
+	}
+	return langchainschema.GetBufferString(messages, "Human", "AI")
+}
+
+func (l *QueryTransform) Ready() (isReady bool, msg string) {
+	isReady, msg = l.Instance.Status.IsReadyOrGetReadyMessage()
+	if !isReady {
+		return isReady, msg
+	}
+	for _, n := range l.BaseNode.GetPrevNode() {
+		if n.Kind() == "llm" {
+			return true, ""
+		}
+	}
+	return false, "the query transform's prev node should have one llm"
+}
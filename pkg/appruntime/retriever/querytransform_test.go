@@ -0,0 +1,125 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retriever
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/memory"
+	langchainschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/utils/pointer"
+
+	apiretriever "github.com/kubeagi/arcadia/api/app-node/retriever/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+// fakeLLM returns the answer and records the prompts
+type fakeLLM struct {
+	answer  string
+	prompts []string
+}
+
+func (f *fakeLLM) GenerateContent(_ context.Context, messages []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	f.prompts = append(f.prompts, messages[0].Parts[0].(llms.TextContent).Text)
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: f.answer}}}, nil
+}
+
+func (f *fakeLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
+
+func newHistory(rounds ...string) langchainschema.ChatMessageHistory {
+	messages := make([]langchainschema.ChatMessage, 0, len(rounds)*2)
+	for _, r := range rounds {
+		messages = append(messages, langchainschema.HumanChatMessage{Content: r + "?"}, langchainschema.AIChatMessage{Content: r + "!"})
+	}
+	return memory.NewChatMessageHistory(memory.WithPreviousMessages(messages))
+}
+
+func TestHistoryString(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	args := map[string]any{base.LangchaingoChatMessageHistoryKeyInArg: newHistory("a", "b", "c")}
+
+	history, err := historyString(ctx, args, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Human: b?\nAI: b!\nHuman: c?\nAI: c!", history)
+
+	history, err = historyString(ctx, args, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "Human: a?\nAI: a!\nHuman: b?\nAI: b!\nHuman: c?\nAI: c!", history)
+
+	history, err = historyString(ctx, args, 0)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	history, err = historyString(ctx, map[string]any{}, 5)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestQueryTransform(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	newTransform := func(config apiretriever.QueryTransformConfig) *QueryTransform {
+		l := NewQueryTransform(base.BaseNode{})
+		l.Instance = &apiretriever.QueryTransform{Spec: apiretriever.QueryTransformSpec{QueryTransformConfig: config}}
+		return l
+	}
+	run := func(l *QueryTransform, llm *fakeLLM, args map[string]any) map[string]any {
+		args[base.LangchaingoLLMKeyInArg] = llm
+		out, err := l.Run(ctx, nil, args)
+		require.NoError(t, err)
+		return out
+	}
+
+	// condense skips the first question of the conversation
+	llm := &fakeLLM{answer: "what is the price of arcadia"}
+	out := run(newTransform(apiretriever.QueryTransformConfig{}), llm, map[string]any{
+		base.InputQuestionKeyInArg:                 "what is its price",
+		base.LangchaingoChatMessageHistoryKeyInArg: newHistory(),
+	})
+	assert.Empty(t, llm.prompts)
+	assert.NotContains(t, out, base.RetrievalQueryKeyInArg)
+
+	out = run(newTransform(apiretriever.QueryTransformConfig{ConversionWindowSize: pointer.Int(1)}), llm, map[string]any{
+		base.InputQuestionKeyInArg:                 "what is its price",
+		base.LangchaingoChatMessageHistoryKeyInArg: newHistory("what is kubeagi", "what is arcadia"),
+	})
+	require.Len(t, llm.prompts, 1)
+	assert.Contains(t, llm.prompts[0], "Human: what is arcadia?")
+	assert.NotContains(t, llm.prompts[0], "what is kubeagi")
+	assert.Equal(t, "what is the price of arcadia", out[base.RetrievalQueryKeyInArg])
+	// the question of the user is not changed
+	assert.Equal(t, "what is its price", out[base.InputQuestionKeyInArg])
+
+	// the keywords are separated by spaces
+	out = run(newTransform(apiretriever.QueryTransformConfig{Type: apiretriever.QueryTransformTypeKeywords}), &fakeLLM{answer: " arcadia，price、 license;\nversion "}, map[string]any{
+		base.InputQuestionKeyInArg: "how much is the license of arcadia",
+	})
+	assert.Equal(t, "arcadia price license version", out[base.RetrievalQueryKeyInArg])
+
+	// an empty answer keeps the query
+	out = run(newTransform(apiretriever.QueryTransformConfig{Type: apiretriever.QueryTransformTypeHyDE}), &fakeLLM{answer: " \n"}, map[string]any{
+		base.InputQuestionKeyInArg: "what is arcadia",
+	})
+	assert.NotContains(t, out, base.RetrievalQueryKeyInArg)
+}
@@ -70,13 +70,10 @@ func (l *RerankRetriever) Run(ctx context.Context, cli client.Client, args map[s
 		args[base.LangchaingoRetrieversKeyInArg] = []langchainschema.Retriever{&Fakeretriever{Docs: nil, Name: "RerankRetriever"}}
 		return args, nil
 	}
-	q, ok := args[base.InputQuestionKeyInArg]
-	if !ok {
-		return args, errors.New("no question in args")
-	}
-	query, ok := q.(string)
-	if !ok || len(query) == 0 {
-		return args, errors.New("empty question")
+	// the documents are reranked by the question of the user, not the query rewritten for retrieval
+	query, err := base.GetInputQuestionFromArg(args)
+	if err != nil {
+		return args, err
 	}
 	body := RerankRequestBody{
 		Query:    query,
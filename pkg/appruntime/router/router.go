@@ -0,0 +1,219 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/prompts"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeagi/arcadia/api/app-node/router/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+const defaultClassifyPrompt = `Classify the question into one of the intents below, answer with the intent name only.
+If the question does not match any intent, answer none.
+
+Intents:
+{{.routes}}
+
+Question: {{.question}}
+Intent:`
+
+// Router picks one route and only the next nodes of the route will run, other next nodes are skipped
+type Router struct {
+	base.BaseNode
+	Instance *v1alpha1.Router
+	regexps  map[string][]*regexp.Regexp
+}
+
+func NewRouter(baseNode base.BaseNode) *Router {
+	return &Router{
+		BaseNode: baseNode,
+	}
+}
+
+func (r *Router) Init(ctx context.Context, cli client.Client, _ map[string]any) error {
+	instance := &v1alpha1.Router{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: r.RefNamespace(), Name: r.Ref.Name}, instance); err != nil {
+		return fmt.Errorf("can't find the router in cluster: %w", err)
+	}
+	r.Instance = instance
+	r.regexps = make(map[string][]*regexp.Regexp, len(instance.Spec.Routes))
+	for _, route := range instance.Spec.Routes {
+		for _, expr := range route.Regexps {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("route %s has an invalid regexp %s: %w", route.Name, expr, err)
+			}
+			r.regexps[route.Name] = append(r.regexps[route.Name], re)
+		}
+	}
+	return nil
+}
+
+func (r *Router) Run(ctx context.Context, _ client.Client, args map[string]any) (map[string]any, error) {
+	route, err := r.pick(ctx, args)
+	if err != nil {
+		return args, err
+	}
+	nextNodes := r.Instance.Spec.DefaultNextNodeName
+	if route != nil {
+		nextNodes = route.NextNodeName
+		args[base.RouterRouteKeyInArg] = route.Name
+	} else if len(nextNodes) == 0 {
+		return args, errors.New("no route matches and no default next node")
+	}
+	klog.FromContext(ctx).V(3).Info("router picks next nodes", "router", r.Name(), "route", args[base.RouterRouteKeyInArg], "nextNodes", nextNodes)
+	args[base.RouterNextNodesKeyInArg] = append([]string(nil), nextNodes...)
+	return args, nil
+}
+
+// pick returns the route matches the args, nil if no route matches
+func (r *Router) pick(ctx context.Context, args map[string]any) (*v1alpha1.Route, error) {
+	routes := r.Instance.Spec.Routes
+	switch r.Instance.Spec.GetType() {
+	case v1alpha1.RouterTypeRule:
+		question, err := base.GetInputQuestionFromArg(args)
+		if err != nil {
+			return nil, err
+		}
+		lower := strings.ToLower(question)
+		for i, route := range routes {
+			for _, keyword := range route.Keywords {
+				if keyword != "" && strings.Contains(lower, strings.ToLower(keyword)) {
+					return &routes[i], nil
+				}
+			}
+			for _, re := range r.regexps[route.Name] {
+				if re.MatchString(question) {
+					return &routes[i], nil
+				}
+			}
+		}
+	case v1alpha1.RouterTypeArg:
+		v, ok := args[r.Instance.Spec.ArgKey]
+		if !ok || v == nil {
+			return nil, nil
+		}
+		value := fmt.Sprint(v)
+		for i, route := range routes {
+			for _, expected := range route.Values {
+				if value == expected {
+					return &routes[i], nil
+				}
+			}
+		}
+	case v1alpha1.RouterTypeLLM:
+		return r.classify(ctx, args)
+	default:
+		return nil, fmt.Errorf("unknown router type %s", r.Instance.Spec.Type)
+	}
+	return nil, nil
+}
+
+// classify asks the llm which route the question belongs to
+func (r *Router) classify(ctx context.Context, args map[string]any) (*v1alpha1.Route, error) {
+	question, err := base.GetInputQuestionFromArg(args)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := args[base.LangchaingoLLMKeyInArg]
+	if !ok {
+		return nil, errors.New("no llm")
+	}
+	llm, ok := v.(llms.Model)
+	if !ok {
+		return nil, errors.New("llm not llms.Model")
+	}
+	routes := r.Instance.Spec.Routes
+	intents := make([]string, 0, len(routes))
+	for _, route := range routes {
+		if route.Description != "" {
+			intents = append(intents, fmt.Sprintf("- %s: %s", route.Name, route.Description))
+		} else {
+			intents = append(intents, "- "+route.Name)
+		}
+	}
+	template := r.Instance.Spec.Prompt
+	if template == "" {
+		template = defaultClassifyPrompt
+	}
+	prompt, err := prompts.NewPromptTemplate(template, []string{"routes", "question"}).Format(map[string]any{
+		"routes":   strings.Join(intents, "\n"),
+		"question": question,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the router prompt: %w", err)
+	}
+	tracer := base.NodeTracerFromContext(ctx)
+	tracer.AddEvent(base.TraceEventLLMStart, prompt)
+	answer, err := llms.GenerateFromSinglePrompt(ctx, llm, prompt, llms.WithTemperature(0))
+	if err != nil {
+		tracer.AddEvent(base.TraceEventLLMError, err.Error())
+		return nil, fmt.Errorf("failed to classify the question: %w", err)
+	}
+	tracer.AddEvent(base.TraceEventLLMEnd, answer)
+	return matchIntent(routes, answer), nil
+}
+
+// matchIntent finds the route named by the llm answer, the exact name first, then the first name in the answer
+func matchIntent(routes []v1alpha1.Route, answer string) *v1alpha1.Route {
+	answer = strings.ToLower(strings.Trim(strings.TrimSpace(answer), "\"'`.。"))
+	for i, route := range routes {
+		if strings.ToLower(route.Name) == answer {
+			return &routes[i]
+		}
+	}
+	first, index := -1, len(answer)
+	for i, route := range routes {
+		if pos := strings.Index(answer, strings.ToLower(route.Name)); pos >= 0 && pos < index {
+			first, index = i, pos
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+	return &routes[first]
+}
+
+func (r *Router) Ready() (isReady bool, msg string) {
+	isReady, msg = r.Instance.Status.IsReadyOrGetReadyMessage()
+	if !isReady {
+		return isReady, msg
+	}
+	next := make(map[string]bool, len(r.GetNextNode()))
+	for _, n := range r.GetNextNode() {
+		next[n.Name()] = true
+	}
+	for _, names := range r.Instance.Spec.RouteNextNodeNames() {
+		for _, name := range names {
+			if !next[name] {
+				return false, fmt.Sprintf("node %s of the router is not a next node of %s", name, r.Name())
+			}
+		}
+	}
+	return true, ""
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+)
+
+// MaxAppDepth is the max number of applications in a chain of applications calling each other,
+// including the application called by the user
+const MaxAppDepth = 5
+
+// AppCycleError is returned when applications call each other in a cycle
+type AppCycleError struct {
+	// Path is the applications on the cycle, the first application is repeated at the end
+	Path []string
+}
+
+func (e *AppCycleError) Error() string {
+	return fmt.Sprintf("applications should not call each other in a cycle: %s", strings.Join(e.Path, " -> "))
+}
+
+// SubApplication runs another application as a step.
+// The question, history, files and conversation of the parent run are passed to the child application,
+// and the answer and references of the child are written back to args, so the next nodes or the output use them.
+type SubApplication struct {
+	base.BaseNode
+	Instance *arcadiav1alpha1.Application
+}
+
+func NewSubApplication(baseNode base.BaseNode) *SubApplication {
+	return &SubApplication{
+		BaseNode: baseNode,
+	}
+}
+
+func (s *SubApplication) Init(ctx context.Context, cli client.Client, _ map[string]any) error {
+	instance := &arcadiav1alpha1.Application{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: s.RefNamespace(), Name: s.Ref.Name}, instance); err != nil {
+		return fmt.Errorf("can't find the application in cluster: %w", err)
+	}
+	// a cycle through the parent application always goes back to the child, so it is found from the child
+	if _, err := CheckAppCalls(ctx, cli, instance, nil); err != nil {
+		return err
+	}
+	s.Instance = instance
+	return nil
+}
+
+func (s *SubApplication) Run(ctx context.Context, cli client.Client, args map[string]any) (map[string]any, error) {
+	key := types.NamespacedName{Namespace: s.Instance.Namespace, Name: s.Instance.Name}
+	stack := appStackFromContext(ctx)
+	if err := findAppCycle(stack, key); err != nil {
+		return args, err
+	}
+	if len(stack) >= MaxAppDepth {
+		return args, fmt.Errorf("applications can call each other at most %d levels deep", MaxAppDepth)
+	}
+	question, err := base.GetInputQuestionFromArg(args)
+	if err != nil {
+		return args, err
+	}
+	input := Input{Question: question}
+	input.Files, _ = args["files"].([]string)
+	input.History, _ = args[base.LangchaingoChatMessageHistoryKeyInArg].(langchaingoschema.ChatMessageHistory)
+	input.ConversationID, _ = args[base.ConversationIDInArg].(string)
+	input.Summary = base.GetConversationSummaryFromArg(args)
+	// the child application gets the values of the variables it also has
+	if variables, ok := args[base.InputVariablesKeyInArg].(map[string]string); ok {
+		input.Variables = make(map[string]any, len(variables))
+		for k, v := range variables {
+			input.Variables[k] = v
+		}
+	}
+	// only the answer of the ending node goes to the user, so the child streams only if this node is the ending node
+	var respStream chan string
+	if needStream, _ := args[base.InputIsNeedStreamKeyInArg].(bool); needStream && s.isEnding() {
+		if respStream, _ = args[base.OutputAnswerStreamChanKeyInArg].(chan string); respStream != nil {
+			input.NeedStream = true
+		}
+	}
+
+	logger := klog.FromContext(ctx).WithValues("node", s.Name(), "subApp", key)
+	ctx = klog.NewContext(ctx, logger)
+	logger.V(3).Info("run sub application")
+	app, err := NewAppOrGetFromCache(ctx, cli, s.Instance)
+	if err != nil {
+		return args, fmt.Errorf("init application %s failed: %w", key, err)
+	}
+	defer app.Release()
+	out, err := app.Run(ctx, cli, respStream, input)
+	base.NodeTracerFromContext(ctx).SetChildren(out.Trace)
+	if err != nil {
+		logger.Error(err, "sub application failed")
+		return args, fmt.Errorf("run application %s failed: %w", key, err)
+	}
+	logger.V(3).Info("sub application done", "references", len(out.References))
+	args[base.OutputAnswerKeyInArg] = out.Answer
+	if out.Structured != nil {
+		args[base.OutputStructuredKeyInArg] = out.Structured
+	}
+	if len(out.References) > 0 {
+		references, _ := args[base.RuntimeRetrieverReferencesKeyInArg].([]retriever.Reference)
+		args[base.RuntimeRetrieverReferencesKeyInArg] = append(append([]retriever.Reference(nil), references...), out.References...)
+	}
+	return args, nil
+}
+
+// isEnding checks if the next node is the output node
+func (s *SubApplication) isEnding() bool {
+	for _, n := range s.GetNextNode() {
+		if n.Kind() == "output" {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *SubApplication) Ready() (isReady bool, msg string) {
+	return s.Instance.Status.IsReadyOrGetReadyMessage()
+}
+
+// CheckAppCalls walks the applications called by the application through its application nodes,
+// it returns the depth of the calls, or an *AppCycleError if the applications call each other in a cycle,
+// or an error if the calls are deeper than MaxAppDepth.
+// stack is the applications which call the application, normally nil.
+func CheckAppCalls(ctx context.Context, cli client.Client, app *arcadiav1alpha1.Application, stack []types.NamespacedName) (int, error) {
+	key := types.NamespacedName{Namespace: app.Namespace, Name: app.Name}
+	if err := findAppCycle(stack, key); err != nil {
+		return 0, err
+	}
+	stack = append(stack, key)
+	if len(stack) > MaxAppDepth {
+		return 0, fmt.Errorf("applications can call each other at most %d levels deep", MaxAppDepth)
+	}
+	depth := 1
+	for _, node := range app.Spec.Nodes {
+		if node.Ref == nil {
+			continue
+		}
+		baseNode := base.NewBaseNode(app.Namespace, node.Name, *node.Ref)
+		if baseNode.Group() != "" || baseNode.Kind() != "application" {
+			continue
+		}
+		child := &arcadiav1alpha1.Application{}
+		if err := cli.Get(ctx, types.NamespacedName{Namespace: baseNode.RefNamespace(), Name: baseNode.RefName()}, child); err != nil {
+			return 0, fmt.Errorf("can't find the application of node %s: %w", node.Name, err)
+		}
+		d, err := CheckAppCalls(ctx, cli, child, stack)
+		if err != nil {
+			var cycleErr *AppCycleError
+			if errors.As(err, &cycleErr) {
+				return 0, err
+			}
+			return 0, fmt.Errorf("application %s: %w", key, err)
+		}
+		if d+1 > depth {
+			depth = d + 1
+		}
+	}
+	return depth, nil
+}
+
+// findAppCycle returns an *AppCycleError if the application is already in the stack
+func findAppCycle(stack []types.NamespacedName, key types.NamespacedName) error {
+	for i, k := range stack {
+		if k != key {
+			continue
+		}
+		path := make([]string, 0, len(stack)-i+1)
+		for _, k := range stack[i:] {
+			path = append(path, k.String())
+		}
+		return &AppCycleError{Path: append(path, key.String())}
+	}
+	return nil
+}
+
+type appStackContextKey struct{}
+
+// withApp returns a context with the application added to the applications of the current run
+func withApp(ctx context.Context, namespace, name string) context.Context {
+	stack := appStackFromContext(ctx)
+	next := make([]types.NamespacedName, 0, len(stack)+1)
+	next = append(next, stack...)
+	return context.WithValue(ctx, appStackContextKey{}, append(next, types.NamespacedName{Namespace: namespace, Name: name}))
+}
+
+// appStackFromContext returns the applications of the current run, the first one is called by the user
+// and the last one is running
+func appStackFromContext(ctx context.Context) []types.NamespacedName {
+	stack, _ := ctx.Value(appStackContextKey{}).([]types.NamespacedName)
+	return stack
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+)
+
+// callingApp returns an application which calls the applications by application nodes
+func callingApp(name string, calls ...string) *arcadiav1alpha1.Application {
+	app := &arcadiav1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+	for i, call := range calls {
+		app.Spec.Nodes = append(app.Spec.Nodes, arcadiav1alpha1.Node{NodeConfig: arcadiav1alpha1.NodeConfig{
+			Name: fmt.Sprintf("app-%d", i),
+			Ref:  &arcadiav1alpha1.TypedObjectReference{APIGroup: pointer.String("arcadia.kubeagi.k8s.com.cn"), Kind: "Application", Name: call},
+		}})
+	}
+	return app
+}
+
+func TestCheckAppCalls(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(arcadiav1alpha1.AddToScheme(scheme))
+	newClient := func(apps ...*arcadiav1alpha1.Application) client.Client {
+		objs := make([]client.Object, 0, len(apps))
+		for _, app := range apps {
+			objs = append(objs, app)
+		}
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	}
+
+	front := callingApp("front", "hr", "it")
+	cli := newClient(front, callingApp("hr", "faq"), callingApp("it", "faq"), callingApp("faq"))
+	depth, err := CheckAppCalls(ctx, cli, front, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, depth)
+
+	front = callingApp("front", "hr")
+	cli = newClient(front, callingApp("hr", "it"), callingApp("it", "hr"))
+	_, err = CheckAppCalls(ctx, cli, front, nil)
+	var cycleErr *AppCycleError
+	require.ErrorAs(t, err, &cycleErr)
+	assert.Equal(t, []string{"default/hr", "default/it", "default/hr"}, cycleErr.Path)
+
+	apps := make([]*arcadiav1alpha1.Application, 0, MaxAppDepth+1)
+	for i := 0; i < MaxAppDepth; i++ {
+		apps = append(apps, callingApp(fmt.Sprintf("app-%d", i), fmt.Sprintf("app-%d", i+1)))
+	}
+	apps = append(apps, callingApp(fmt.Sprintf("app-%d", MaxAppDepth)))
+	_, err = CheckAppCalls(ctx, newClient(apps...), apps[0], nil)
+	assert.ErrorContains(t, err, "levels deep")
+	depth, err = CheckAppCalls(ctx, newClient(apps...), apps[1], nil)
+	require.NoError(t, err)
+	assert.Equal(t, MaxAppDepth, depth)
+}
+
+func TestSubApplicationRunChecksStack(t *testing.T) {
+	child := callingApp("child")
+	s := NewSubApplication(base.NewBaseNode("default", "app-node", *callingApp("parent", "child").Spec.Nodes[0].Ref))
+	s.Instance = child
+	args := map[string]any{base.InputQuestionKeyInArg: "hello"}
+
+	ctx := withApp(withApp(context.Background(), "default", "child"), "default", "parent")
+	_, err := s.Run(ctx, nil, args)
+	var cycleErr *AppCycleError
+	require.ErrorAs(t, err, &cycleErr)
+	assert.Equal(t, []string{"default/child", "default/parent", "default/child"}, cycleErr.Path)
+
+	ctx = context.Background()
+	for i := 0; i < MaxAppDepth; i++ {
+		ctx = withApp(ctx, "default", fmt.Sprintf("app-%d", i))
+	}
+	_, err = s.Run(ctx, nil, args)
+	assert.ErrorContains(t, err, "levels deep")
+}
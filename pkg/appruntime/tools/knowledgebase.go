@@ -0,0 +1,178 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tmc/langchaingo/callbacks"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/tools"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiretriever "github.com/kubeagi/arcadia/api/app-node/retriever/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/appruntime/base"
+	"github.com/kubeagi/arcadia/pkg/appruntime/log"
+	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+)
+
+// KnowledgebaseSearchToolName is the name of the knowledgebase search tool, and the default name of the tool used by the llm
+const KnowledgebaseSearchToolName = "knowledgebase_search"
+
+// KnowledgebaseSearch searches a knowledgebase with the query from the llm, like the knowledgebase retriever does
+// with the question. The found chunks are kept as references, which are added to the references of the agent answer.
+type KnowledgebaseSearch struct {
+	name        string
+	description string
+	search      func(ctx context.Context, query string) ([]langchaingoschema.Document, []retriever.Reference, error)
+
+	mu         sync.Mutex
+	references []retriever.Reference
+
+	CallbacksHandler callbacks.Handler
+}
+
+var _ ReferenceTool = &KnowledgebaseSearch{}
+
+func newKnowledgebaseSearch(ctx context.Context, cli client.Client, namespace string, params Params) ([]tools.Tool, error) {
+	if ns := params.String("namespace"); ns != "" {
+		namespace = ns
+	}
+	kb := &arcadiav1alpha1.KnowledgeBase{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: params.String("knowledgebase")}, kb); err != nil {
+		return nil, fmt.Errorf("can't find the knowledgebase in cluster: %w", err)
+	}
+	scoreThreshold := float32(params.Float("scoreThreshold"))
+	config := apiretriever.CommonRetrieverConfig{
+		ScoreThreshold: &scoreThreshold,
+		NumDocuments:   params.Int("numDocuments"),
+		SearchMode:     apiretriever.SearchMode(params.String("searchMode")),
+	}
+	description := params.String("description")
+	if description == "" {
+		description = strings.TrimSpace(fmt.Sprintf("%s %s", kb.Spec.DisplayName, kb.Spec.Description))
+	}
+	tool := &KnowledgebaseSearch{
+		name:        params.String("name"),
+		description: description,
+		search: func(ctx context.Context, query string) ([]langchaingoschema.Document, []retriever.Reference, error) {
+			args := map[string]any{base.InputQuestionKeyInArg: query}
+			args, finish, err := retriever.GenerateKnowledgebaseRetriever(ctx, cli, kb.Name, kb.Namespace, config, args)
+			if finish != nil {
+				defer finish()
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			refs, _ := args[base.RuntimeRetrieverReferencesKeyInArg].([]retriever.Reference)
+			retrievers, err := base.GetRetrieversFromArg(args)
+			if err != nil {
+				return nil, refs, err
+			}
+			docs, err := retrievers[0].GetRelevantDocuments(ctx, query)
+			return docs, refs, err
+		},
+		CallbacksHandler: log.KLogHandler{LogLevel: 3},
+	}
+	return []tools.Tool{tool}, nil
+}
+
+func (k *KnowledgebaseSearch) Name() string {
+	return k.name
+}
+
+func (k *KnowledgebaseSearch) Description() string {
+	desc := "Search the knowledgebase for the documents related to the input, the input should be a search query."
+	if k.description != "" {
+		desc = fmt.Sprintf("Search the knowledgebase about %s for the documents related to the input, the input should be a search query.", k.description)
+	}
+	return desc + " Cite the numbers of the documents used in the answer, like [1]."
+}
+
+// Parameters returns the json schema of the input for function calling agents
+func (k *KnowledgebaseSearch) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"query": map[string]any{"type": "string", "description": "the search query"},
+		},
+		"required": []string{"query"},
+	}
+}
+
+// References returns the chunks found by all calls, numbered in the same order as in the results of the calls
+func (k *KnowledgebaseSearch) References() []retriever.Reference {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return append([]retriever.Reference(nil), k.references...)
+}
+
+func (k *KnowledgebaseSearch) Call(ctx context.Context, input string) (string, error) {
+	klog.FromContext(ctx).V(3).Info(fmt.Sprintf("running tool %s", k.Name()))
+	if k.CallbacksHandler != nil {
+		k.CallbacksHandler.HandleToolStart(ctx, input)
+	}
+	query := strings.TrimSpace(input)
+	var args struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal([]byte(query), &args); err == nil && args.Query != "" {
+		query = args.Query
+	}
+	docs, refs, err := k.search(ctx, query)
+	if err != nil {
+		if k.CallbacksHandler != nil {
+			k.CallbacksHandler.HandleToolError(ctx, err)
+		}
+		return "", err
+	}
+	result := k.addResults(docs, refs)
+	if k.CallbacksHandler != nil {
+		k.CallbacksHandler.HandleToolEnd(ctx, result)
+	}
+	return result, nil
+}
+
+// addResults keeps the references and formats the documents with the numbers of their references
+func (k *KnowledgebaseSearch) addResults(docs []langchaingoschema.Document, refs []retriever.Reference) string {
+	if len(docs) == 0 {
+		return "No related documents found in the knowledgebase."
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	start := len(k.references) + 1
+	k.references = append(k.references, refs...)
+	var b strings.Builder
+	for i, doc := range docs {
+		source := ""
+		if i < len(refs) && refs[i].FileName != "" {
+			source = " " + refs[i].FileName
+			if refs[i].PageNumber > 0 {
+				source += fmt.Sprintf(" page %d", refs[i].PageNumber)
+			}
+		}
+		b.WriteString(fmt.Sprintf("[%d]%s\n%s\n\n", start+i, source, strings.TrimSpace(doc.PageContent)))
+	}
+	return strings.TrimSpace(b.String())
+}
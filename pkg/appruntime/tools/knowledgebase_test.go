@@ -0,0 +1,62 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+)
+
+func TestKnowledgebaseSearch(t *testing.T) {
+	t.Parallel()
+	var queries []string
+	tool := &KnowledgebaseSearch{
+		name:        KnowledgebaseSearchToolName,
+		description: "HR policies",
+		search: func(_ context.Context, query string) ([]langchaingoschema.Document, []retriever.Reference, error) {
+			queries = append(queries, query)
+			if query == "nothing" {
+				return nil, nil, nil
+			}
+			docs := []langchaingoschema.Document{{PageContent: query + " chunk"}}
+			refs := []retriever.Reference{{FileName: query + ".pdf", PageNumber: 2, Content: query + " chunk"}}
+			return docs, refs, nil
+		},
+	}
+	require.Contains(t, tool.Description(), "HR policies")
+
+	out, err := tool.Call(context.Background(), "leave")
+	require.NoError(t, err)
+	require.Equal(t, "[1] leave.pdf page 2\nleave chunk", out)
+	out, err = tool.Call(context.Background(), `{"query": "overtime"}`)
+	require.NoError(t, err)
+	require.Equal(t, "[2] overtime.pdf page 2\novertime chunk", out)
+	out, err = tool.Call(context.Background(), "nothing")
+	require.NoError(t, err)
+	require.Equal(t, "No related documents found in the knowledgebase.", out)
+
+	require.Equal(t, []string{"leave", "overtime", "nothing"}, queries)
+	refs := tool.References()
+	require.Len(t, refs, 2)
+	require.Equal(t, "leave.pdf", refs[0].FileName)
+	require.Equal(t, "overtime.pdf", refs[1].FileName)
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/tools"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+)
+
+// ParamType is the type of a tool param, params are always strings in the Agent CR
+// and are converted to the type declared in the ParamSchema
+type ParamType string
+
+const (
+	ParamTypeString ParamType = "string"
+	ParamTypeInt    ParamType = "int"
+	ParamTypeFloat  ParamType = "float"
+	ParamTypeBool   ParamType = "bool"
+	// ParamTypeStringList is a comma separated list of strings
+	ParamTypeStringList ParamType = "stringList"
+)
+
+// ParamSchema describes a param accepted by a tool
+type ParamSchema struct {
+	Name        string
+	Type        ParamType
+	Description string
+	Required    bool
+	// Default is used when the param is not set, it is in the same format as the value in the Agent CR
+	Default string
+}
+
+// Params are the converted params of a tool, the value type is decided by the ParamType
+type Params map[string]any
+
+func (p Params) String(name string) string {
+	v, _ := p[name].(string)
+	return v
+}
+
+func (p Params) Int(name string) int {
+	v, _ := p[name].(int)
+	return v
+}
+
+func (p Params) Float(name string) float64 {
+	v, _ := p[name].(float64)
+	return v
+}
+
+func (p Params) Bool(name string) bool {
+	v, _ := p[name].(bool)
+	return v
+}
+
+func (p Params) StringList(name string) []string {
+	v, _ := p[name].([]string)
+	return v
+}
+
+// Has returns whether the param is set in the Agent CR or has a default value
+func (p Params) Has(name string) bool {
+	_, ok := p[name]
+	return ok
+}
+
+// Factory creates the tools with the converted params.
+// namespace is the namespace of the agent, and cli can be used to get the resources the tool depends on.
+// One tool spec can create more than one tool, for example, an OpenAPI document with several operations.
+type Factory func(ctx context.Context, cli client.Client, namespace string, params Params) ([]tools.Tool, error)
+
+// Definition is a kind of tool which can be used by agents
+type Definition struct {
+	// Name is the name used in the allowedTools of the Agent CR
+	Name        string
+	Description string
+	Params      []ParamSchema
+	New         Factory
+}
+
+// ParseParams validates the raw params from the Agent CR and converts them to the declared types
+func (d Definition) ParseParams(raw map[string]string) (Params, error) {
+	params := make(Params, len(d.Params))
+	for _, schema := range d.Params {
+		v, ok := raw[schema.Name]
+		if !ok || v == "" {
+			if schema.Required {
+				return nil, fmt.Errorf("tool %s: param %s is required", d.Name, schema.Name)
+			}
+			if schema.Default == "" {
+				continue
+			}
+			v = schema.Default
+		}
+		converted, err := convertParam(schema.Type, v)
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: param %s should be %s: %w", d.Name, schema.Name, schema.Type, err)
+		}
+		params[schema.Name] = converted
+	}
+	return params, nil
+}
+
+func convertParam(t ParamType, v string) (any, error) {
+	switch t {
+	case ParamTypeString, "":
+		return v, nil
+	case ParamTypeInt:
+		return strconv.Atoi(v)
+	case ParamTypeFloat:
+		return strconv.ParseFloat(v, 64)
+	case ParamTypeBool:
+		return strconv.ParseBool(v)
+	case ParamTypeStringList:
+		list := make([]string, 0)
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("unknown param type %s", t)
+	}
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = make(map[string]Definition)
+)
+
+// Register makes a tool available to agents by its name.
+// It panics if the definition is invalid or a tool with the same name is already registered.
+func Register(d Definition) {
+	if d.Name == "" || d.New == nil {
+		panic("tools: Register a tool without name or factory")
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if _, exist := registry[d.Name]; exist {
+		panic("tools: Register called twice for tool " + d.Name)
+	}
+	registry[d.Name] = d
+}
+
+// Lookup returns the registered tool definition with the name
+func Lookup(name string) (Definition, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	d, ok := registry[name]
+	return d, ok
+}
+
+// Definitions returns all registered tool definitions, sorted by name
+func Definitions() []Definition {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	res := make([]Definition, 0, len(registry))
+	for _, d := range registry {
+		res = append(res, d)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// ParametersTool is implemented by the tools which describe their input with a json schema.
+// Function calling agents send the schema to the llm and pass the json arguments from the llm to the tool as the input,
+// for other tools, the llm is asked for a single string input.
+type ParametersTool interface {
+	tools.Tool
+	Parameters() map[string]any
+}
+
+// ActionTool is implemented by the tools which report the actions they take when called, like the sql executed by
+// the sql_query tool. The actions are sent to the handler by HandleText, agents stream them if tool actions are shown.
+type ActionTool interface {
+	tools.Tool
+	SetActionHandler(handler callbacks.Handler)
+}
+
+// ReferenceTool is implemented by the tools which find the references of the answer, like the knowledgebase_search tool.
+// Agents add the references found by all calls to the references of the answer.
+type ReferenceTool interface {
+	tools.Tool
+	References() []retriever.Reference
+}
@@ -18,96 +18,230 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strconv"
-	"strings"
+	"time"
 
 	"github.com/tmc/langchaingo/tools"
 	"github.com/tmc/langchaingo/tools/scraper"
-	"k8s.io/klog/v2"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kubeagi/arcadia/api/app-node/agent/v1alpha1"
+	apiretriever "github.com/kubeagi/arcadia/api/app-node/retriever/v1alpha1"
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
 	"github.com/kubeagi/arcadia/pkg/appruntime/log"
+	"github.com/kubeagi/arcadia/pkg/datasource"
 	"github.com/kubeagi/arcadia/pkg/tools/bingsearch"
+	"github.com/kubeagi/arcadia/pkg/tools/openapi"
+	"github.com/kubeagi/arcadia/pkg/tools/sqlquery"
 	"github.com/kubeagi/arcadia/pkg/tools/weather"
 )
 
-func InitTools(ctx context.Context, specTools []v1alpha1.Tool) []tools.Tool {
-	logger := klog.FromContext(ctx)
-	allowedTools := make([]tools.Tool, 0, len(specTools))
-	for _, toolSpec := range specTools {
-		switch toolSpec.Name {
-		case bingsearch.ToolName:
-			client, err := bingsearch.New(&toolSpec)
-			if err != nil {
-				logger.Error(err, "failed to create a new bingsearch tool")
-				continue
-			}
-			client.CallbacksHandler = log.KLogHandler{LogLevel: 3}
-			allowedTools = append(allowedTools, client)
-		case weather.ToolName:
-			tool, err := weather.New(&toolSpec)
-			if err != nil {
-				logger.Error(err, "failed to create a new weather tool")
-				continue
-			}
+func init() {
+	Register(Definition{
+		Name:        bingsearch.ToolName,
+		Description: "Search on the internet with bing",
+		Params: []ParamSchema{
+			{Name: bingsearch.ParamAPIKey, Type: ParamTypeString, Description: "api key of bing search", Required: true},
+			{Name: bingsearch.ParamCount, Type: ParamTypeInt, Description: "total number of results"},
+			{Name: bingsearch.ParamScraperPage, Type: ParamTypeBool, Description: "use web scraper to get page content", Default: "true"},
+		},
+		New: func(ctx context.Context, cli client.Client, namespace string, params Params) ([]tools.Tool, error) {
+			tool := bingsearch.NewWithOptions(
+				bingsearch.WithAPIKey(params.String(bingsearch.ParamAPIKey)),
+				bingsearch.WithCount(params.Int(bingsearch.ParamCount)),
+				bingsearch.WithScraperPage(params.Bool(bingsearch.ParamScraperPage)),
+			)
+			tool.CallbacksHandler = log.KLogHandler{LogLevel: 3}
+			return []tools.Tool{tool}, nil
+		},
+	})
+	Register(Definition{
+		Name:        weather.ToolName,
+		Description: "Get the realtime weather data",
+		Params: []ParamSchema{
+			{Name: "apiKey", Type: ParamTypeString, Description: "api key of seniverse weather api", Required: true},
+		},
+		New: func(ctx context.Context, cli client.Client, namespace string, params Params) ([]tools.Tool, error) {
+			tool := weather.NewWithAPIKey(params.String("apiKey"))
 			tool.CallbacksHandler = log.KLogHandler{LogLevel: 3}
-			allowedTools = append(allowedTools, tool)
-		case tools.Calculator{}.Name():
+			return []tools.Tool{tool}, nil
+		},
+	})
+	Register(Definition{
+		Name:        tools.Calculator{}.Name(),
+		Description: "Calculate the math expression",
+		New: func(ctx context.Context, cli client.Client, namespace string, params Params) ([]tools.Tool, error) {
 			tool := tools.Calculator{}
 			tool.CallbacksHandler = log.KLogHandler{LogLevel: 3}
-			allowedTools = append(allowedTools, tool)
-		case scraper.Scraper{}.Name():
-			// prepare options from toolSpec
-			options := make([]scraper.Options, 0)
-			if toolSpec.Params["delay"] != "" {
-				delay, err := strconv.ParseInt(toolSpec.Params["delay"], 10, 64)
-				if err != nil {
-					logger.Error(err, fmt.Sprintf("failed to parse delay %s", toolSpec.Params["delay"]))
-				} else {
-					options = append(options, scraper.WithDelay(delay))
-				}
-			}
-			if toolSpec.Params["async"] != "" {
-				async, err := strconv.ParseBool(toolSpec.Params["async"])
-				if err != nil {
-					logger.Error(err, fmt.Sprintf("failed to parse async %s", toolSpec.Params["async"]))
-				} else {
-					options = append(options, scraper.WithAsync(async))
-				}
-			}
-			if toolSpec.Params["handleLinks"] != "" {
-				handleLinks, err := strconv.ParseBool(toolSpec.Params["handleLinks"])
-				if err != nil {
-					logger.Error(err, fmt.Sprintf("failed to parse handleLinks %s", toolSpec.Params["handleLinks"]))
-				} else {
-					options = append(options, scraper.WithHandleLinks(handleLinks))
-				}
-			}
-			if toolSpec.Params["blacklist"] != "" {
-				blacklistArray := strings.Split(toolSpec.Params["blacklist"], ",")
-				options = append(options, scraper.WithBlacklist(blacklistArray))
-			}
-			if toolSpec.Params["maxScrapedDataLength"] != "" {
-				maxScrapedDataLength, err := strconv.Atoi(toolSpec.Params["maxScrapedDataLength"])
-				if err != nil {
-					klog.Errorln("failed to parse maxScrapedDataLength %s", toolSpec.Params["maxScrapedDataLength"])
-				} else {
-					options = append(options, scraper.WithMaxScrapedDataLength(maxScrapedDataLength))
-				}
-			}
-			tool, err := scraper.New(options...)
-			if err != nil {
-				logger.Error(err, "failed to create a new scraper tool")
-				continue
-			}
-			allowedTools = append(allowedTools, tool)
-		default:
-			// Just continue if the tool does not exist
-			klog.Errorf("no tool found with name: %s", toolSpec.Name)
+			return []tools.Tool{tool}, nil
+		},
+	})
+	Register(Definition{
+		Name:        scraper.Scraper{}.Name(),
+		Description: "Scrape the content of web pages",
+		Params: []ParamSchema{
+			{Name: "delay", Type: ParamTypeInt, Description: "delay time in seconds before creating a new request"},
+			{Name: "async", Type: ParamTypeBool, Description: "whether to scrape pages asynchronously"},
+			{Name: "handleLinks", Type: ParamTypeBool, Description: "whether to follow the links in the page"},
+			{Name: "blacklist", Type: ParamTypeStringList, Description: "comma separated url patterns that will not be scraped"},
+			{Name: "maxScrapedDataLength", Type: ParamTypeInt, Description: "max length of the scraped data"},
+		},
+		New: newScraper,
+	})
+	Register(Definition{
+		Name:        openapi.ToolName,
+		Description: "Call the REST endpoints described by the OpenAPI document in an APITool",
+		Params: []ParamSchema{
+			{Name: "apiTool", Type: ParamTypeString, Description: "name of the APITool", Required: true},
+			{Name: "namespace", Type: ParamTypeString, Description: "namespace of the APITool, default to the namespace of the agent"},
+		},
+		New: newOpenAPITools,
+	})
+	Register(Definition{
+		Name:        sqlquery.ToolName,
+		Description: "Answer questions about the data in PostgreSQL by read-only SQL queries on a PostgreSQL Datasource",
+		Params: []ParamSchema{
+			{Name: "datasource", Type: ParamTypeString, Description: "name of the PostgreSQL Datasource", Required: true},
+			{Name: "namespace", Type: ParamTypeString, Description: "namespace of the Datasource, default to the namespace of the agent"},
+			{Name: "schemas", Type: ParamTypeStringList, Description: "comma separated schemas whose tables can be queried"},
+			{Name: "tables", Type: ParamTypeStringList, Description: "comma separated tables in the form of schema.table which can be queried"},
+			{Name: "maxRows", Type: ParamTypeInt, Description: "max number of rows returned", Default: "100"},
+			{Name: "timeout", Type: ParamTypeInt, Description: "timeout of a query in seconds", Default: "10"},
+		},
+		New: newSQLQuery,
+	})
+	Register(Definition{
+		Name:        KnowledgebaseSearchToolName,
+		Description: "Search the documents in a KnowledgeBase, the found chunks are added to the references of the answer",
+		Params: []ParamSchema{
+			{Name: "knowledgebase", Type: ParamTypeString, Description: "name of the KnowledgeBase", Required: true},
+			{Name: "namespace", Type: ParamTypeString, Description: "namespace of the KnowledgeBase, default to the namespace of the agent"},
+			{Name: "name", Type: ParamTypeString, Description: "name of the tool used by the llm, should be unique in the agent", Default: KnowledgebaseSearchToolName},
+			{Name: "description", Type: ParamTypeString, Description: "what the knowledgebase is about, so the llm knows when to search it, default to the description of the KnowledgeBase"},
+			{Name: "scoreThreshold", Type: ParamTypeFloat, Description: "the min similarity of the found chunks", Default: "0.3"},
+			{Name: "numDocuments", Type: ParamTypeInt, Description: "the max number of the found chunks", Default: "5"},
+			{Name: "searchMode", Type: ParamTypeString, Description: "vector, keyword or hybrid", Default: string(apiretriever.SearchModeVector)},
+		},
+		New: newKnowledgebaseSearch,
+	})
+}
+
+// InitTools creates the tools allowed by the agent with the registered tool definitions
+func InitTools(ctx context.Context, cli client.Client, namespace string, specTools []v1alpha1.Tool) ([]tools.Tool, error) {
+	allowedTools := make([]tools.Tool, 0, len(specTools))
+	for _, toolSpec := range specTools {
+		d, ok := Lookup(toolSpec.Name)
+		if !ok {
+			return nil, fmt.Errorf("no tool found with name: %s", toolSpec.Name)
+		}
+		params, err := d.ParseParams(toolSpec.Params)
+		if err != nil {
+			return nil, err
+		}
+		ts, err := d.New(ctx, cli, namespace, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create tool %s: %w", toolSpec.Name, err)
+		}
+		allowedTools = append(allowedTools, ts...)
+	}
+	return allowedTools, nil
+}
+
+func newScraper(ctx context.Context, cli client.Client, namespace string, params Params) ([]tools.Tool, error) {
+	options := make([]scraper.Options, 0)
+	if params.Has("delay") {
+		options = append(options, scraper.WithDelay(int64(params.Int("delay"))))
+	}
+	if params.Has("async") {
+		options = append(options, scraper.WithAsync(params.Bool("async")))
+	}
+	if params.Has("handleLinks") {
+		options = append(options, scraper.WithHandleLinks(params.Bool("handleLinks")))
+	}
+	if params.Has("blacklist") {
+		options = append(options, scraper.WithBlacklist(params.StringList("blacklist")))
+	}
+	if params.Has("maxScrapedDataLength") {
+		options = append(options, scraper.WithMaxScrapedDataLength(params.Int("maxScrapedDataLength")))
+	}
+	tool, err := scraper.New(options...)
+	if err != nil {
+		return nil, err
+	}
+	return []tools.Tool{tool}, nil
+}
+
+func newOpenAPITools(ctx context.Context, cli client.Client, namespace string, params Params) ([]tools.Tool, error) {
+	if ns := params.String("namespace"); ns != "" {
+		namespace = ns
+	}
+	instance := &v1alpha1.APITool{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: params.String("apiTool")}, instance); err != nil {
+		return nil, fmt.Errorf("can't find the apitool in cluster: %w", err)
+	}
+	doc, err := openapi.ParseDocument([]byte(instance.Spec.Schema))
+	if err != nil {
+		return nil, err
+	}
+	options := []openapi.Option{openapi.WithTimeout(time.Duration(instance.Spec.Timeout) * time.Second)}
+	if instance.Spec.AuthSecret != nil {
+		endpoint := arcadiav1alpha1.Endpoint{AuthSecret: instance.Spec.AuthSecret}
+		apiKey, err := endpoint.AuthAPIKey(ctx, namespace, cli)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the auth secret of apitool: %w", err)
+		}
+		header := instance.Spec.AuthHeader
+		if header == "" {
+			header = "Authorization"
 		}
+		if header == "Authorization" {
+			apiKey = "Bearer " + apiKey
+		}
+		options = append(options, openapi.WithHeader(header, apiKey))
+	}
+	ts, err := openapi.New(doc, instance.Spec.Server, instance.Spec.Operations, options...)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]tools.Tool, 0, len(ts))
+	for _, t := range ts {
+		t.CallbacksHandler = log.KLogHandler{LogLevel: 3}
+		res = append(res, t)
+	}
+	return res, nil
+}
+
+func newSQLQuery(ctx context.Context, cli client.Client, namespace string, params Params) ([]tools.Tool, error) {
+	if ns := params.String("namespace"); ns != "" {
+		namespace = ns
+	}
+	schemas, tables := params.StringList("schemas"), params.StringList("tables")
+	if len(schemas) == 0 && len(tables) == 0 {
+		return nil, errors.New("schemas or tables should be set to allow the tables to query")
+	}
+	instance := &arcadiav1alpha1.Datasource{}
+	if err := cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: params.String("datasource")}, instance); err != nil {
+		return nil, fmt.Errorf("can't find the datasource in cluster: %w", err)
+	}
+	pg, err := datasource.GetPostgreSQLPool(ctx, cli, instance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the datasource: %w", err)
+	}
+	described, err := pg.DescribeTables(ctx, schemas, tables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe the tables: %w", err)
+	}
+	tool, err := sqlquery.New(pg, described,
+		sqlquery.WithMaxRows(params.Int("maxRows")),
+		sqlquery.WithTimeout(time.Duration(params.Int("timeout"))*time.Second),
+	)
+	if err != nil {
+		return nil, err
 	}
-	return allowedTools
+	tool.CallbacksHandler = log.KLogHandler{LogLevel: 3}
+	return []tools.Tool{tool}, nil
 }
 
 // FIXME: should add web reference into chat result
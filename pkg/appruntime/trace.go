@@ -0,0 +1,66 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"fmt"
+	"strings"
+
+	langchaingoschema "github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/pkg/appruntime/retriever"
+)
+
+// briefArg returns a readable brief of a value in args for the node trace.
+// Values which can't be shown, like llm clients or stream channels, are recorded only by their type.
+func briefArg(v any) (string, bool) {
+	switch value := v.(type) {
+	case nil:
+		return "", false
+	case chan string:
+		return "", false
+	case string:
+		return value, true
+	case bool, int, int32, int64, float32, float64:
+		return fmt.Sprint(value), true
+	case []string:
+		return strings.Join(value, "\n"), true
+	case []retriever.Reference:
+		buf := strings.Builder{}
+		for i, ref := range value {
+			content := ref.Question
+			if ref.Answer != "" {
+				content += " " + ref.Answer
+			}
+			if ref.Content != "" {
+				content = ref.Content
+			}
+			buf.WriteString(fmt.Sprintf("[%d] score: %.4f file: %s %s\n", i, ref.Score, ref.FileName, content))
+		}
+		return buf.String(), true
+	case []langchaingoschema.Document:
+		buf := strings.Builder{}
+		for i, doc := range value {
+			buf.WriteString(fmt.Sprintf("[%d] score: %.4f %s\n", i, doc.Score, doc.PageContent))
+		}
+		return buf.String(), true
+	case []langchaingoschema.Retriever:
+		return fmt.Sprintf("%d retrievers", len(value)), true
+	default:
+		return fmt.Sprintf("%T", v), true
+	}
+}
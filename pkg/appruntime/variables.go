@@ -0,0 +1,136 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+// reservedVariableNames are the variables of the prompts set by the application
+var reservedVariableNames = map[string]bool{"question": true, "context": true, "date": true, "history": true}
+
+// InputVariableError is returned when a value of the input variables is not valid
+type InputVariableError struct {
+	Name string
+	Msg  string
+}
+
+func (e *InputVariableError) Error() string {
+	return fmt.Sprintf("input variable %s: %s", e.Name, e.Msg)
+}
+
+// CheckInputVariables checks the input variables declared by the application
+func CheckInputVariables(variables []arcadiav1alpha1.InputVariable) error {
+	names := make(map[string]bool, len(variables))
+	for _, v := range variables {
+		if reservedVariableNames[v.Name] {
+			return fmt.Errorf("input variable %s: the name is used by the application", v.Name)
+		}
+		if names[v.Name] {
+			return fmt.Errorf("input variable %s: the name should be unique", v.Name)
+		}
+		names[v.Name] = true
+		if v.Type == arcadiav1alpha1.InputVariableTypeEnum && len(v.Options) == 0 {
+			return fmt.Errorf("input variable %s: enum needs options", v.Name)
+		}
+		if v.Default != "" {
+			if _, err := resolveInputVariable(v, v.Default); err != nil {
+				return fmt.Errorf("input variable %s: invalid default value: %w", v.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveInputVariables validates the values of the input variables and returns them as strings for the prompts,
+// the default values are used for the variables without values, and the values of unknown variables are dropped.
+// An *InputVariableError is returned if a value is not valid or a required value is missing.
+func ResolveInputVariables(variables []arcadiav1alpha1.InputVariable, values map[string]any) (map[string]string, error) {
+	resolved := make(map[string]string, len(variables))
+	for _, v := range variables {
+		value, ok := values[v.Name]
+		if !ok || value == nil || value == "" {
+			if v.Default == "" {
+				if v.Required {
+					return nil, &InputVariableError{Name: v.Name, Msg: "the value is required"}
+				}
+				// the prompts can still use the variable
+				resolved[v.Name] = ""
+				continue
+			}
+			value = v.Default
+		}
+		s, err := resolveInputVariable(v, value)
+		if err != nil {
+			return nil, &InputVariableError{Name: v.Name, Msg: err.Error()}
+		}
+		resolved[v.Name] = s
+	}
+	return resolved, nil
+}
+
+func resolveInputVariable(v arcadiav1alpha1.InputVariable, value any) (string, error) {
+	switch v.Type {
+	case arcadiav1alpha1.InputVariableTypeNumber:
+		var f float64
+		switch n := value.(type) {
+		case float64:
+			f = n
+		case int:
+			f = float64(n)
+		case json.Number:
+			var err error
+			if f, err = n.Float64(); err != nil {
+				return "", fmt.Errorf("%q is not a number", n)
+			}
+		case string:
+			var err error
+			if f, err = strconv.ParseFloat(strings.TrimSpace(n), 64); err != nil {
+				return "", fmt.Errorf("%q is not a number", n)
+			}
+		default:
+			return "", fmt.Errorf("%v is not a number", value)
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case arcadiav1alpha1.InputVariableTypeEnum:
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("%v is not a string", value)
+		}
+		for _, option := range v.Options {
+			if s == option {
+				return s, nil
+			}
+		}
+		return "", fmt.Errorf("%q is not one of %s", s, strings.Join(v.Options, ", "))
+	default:
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("%v is not a string", value)
+		}
+		if v.MaxLength > 0 && utf8.RuneCountInString(s) > v.MaxLength {
+			return "", fmt.Errorf("the value is longer than %d characters", v.MaxLength)
+		}
+		return s, nil
+	}
+}
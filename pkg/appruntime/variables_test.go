@@ -0,0 +1,68 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appruntime
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+func TestResolveInputVariables(t *testing.T) {
+	variables := []arcadiav1alpha1.InputVariable{
+		{Name: "language", Type: arcadiav1alpha1.InputVariableTypeEnum, Options: []string{"English", "French"}, Default: "English"},
+		{Name: "words", Type: arcadiav1alpha1.InputVariableTypeNumber, Required: true},
+		{Name: "style", MaxLength: 5},
+	}
+
+	resolved, err := ResolveInputVariables(variables, map[string]any{"words": float64(100), "unknown": "dropped"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"language": "English", "words": "100", "style": ""}, resolved)
+
+	resolved, err = ResolveInputVariables(variables, map[string]any{"language": "French", "words": json.Number("1.5"), "style": "short"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"language": "French", "words": "1.5", "style": "short"}, resolved)
+
+	resolved, err = ResolveInputVariables(variables, map[string]any{"words": "20"})
+	require.NoError(t, err)
+	assert.Equal(t, "20", resolved["words"])
+
+	for name, values := range map[string]map[string]any{
+		"words":    {},
+		"language": {"words": 1, "language": "German"},
+		"style":    {"words": 1, "style": "too long"},
+	} {
+		_, err = ResolveInputVariables(variables, values)
+		var inputErr *InputVariableError
+		require.ErrorAs(t, err, &inputErr)
+		assert.Equal(t, name, inputErr.Name)
+	}
+	_, err = ResolveInputVariables(variables, map[string]any{"words": "many"})
+	assert.ErrorContains(t, err, "is not a number")
+}
+
+func TestCheckInputVariables(t *testing.T) {
+	assert.NoError(t, CheckInputVariables([]arcadiav1alpha1.InputVariable{{Name: "language"}, {Name: "words", Type: arcadiav1alpha1.InputVariableTypeNumber, Default: "10"}}))
+	assert.ErrorContains(t, CheckInputVariables([]arcadiav1alpha1.InputVariable{{Name: "question"}}), "used by the application")
+	assert.ErrorContains(t, CheckInputVariables([]arcadiav1alpha1.InputVariable{{Name: "a"}, {Name: "a"}}), "unique")
+	assert.ErrorContains(t, CheckInputVariables([]arcadiav1alpha1.InputVariable{{Name: "a", Type: arcadiav1alpha1.InputVariableTypeEnum}}), "needs options")
+	assert.ErrorContains(t, CheckInputVariables([]arcadiav1alpha1.InputVariable{{Name: "a", Type: arcadiav1alpha1.InputVariableTypeEnum, Options: []string{"x"}, Default: "y"}}), "invalid default value")
+}
@@ -17,11 +17,20 @@ package datasource
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -29,6 +38,8 @@ import (
 )
 
 var (
+	ErrPGNoTableSource = errors.New("no table or query provided")
+
 	_          Datasource = (*PostgreSQL)(nil)
 	pgEnvMutex sync.Mutex
 	poolsMutex sync.Mutex
@@ -204,27 +215,525 @@ func (p *PostgreSQL) Stat(ctx context.Context, _ any) error {
 	return p.Ping(ctx)
 }
 
+// Remove does nothing, the rows are never removed from the database by arcadia
 func (p *PostgreSQL) Remove(ctx context.Context, info any) error {
-	// TODO implement me
-	panic("implement me")
+	return nil
 }
 
+// ReadFile reads the rows of the table source in info, which should be of type *v1alpha1.TableSource, as a csv file.
+// The rows are written to the file while they are read from the database, so the table never needs to fit in memory.
 func (p *PostgreSQL) ReadFile(ctx context.Context, info any) (io.ReadCloser, error) {
-	// TODO implement me
-	panic("implement me")
+	source, err := p.preCheck(info)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := p.ReadRows(ctx, source, "")
+	if err != nil {
+		return nil, err
+	}
+	r, w := io.Pipe()
+	go func() {
+		defer rows.Close()
+		writer := csv.NewWriter(w)
+		err := writer.Write(rows.Columns)
+		for err == nil {
+			var row []string
+			if row, err = rows.Next(); err != nil {
+				break
+			}
+			err = writer.Write(row)
+		}
+		if errors.Is(err, io.EOF) {
+			writer.Flush()
+			err = writer.Error()
+		}
+		w.CloseWithError(err)
+	}()
+	return r, nil
 }
 
+// StatFile returns the *TableStat of the table source in info, which should be of type *v1alpha1.TableSource
 func (p *PostgreSQL) StatFile(ctx context.Context, info any) (any, error) {
-	// TODO implement me
-	panic("implement me")
+	source, err := p.preCheck(info)
+	if err != nil {
+		return nil, err
+	}
+	from, err := tableSourceFrom(source)
+	if err != nil {
+		return nil, err
+	}
+	stat := &TableStat{}
+	query := fmt.Sprintf("SELECT count(*) FROM %s", from)
+	if source.WatermarkColumn != "" {
+		query = fmt.Sprintf("SELECT count(*), coalesce(max(t.%s)::text, '') FROM %s", pgx.Identifier{source.WatermarkColumn}.Sanitize(), from)
+		err = p.QueryRow(ctx, query).Scan(&stat.Count, &stat.Watermark)
+	} else {
+		err = p.QueryRow(ctx, query).Scan(&stat.Count)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stat, nil
 }
 
+// GetTags returns the object type and count tags of the table source in info, like the tags of a file
 func (p *PostgreSQL) GetTags(ctx context.Context, info any) (map[string]string, error) {
-	// TODO implement me
-	panic("implement me")
+	source, err := p.preCheck(info)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := p.StatFile(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{
+		v1alpha1.ObjectCountTag: strconv.FormatInt(stat.(*TableStat).Count, 10),
+	}
+	if source.IsQA() {
+		tags[v1alpha1.ObjectTypeTag] = v1alpha1.ObjectTypeQA
+	}
+	return tags, nil
 }
 
+// ListObjects lists the tables in the schema source, or in all schemas except the system ones if source is empty.
+// The tables are returned as []string in the form of `schema.table`.
 func (p *PostgreSQL) ListObjects(ctx context.Context, source string, info any) (any, error) {
-	// TODO implement me
-	panic("implement me")
+	query := "SELECT table_schema, table_name FROM information_schema.tables WHERE table_schema NOT IN ('pg_catalog', 'information_schema') ORDER BY table_schema, table_name"
+	args := make([]any, 0, 1)
+	if source != "" {
+		query = "SELECT table_schema, table_name FROM information_schema.tables WHERE table_schema = $1 ORDER BY table_name"
+		args = append(args, source)
+	}
+	rows, err := p.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tables := make([]string, 0)
+	for rows.Next() {
+		var schema, table string
+		if err := rows.Scan(&schema, &table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, schema+"."+table)
+	}
+	return tables, rows.Err()
+}
+
+func (p *PostgreSQL) preCheck(info any) (*v1alpha1.TableSource, error) {
+	source, ok := info.(*v1alpha1.TableSource)
+	if !ok || source == nil {
+		return nil, ErrPGNoTableSource
+	}
+	return source, nil
+}
+
+// TableStat is the stat of a table source
+type TableStat struct {
+	// Count is the number of rows
+	Count int64
+	// Watermark is the largest value of the watermark column in text
+	Watermark string
+}
+
+// TableRows are the rows loaded by a read-only query, the values are converted to strings
+type TableRows struct {
+	Columns []string
+	Rows    [][]string
+	// Truncated is true if there are more rows than the loaded ones
+	Truncated bool
+}
+
+// watermarkColumn is the extra column added to the query to read the watermark in its text form
+const watermarkColumn = "__arcadia_watermark"
+
+// tableSourceFrom returns the from clause of the table source, the rows are always aliased as t
+func tableSourceFrom(source *v1alpha1.TableSource) (string, error) {
+	switch {
+	case source.Query != "":
+		return fmt.Sprintf("(%s) AS t", strings.TrimSuffix(strings.TrimSpace(source.Query), ";")), nil
+	case source.Table != "":
+		return fmt.Sprintf("%s AS t", pgx.Identifier(strings.Split(source.Table, ".")).Sanitize()), nil
+	default:
+		return "", ErrPGNoTableSource
+	}
+}
+
+// TableRowsReader reads the rows of a table source one by one, the values are converted to strings.
+// It holds a connection of the pool until it is closed.
+type TableRowsReader struct {
+	rows    pgx.Rows
+	Columns []string
+	// Watermark is the largest value of the watermark column in the read rows,
+	// it is the same as the given one if no rows are read.
+	Watermark      string
+	watermarkIndex int
+}
+
+// Next returns the next row, or io.EOF if all rows are read
+func (r *TableRowsReader) Next() ([]string, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	values, err := r.rows.Values()
+	if err != nil {
+		return nil, err
+	}
+	row := make([]string, 0, len(r.Columns))
+	for i, v := range values {
+		if i == r.watermarkIndex {
+			if s, ok := v.(string); ok {
+				r.Watermark = s
+			}
+			continue
+		}
+		row = append(row, pgValueString(v))
+	}
+	return row, nil
+}
+
+func (r *TableRowsReader) Close() {
+	r.rows.Close()
+}
+
+// ReadRows starts to read the rows of the table source. If the source has a watermark column and after is not empty,
+// only the rows with a larger watermark are read, ordered by the watermark.
+// The rows are streamed from the database, the reader must be closed after use.
+func (p *PostgreSQL) ReadRows(ctx context.Context, source *v1alpha1.TableSource, after string) (*TableRowsReader, error) {
+	from, err := tableSourceFrom(source)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT t.* FROM %s", from)
+	args := make([]any, 0, 1)
+	if source.WatermarkColumn != "" {
+		watermark := "t." + pgx.Identifier{source.WatermarkColumn}.Sanitize()
+		query = fmt.Sprintf("SELECT t.*, %s::text AS %s FROM %s", watermark, watermarkColumn, from)
+		if after != "" {
+			// the simple protocol sends the watermark as an untyped literal, so it is compared as the type of the column
+			query += fmt.Sprintf(" WHERE %s > $1", watermark)
+			args = append(args, pgx.QueryExecModeSimpleProtocol, after)
+		}
+		query += fmt.Sprintf(" ORDER BY %s", watermark)
+	}
+	rows, err := p.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	res := &TableRowsReader{rows: rows, Watermark: after, watermarkIndex: -1}
+	for i, field := range rows.FieldDescriptions() {
+		if field.Name == watermarkColumn {
+			res.watermarkIndex = i
+			continue
+		}
+		res.Columns = append(res.Columns, field.Name)
+	}
+	return res, nil
+}
+
+func pgValueString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case [16]byte:
+		// uuid
+		return fmt.Sprintf("%x-%x-%x-%x-%x", v[0:4], v[4:6], v[6:8], v[8:10], v[10:16])
+	case fmt.Stringer:
+		return v.String()
+	default:
+		if b, err := json.Marshal(v); err == nil {
+			return strings.Trim(string(b), `"`)
+		}
+		return fmt.Sprint(v)
+	}
+}
+
+// TableSchema is a table with its columns
+type TableSchema struct {
+	Schema  string
+	Name    string
+	Columns []ColumnSchema
+}
+
+// FullName returns the table name in the form of `schema.table`
+func (t TableSchema) FullName() string {
+	return t.Schema + "." + t.Name
+}
+
+type ColumnSchema struct {
+	Name string
+	Type string
+}
+
+// DescribeTables returns the columns of the tables and views in the schemas and the tables in the form of `schema.table`.
+// The tables are sorted by schema and name, and the columns are in their order in the table.
+func (p *PostgreSQL) DescribeTables(ctx context.Context, schemas, tables []string) ([]TableSchema, error) {
+	rows, err := p.Query(ctx, `SELECT table_schema, table_name, column_name, data_type FROM information_schema.columns
+WHERE table_schema = ANY($1) OR table_schema || '.' || table_name = ANY($2)
+ORDER BY table_schema, table_name, ordinal_position`, schemas, tables)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := make([]TableSchema, 0)
+	for rows.Next() {
+		var schema, table string
+		var column ColumnSchema
+		if err := rows.Scan(&schema, &table, &column.Name, &column.Type); err != nil {
+			return nil, err
+		}
+		if n := len(res); n == 0 || res[n-1].Schema != schema || res[n-1].Name != table {
+			res = append(res, TableSchema{Schema: schema, Name: table})
+		}
+		res[len(res)-1].Columns = append(res[len(res)-1].Columns, column)
+	}
+	return res, rows.Err()
+}
+
+// ReadOnlyQueryOptions are the limits of a query run by QueryReadOnly
+type ReadOnlyQueryOptions struct {
+	// Timeout is the statement timeout of the query, no timeout if it is 0
+	Timeout time.Duration
+	// MaxRows is the max number of rows returned, all rows are returned if it is 0
+	MaxRows int
+	// SearchPath is the schemas to find the tables without schema in the query
+	SearchPath []string
+	// AllowedTables are the tables in the form of `schema.table` which the query can read,
+	// any table is allowed if it is nil. Views are checked by the tables they read.
+	// If it is not nil, the query can't read from functions and can only call the functions in pgAllowedFunctions,
+	// as functions like query_to_xml, pg_read_file or dblink read the data out of the tables.
+	AllowedTables map[string]bool
+}
+
+var (
+	// ErrPGTableNotAllowed is returned by QueryReadOnly when the query reads a table which is not allowed
+	ErrPGTableNotAllowed = errors.New("the query reads a table which is not allowed")
+	// ErrPGFunctionNotAllowed is returned by QueryReadOnly when the query calls a function which is not allowed
+	ErrPGFunctionNotAllowed = errors.New("the query calls a function which is not allowed")
+)
+
+// pgAllowedFunctions are the functions a query with allowed tables can call, they only compute on their arguments
+var pgAllowedFunctions = map[string]bool{
+	// aggregates
+	"count": true, "sum": true, "avg": true, "min": true, "max": true, "string_agg": true, "array_agg": true,
+	"bool_and": true, "bool_or": true, "stddev": true, "stddev_pop": true, "stddev_samp": true,
+	"variance": true, "var_pop": true, "var_samp": true, "percentile_cont": true, "percentile_disc": true,
+	// window functions
+	"row_number": true, "rank": true, "dense_rank": true, "percent_rank": true, "cume_dist": true, "ntile": true,
+	"lag": true, "lead": true, "first_value": true, "last_value": true,
+	// conditional expressions
+	"coalesce": true, "nullif": true, "greatest": true, "least": true, "row": true,
+	// strings
+	"lower": true, "upper": true, "initcap": true, "length": true, "char_length": true, "character_length": true,
+	"substring": true, "substr": true, "btrim": true, "ltrim": true, "rtrim": true, "concat": true, "concat_ws": true,
+	"replace": true, "position": true, "strpos": true, "left": true, "right": true, "split_part": true,
+	// numbers
+	"round": true, "trunc": true, "floor": true, "ceil": true, "ceiling": true, "abs": true, "mod": true,
+	"power": true, "sqrt": true,
+	// date and time
+	"now": true, "age": true, "date_trunc": true, "date_part": true, "extract": true, "make_date": true,
+	"to_char": true, "to_date": true, "to_timestamp": true, "to_number": true,
+}
+
+// QueryReadOnly runs a SELECT query in a read-only transaction with the limits in options, and rolls back the
+// transaction after the rows are read. Truncated of the rows is set if more than MaxRows rows are found.
+//
+// The read-only transaction and the SET LOCAL limits can be changed by the query itself, and the checks of the
+// tables and the functions are done on the plan, so they are not a security boundary. The datasource must use a
+// role which can only read the tables to be queried, without the privileges to read files or connect to other
+// databases, like the members of pg_read_server_files or the owner of dblink.
+func (p *PostgreSQL) QueryReadOnly(ctx context.Context, query string, options ReadOnlyQueryOptions) (*TableRows, error) {
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	tx, err := p.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+	if options.Timeout > 0 {
+		if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", options.Timeout.Milliseconds())); err != nil {
+			return nil, err
+		}
+	}
+	if len(options.SearchPath) > 0 {
+		// pg_catalog is searched first unless it is in the search path, so put it at last
+		if _, err := tx.Exec(ctx, fmt.Sprintf("SET LOCAL search_path TO %s, pg_catalog", identifierList(options.SearchPath))); err != nil {
+			return nil, err
+		}
+	}
+	if options.AllowedTables != nil {
+		var plan []byte
+		if err := tx.QueryRow(ctx, "EXPLAIN (VERBOSE, FORMAT JSON) "+query).Scan(&plan); err != nil {
+			return nil, err
+		}
+		tables, err := planTables(plan)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tables {
+			if !options.AllowedTables[t] {
+				return nil, fmt.Errorf("%w: %s", ErrPGTableNotAllowed, t)
+			}
+		}
+		functions, err := planFunctions(plan)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range functions {
+			if !pgAllowedFunctions[f] {
+				return nil, fmt.Errorf("%w: %s", ErrPGFunctionNotAllowed, f)
+			}
+		}
+	}
+	if options.MaxRows > 0 {
+		// the query may end with a comment, so it is wrapped in new lines
+		query = fmt.Sprintf("SELECT * FROM (\n%s\n) AS t LIMIT %d", query, options.MaxRows+1)
+	}
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := &TableRows{}
+	for _, field := range rows.FieldDescriptions() {
+		res.Columns = append(res.Columns, field.Name)
+	}
+	for rows.Next() {
+		if options.MaxRows > 0 && len(res.Rows) == options.MaxRows {
+			res.Truncated = true
+			break
+		}
+		values, err := rows.Values()
+		if err != nil {
+			return nil, err
+		}
+		row := make([]string, 0, len(values))
+		for _, v := range values {
+			row = append(row, pgValueString(v))
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res, rows.Err()
+}
+
+func identifierList(names []string) string {
+	res := make([]string, 0, len(names))
+	for _, name := range names {
+		res = append(res, pgx.Identifier{name}.Sanitize())
+	}
+	return strings.Join(res, ", ")
+}
+
+// planTables returns the tables in the form of `schema.table` read by the plan from `EXPLAIN (VERBOSE, FORMAT JSON)`
+func planTables(data []byte) ([]string, error) {
+	var plans []struct {
+		Plan map[string]any `json:"Plan"`
+	}
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return nil, fmt.Errorf("failed to parse the query plan: %w", err)
+	}
+	seen := make(map[string]bool)
+	res := make([]string, 0)
+	var walk func(node map[string]any)
+	walk = func(node map[string]any) {
+		if relation, ok := node["Relation Name"].(string); ok {
+			schema, _ := node["Schema"].(string)
+			if t := schema + "." + relation; !seen[t] {
+				seen[t] = true
+				res = append(res, t)
+			}
+		}
+		children, _ := node["Plans"].([]any)
+		for _, child := range children {
+			if c, ok := child.(map[string]any); ok {
+				walk(c)
+			}
+		}
+	}
+	for _, p := range plans {
+		walk(p.Plan)
+	}
+	return res, nil
+}
+
+var (
+	// planLiteral is a string literal in the expressions of the plan
+	planLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// planCast is a type cast like `::character varying(10)[]`, the type modifiers are not function calls
+	planCast = regexp.MustCompile(`::\s*(?:"[^"]+"|[A-Za-z_][\w$.]*)(?:\s+(?:varying|precision|with|without|time|zone))*\s*(?:\(\s*\d+(?:\s*,\s*\d+)?\s*\))?(?:\s+(?:with|without|time|zone))*(?:\[\])*`)
+	// planFunctionCall is a function name followed by its arguments, the name may be quoted or has a schema
+	planFunctionCall = regexp.MustCompile(`((?:"[^"]+"|[A-Za-z_][\w$]*)(?:\.(?:"[^"]+"|[A-Za-z_][\w$]*))*)\(`)
+	// planNameKeys are the keys of the plan nodes which are names instead of expressions
+	planNameKeys = map[string]bool{
+		"Node Type": true, "Relation Name": true, "Schema": true, "Alias": true, "Index Name": true, "CTE Name": true,
+		"Subplan Name": true, "Parent Relationship": true, "Strategy": true, "Join Type": true, "Scan Direction": true,
+		"Partial Mode": true, "Operation": true, "Sort Method": true, "Sort Space Type": true,
+	}
+)
+
+// planFunctions returns the lower-case names of the functions called by the expressions in the plan from
+// `EXPLAIN (VERBOSE, FORMAT JSON)`, the functions in pg_catalog are returned without the schema.
+// Reading rows from functions is not allowed, so an error is returned if the plan has a function scan.
+func planFunctions(data []byte) ([]string, error) {
+	var plans []struct {
+		Plan map[string]any `json:"Plan"`
+	}
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return nil, fmt.Errorf("failed to parse the query plan: %w", err)
+	}
+	seen := make(map[string]bool)
+	res := make([]string, 0)
+	addExpression := func(expr string) {
+		expr = planCast.ReplaceAllString(planLiteral.ReplaceAllString(expr, "''"), " ")
+		for _, m := range planFunctionCall.FindAllStringSubmatch(expr, -1) {
+			name := strings.ToLower(strings.ReplaceAll(m[1], `"`, ""))
+			name = strings.TrimPrefix(name, "pg_catalog.")
+			if !seen[name] {
+				seen[name] = true
+				res = append(res, name)
+			}
+		}
+	}
+	var walk func(node map[string]any) error
+	walk = func(node map[string]any) error {
+		if nodeType, _ := node["Node Type"].(string); strings.HasSuffix(nodeType, "Function Scan") {
+			return fmt.Errorf("%w: the query reads rows from a function", ErrPGFunctionNotAllowed)
+		}
+		for key, value := range node {
+			if planNameKeys[key] {
+				continue
+			}
+			switch v := value.(type) {
+			case string:
+				addExpression(v)
+			case []any:
+				for _, item := range v {
+					switch item := item.(type) {
+					case string:
+						addExpression(item)
+					case map[string]any:
+						if err := walk(item); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+		return nil
+	}
+	for _, p := range plans {
+		if err := walk(p.Plan); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(res)
+	return res, nil
 }
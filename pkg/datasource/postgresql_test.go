@@ -0,0 +1,68 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanTables(t *testing.T) {
+	t.Parallel()
+	plan := `[{"Plan": {"Node Type": "Hash Join", "Plans": [
+		{"Node Type": "Seq Scan", "Relation Name": "orders", "Schema": "sales", "Alias": "o"},
+		{"Node Type": "Hash", "Plans": [
+			{"Node Type": "Index Scan", "Relation Name": "users", "Schema": "public", "Alias": "u"},
+			{"Node Type": "Seq Scan", "Relation Name": "orders", "Schema": "sales", "Alias": "o2"}
+		]}
+	]}}]`
+	tables, err := planTables([]byte(plan))
+	require.NoError(t, err)
+	require.Equal(t, []string{"sales.orders", "public.users"}, tables)
+
+	_, err = planTables([]byte("not json"))
+	require.Error(t, err)
+}
+
+func TestPlanFunctions(t *testing.T) {
+	t.Parallel()
+	plan := `[{"Plan": {"Node Type": "Aggregate", "Output": ["count(*)", "max((o.created_at)::date)", "\"substring\"((u.name)::text, 1, 2)"], "Plans": [
+		{"Node Type": "Seq Scan", "Relation Name": "orders", "Schema": "sales", "Alias": "o",
+			"Output": ["o.id", "(o.amount)::numeric(10,2)", "(o.name)::character varying(20)"],
+			"Filter": "((o.status)::text = 'query_to_xml(''x'')'::text)"},
+		{"Node Type": "Result", "Output": ["pg_catalog.lower(u.name)"], "Plans": [
+			{"Node Type": "Seq Scan", "Relation Name": "users", "Schema": "public", "Alias": "u",
+				"Filter": "(current_setting('app.key'::text) = (u.key)::text)"}
+		]}
+	]}}]`
+	functions, err := planFunctions([]byte(plan))
+	require.NoError(t, err)
+	require.Equal(t, []string{"count", "current_setting", "lower", "max", "substring"}, functions)
+
+	for _, nodeType := range []string{"Function Scan", "Table Function Scan"} {
+		plan = `[{"Plan": {"Node Type": "Nested Loop", "Plans": [
+			{"Node Type": "Seq Scan", "Relation Name": "orders", "Schema": "sales", "Alias": "o"},
+			{"Node Type": "` + nodeType + `", "Function Name": "query_to_xml", "Alias": "x"}
+		]}}]`
+		_, err = planFunctions([]byte(plan))
+		require.ErrorIs(t, err, ErrPGFunctionNotAllowed)
+	}
+
+	_, err = planFunctions([]byte("not json"))
+	require.Error(t, err)
+}
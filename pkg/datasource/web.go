@@ -15,51 +15,405 @@ limitations under the License.
 package datasource
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+const (
+	// WebPageURLTag is the object tag which stores the url of a crawled page
+	WebPageURLTag = "web_url"
+	// WebPageTitleTag is the object tag which stores the title of a crawled page
+	WebPageTitleTag = "web_title"
+
+	// object tag values can only have limited characters, so the url and title are stored in base64,
+	// and the base64 value can not be longer than 256 characters
+	maxTagValueLength = 256
+
+	defaultCrawlMaxDepth     = 1
+	defaultCrawlMaxCount     = 100
+	defaultCrawlIntervalTime = 1000
+	crawlUserAgent           = "arcadia-web-crawler"
+)
+
+var (
+	ErrWebNoURL      = errors.New("no url provided for web datasource")
+	ErrWebInvalidURL = errors.New("web datasource url must be an absolute http or https url")
 )
 
 var _ Datasource = (*Web)(nil)
 
+// WebCrawlerFileSuffix is the suffix of the file with the CrawlOptions in a versioneddataset,
+// the pages are crawled into the directory of the same name without the suffix
+const WebCrawlerFileSuffix = ".web"
+
+// WebPagesPrefix returns the directory of the pages crawled by the web crawler file
+func WebPagesPrefix(crawlerFile string) string {
+	return strings.TrimSuffix(crawlerFile, WebCrawlerFileSuffix) + "/"
+}
+
+// Web is a datasource which crawls pages from a website
 type Web struct {
 	url string
+	// client is used to check and read single pages, the crawler has its own client
+	client *http.Client
 }
 
-func NewWeb(ctx context.Context, url string) (*Web, error) {
+func NewWeb(ctx context.Context, rawURL string) (*Web, error) {
+	if _, err := parseWebURL(rawURL); err != nil {
+		return nil, err
+	}
 	return &Web{
-		url: url,
+		url:    rawURL,
+		client: &http.Client{Timeout: 30 * time.Second},
 	}, nil
 }
 
+func parseWebURL(rawURL string) (*url.URL, error) {
+	if rawURL == "" {
+		return nil, ErrWebNoURL
+	}
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrWebInvalidURL
+	}
+	return u, nil
+}
+
+// Stat checks whether the website can be reached
 func (w *Web) Stat(ctx context.Context, info any) error {
-	_, err := url.ParseRequestURI("http://google.com/")
+	resp, err := w.get(ctx, w.url)
 	if err != nil {
 		return err
 	}
-	return nil
+	return resp.Body.Close()
 }
 
 func (w *Web) Remove(ctx context.Context, info any) error {
 	return nil
 }
 
+// ReadFile reads the page with the url in info, which is a string, the url of the datasource is used if it is empty
 func (w *Web) ReadFile(ctx context.Context, info any) (io.ReadCloser, error) {
-	// TODO implement me
-	panic("implement me")
+	resp, err := w.get(ctx, w.pageURL(info))
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
 }
 
+// StatFile fetches the page with the url in info and returns it as a *WebPage
 func (w *Web) StatFile(ctx context.Context, info any) (any, error) {
-	// TODO implement me
-	panic("implement me")
+	return w.fetchPage(ctx, w.pageURL(info))
 }
 
+// GetTags returns the url and title tags of the page with the url in info
 func (w *Web) GetTags(ctx context.Context, info any) (map[string]string, error) {
-	// TODO implement me
-	panic("implement me")
+	page, err := w.fetchPage(ctx, w.pageURL(info))
+	if err != nil {
+		return nil, err
+	}
+	return page.Tags(), nil
 }
 
+// ListObjects crawls the website from source, or the url of the datasource if source is empty,
+// info should be of type CrawlOptions, and the crawled pages are returned as []*WebPage
 func (w *Web) ListObjects(ctx context.Context, source string, info any) (any, error) {
-	// TODO implement me
-	panic("implement me")
+	options, ok := info.(CrawlOptions)
+	if !ok {
+		return nil, fmt.Errorf("info should be of type CrawlOptions")
+	}
+	if source != "" {
+		options.URL = source
+	}
+	pages := make([]*WebPage, 0)
+	err := w.Crawl(ctx, options, func(_ context.Context, page *WebPage) error {
+		pages = append(pages, page)
+		return nil
+	})
+	return pages, err
+}
+
+func (w *Web) pageURL(info any) string {
+	if s, ok := info.(string); ok && s != "" {
+		return s
+	}
+	return w.url
+}
+
+func (w *Web) get(ctx context.Context, pageURL string) (*http.Response, error) {
+	if _, err := parseWebURL(pageURL); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", crawlUserAgent)
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to get %s: %s", pageURL, resp.Status)
+	}
+	return resp, nil
+}
+
+func (w *Web) fetchPage(ctx context.Context, pageURL string) (*WebPage, error) {
+	resp, err := w.get(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &WebPage{URL: pageURL, Title: htmlTitle(body), Content: body}, nil
+}
+
+var titleRegexp = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+func htmlTitle(body []byte) string {
+	if m := titleRegexp.FindSubmatch(body); len(m) == 2 {
+		return strings.TrimSpace(string(m[1]))
+	}
+	return ""
+}
+
+// WebPage is a page crawled from a website
+type WebPage struct {
+	URL   string
+	Title string
+	// Content is the raw html of the page
+	Content []byte
+	// Depth is the depth of the page in the crawl, the start page is 1
+	Depth int
+}
+
+// ObjectName returns a stable object name for the page, so crawling again overwrites the same object.
+// The name is made of the host and path of the page, with a hash of the full url to tell pages with different queries apart.
+func (p *WebPage) ObjectName() string {
+	name := p.URL
+	if u, err := url.Parse(p.URL); err == nil {
+		name = u.Host + u.Path
+	}
+	name = strings.Trim(objectNameReplacer.ReplaceAllString(name, "_"), "_")
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	return fmt.Sprintf("%s-%x.html", name, sha1.Sum([]byte(p.URL)))
+}
+
+var objectNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9.\-]+`)
+
+// Tags returns the object tags which record the url and title of the page
+func (p *WebPage) Tags() map[string]string {
+	tags := make(map[string]string, 2)
+	if v := encodeTagValue(p.URL); v != "" {
+		tags[WebPageURLTag] = v
+	}
+	title := p.Title
+	for title != "" && base64.RawURLEncoding.EncodedLen(len(title)) > maxTagValueLength {
+		// cut the title by runes until it fits
+		runes := []rune(title)
+		title = string(runes[:len(runes)*3/4])
+	}
+	if title != "" {
+		tags[WebPageTitleTag] = encodeTagValue(title)
+	}
+	return tags
+}
+
+func encodeTagValue(v string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(v))
+	if len(encoded) > maxTagValueLength {
+		return ""
+	}
+	return encoded
+}
+
+// ParseWebPageTags returns the url and title stored in the object tags of a crawled page
+func ParseWebPageTags(tags map[string]string) (pageURL, title string) {
+	decode := func(key string) string {
+		v, err := base64.RawURLEncoding.DecodeString(tags[key])
+		if err != nil {
+			return ""
+		}
+		return string(v)
+	}
+	return decode(WebPageURLTag), decode(WebPageTitleTag)
+}
+
+// CrawlOptions are the params of a crawl, which are the same as the params in the .web file of a versioneddataset
+type CrawlOptions struct {
+	// URL is the start page of the crawl
+	URL string `json:"url,omitempty"`
+	// IntervalTime is the interval between two requests in milliseconds
+	IntervalTime int `json:"interval_time,omitempty"`
+	// MaxDepth is the max depth of the crawl, 1 means only the start page
+	MaxDepth int `json:"max_depth,omitempty"`
+	// MaxCount is the max number of the crawled pages
+	MaxCount int `json:"max_count,omitempty"`
+	// IncludeSubURLs are regular expressions, if not empty, only the pages whose url matches one of them are crawled.
+	// The start page is always crawled.
+	IncludeSubURLs []string `json:"include_sub_urls,omitempty"`
+	// ExcludeSubURLs are regular expressions, the pages whose url matches one of them are not crawled
+	ExcludeSubURLs []string `json:"exclude_sub_urls,omitempty"`
+}
+
+func (o CrawlOptions) withDefaults() CrawlOptions {
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = defaultCrawlMaxDepth
+	}
+	if o.MaxCount <= 0 {
+		o.MaxCount = defaultCrawlMaxCount
+	}
+	if o.IntervalTime <= 0 {
+		o.IntervalTime = defaultCrawlIntervalTime
+	}
+	return o
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url pattern %q: %w", p, err)
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, p := range patterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Crawl crawls the html pages of the website from options.URL, or the url of the datasource if it is empty,
+// and calls handle for each page. Only the pages on the same host as the start page are crawled,
+// the requests are sent one by one with options.IntervalTime between them, and robots.txt is respected.
+// Crawl stops at the first error returned by handle.
+func (w *Web) Crawl(ctx context.Context, options CrawlOptions, handle func(ctx context.Context, page *WebPage) error) error {
+	options = options.withDefaults()
+	if options.URL == "" {
+		options.URL = w.url
+	}
+	start, err := parseWebURL(options.URL)
+	if err != nil {
+		return err
+	}
+	includes, err := compilePatterns(options.IncludeSubURLs)
+	if err != nil {
+		return err
+	}
+	excludes, err := compilePatterns(options.ExcludeSubURLs)
+	if err != nil {
+		return err
+	}
+
+	c := colly.NewCollector(
+		colly.UserAgent(crawlUserAgent),
+		colly.MaxDepth(options.MaxDepth),
+		colly.AllowedDomains(start.Host),
+	)
+	c.IgnoreRobotsTxt = false
+	if err := c.Limit(&colly.LimitRule{
+		DomainGlob:  "*",
+		Parallelism: 1,
+		Delay:       time.Duration(options.IntervalTime) * time.Millisecond,
+	}); err != nil {
+		return err
+	}
+
+	var (
+		mu       sync.Mutex
+		count    int
+		crawlErr error
+	)
+	// stopped reports whether no more pages should be crawled
+	stopped := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return crawlErr != nil || count >= options.MaxCount || ctx.Err() != nil
+	}
+	c.OnHTML("html", func(e *colly.HTMLElement) {
+		if stopped() {
+			return
+		}
+		page := &WebPage{
+			URL:     e.Request.URL.String(),
+			Title:   e.ChildText("title"),
+			Content: bytes.Clone(e.Response.Body),
+			Depth:   e.Request.Depth,
+		}
+		err := handle(ctx, page)
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+		if err != nil && crawlErr == nil {
+			crawlErr = fmt.Errorf("failed to handle page %s: %w", page.URL, err)
+		}
+	})
+	c.OnHTML("a[href]", func(e *colly.HTMLElement) {
+		if stopped() {
+			return
+		}
+		link := e.Request.AbsoluteURL(e.Attr("href"))
+		if link == "" {
+			return
+		}
+		if len(includes) > 0 && !matchAny(includes, link) {
+			return
+		}
+		if matchAny(excludes, link) {
+			return
+		}
+		// the links which are already visited, blocked by robots.txt or out of depth are skipped by the collector
+		_ = e.Request.Visit(link)
+	})
+	var startErr error
+	c.OnError(func(r *colly.Response, err error) {
+		if r.Request.Depth == 1 {
+			startErr = err
+		}
+	})
+
+	if err := c.Visit(start.String()); err != nil && startErr == nil {
+		startErr = err
+	}
+	c.Wait()
+	if startErr != nil {
+		return fmt.Errorf("failed to crawl %s: %w", start.String(), startErr)
+	}
+	if crawlErr != nil {
+		return crawlErr
+	}
+	return ctx.Err()
 }
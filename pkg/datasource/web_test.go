@@ -0,0 +1,90 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebCrawl(t *testing.T) {
+	t.Parallel()
+	pages := map[string]string{
+		"/":          `<a href="/a">a</a><a href="/private/x">private</a><a href="/skip">skip</a>`,
+		"/a":         `<a href="/a/b">b</a>`,
+		"/a/b":       `<a href="/a/b/c">c</a>`,
+		"/a/b/c":     ``,
+		"/private/x": ``,
+		"/skip":      ``,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		body, ok := pages[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprintf(w, "<html><head><title>page %s</title></head><body>%s</body></html>", r.URL.Path, body)
+	}))
+	defer server.Close()
+
+	web, err := NewWeb(context.Background(), server.URL)
+	require.NoError(t, err)
+	crawled := make([]string, 0)
+	titles := make(map[string]string)
+	err = web.Crawl(context.Background(), CrawlOptions{
+		IntervalTime:   1,
+		MaxDepth:       3,
+		ExcludeSubURLs: []string{"/skip$"},
+	}, func(_ context.Context, page *WebPage) error {
+		crawled = append(crawled, page.URL)
+		titles[page.URL] = page.Title
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(crawled)
+	require.Equal(t, []string{server.URL, server.URL + "/a", server.URL + "/a/b"}, crawled)
+	require.Equal(t, "page /a", titles[server.URL+"/a"])
+
+	_, err = NewWeb(context.Background(), "ftp://example.com")
+	require.ErrorIs(t, err, ErrWebInvalidURL)
+}
+
+func TestWebPageTags(t *testing.T) {
+	t.Parallel()
+	page := &WebPage{URL: "https://example.com/docs?id=1&lang=zh", Title: "开始使用 – Docs"}
+	pageURL, title := ParseWebPageTags(page.Tags())
+	require.Equal(t, page.URL, pageURL)
+	require.Equal(t, page.Title, title)
+
+	page.Title = strings.Repeat("标题", 100)
+	_, title = ParseWebPageTags(page.Tags())
+	require.NotEmpty(t, title)
+	require.True(t, strings.HasPrefix(page.Title, title))
+	require.Regexp(t, `^example.com_docs-[0-9a-f]{40}\.html$`, page.ObjectName())
+}
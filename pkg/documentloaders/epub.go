@@ -0,0 +1,225 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package documentloaders
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/tmc/langchaingo/documentloaders"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// Epub loads an epub book, a document for each chapter in the reading order.
+// The title of a chapter is stored as the heading of the document.
+type Epub struct {
+	r        io.Reader
+	fileName string
+}
+
+var (
+	_ documentloaders.Loader = Epub{}
+	_ Streamer               = Epub{}
+)
+
+func NewEpub(r io.Reader, fileName string) Epub {
+	return Epub{r: r, fileName: fileName}
+}
+
+func (e Epub) Stream(ctx context.Context, batchSize int, handle func(docs []schema.Document) error) error {
+	zr, done, err := openZip(e.r)
+	if err != nil {
+		return err
+	}
+	defer done()
+	container := struct {
+		Rootfiles struct {
+			Rootfile []struct {
+				FullPath string `xml:"full-path,attr"`
+			} `xml:"rootfile"`
+		} `xml:"rootfiles"`
+	}{}
+	if err = decodeZipFile(zr, "META-INF/container.xml", &container); err != nil {
+		return err
+	}
+	if len(container.Rootfiles.Rootfile) == 0 {
+		return errors.New("no rootfile found in the epub file")
+	}
+	opfPath := container.Rootfiles.Rootfile[0].FullPath
+	opf := struct {
+		Manifest struct {
+			Item []struct {
+				ID   string `xml:"id,attr"`
+				Href string `xml:"href,attr"`
+			} `xml:"item"`
+		} `xml:"manifest"`
+		Spine struct {
+			ItemRef []struct {
+				IDRef string `xml:"idref,attr"`
+			} `xml:"itemref"`
+		} `xml:"spine"`
+	}{}
+	if err = decodeZipFile(zr, opfPath, &opf); err != nil {
+		return err
+	}
+	hrefs := make(map[string]string, len(opf.Manifest.Item))
+	for _, item := range opf.Manifest.Item {
+		href, err := url.PathUnescape(item.Href)
+		if err != nil {
+			href = item.Href
+		}
+		hrefs[item.ID] = path.Join(path.Dir(opfPath), href)
+	}
+	b := newBatcher(batchSize, handle)
+	chapter := 0
+	for _, ref := range opf.Spine.ItemRef {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		href, ok := hrefs[ref.IDRef]
+		if !ok {
+			return fmt.Errorf("no item %s found in the manifest", ref.IDRef)
+		}
+		title, text, err := e.chapterText(zr, href)
+		if err != nil {
+			return err
+		}
+		if text == "" {
+			continue
+		}
+		chapter++
+		doc := schema.Document{
+			PageContent: text,
+			Metadata: map[string]any{
+				FileNameCol:      e.fileName,
+				ChapterNumberCol: strconv.Itoa(chapter),
+			},
+		}
+		if title != "" {
+			doc.Metadata[HeadingCol] = title
+		}
+		if err = b.add(doc); err != nil {
+			return err
+		}
+	}
+	return b.flush()
+}
+
+// blockElements are the html elements which start a new line
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "blockquote": true, "pre": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "section": true, "table": true,
+}
+
+// chapterText returns the title and the text of a xhtml chapter,
+// the title is the first heading, or the title element if the chapter has no heading.
+func (e Epub) chapterText(zr *zip.Reader, name string) (title, text string, err error) {
+	rc, err := openZipFile(zr, name)
+	if err != nil {
+		return "", "", err
+	}
+	defer rc.Close()
+	dec := xml.NewDecoder(rc)
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+	body := &strings.Builder{}
+	heading := &strings.Builder{}
+	var docTitle string
+	skip, inTitle, inHeading, headingDone := 0, false, false, false
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case name == "script" || name == "style":
+				skip++
+			case name == "title":
+				inTitle = true
+			case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6' && !headingDone:
+				inHeading = true
+			}
+			if blockElements[name] {
+				body.WriteString("\n")
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case name == "script" || name == "style":
+				skip--
+			case name == "title":
+				inTitle = false
+			case inHeading && len(name) == 2 && name[0] == 'h':
+				inHeading, headingDone = false, true
+			}
+			if blockElements[name] {
+				body.WriteString("\n")
+			}
+		case xml.CharData:
+			switch {
+			case skip > 0:
+			case inTitle:
+				docTitle += string(t)
+			default:
+				if inHeading {
+					heading.Write(t)
+				}
+				body.Write(t)
+			}
+		}
+	}
+	title = strings.Join(strings.Fields(heading.String()), " ")
+	if title == "" {
+		title = strings.Join(strings.Fields(docTitle), " ")
+	}
+	lines := strings.Split(body.String(), "\n")
+	res := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			res = append(res, line)
+		}
+	}
+	return title, strings.Join(res, "\n"), nil
+}
+
+func (e Epub) Load(ctx context.Context) ([]schema.Document, error) {
+	return loadAll(ctx, e)
+}
+
+func (e Epub) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := e.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package documentloaders
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/tmc/langchaingo/documentloaders"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// LoaderOptions are the options to create a loader by NewLoader
+type LoaderOptions struct {
+	// ExtName overrides the extension name of the file, like `.pdf`
+	ExtName string
+	// QA means the csv file is a QA csv file
+	QA bool
+}
+
+// NewLoader creates the loader of a file by its extension name, the text loader is used for the unknown extensions.
+// The knowledgebase controller and the document loader node of applications share it to support the same formats.
+func NewLoader(r io.Reader, fileName string, options LoaderOptions) (documentloaders.Loader, error) {
+	ext := options.ExtName
+	if ext == "" {
+		ext = filepath.Ext(fileName)
+	}
+	switch strings.ToLower(ext) {
+	case ".csv":
+		if options.QA {
+			return NewQACSV(r, fileName), nil
+		}
+		return NewCSV(r), nil
+	case ".html", ".htm":
+		return documentloaders.NewHTML(r), nil
+	case ".pdf":
+		return NewPDF(r, fileName), nil
+	case ".docx":
+		return NewDocx(r, fileName), nil
+	case ".xlsx":
+		return NewXlsx(r, fileName), nil
+	case ".pptx":
+		return NewPptx(r, fileName), nil
+	case ".md", ".markdown":
+		return NewMarkdown(r, fileName), nil
+	case ".epub":
+		return NewEpub(r, fileName), nil
+	case ".mp3", ".wav":
+		// the whisper api needs the whole audio
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return NewAudoWithWhisper(data, fileName, "", "", false), nil
+	default:
+		return NewText(r, fileName), nil
+	}
+}
+
+// Markdown loads a markdown file like a text file, but without the yaml front matter.
+// Use it with the markdown splitter to keep the header path of the chunks.
+type Markdown struct {
+	r        io.Reader
+	fileName string
+}
+
+var (
+	_ documentloaders.Loader = Markdown{}
+	_ Streamer               = Markdown{}
+)
+
+func NewMarkdown(r io.Reader, fileName string) Markdown {
+	return Markdown{r: r, fileName: fileName}
+}
+
+func (m Markdown) Stream(ctx context.Context, batchSize int, handle func(docs []schema.Document) error) error {
+	rd := bufio.NewReaderSize(m.r, frontMatterMaxSize)
+	head, err := rd.Peek(frontMatterMaxSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return err
+	}
+	if n := frontMatterSize(string(head)); n > 0 {
+		if _, err = rd.Discard(n); err != nil {
+			return err
+		}
+	}
+	return NewText(rd, m.fileName).Stream(ctx, batchSize, handle)
+}
+
+// frontMatterMaxSize is the max size of a yaml front matter, a longer one is kept as content
+const frontMatterMaxSize = 64 << 10
+
+// frontMatterSize returns the size of the yaml front matter at the beginning of head, including the closing line
+func frontMatterSize(head string) int {
+	if !strings.HasPrefix(head, "---\n") && !strings.HasPrefix(head, "---\r\n") {
+		return 0
+	}
+	offset := strings.Index(head, "\n") + 1
+	for offset < len(head) {
+		end := strings.Index(head[offset:], "\n")
+		if end == -1 {
+			if strings.TrimSpace(head[offset:]) == "---" {
+				return len(head)
+			}
+			return 0
+		}
+		if strings.TrimSpace(head[offset:offset+end]) == "---" {
+			return offset + end + 1
+		}
+		offset += end + 1
+	}
+	return 0
+}
+
+func (m Markdown) Load(ctx context.Context) ([]schema.Document, error) {
+	return loadAll(ctx, m)
+}
+
+func (m Markdown) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := m.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
@@ -0,0 +1,551 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package documentloaders
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"code.sajari.com/docconv/v2"
+	"github.com/tmc/langchaingo/documentloaders"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// openZip copies r to a local temporary file if it is not a file, because a zip file can only be read with random access.
+// done must be called to remove the temporary file.
+func openZip(r io.Reader) (zr *zip.Reader, done func(), err error) {
+	f, err := docconv.NewLocalFile(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating local file: %w", err)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Done()
+		return nil, nil, err
+	}
+	if zr, err = zip.NewReader(f, stat.Size()); err != nil {
+		f.Done()
+		return nil, nil, err
+	}
+	return zr, f.Done, nil
+}
+
+func openZipFile(zr *zip.Reader, name string) (io.ReadCloser, error) {
+	name = strings.TrimPrefix(path.Clean(name), "/")
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f.Open()
+		}
+	}
+	return nil, fmt.Errorf("%s not found in the file", name)
+}
+
+func decodeZipFile(zr *zip.Reader, name string, v any) error {
+	rc, err := openZipFile(zr, name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// relationships returns the targets of the relationships of a part in an office file by the relationship ids,
+// the targets are full paths in the zip file.
+func relationships(zr *zip.Reader, part string) (map[string]string, error) {
+	rels := struct {
+		Relationship []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		}
+	}{}
+	dir, file := path.Split(part)
+	if err := decodeZipFile(zr, path.Join(dir, "_rels", file+".rels"), &rels); err != nil {
+		return nil, err
+	}
+	res := make(map[string]string, len(rels.Relationship))
+	for _, r := range rels.Relationship {
+		if strings.HasPrefix(r.Target, "/") {
+			res[r.ID] = r.Target
+		} else {
+			res[r.ID] = path.Join(dir, r.Target)
+		}
+	}
+	return res, nil
+}
+
+// relationshipID returns the value of the r:id attribute
+func relationshipID(attrs []xml.Attr) string {
+	for _, a := range attrs {
+		if a.Name.Local == "id" && strings.HasSuffix(a.Name.Space, "/relationships") {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func attr(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Docx loads a word document, a document for each page.
+// Pages are split by the page breaks and the page breaks rendered by word last time,
+// so the whole file is a single page if it was not saved by word.
+type Docx struct {
+	r        io.Reader
+	fileName string
+}
+
+var (
+	_ documentloaders.Loader = Docx{}
+	_ Streamer               = Docx{}
+)
+
+func NewDocx(r io.Reader, fileName string) Docx {
+	return Docx{r: r, fileName: fileName}
+}
+
+func (d Docx) Stream(ctx context.Context, batchSize int, handle func(docs []schema.Document) error) error {
+	zr, done, err := openZip(d.r)
+	if err != nil {
+		return err
+	}
+	defer done()
+	rc, err := openZipFile(zr, "word/document.xml")
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	b := newBatcher(batchSize, handle)
+	page := 1
+	text := &strings.Builder{}
+	// a page break is ignored if the page is empty,
+	// word renders a page break again at the beginning of the page after an explicit page break
+	pageBreak := func() error {
+		content := strings.TrimSpace(text.String())
+		text.Reset()
+		if content == "" {
+			return nil
+		}
+		err := b.add(schema.Document{
+			PageContent: content,
+			Metadata: map[string]any{
+				FileNameCol:   d.fileName,
+				PageNumberCol: strconv.Itoa(page),
+			},
+		})
+		page++
+		return err
+	}
+	inText := false
+	dec := xml.NewDecoder(rc)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				text.WriteString("\t")
+			case "br", "cr":
+				if attr(t.Attr, "type") == "page" {
+					if err := pageBreak(); err != nil {
+						return err
+					}
+				} else {
+					text.WriteString("\n")
+				}
+			case "lastRenderedPageBreak":
+				if err := pageBreak(); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text.WriteString("\n")
+			case "tc":
+				text.WriteString("\t")
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+	if err := pageBreak(); err != nil {
+		return err
+	}
+	return b.flush()
+}
+
+func (d Docx) Load(ctx context.Context) ([]schema.Document, error) {
+	return loadAll(ctx, d)
+}
+
+func (d Docx) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := d.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
+
+// Xlsx loads an excel workbook, a document for each row like a csv file.
+// The first non-empty row of a sheet is the header, the content of a row is `header: value` lines.
+type Xlsx struct {
+	r        io.Reader
+	fileName string
+}
+
+var (
+	_ documentloaders.Loader = Xlsx{}
+	_ Streamer               = Xlsx{}
+)
+
+func NewXlsx(r io.Reader, fileName string) Xlsx {
+	return Xlsx{r: r, fileName: fileName}
+}
+
+// columnIndex returns the zero-based column index of a cell reference like `AB12`
+func columnIndex(ref string) int {
+	index := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		index = index*26 + int(c-'A'+1)
+	}
+	return index - 1
+}
+
+func (x Xlsx) sharedStrings(zr *zip.Reader) ([]string, error) {
+	rc, err := openZipFile(zr, "xl/sharedStrings.xml")
+	if err != nil {
+		// a workbook without any string has no shared strings
+		return nil, nil
+	}
+	defer rc.Close()
+	res := make([]string, 0)
+	current := &strings.Builder{}
+	inText := false
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			inText = t.Name.Local == "t"
+		case xml.EndElement:
+			inText = false
+			if t.Name.Local == "si" {
+				res = append(res, current.String())
+				current.Reset()
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(t)
+			}
+		}
+	}
+}
+
+func (x Xlsx) Stream(ctx context.Context, batchSize int, handle func(docs []schema.Document) error) error {
+	zr, done, err := openZip(x.r)
+	if err != nil {
+		return err
+	}
+	defer done()
+	shared, err := x.sharedStrings(zr)
+	if err != nil {
+		return err
+	}
+	workbook := struct {
+		Sheets struct {
+			Sheet []struct {
+				Name  string     `xml:"name,attr"`
+				Attrs []xml.Attr `xml:",any,attr"`
+			} `xml:"sheet"`
+		} `xml:"sheets"`
+	}{}
+	if err = decodeZipFile(zr, "xl/workbook.xml", &workbook); err != nil {
+		return err
+	}
+	rels, err := relationships(zr, "xl/workbook.xml")
+	if err != nil {
+		return err
+	}
+	b := newBatcher(batchSize, handle)
+	for i, sheet := range workbook.Sheets.Sheet {
+		target, ok := rels[relationshipID(sheet.Attrs)]
+		if !ok {
+			return fmt.Errorf("no worksheet found for sheet %s", sheet.Name)
+		}
+		if err = x.streamSheet(ctx, zr, target, sheet.Name, i+1, shared, b); err != nil {
+			return err
+		}
+	}
+	return b.flush()
+}
+
+func (x Xlsx) streamSheet(ctx context.Context, zr *zip.Reader, target, name string, number int, shared []string, b *batcher) error {
+	rc, err := openZipFile(zr, target)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	var header, row []string
+	var rowNumber, column int
+	var cellType string
+	value := &strings.Builder{}
+	inValue := false
+	dec := xml.NewDecoder(rc)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				row = row[:0]
+				rowNumber, _ = strconv.Atoi(attr(t.Attr, "r"))
+				column = 0
+			case "c":
+				if ref := attr(t.Attr, "r"); ref != "" {
+					column = columnIndex(ref)
+				}
+				cellType = attr(t.Attr, "t")
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				v := value.String()
+				if cellType == "s" {
+					if n, err := strconv.Atoi(v); err == nil && n >= 0 && n < len(shared) {
+						v = shared[n]
+					}
+				}
+				for len(row) <= column {
+					row = append(row, "")
+				}
+				row[column] = strings.TrimSpace(v)
+				column++
+			case "row":
+				if strings.TrimSpace(strings.Join(row, "")) == "" {
+					continue
+				}
+				if header == nil {
+					header = append([]string{}, row...)
+					continue
+				}
+				lines := make([]string, 0, len(row))
+				for i, v := range row {
+					if v == "" {
+						continue
+					}
+					h := ""
+					if i < len(header) {
+						h = header[i]
+					}
+					if h == "" {
+						h = "column " + strconv.Itoa(i+1)
+					}
+					lines = append(lines, fmt.Sprintf("%s: %s", h, v))
+				}
+				if err := b.add(schema.Document{
+					PageContent: strings.Join(lines, "\n"),
+					Metadata: map[string]any{
+						FileNameCol:    x.fileName,
+						SheetCol:       name,
+						SheetNumberCol: strconv.Itoa(number),
+						"row":          rowNumber,
+					},
+				}); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+}
+
+func (x Xlsx) Load(ctx context.Context) ([]schema.Document, error) {
+	return loadAll(ctx, x)
+}
+
+func (x Xlsx) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := x.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
+
+// Pptx loads a powerpoint presentation, a document for each slide
+type Pptx struct {
+	r        io.Reader
+	fileName string
+}
+
+var (
+	_ documentloaders.Loader = Pptx{}
+	_ Streamer               = Pptx{}
+)
+
+func NewPptx(r io.Reader, fileName string) Pptx {
+	return Pptx{r: r, fileName: fileName}
+}
+
+func (p Pptx) Stream(ctx context.Context, batchSize int, handle func(docs []schema.Document) error) error {
+	zr, done, err := openZip(p.r)
+	if err != nil {
+		return err
+	}
+	defer done()
+	presentation := struct {
+		SlideIDs struct {
+			SlideID []struct {
+				Attrs []xml.Attr `xml:",any,attr"`
+			} `xml:"sldId"`
+		} `xml:"sldIdLst"`
+	}{}
+	if err = decodeZipFile(zr, "ppt/presentation.xml", &presentation); err != nil {
+		return err
+	}
+	rels, err := relationships(zr, "ppt/presentation.xml")
+	if err != nil {
+		return err
+	}
+	b := newBatcher(batchSize, handle)
+	for i, slide := range presentation.SlideIDs.SlideID {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		target, ok := rels[relationshipID(slide.Attrs)]
+		if !ok {
+			return fmt.Errorf("no slide found for slide %d", i+1)
+		}
+		text, err := p.slideText(zr, target)
+		if err != nil {
+			return err
+		}
+		if text == "" {
+			continue
+		}
+		if err = b.add(schema.Document{
+			PageContent: text,
+			Metadata: map[string]any{
+				FileNameCol:    p.fileName,
+				SlideNumberCol: strconv.Itoa(i + 1),
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return b.flush()
+}
+
+func (p Pptx) slideText(zr *zip.Reader, target string) (string, error) {
+	rc, err := openZipFile(zr, target)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	text := &strings.Builder{}
+	inText := false
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return strings.TrimSpace(text.String()), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			inText = t.Name.Local == "t"
+		case xml.EndElement:
+			inText = false
+			if t.Name.Local == "p" {
+				text.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+}
+
+func (p Pptx) Load(ctx context.Context) ([]schema.Document, error) {
+	return loadAll(ctx, p)
+}
+
+func (p Pptx) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := p.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package documentloaders
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func zipOf(t *testing.T, files map[string]string) *bytes.Reader {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return bytes.NewReader(buf.Bytes())
+}
+
+const relsNS = `xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+
+func TestDocx(t *testing.T) {
+	t.Parallel()
+	r := zipOf(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="w"><w:body>
+<w:p><w:r><w:t>Attendance</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Work starts at </w:t><w:t>9:00</w:t></w:r><w:r><w:br w:type="page"/></w:r></w:p>
+<w:p><w:r><w:lastRenderedPageBreak/><w:t>Leave</w:t></w:r></w:p>
+</w:body></w:document>`,
+	})
+	loader, err := NewLoader(r, "handbook.docx", LoaderOptions{})
+	require.NoError(t, err)
+	docs, err := loader.Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "Attendance\nWork starts at 9:00", docs[0].PageContent)
+	assert.Equal(t, "1", docs[0].Metadata[PageNumberCol])
+	assert.Equal(t, "Leave", docs[1].PageContent)
+	assert.Equal(t, "2", docs[1].Metadata[PageNumberCol])
+}
+
+func TestXlsx(t *testing.T) {
+	t.Parallel()
+	r := zipOf(t, map[string]string{
+		"xl/workbook.xml":            `<workbook ` + relsNS + `><sheets><sheet name="staff" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>name</t></si><si><t>age</t></si><si><r><t>To</t></r><r><t>m</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2" t="inlineStr"><is><t>extra</t></is></c></row>
+<row r="3"><c r="B3"><v>30</v></c></row>
+</sheetData></worksheet>`,
+	})
+	docs, err := NewXlsx(r, "staff.xlsx").Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "name: Tom\ncolumn 3: extra", docs[0].PageContent)
+	assert.Equal(t, "staff", docs[0].Metadata[SheetCol])
+	assert.Equal(t, 2, docs[0].Metadata["row"])
+	assert.Equal(t, "age: 30", docs[1].PageContent)
+}
+
+func TestPptx(t *testing.T) {
+	t.Parallel()
+	r := zipOf(t, map[string]string{
+		"ppt/presentation.xml":            `<p:presentation xmlns:p="p" ` + relsNS + `><p:sldIdLst><p:sldId id="257" r:id="rId3"/><p:sldId id="256" r:id="rId2"/></p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": `<Relationships><Relationship Id="rId2" Target="slides/slide1.xml"/><Relationship Id="rId3" Target="slides/slide2.xml"/></Relationships>`,
+		"ppt/slides/slide1.xml":           `<p:sld xmlns:p="p" xmlns:a="a"><a:p><a:r><a:t>second</a:t></a:r></a:p></p:sld>`,
+		"ppt/slides/slide2.xml":           `<p:sld xmlns:p="p" xmlns:a="a"><a:p><a:r><a:t>Roadmap</a:t></a:r></a:p><a:p><a:r><a:t>Q1</a:t></a:r></a:p></p:sld>`,
+	})
+	docs, err := NewPptx(r, "plan.pptx").Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "Roadmap\nQ1", docs[0].PageContent)
+	assert.Equal(t, "1", docs[0].Metadata[SlideNumberCol])
+	assert.Equal(t, "second", docs[1].PageContent)
+}
+
+func TestEpub(t *testing.T) {
+	t.Parallel()
+	r := zipOf(t, map[string]string{
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<package><manifest><item id="c1" href="text/ch%201.xhtml"/><item id="c2" href="text/ch2.xhtml"/></manifest>
+<spine><itemref idref="c2"/><itemref idref="c1"/></spine></package>`,
+		"OEBPS/text/ch 1.xhtml": `<html><head><title>Two</title><style>p{}</style></head><body><p>Second&nbsp;chapter<br>ends</p></body></html>`,
+		"OEBPS/text/ch2.xhtml":  `<html><body><h1>Chapter One</h1><p>It begins &amp; goes on.</p></body></html>`,
+	})
+	docs, err := NewEpub(r, "book.epub").Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "Chapter One\nIt begins & goes on.", docs[0].PageContent)
+	assert.Equal(t, "Chapter One", docs[0].Metadata[HeadingCol])
+	assert.Equal(t, "1", docs[0].Metadata[ChapterNumberCol])
+	assert.Equal(t, "Two", docs[1].Metadata[HeadingCol])
+	assert.Equal(t, "Second chapter\nends", docs[1].PageContent)
+}
+
+func TestMarkdownFrontMatter(t *testing.T) {
+	t.Parallel()
+	docs, err := NewMarkdown(strings.NewReader("---\ntitle: a\n---\n# Title\nbody\n"), "a.md").Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "# Title\nbody\n", docs[0].PageContent)
+
+	docs, err = NewMarkdown(strings.NewReader("---\nnot closed\n"), "b.md").Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "---\nnot closed\n", docs[0].PageContent)
+}
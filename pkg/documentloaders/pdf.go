@@ -17,9 +17,12 @@ limitations under the License.
 package documentloaders
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os/exec"
 	"strconv"
 	"strings"
 
@@ -28,6 +31,8 @@ import (
 	"github.com/tmc/langchaingo/textsplitter"
 )
 
+var _ Streamer = &PDF{}
+
 type PDF struct {
 	r        io.Reader
 	fileName string
@@ -87,3 +92,80 @@ func (p *PDF) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitt
 	}
 	return textsplitter.SplitDocuments(splitter, docs)
 }
+
+// Stream copies the pdf to a local temporary file, then extracts the text by one pdftotext process,
+// and reads the pages from its output one by one, so only the text of a batch of pages is in memory at a time.
+func (p *PDF) Stream(ctx context.Context, batchSize int, handle func(docs []schema.Document) error) error {
+	f, err := docconv.NewLocalFile(p.r)
+	if err != nil {
+		return fmt.Errorf("error creating local file: %w", err)
+	}
+	defer f.Done()
+	info, err := exec.CommandContext(ctx, "pdfinfo", f.Name()).Output()
+	if err != nil {
+		return fmt.Errorf("failed to get pdf info: %w", err)
+	}
+	pages := 0
+	for _, line := range strings.Split(string(info), "\n") {
+		if k, v, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(k) == "Pages" {
+			pages, _ = strconv.Atoi(strings.TrimSpace(v))
+		}
+	}
+	// the process is killed if the handler fails before all pages are read
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "pdftotext", "-q", "-enc", "UTF-8", "-eol", "unix", f.Name(), "-")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("failed to extract text: %w", err)
+	}
+	b := newBatcher(batchSize, handle)
+	err = readPDFPages(stdout, func(page int, text string) error {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		return b.add(schema.Document{
+			PageContent: text,
+			Metadata: map[string]any{
+				"page":        page,
+				"total_pages": pages,
+				FileNameCol:   p.fileName,
+				PageNumberCol: strconv.Itoa(page),
+			},
+		})
+	})
+	if err != nil {
+		cancel()
+		_ = cmd.Wait()
+		return err
+	}
+	if err = cmd.Wait(); err != nil {
+		return fmt.Errorf("failed to extract text: %w", err)
+	}
+	return b.flush()
+}
+
+// readPDFPages reads the output of pdftotext, and calls handle with the number and the text of each page,
+// the pages end with a form feed
+func readPDFPages(r io.Reader, handle func(page int, text string) error) error {
+	rd := bufio.NewReader(r)
+	for page := 1; ; page++ {
+		text, err := rd.ReadString('\f')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		// the text after the last form feed is empty
+		if errors.Is(err, io.EOF) && text == "" {
+			return nil
+		}
+		if handleErr := handle(page, strings.TrimSuffix(text, "\f")); handleErr != nil {
+			return handleErr
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+	}
+}
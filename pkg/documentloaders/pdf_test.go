@@ -18,7 +18,9 @@ package documentloaders
 
 import (
 	"context"
+	"errors"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -66,3 +68,22 @@ func TestPDFLoader(t *testing.T) {
 	assert.Equal(t, docs[1].Metadata, expected2Metadata)
 	t.Logf("last doc question:%s", docs[len(docs)-1].PageContent)
 }
+
+func TestReadPDFPages(t *testing.T) {
+	t.Parallel()
+	var pages []string
+	err := readPDFPages(strings.NewReader("page 1\n\fpage 2\n\f\fpage 4\n\f"), func(page int, text string) error {
+		assert.Equal(t, len(pages)+1, page)
+		pages = append(pages, text)
+		return nil
+	})
+	require.NoError(t, err)
+	// the empty page is passed too, so the numbers of the pages after it are right
+	assert.Equal(t, []string{"page 1\n", "page 2\n", "", "page 4\n"}, pages)
+
+	failed := errors.New("failed")
+	err = readPDFPages(strings.NewReader("page 1\n\fpage 2\n\f"), func(int, string) error {
+		return failed
+	})
+	require.ErrorIs(t, err, failed)
+}
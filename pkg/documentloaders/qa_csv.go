@@ -45,6 +45,22 @@ const (
 	LineNumber = "line_number"
 	// QAFileName the qafile name
 	QAFileName = "qafile_name"
+	// TitleCol the title of the webpage, will show in reference
+	TitleCol = "title"
+	// URLCol the url of the webpage, will show in reference
+	URLCol = "url"
+	// HeadingCol the header path of a markdown chunk, like `h1 > h2`, will show in reference
+	HeadingCol = "heading"
+	// SheetCol the sheet name of an excel row, will show in reference
+	SheetCol = "sheet"
+	// SheetNumberCol the sheet number of an excel row, starts from 1
+	SheetNumberCol = "sheet_number"
+	// SlideNumberCol the slide number of a powerpoint slide, will show in reference
+	SlideNumberCol = "slide_number"
+	// ChapterNumberCol the chapter number of an epub book, starts from 1
+	ChapterNumberCol = "chapter_number"
+	// RowKeyCol the key of a table row, the documents of an updated row are replaced by it
+	RowKeyCol = "row_key"
 )
 
 // QACSV represents a QA CSV document loader.
@@ -106,22 +122,32 @@ func NewQACSV(r io.Reader, fileName string, opts ...Option) QACSV {
 	return q
 }
 
-// Load reads from the io.Reader and returns a single document with the data.
-func (c QACSV) Load(_ context.Context) ([]schema.Document, error) {
+var _ Streamer = QACSV{}
+
+// Load reads from the io.Reader and returns a document for each q/a pair.
+func (c QACSV) Load(ctx context.Context) ([]schema.Document, error) {
+	return loadAll(ctx, c)
+}
+
+// Stream reads from the io.Reader and passes the q/a pairs to handle in batches.
+func (c QACSV) Stream(ctx context.Context, batchSize int, handle func(docs []schema.Document) error) error {
+	b := newBatcher(batchSize, handle)
 	var header []string
-	var docs []schema.Document
 	var rown int
 	cols := []string{c.questionColumn, c.answerColumn, c.fileNameColumn, c.pageNumberColumn, c.chunkContentColumn}
 
 	rd := csv.NewReader(c.r)
 	rd.LazyQuotes = true
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		row, err := rd.Read()
 		if errors.Is(err, io.EOF) {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if len(header) == 0 {
 			header = append(header, row...)
@@ -155,10 +181,12 @@ func (c QACSV) Load(_ context.Context) ([]schema.Document, error) {
 		doc.Metadata[QAFileName] = c.fileName
 		doc.Metadata[LineNumber] = strconv.Itoa(rown)
 		rown++
-		docs = append(docs, doc)
+		if err := b.add(doc); err != nil {
+			return err
+		}
 	}
 
-	return docs, nil
+	return b.flush()
 }
 
 // LoadAndSplit reads text data from the io.Reader and splits it into multiple
@@ -0,0 +1,222 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package documentloaders
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tmc/langchaingo/documentloaders"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// DefaultStreamBatchSize is the default number of documents passed to the handler of Stream at a time
+const DefaultStreamBatchSize = 100
+
+// textPieceSize is the max size in bytes of a document loaded by the text loader,
+// a piece ends at the end of a line unless the line itself is longer than it
+const textPieceSize = 1 << 20
+
+// Streamer loads documents from a reader in batches, so a large file never needs to fit in memory.
+// handle is called with at most batchSize documents each time, the slice must not be retained.
+type Streamer interface {
+	Stream(ctx context.Context, batchSize int, handle func(docs []schema.Document) error) error
+}
+
+// batcher collects documents and flushes them to the handler when the batch is full
+type batcher struct {
+	size   int
+	docs   []schema.Document
+	handle func(docs []schema.Document) error
+}
+
+func newBatcher(size int, handle func(docs []schema.Document) error) *batcher {
+	if size <= 0 {
+		size = DefaultStreamBatchSize
+	}
+	return &batcher{size: size, docs: make([]schema.Document, 0, size), handle: handle}
+}
+
+func (b *batcher) add(doc schema.Document) error {
+	b.docs = append(b.docs, doc)
+	if len(b.docs) < b.size {
+		return nil
+	}
+	return b.flush()
+}
+
+func (b *batcher) flush() error {
+	if len(b.docs) == 0 {
+		return nil
+	}
+	err := b.handle(b.docs)
+	b.docs = b.docs[:0]
+	return err
+}
+
+// loadAll collects all the documents of a streamer
+func loadAll(ctx context.Context, s Streamer) ([]schema.Document, error) {
+	docs := make([]schema.Document, 0)
+	err := s.Stream(ctx, DefaultStreamBatchSize, func(batch []schema.Document) error {
+		docs = append(docs, batch...)
+		return nil
+	})
+	return docs, err
+}
+
+// Text loads a text file in pieces of lines, instead of a single document for the whole file
+type Text struct {
+	r        io.Reader
+	fileName string
+}
+
+var (
+	_ documentloaders.Loader = Text{}
+	_ Streamer               = Text{}
+)
+
+// NewText creates a new text loader with an io.Reader
+func NewText(r io.Reader, fileName string) Text {
+	return Text{r: r, fileName: fileName}
+}
+
+func (t Text) Stream(ctx context.Context, batchSize int, handle func(docs []schema.Document) error) error {
+	b := newBatcher(batchSize, handle)
+	rd := bufio.NewReaderSize(t.r, textPieceSize)
+	piece := &strings.Builder{}
+	emit := func() error {
+		if strings.TrimSpace(piece.String()) == "" {
+			piece.Reset()
+			return nil
+		}
+		doc := schema.Document{PageContent: piece.String(), Metadata: map[string]any{FileNameCol: t.fileName}}
+		piece.Reset()
+		return b.add(doc)
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// a line longer than the buffer is returned in parts with bufio.ErrBufferFull
+		line, err := rd.ReadSlice('\n')
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+			return err
+		}
+		if piece.Len() > 0 && piece.Len()+len(line) > textPieceSize {
+			if err := emit(); err != nil {
+				return err
+			}
+		}
+		piece.Write(line)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+	if err := emit(); err != nil {
+		return err
+	}
+	return b.flush()
+}
+
+func (t Text) Load(ctx context.Context) ([]schema.Document, error) {
+	return loadAll(ctx, t)
+}
+
+func (t Text) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := t.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
+
+// CSV loads a document for each row of a csv file, the content is the same as the csv loader of langchaingo
+type CSV struct {
+	r       io.Reader
+	columns []string
+}
+
+var (
+	_ documentloaders.Loader = CSV{}
+	_ Streamer               = CSV{}
+)
+
+// NewCSV creates a new csv loader with an io.Reader and optional column names for filtering.
+func NewCSV(r io.Reader, columns ...string) CSV {
+	return CSV{r: r, columns: columns}
+}
+
+func (c CSV) Stream(ctx context.Context, batchSize int, handle func(docs []schema.Document) error) error {
+	b := newBatcher(batchSize, handle)
+	filter := make(map[string]bool, len(c.columns))
+	for _, col := range c.columns {
+		filter[col] = true
+	}
+	var header []string
+	var rown int
+	rd := csv.NewReader(c.r)
+	rd.ReuseRecord = true
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		row, err := rd.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(header) == 0 {
+			header = append(header, row...)
+			continue
+		}
+		content := make([]string, 0, len(row))
+		for i, value := range row {
+			if len(filter) > 0 && !filter[header[i]] {
+				continue
+			}
+			content = append(content, fmt.Sprintf("%s: %s", header[i], value))
+		}
+		rown++
+		if err := b.add(schema.Document{
+			PageContent: strings.Join(content, "\n"),
+			Metadata:    map[string]any{"row": rown},
+		}); err != nil {
+			return err
+		}
+	}
+	return b.flush()
+}
+
+func (c CSV) Load(ctx context.Context) ([]schema.Document, error) {
+	return loadAll(ctx, c)
+}
+
+func (c CSV) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := c.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package documentloaders
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func TestTextStream(t *testing.T) {
+	t.Parallel()
+	line := strings.Repeat("a", 1023) + "\n"
+	text := strings.Repeat(line, 2500)
+	pieces := make([]int, 0)
+	err := NewText(strings.NewReader(text), "big.txt").Stream(context.Background(), 2, func(docs []schema.Document) error {
+		assert.LessOrEqual(t, len(docs), 2)
+		for _, doc := range docs {
+			assert.LessOrEqual(t, len(doc.PageContent), textPieceSize)
+			assert.True(t, strings.HasSuffix(doc.PageContent, "\n"))
+			assert.Equal(t, "big.txt", doc.Metadata[FileNameCol])
+			pieces = append(pieces, len(doc.PageContent))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	total := 0
+	for _, p := range pieces {
+		total += p
+	}
+	assert.Equal(t, len(text), total)
+	assert.Len(t, pieces, 3)
+}
+
+func TestCSVStream(t *testing.T) {
+	t.Parallel()
+	csv := &strings.Builder{}
+	csv.WriteString("name,age\n")
+	for i := 0; i < 250; i++ {
+		fmt.Fprintf(csv, "user%d,%d\n", i, i)
+	}
+	batches := 0
+	var last schema.Document
+	err := NewCSV(strings.NewReader(csv.String()), "name").Stream(context.Background(), 100, func(docs []schema.Document) error {
+		batches++
+		last = docs[len(docs)-1]
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, batches)
+	assert.Equal(t, "name: user249", last.PageContent)
+	assert.Equal(t, 250, last.Metadata["row"])
+
+	docs, err := NewQACSV(strings.NewReader("q,a\nhi,hello\n"), "qa.csv").Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "q: hi", docs[0].PageContent)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package documentloaders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tmc/langchaingo/documentloaders"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// TableColumns defines which columns of a table are used in the documents
+type TableColumns struct {
+	// Content columns are embedded, all columns are used if it is empty
+	Content []string
+	// Metadata columns are stored in the metadata of the documents
+	Metadata []string
+	// Question and Answer make the rows q/a pairs, like a QA csv file
+	Question string
+	Answer   string
+	// Key identifies a row, it is stored in the metadata of the documents
+	Key string
+}
+
+// Table loads the rows of a database table, one document for each row
+type Table struct {
+	name    string
+	columns []string
+	// next returns the next row, or io.EOF if there are no more rows
+	next    func() ([]string, error)
+	mapping TableColumns
+}
+
+var (
+	_ documentloaders.Loader = Table{}
+	_ Streamer               = Table{}
+)
+
+// NewTable creates a new table loader, name is used as the file name in the references
+func NewTable(name string, columns []string, rows [][]string, mapping TableColumns) Table {
+	return NewTableReader(name, columns, func() ([]string, error) {
+		if len(rows) == 0 {
+			return nil, io.EOF
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return row, nil
+	}, mapping)
+}
+
+// NewTableReader creates a new table loader which reads the rows by next one by one until it returns io.EOF,
+// so the rows streamed from the database never need to be in memory at the same time
+func NewTableReader(name string, columns []string, next func() ([]string, error), mapping TableColumns) Table {
+	return Table{
+		name:    name,
+		columns: columns,
+		next:    next,
+		mapping: mapping,
+	}
+}
+
+func (t Table) isQA() bool {
+	return t.mapping.Question != "" && t.mapping.Answer != ""
+}
+
+// Stream reads the rows and passes a document for each row to handle in batches.
+// For q/a rows, the document is the same as the one loaded from a QA csv file,
+// otherwise the content columns are joined as `column: value` lines.
+func (t Table) Stream(ctx context.Context, batchSize int, handle func(docs []schema.Document) error) error {
+	index := make(map[string]int, len(t.columns))
+	for i, c := range t.columns {
+		index[c] = i
+	}
+	check := func(columns ...string) error {
+		for _, c := range columns {
+			if _, ok := index[c]; !ok {
+				return fmt.Errorf("column %s not found in table %s", c, t.name)
+			}
+		}
+		return nil
+	}
+	content := t.mapping.Content
+	if t.isQA() {
+		content = []string{t.mapping.Question, t.mapping.Answer}
+	} else if len(content) == 0 {
+		content = t.columns
+	}
+	if err := check(content...); err != nil {
+		return err
+	}
+	if err := check(t.mapping.Metadata...); err != nil {
+		return err
+	}
+	if t.mapping.Key != "" {
+		if err := check(t.mapping.Key); err != nil {
+			return err
+		}
+	}
+
+	b := newBatcher(batchSize, handle)
+	for n := 0; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		row, err := t.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		doc := schema.Document{Metadata: make(map[string]any, len(t.mapping.Metadata)+3)}
+		for _, c := range t.mapping.Metadata {
+			doc.Metadata[c] = row[index[c]]
+		}
+		doc.Metadata[FileNameCol] = t.name
+		doc.Metadata[LineNumber] = strconv.Itoa(n)
+		if t.mapping.Key != "" {
+			doc.Metadata[RowKeyCol] = row[index[t.mapping.Key]]
+		}
+		if t.isQA() {
+			question := strings.TrimSpace(row[index[t.mapping.Question]])
+			if question == "" {
+				continue
+			}
+			doc.PageContent = fmt.Sprintf("%s: %s", QuestionCol, question)
+			doc.Metadata[AnswerCol] = strings.TrimSpace(row[index[t.mapping.Answer]])
+			doc.Metadata[QAFileName] = t.name
+		} else if len(content) == 1 {
+			doc.PageContent = strings.TrimSpace(row[index[content[0]]])
+		} else {
+			lines := make([]string, 0, len(content))
+			for _, c := range content {
+				lines = append(lines, fmt.Sprintf("%s: %s", c, strings.TrimSpace(row[index[c]])))
+			}
+			doc.PageContent = strings.Join(lines, "\n")
+		}
+		if doc.PageContent == "" {
+			continue
+		}
+		if err := b.add(doc); err != nil {
+			return err
+		}
+	}
+	return b.flush()
+}
+
+// Load returns a document for each row
+func (t Table) Load(ctx context.Context) ([]schema.Document, error) {
+	return loadAll(ctx, t)
+}
+
+// LoadAndSplit loads the rows and splits the content of long rows,
+// q/a rows are not split like a QA csv file.
+func (t Table) LoadAndSplit(ctx context.Context, splitter textsplitter.TextSplitter) ([]schema.Document, error) {
+	docs, err := t.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.isQA() {
+		return docs, nil
+	}
+	return textsplitter.SplitDocuments(splitter, docs)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package documentloaders
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func TestTableLoader(t *testing.T) {
+	t.Parallel()
+	columns := []string{"id", "question", "answer", "category"}
+	rows := [][]string{
+		{"1", "how to reset the password", "click forget password", "account"},
+		{"2", "", "", "account"},
+	}
+
+	docs, err := NewTable("faq", columns, rows, TableColumns{Question: "question", Answer: "answer", Metadata: []string{"category"}}).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "q: how to reset the password", docs[0].PageContent)
+	assert.Equal(t, "click forget password", docs[0].Metadata[AnswerCol])
+	assert.Equal(t, "account", docs[0].Metadata["category"])
+	assert.Equal(t, "faq", docs[0].Metadata[QAFileName])
+
+	docs, err = NewTable("faq", columns, rows, TableColumns{Content: []string{"question", "category"}}).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "question: how to reset the password\ncategory: account", docs[0].PageContent)
+
+	docs, err = NewTable("faq", columns, rows, TableColumns{Content: []string{"question"}, Key: "id"}).Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "1", docs[0].Metadata[RowKeyCol])
+
+	_, err = NewTable("faq", columns, rows, TableColumns{Content: []string{"question"}, Key: "uuid"}).Load(context.Background())
+	require.Error(t, err)
+
+	_, err = NewTable("faq", columns, rows, TableColumns{Content: []string{"title"}}).Load(context.Background())
+	require.Error(t, err)
+}
+
+func TestTableLoaderStream(t *testing.T) {
+	t.Parallel()
+	var read int
+	next := func() ([]string, error) {
+		if read == 5 {
+			return nil, io.EOF
+		}
+		read++
+		return []string{strconv.Itoa(read), "row " + strconv.Itoa(read)}, nil
+	}
+	var batches [][]string
+	err := NewTableReader("rows", []string{"id", "content"}, next, TableColumns{Content: []string{"content"}, Key: "id"}).
+		Stream(context.Background(), 2, func(docs []schema.Document) error {
+			// the rows are read batch by batch
+			assert.LessOrEqual(t, read, (len(batches)+1)*2+1)
+			keys := make([]string, 0, len(docs))
+			for _, doc := range docs {
+				keys = append(keys, doc.Metadata[RowKeyCol].(string))
+			}
+			batches = append(batches, keys)
+			return nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"1", "2"}, {"3", "4"}, {"5"}}, batches)
+
+	failed := errors.New("connection lost")
+	err = NewTableReader("rows", []string{"id"}, func() ([]string, error) { return nil, failed }, TableColumns{}).
+		Stream(context.Background(), 2, func(docs []schema.Document) error { return nil })
+	require.ErrorIs(t, err, failed)
+}
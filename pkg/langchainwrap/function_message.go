@@ -0,0 +1,127 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package langchainwrap
+
+import (
+	"encoding/json"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+)
+
+const (
+	// functionCallPart and functionResultPart are the first text parts of the messages of a function call and its result,
+	// the second part is the function call or result in json
+	functionCallPart   = "__function_call__"
+	functionResultPart = "__function_result__"
+)
+
+// functionMessage is a function call of the assistant, or the result of the function call
+type functionMessage struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+// FunctionCallMessage returns the message of the assistant calling a function with the arguments in json.
+// The openai llm of langchaingo can't send the function calls in the messages, so the function call is encoded
+// in the text parts, and is converted to function_call, or tool_calls if tool calling is enabled,
+// by the http clients of the OpenAI compatible llms.
+func FunctionCallMessage(id, name, arguments string) llms.MessageContent {
+	return encodeFunctionMessage(schema.ChatMessageTypeAI, functionCallPart, functionMessage{ID: id, Name: name, Arguments: arguments})
+}
+
+// FunctionResultMessage returns the message of the result of the function call with the id,
+// it is converted to a function message, or a tool message if tool calling is enabled.
+func FunctionResultMessage(id, name, result string) llms.MessageContent {
+	return encodeFunctionMessage(schema.ChatMessageTypeHuman, functionResultPart, functionMessage{ID: id, Name: name, Content: result})
+}
+
+func encodeFunctionMessage(role schema.ChatMessageType, kind string, message functionMessage) llms.MessageContent {
+	data, _ := json.Marshal(message) //nolint:errchkjson
+	return llms.MessageContent{
+		Role:  role,
+		Parts: []llms.ContentPart{llms.TextContent{Text: kind}, llms.TextContent{Text: string(data)}},
+	}
+}
+
+// convertFunctionMessages converts the encoded function calls and results in the messages of a chat request
+// to the messages of the OpenAI api
+func convertFunctionMessages(request map[string]any, toolCalling bool) bool {
+	messages, _ := request["messages"].([]any)
+	changed := false
+	for i, m := range messages {
+		message, ok := m.(map[string]any)
+		if !ok {
+			continue
+		}
+		kind, function, ok := decodeFunctionMessage(message)
+		if !ok {
+			continue
+		}
+		switch {
+		case kind == functionCallPart && toolCalling:
+			messages[i] = map[string]any{
+				"role":    "assistant",
+				"content": "",
+				"tool_calls": []any{map[string]any{
+					"id":       function.ID,
+					"type":     "function",
+					"function": map[string]any{"name": function.Name, "arguments": function.Arguments},
+				}},
+			}
+		case kind == functionCallPart:
+			messages[i] = map[string]any{
+				"role":          "assistant",
+				"content":       "",
+				"function_call": map[string]any{"name": function.Name, "arguments": function.Arguments},
+			}
+		case toolCalling:
+			messages[i] = map[string]any{"role": "tool", "tool_call_id": function.ID, "content": function.Content}
+		default:
+			messages[i] = map[string]any{"role": "function", "name": function.Name, "content": function.Content}
+		}
+		changed = true
+	}
+	return changed
+}
+
+// decodeFunctionMessage returns the function call or result encoded in the content of a message
+func decodeFunctionMessage(message map[string]any) (string, functionMessage, bool) {
+	var function functionMessage
+	parts, ok := message["content"].([]any)
+	if !ok || len(parts) != 2 {
+		return "", function, false
+	}
+	texts := make([]string, 0, len(parts))
+	for _, p := range parts {
+		part, _ := p.(map[string]any)
+		text, ok := part["text"].(string)
+		if !ok {
+			return "", function, false
+		}
+		texts = append(texts, text)
+	}
+	if texts[0] != functionCallPart && texts[0] != functionResultPart {
+		return "", function, false
+	}
+	if err := json.Unmarshal([]byte(texts[1]), &function); err != nil {
+		return "", function, false
+	}
+	return texts[0], function, true
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 
 	langchainllms "github.com/tmc/langchaingo/llms"
@@ -39,6 +40,14 @@ const (
 	GatewayUseExternalURLEnv = "GATEWAY_USE_EXTERNAL_URL"
 )
 
+// openAIHTTPClient returns the http.Client of the OpenAI compatible llm, the tool calls are converted if enabled
+func openAIHTTPClient(llm *v1alpha1.LLM) *http.Client {
+	if llm.Spec.ToolCalling {
+		return ToolCallHTTPClient
+	}
+	return FunctionCallHTTPClient
+}
+
 func GetLangchainLLM(ctx context.Context, llm *v1alpha1.LLM, c client.Client, model string) (langchainllms.Model, error) {
 	switch llm.Spec.Provider.GetType() {
 	case v1alpha1.ProviderType3rdParty:
@@ -61,7 +70,7 @@ func GetLangchainLLM(ctx context.Context, llm *v1alpha1.LLM, c client.Client, mo
 				}
 				model = models[0]
 			}
-			return openai.New(openai.WithToken(apiKey), openai.WithBaseURL(llm.Get3rdPartyLLMBaseURL()), openai.WithModel(model), openai.WithCallback(log.KLogHandler{LogLevel: 3}), openai.WithHTTPClient(DebugHTTPClient))
+			return openai.New(openai.WithToken(apiKey), openai.WithBaseURL(llm.Get3rdPartyLLMBaseURL()), openai.WithModel(model), openai.WithCallback(log.KLogHandler{LogLevel: 3}), openai.WithHTTPClient(openAIHTTPClient(llm)))
 		case llms.Gemini:
 			if model == "" {
 				models := llm.GetModelList()
@@ -104,7 +113,7 @@ func GetLangchainLLM(ctx context.Context, llm *v1alpha1.LLM, c client.Client, mo
 		if os.Getenv(GatewayUseExternalURLEnv) == "true" {
 			gatewayURL = gateway.ExternalAPIServer
 		}
-		return openai.New(openai.WithModel(modelName), openai.WithBaseURL(gatewayURL), openai.WithToken("fake"), openai.WithCallback(log.KLogHandler{LogLevel: 3}), openai.WithHTTPClient(DebugHTTPClient))
+		return openai.New(openai.WithModel(modelName), openai.WithBaseURL(gatewayURL), openai.WithToken("fake"), openai.WithCallback(log.KLogHandler{LogLevel: 3}), openai.WithHTTPClient(openAIHTTPClient(llm)))
 	}
 	return nil, fmt.Errorf("unknown provider type")
 }
@@ -0,0 +1,344 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package langchainwrap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrMultipleToolCalls is returned when the llm calls more than one tool in a response, even if parallel_tool_calls
+// is false in the request, because langchaingo only reads one function call in a response.
+var ErrMultipleToolCalls = errors.New("the llm returned more than one tool call, but only one is supported")
+
+// FunctionCallHTTPClient is the http.Client used by the OpenAI compatible llms.
+// Besides logging like DebugHTTPClient, it converts the function calls and results encoded by FunctionCallMessage
+// and FunctionResultMessage to the function messages.
+var FunctionCallHTTPClient = &http.Client{ //nolint:gochecknoglobals
+	Transport: &toolCallTransport{Transport: DebugHTTPClient.Transport},
+}
+
+// ToolCallHTTPClient is the http.Client used by the OpenAI compatible llms with tool calling enabled.
+// Besides logging like DebugHTTPClient, it converts the deprecated functions in the requests sent by langchaingo
+// to tools, the encoded function calls and results to the tool messages, and converts the tool_calls in the responses
+// back to function_call, so function calling works with the models only support tools, like the ones served by vllm.
+var ToolCallHTTPClient = &http.Client{ //nolint:gochecknoglobals
+	Transport: &toolCallTransport{Transport: DebugHTTPClient.Transport, ToolCalling: true},
+}
+
+type toolCallTransport struct {
+	Transport http.RoundTripper
+	// ToolCalling converts the functions to tools
+	ToolCalling bool
+}
+
+func (t *toolCallTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost || !strings.HasSuffix(req.URL.Path, "/chat/completions") || req.Body == nil {
+		return t.Transport.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	request := make(map[string]any)
+	tools := false
+	if err := json.Unmarshal(body, &request); err == nil {
+		messages := convertFunctionMessages(request, t.ToolCalling)
+		tools = t.ToolCalling && functionsToTools(request)
+		if messages || tools {
+			if converted, err := json.Marshal(request); err == nil {
+				body = converted
+			}
+		}
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	resp, err := t.Transport.RoundTrip(req)
+	if err != nil || !tools || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "event-stream") {
+		stream := &toolCallStream{reader: bufio.NewReader(resp.Body), body: resp.Body}
+		if err := stream.prefetch(); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		resp.Body = stream
+		resp.ContentLength = -1
+		resp.Header.Del("Content-Length")
+		return resp, nil
+	}
+	if !strings.Contains(contentType, "json") {
+		return resp, nil
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	convertedResp, err := toolCallsToFunctionCall(respBody)
+	if err != nil {
+		return nil, err
+	}
+	if convertedResp != nil {
+		respBody = convertedResp
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	resp.ContentLength = int64(len(respBody))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(respBody)))
+	return resp, nil
+}
+
+// functionsToTools converts the functions and function_call in a chat request to tools and tool_choice
+func functionsToTools(request map[string]any) bool {
+	functions, ok := request["functions"].([]any)
+	if !ok || len(functions) == 0 {
+		return false
+	}
+	tools := make([]any, 0, len(functions))
+	for _, f := range functions {
+		tools = append(tools, map[string]any{"type": "function", "function": f})
+	}
+	request["tools"] = tools
+	// langchaingo reads one function call in a response, so the tools are called one by one
+	request["parallel_tool_calls"] = false
+	delete(request, "functions")
+	if behavior, ok := request["function_call"]; ok {
+		if name, ok := behavior.(map[string]any); ok {
+			request["tool_choice"] = map[string]any{"type": "function", "function": name}
+		} else {
+			request["tool_choice"] = behavior
+		}
+		delete(request, "function_call")
+	}
+	return true
+}
+
+// toolCallsToFunctionCall converts the tool call of each choice to function_call,
+// langchaingo only reads function_call when finish_reason is function_call.
+// It returns nil if there is nothing to convert, and ErrMultipleToolCalls if a choice has more than one tool call.
+func toolCallsToFunctionCall(body []byte) ([]byte, error) {
+	response := make(map[string]any)
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, nil
+	}
+	choices, _ := response["choices"].([]any)
+	changed := false
+	for _, c := range choices {
+		choice, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		message, ok := choice["message"].(map[string]any)
+		if !ok {
+			continue
+		}
+		toolCalls, ok := message["tool_calls"].([]any)
+		if !ok || len(toolCalls) == 0 {
+			continue
+		}
+		if len(toolCalls) > 1 {
+			return nil, fmt.Errorf("%w: got %d tool calls", ErrMultipleToolCalls, len(toolCalls))
+		}
+		toolCall, ok := toolCalls[0].(map[string]any)
+		if !ok {
+			continue
+		}
+		function, ok := toolCall["function"].(map[string]any)
+		if !ok || !stringArguments(function) {
+			continue
+		}
+		message["function_call"] = function
+		delete(message, "tool_calls")
+		choice["finish_reason"] = "function_call"
+		changed = true
+	}
+	if !changed {
+		return nil, nil
+	}
+	return json.Marshal(response)
+}
+
+// stringArguments marshals the arguments of a function to a string,
+// some servers return the arguments as an object instead of a string
+func stringArguments(function map[string]any) bool {
+	args, ok := function["arguments"]
+	if !ok {
+		return true
+	}
+	if _, isString := args.(string); isString {
+		return true
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return false
+	}
+	function["arguments"] = string(data)
+	return true
+}
+
+// toolCallStream converts the tool calls in the deltas of a streaming chat response to function_call line by line.
+// The tool call is split into several deltas, the first one has the id and the name of the function,
+// and the rest ones have the pieces of the arguments.
+type toolCallStream struct {
+	reader *bufio.Reader
+	body   io.Closer
+	// pending is the converted data not read yet
+	pending bytes.Buffer
+	err     error
+
+	// toolCallID is the id of the tool call in the response
+	toolCallID string
+	// content is true if any content is streamed
+	content bool
+	// calling is true if any tool call is streamed
+	calling bool
+}
+
+// prefetch reads the stream until the first content or tool call. The deltas of a tool call are not streamed to the
+// user, so the whole response is read and converted, and the error, like ErrMultipleToolCalls, is returned by RoundTrip.
+// Otherwise, the error of the rest of the stream is returned by Read.
+func (s *toolCallStream) prefetch() error {
+	for !s.content && !s.calling {
+		if err := s.next(); err != nil {
+			return s.end(err)
+		}
+	}
+	for s.calling {
+		if err := s.next(); err != nil {
+			return s.end(err)
+		}
+	}
+	return nil
+}
+
+func (s *toolCallStream) end(err error) error {
+	if errors.Is(err, io.EOF) {
+		s.err = io.EOF
+		return nil
+	}
+	return err
+}
+
+func (s *toolCallStream) Read(p []byte) (int, error) {
+	for s.pending.Len() == 0 && s.err == nil {
+		s.err = s.next()
+	}
+	if s.pending.Len() > 0 {
+		return s.pending.Read(p)
+	}
+	return 0, s.err
+}
+
+func (s *toolCallStream) Close() error {
+	return s.body.Close()
+}
+
+// next reads and converts a line of the stream
+func (s *toolCallStream) next() error {
+	line, err := s.reader.ReadString('\n')
+	if line == "" {
+		return err
+	}
+	converted, convertErr := s.convert(line)
+	if convertErr != nil {
+		return convertErr
+	}
+	s.pending.WriteString(converted)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// convert converts the tool calls in a data line of the stream to function_call
+func (s *toolCallStream) convert(line string) (string, error) {
+	data, ok := strings.CutPrefix(strings.TrimRight(line, "\r\n"), "data:")
+	if !ok {
+		return line, nil
+	}
+	data = strings.TrimSpace(data)
+	chunk := make(map[string]any)
+	if data == "[DONE]" || json.Unmarshal([]byte(data), &chunk) != nil {
+		return line, nil
+	}
+	choices, _ := chunk["choices"].([]any)
+	changed := false
+	for _, c := range choices {
+		choice, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if reason, _ := choice["finish_reason"].(string); reason == "tool_calls" {
+			choice["finish_reason"] = "function_call"
+			changed = true
+		}
+		delta, ok := choice["delta"].(map[string]any)
+		if !ok {
+			continue
+		}
+		if content, _ := delta["content"].(string); content != "" {
+			s.content = true
+		}
+		toolCalls, ok := delta["tool_calls"].([]any)
+		if !ok {
+			continue
+		}
+		if len(toolCalls) > 1 {
+			return "", fmt.Errorf("%w: got %d tool calls", ErrMultipleToolCalls, len(toolCalls))
+		}
+		delete(delta, "tool_calls")
+		changed = true
+		if len(toolCalls) == 0 {
+			continue
+		}
+		toolCall, ok := toolCalls[0].(map[string]any)
+		if !ok {
+			continue
+		}
+		index, _ := toolCall["index"].(float64)
+		id, _ := toolCall["id"].(string)
+		if index != 0 || (id != "" && s.toolCallID != "" && id != s.toolCallID) {
+			return "", fmt.Errorf("%w: got the tool call %v at index %v", ErrMultipleToolCalls, id, index)
+		}
+		if id != "" {
+			s.toolCallID = id
+		}
+		s.calling = true
+		if function, ok := toolCall["function"].(map[string]any); ok && stringArguments(function) {
+			delta["function_call"] = function
+		}
+	}
+	if !changed {
+		return line, nil
+	}
+	res, err := json.Marshal(chunk)
+	if err != nil {
+		return "", err
+	}
+	return "data: " + string(res) + "\n", nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package langchainwrap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/openai"
+	"github.com/tmc/langchaingo/schema"
+)
+
+func TestToolCallTransport(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := make(map[string]any)
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		tools, _ := request["tools"].([]any)
+		if _, ok := request["functions"]; ok || len(tools) != 1 || request["parallel_tool_calls"] != false {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","content":null,` +
+			`"tool_calls":[{"id":"call_1","type":"function","function":{"name":"weather","arguments":{"city":"Beijing"}}}]}}]}`))
+	}))
+	defer server.Close()
+
+	llm, err := openai.New(openai.WithToken("fake"), openai.WithBaseURL(server.URL), openai.WithModel("fake"), openai.WithHTTPClient(ToolCallHTTPClient))
+	require.NoError(t, err)
+	resp, err := llm.GenerateContent(context.Background(),
+		[]llms.MessageContent{llms.TextParts(schema.ChatMessageTypeHuman, "how is the weather in Beijing")},
+		llms.WithFunctions([]llms.FunctionDefinition{{Name: "weather", Description: "get the weather", Parameters: map[string]any{"type": "object"}}}))
+	require.NoError(t, err)
+	require.NotNil(t, resp.Choices[0].FuncCall)
+	require.Equal(t, "weather", resp.Choices[0].FuncCall.Name)
+	require.JSONEq(t, `{"city":"Beijing"}`, resp.Choices[0].FuncCall.Arguments)
+}
+
+func TestToolCallTransportMultipleToolCalls(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","content":null,"tool_calls":[` +
+			`{"id":"call_1","type":"function","function":{"name":"weather","arguments":"{\"city\":\"Beijing\"}"}},` +
+			`{"id":"call_2","type":"function","function":{"name":"weather","arguments":"{\"city\":\"Shanghai\"}"}}]}}]}`))
+	}))
+	defer server.Close()
+
+	llm, err := openai.New(openai.WithToken("fake"), openai.WithBaseURL(server.URL), openai.WithModel("fake"), openai.WithHTTPClient(ToolCallHTTPClient))
+	require.NoError(t, err)
+	_, err = llm.GenerateContent(context.Background(),
+		[]llms.MessageContent{llms.TextParts(schema.ChatMessageTypeHuman, "how is the weather in Beijing and Shanghai")},
+		llms.WithFunctions([]llms.FunctionDefinition{{Name: "weather", Description: "get the weather", Parameters: map[string]any{"type": "object"}}}))
+	require.ErrorIs(t, err, ErrMultipleToolCalls)
+}
+
+func TestToolCallTransportStreaming(t *testing.T) {
+	t.Parallel()
+	chunks := map[string][]string{
+		"weather": {
+			`{"choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}`,
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"weather","arguments":""}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Beijing\"}"}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		},
+		"answer": {
+			`{"choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}`,
+			`{"choices":[{"index":0,"delta":{"content":"It is "}}]}`,
+			`{"choices":[{"index":0,"delta":{"content":"sunny."}}]}`,
+			`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		},
+		"parallel": {
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"weather","arguments":"{}"}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"weather","arguments":"{}"}}]}}]}`,
+			`{"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := make(map[string]any)
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		tools, _ := request["tools"].([]any)
+		if _, ok := request["functions"]; ok || len(tools) != 1 || request["stream"] != true {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// the question is the key of the chunks to return
+		var question string
+		messages, _ := request["messages"].([]any)
+		if content, _ := messages[0].(map[string]any)["content"].([]any); len(content) > 0 {
+			question, _ = content[0].(map[string]any)["text"].(string)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks[question] {
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	llm, err := openai.New(openai.WithToken("fake"), openai.WithBaseURL(server.URL), openai.WithModel("fake"), openai.WithHTTPClient(ToolCallHTTPClient))
+	require.NoError(t, err)
+	generate := func(question string) (*llms.ContentResponse, string, error) {
+		var streamed string
+		resp, err := llm.GenerateContent(context.Background(),
+			[]llms.MessageContent{llms.TextParts(schema.ChatMessageTypeHuman, question)},
+			llms.WithFunctions([]llms.FunctionDefinition{{Name: "weather", Description: "get the weather", Parameters: map[string]any{"type": "object"}}}),
+			llms.WithStreamingFunc(func(_ context.Context, chunk []byte) error {
+				streamed += string(chunk)
+				return nil
+			}))
+		return resp, streamed, err
+	}
+
+	resp, _, err := generate("weather")
+	require.NoError(t, err)
+	require.NotNil(t, resp.Choices[0].FuncCall)
+	require.Equal(t, "weather", resp.Choices[0].FuncCall.Name)
+	require.JSONEq(t, `{"city":"Beijing"}`, resp.Choices[0].FuncCall.Arguments)
+
+	resp, streamed, err := generate("answer")
+	require.NoError(t, err)
+	require.Nil(t, resp.Choices[0].FuncCall)
+	require.Equal(t, "It is sunny.", resp.Choices[0].Content)
+	require.Equal(t, "It is sunny.", streamed)
+
+	_, _, err = generate("parallel")
+	require.ErrorIs(t, err, ErrMultipleToolCalls)
+}
+
+func TestFunctionMessages(t *testing.T) {
+	t.Parallel()
+	var messages []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := make(map[string]any)
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		messages, _ = request["messages"].([]any)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"It is sunny."}}]}`))
+	}))
+	defer server.Close()
+
+	for client, expected := range map[*http.Client]string{
+		FunctionCallHTTPClient: `[{"role":"user","content":[{"type":"text","text":"how is the weather in Beijing"}]},` +
+			`{"role":"assistant","content":"","function_call":{"name":"weather","arguments":"{\"city\":\"Beijing\"}"}},` +
+			`{"role":"function","name":"weather","content":"sunny"}]`,
+		ToolCallHTTPClient: `[{"role":"user","content":[{"type":"text","text":"how is the weather in Beijing"}]},` +
+			`{"role":"assistant","content":"","tool_calls":[{"id":"call_0","type":"function","function":{"name":"weather","arguments":"{\"city\":\"Beijing\"}"}}]},` +
+			`{"role":"tool","tool_call_id":"call_0","content":"sunny"}]`,
+	} {
+		llm, err := openai.New(openai.WithToken("fake"), openai.WithBaseURL(server.URL), openai.WithModel("fake"), openai.WithHTTPClient(client))
+		require.NoError(t, err)
+		resp, err := llm.GenerateContent(context.Background(), []llms.MessageContent{
+			llms.TextParts(schema.ChatMessageTypeHuman, "how is the weather in Beijing"),
+			FunctionCallMessage("call_0", "weather", `{"city":"Beijing"}`),
+			FunctionResultMessage("call_0", "weather", "sunny"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "It is sunny.", resp.Choices[0].Content)
+		data, err := json.Marshal(messages)
+		require.NoError(t, err)
+		require.JSONEq(t, expected, string(data))
+	}
+}
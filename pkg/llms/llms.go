@@ -52,6 +52,19 @@ var (
 )
 var ZhiPuAIModels = []string{ZhiPuAILite, ZhiPuAIStd, ZhiPuAIPro, ZhiPuAITurbo, ZhiPuAIGLM3Turbo, ZhiPuAIGLM4}
 
+// ModelContextLengths are the max context lengths of the models of the 3rd party llms,
+// the context length of a model served by a worker is in the spec of the Model
+var ModelContextLengths = map[string]int{
+	"gpt-3.5-turbo":  16385,
+	"gpt-4":          8192,
+	"gpt-4-turbo":    128000,
+	"gpt-4o":         128000,
+	"gemini-pro":     30720,
+	ZhiPuAITurbo:     32768,
+	ZhiPuAIGLM3Turbo: 128000,
+	ZhiPuAIGLM4:      128000,
+}
+
 type LLM interface {
 	Type() LLMType
 	Call([]byte) (Response, error)
@@ -61,6 +61,7 @@ func (e *executor) generateJob(ctx context.Context, jobCh chan<- JobPayload, dat
 		dstPrefix := fmt.Sprintf("dataset/%s/%s/", e.instance.Spec.Dataset.Name, e.instance.Spec.Version)
 
 		var srcBucket, srcPrefix string
+		crawl := false
 		if !removeAction {
 			switch fs.Kind {
 			case "Datasource":
@@ -77,6 +78,8 @@ func (e *executor) generateJob(ctx context.Context, jobCh chan<- JobPayload, dat
 				if ds.Spec.OSS != nil {
 					srcBucket = ds.Spec.OSS.Bucket
 				}
+				// the web crawler files are put into the versioneddataset by the apiserver, and the pages are crawled
+				crawl = ds.Spec.Type() == v1alpha1.DatasourceTypeWeb
 			case "VersionedDataset":
 				srcVersion := fs.Name[len(v1alpha1.InheritedFromVersionName):]
 				srcBucket = e.instance.Namespace
@@ -126,6 +129,7 @@ func (e *executor) generateJob(ctx context.Context, jobCh chan<- JobPayload, dat
 				DstBucket:  dstBucket,
 				Oss:        e.oss,
 				Remove:     removeAction,
+				Crawl:      crawl && isWebCrawlerFile(targetPath),
 			}
 
 			klog.V(4).Infof("[Debug] send %+v to jobch", payload)
@@ -143,6 +147,9 @@ func (e *executor) GenerateJob(ctx context.Context, jobCh chan<- JobPayload) err
 }
 
 func (e *executor) Task(ctx context.Context, job JobPayload) error {
+	if job.Crawl {
+		return crawlWebPages(ctx, job)
+	}
 	if !job.Remove {
 		klog.V(4).Infof("[Debug] copyObject task from %s/%s to %s/%s", job.SrcBucket, job.Src, job.DstBucket, job.Dst)
 		_, err := job.Oss.Client.CopyObject(ctx, minio.CopyDestOptions{
@@ -156,6 +163,11 @@ func (e *executor) Task(ctx context.Context, job JobPayload) error {
 		return err
 	}
 
+	if isWebCrawlerFile(job.Dst) {
+		if err := removeWebPages(ctx, job); err != nil {
+			return err
+		}
+	}
 	err := job.Oss.Client.RemoveObject(ctx, job.DstBucket, job.Dst, minio.RemoveObjectOptions{})
 	klog.V(4).Infof("[Debug] removeObject %s/%s result %s", job.DstBucket, job.Dst, err)
 
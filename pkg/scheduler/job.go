@@ -26,4 +26,6 @@ type JobPayload struct {
 
 	Oss    *datasource.OSS
 	Remove bool
+	// Crawl is true if Dst is a web crawler file, whose pages are crawled instead of copying the file
+	Crawl bool
 }
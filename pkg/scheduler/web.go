@@ -0,0 +1,118 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio-go/v7"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/pkg/datasource"
+)
+
+// webCrawls serializes the crawls into the same pages prefix, so a crawl never removes the pages of another one
+var webCrawls sync.Map
+
+// crawlWebPages crawls the website with the options in the web crawler file job.Dst, and stores the pages as html
+// files under the directory of the file. The files under the directory which are not crawled this time are removed.
+func crawlWebPages(ctx context.Context, job JobPayload) error {
+	prefix := datasource.WebPagesPrefix(job.Dst)
+	v, _ := webCrawls.LoadOrStore(job.DstBucket+"/"+prefix, &sync.Mutex{})
+	lock := v.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+
+	obj, err := job.Oss.Client.GetObject(ctx, job.DstBucket, job.Dst, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	content, err := io.ReadAll(obj)
+	obj.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read the web crawler file: %w", err)
+	}
+	var options datasource.CrawlOptions
+	if err := json.Unmarshal(content, &options); err != nil {
+		return fmt.Errorf("invalid web crawler file: %w", err)
+	}
+	web, err := datasource.NewWeb(ctx, options.URL)
+	if err != nil {
+		return err
+	}
+
+	logger := klog.FromContext(ctx).WithValues("url", options.URL, "bucket", job.DstBucket, "prefix", prefix)
+	logger.Info("start to crawl web pages")
+	crawled := make(map[string]bool)
+	err = web.Crawl(ctx, options, func(ctx context.Context, page *datasource.WebPage) error {
+		object := prefix + page.ObjectName()
+		if _, err := job.Oss.Client.PutObject(ctx, job.DstBucket, object, bytes.NewReader(page.Content), int64(len(page.Content)), minio.PutObjectOptions{
+			ContentType: "text/html",
+			UserTags:    page.Tags(),
+		}); err != nil {
+			return err
+		}
+		crawled[object] = true
+		logger.V(3).Info("web page crawled", "page", page.URL, "object", object, "crawled", len(crawled))
+		return nil
+	})
+	if err != nil {
+		// keep the pages of the last crawl if this one fails
+		return fmt.Errorf("failed to crawl web pages after %d pages: %w", len(crawled), err)
+	}
+	for obj := range job.Oss.Client.ListObjects(ctx, job.DstBucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return obj.Err
+		}
+		if crawled[obj.Key] {
+			continue
+		}
+		if err := job.Oss.Client.RemoveObject(ctx, job.DstBucket, obj.Key, minio.RemoveObjectOptions{}); err != nil {
+			return fmt.Errorf("failed to remove stale web page %s: %w", obj.Key, err)
+		}
+	}
+	logger.Info("crawl web pages done", "crawled", len(crawled))
+	return nil
+}
+
+// removeWebPages removes the pages crawled by the web crawler file job.Dst
+func removeWebPages(ctx context.Context, job JobPayload) error {
+	prefix := datasource.WebPagesPrefix(job.Dst)
+	v, _ := webCrawls.LoadOrStore(job.DstBucket+"/"+prefix, &sync.Mutex{})
+	lock := v.(*sync.Mutex)
+	lock.Lock()
+	defer lock.Unlock()
+	var err error
+	for e := range job.Oss.Client.RemoveObjects(ctx, job.DstBucket, job.Oss.Client.ListObjects(ctx, job.DstBucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	}), minio.RemoveObjectsOptions{}) {
+		err = e.Err
+		klog.Errorf("failed to remove web page %s: %s", e.ObjectName, e.Err)
+	}
+	return err
+}
+
+func isWebCrawlerFile(path string) bool {
+	return strings.HasSuffix(path, datasource.WebCrawlerFileSuffix)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package splitter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/textsplitter"
+
+	"github.com/kubeagi/arcadia/pkg/documentloaders"
+)
+
+const headingSeparator = " > "
+
+var headingRegexp = regexp.MustCompile(`^(#{1,6})\s+(.*?)[\s#]*$`)
+
+// Markdown splits markdown by headers first, then splits the long sections with the recursive character splitter.
+// The header path of a chunk, like `h1 > h2`, is stored in the metadata documentloaders.HeadingCol.
+type Markdown struct {
+	splitter textsplitter.TextSplitter
+}
+
+// section is the content under a header
+type section struct {
+	heading string
+	content string
+}
+
+func splitSections(text string) []section {
+	sections := make([]section, 0)
+	headings := make([]string, 0, 6)
+	levels := make([]int, 0, 6)
+	content := &strings.Builder{}
+	flush := func() {
+		if c := strings.TrimSpace(content.String()); c != "" {
+			sections = append(sections, section{heading: strings.Join(headings, headingSeparator), content: c})
+		}
+		content.Reset()
+	}
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if m := headingRegexp.FindStringSubmatch(trimmed); !inFence && m != nil {
+			flush()
+			level := len(m[1])
+			for len(levels) > 0 && levels[len(levels)-1] >= level {
+				levels = levels[:len(levels)-1]
+				headings = headings[:len(headings)-1]
+			}
+			levels = append(levels, level)
+			headings = append(headings, m[2])
+			continue
+		}
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+	flush()
+	return sections
+}
+
+func (m *Markdown) SplitText(text string) ([]string, error) {
+	res := make([]string, 0)
+	for _, s := range splitSections(text) {
+		chunks, err := m.splitter.SplitText(s.content)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, chunks...)
+	}
+	return res, nil
+}
+
+func (m *Markdown) SplitDocuments(docs []schema.Document) ([]schema.Document, error) {
+	res := make([]schema.Document, 0, len(docs))
+	for _, doc := range docs {
+		for _, s := range splitSections(doc.PageContent) {
+			chunks, err := m.splitter.SplitText(s.content)
+			if err != nil {
+				return nil, err
+			}
+			for _, chunk := range chunks {
+				metadata := make(map[string]any, len(doc.Metadata)+1)
+				for k, v := range doc.Metadata {
+					metadata[k] = v
+				}
+				if s.heading != "" {
+					metadata[documentloaders.HeadingCol] = s.heading
+				}
+				res = append(res, schema.Document{PageContent: chunk, Metadata: metadata})
+			}
+		}
+	}
+	return res, nil
+}
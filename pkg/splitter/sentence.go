@@ -0,0 +1,206 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package splitter
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// breakpointPercentile is the percentile of the distances between sentences,
+// the semantic splitter starts a new chunk where the distance is larger than it
+const breakpointPercentile = 95
+
+// splitSentences splits text into sentences, the whitespace after a sentence is kept in it,
+// so joining the sentences gives the original text
+func splitSentences(text string) []string {
+	sentences := make([]string, 0)
+	runes := []rune(text)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		end := false
+		switch runes[i] {
+		case '。', '！', '？', '；', '…', '\n':
+			end = true
+		case '.', '!', '?', ';':
+			// the dots in numbers, urls and abbreviations like `e.g.` are not followed by a space
+			end = i+1 == len(runes) || unicode.IsSpace(runes[i+1])
+		}
+		if !end {
+			continue
+		}
+		for i+1 < len(runes) && unicode.IsSpace(runes[i+1]) {
+			i++
+		}
+		sentences = append(sentences, string(runes[start:i+1]))
+		start = i + 1
+	}
+	if start < len(runes) {
+		sentences = append(sentences, string(runes[start:]))
+	}
+	return sentences
+}
+
+// Sentence merges sentences into chunks no longer than ChunkSize, and never splits in the middle of a sentence
+// unless the sentence itself is longer than ChunkSize. The overlap is made of whole sentences too.
+type Sentence struct {
+	ChunkSize    int
+	ChunkOverlap int
+	// splitter splits the sentences longer than the chunk size
+	splitter textsplitter.TextSplitter
+}
+
+func (s *Sentence) SplitText(text string) ([]string, error) {
+	chunks := make([]string, 0)
+	current := make([]string, 0)
+	currentLen := 0
+	flush := func() {
+		if chunk := strings.TrimSpace(strings.Join(current, "")); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+	}
+	for _, sentence := range splitSentences(text) {
+		length := utf8.RuneCountInString(sentence)
+		if length > s.ChunkSize {
+			flush()
+			current, currentLen = current[:0], 0
+			long, err := s.splitter.SplitText(sentence)
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, long...)
+			continue
+		}
+		if currentLen > 0 && currentLen+length > s.ChunkSize {
+			flush()
+			// keep the last sentences as the overlap of the next chunk
+			overlap, overlapLen := 0, 0
+			for i := len(current) - 1; i >= 0; i-- {
+				l := utf8.RuneCountInString(current[i])
+				if overlapLen+l > s.ChunkOverlap || overlapLen+l+length > s.ChunkSize {
+					break
+				}
+				overlap++
+				overlapLen += l
+			}
+			current = append(current[:0], current[len(current)-overlap:]...)
+			currentLen = overlapLen
+		}
+		current = append(current, sentence)
+		currentLen += length
+	}
+	flush()
+	return chunks, nil
+}
+
+// Semantic embeds each sentence and starts a new chunk where the meaning of adjacent sentences changes a lot,
+// or the chunk would be longer than ChunkSize.
+type Semantic struct {
+	ctx       context.Context
+	embedder  embeddings.Embedder
+	ChunkSize int
+	// sentence is used to split the sentences longer than the chunk size
+	sentence *Sentence
+}
+
+func (s *Semantic) SplitText(text string) ([]string, error) {
+	sentences := make([]string, 0)
+	for _, sentence := range splitSentences(text) {
+		if strings.TrimSpace(sentence) != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+	if len(sentences) < 3 {
+		return s.sentence.SplitText(text)
+	}
+	vectors, err := s.embedder.EmbedDocuments(s.ctx, sentences)
+	if err != nil {
+		return nil, err
+	}
+	distances := make([]float64, len(sentences)-1)
+	for i := range distances {
+		distances[i] = 1 - cosine(vectors[i], vectors[i+1])
+	}
+	threshold := percentile(distances, breakpointPercentile)
+
+	chunks := make([]string, 0)
+	current := &strings.Builder{}
+	currentLen := 0
+	flush := func() error {
+		if currentLen == 0 {
+			return nil
+		}
+		// only the sentences longer than the chunk size make a chunk too long
+		parts, err := s.sentence.SplitText(current.String())
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, parts...)
+		current.Reset()
+		currentLen = 0
+		return nil
+	}
+	for i, sentence := range sentences {
+		length := utf8.RuneCountInString(sentence)
+		if currentLen > 0 && (currentLen+length > s.ChunkSize || distances[i-1] > threshold) {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+		current.WriteString(sentence)
+		currentLen += length
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		if i >= len(b) {
+			break
+		}
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// percentile interpolates linearly between the closest ranks, like numpy.percentile
+func percentile(values []float64, p int) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := float64(p) / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package splitter creates the text splitters selected by the splitter type of knowledgebases and document loaders.
+package splitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/tmc/langchaingo/embeddings"
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/textsplitter"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+)
+
+var ErrNoEmbedder = errors.New("semantic splitter needs an embedder")
+
+// Splitter splits documents into chunks, the metadata of a document is copied to its chunks,
+// and some splitters add their own metadata to the chunks, like the header path of markdown.
+type Splitter interface {
+	textsplitter.TextSplitter
+	SplitDocuments(docs []schema.Document) ([]schema.Document, error)
+}
+
+// Options are the options to create a splitter
+type Options struct {
+	Type         v1alpha1.SplitterType
+	ChunkSize    int
+	ChunkOverlap int
+	// Embedder is only used by the semantic splitter
+	Embedder embeddings.Embedder
+}
+
+// New creates the splitter of the type in options, the recursive character splitter is used if the type is empty
+func New(ctx context.Context, options Options) (Splitter, error) {
+	recursive := textsplitter.NewRecursiveCharacter(
+		textsplitter.WithChunkSize(options.ChunkSize),
+		textsplitter.WithChunkOverlap(options.ChunkOverlap),
+	)
+	switch options.Type {
+	case v1alpha1.SplitterTypeRecursiveCharacter, "":
+		return &simple{TextSplitter: recursive}, nil
+	case v1alpha1.SplitterTypeToken:
+		return &simple{TextSplitter: textsplitter.NewTokenSplitter(
+			textsplitter.WithChunkSize(options.ChunkSize),
+			textsplitter.WithChunkOverlap(options.ChunkOverlap),
+		)}, nil
+	case v1alpha1.SplitterTypeMarkdown:
+		return &Markdown{splitter: recursive}, nil
+	case v1alpha1.SplitterTypePage:
+		return &simple{TextSplitter: &page{chunkSize: options.ChunkSize, splitter: recursive}}, nil
+	case v1alpha1.SplitterTypeSentence:
+		return &simple{TextSplitter: &Sentence{
+			ChunkSize:    options.ChunkSize,
+			ChunkOverlap: options.ChunkOverlap,
+			splitter:     recursive,
+		}}, nil
+	case v1alpha1.SplitterTypeSemantic:
+		if options.Embedder == nil {
+			return nil, ErrNoEmbedder
+		}
+		return &simple{TextSplitter: &Semantic{
+			ctx:       ctx,
+			embedder:  options.Embedder,
+			ChunkSize: options.ChunkSize,
+			sentence:  &Sentence{ChunkSize: options.ChunkSize, splitter: recursive},
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unknown splitter type %s", options.Type)
+	}
+}
+
+// simple is a splitter which only splits the text and keeps the metadata as is
+type simple struct {
+	textsplitter.TextSplitter
+}
+
+func (s *simple) SplitDocuments(docs []schema.Document) ([]schema.Document, error) {
+	return textsplitter.SplitDocuments(s.TextSplitter, docs)
+}
+
+// page keeps the whole text as a chunk, the pdf loader loads each page as a document,
+// so each page is a chunk unless it is longer than the chunk size
+type page struct {
+	chunkSize int
+	splitter  textsplitter.TextSplitter
+}
+
+func (p *page) SplitText(text string) ([]string, error) {
+	if utf8.RuneCountInString(text) <= p.chunkSize {
+		return []string{text}, nil
+	}
+	return p.splitter.SplitText(text)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package splitter
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/schema"
+
+	"github.com/kubeagi/arcadia/api/base/v1alpha1"
+	"github.com/kubeagi/arcadia/pkg/documentloaders"
+)
+
+func TestMarkdownSplitter(t *testing.T) {
+	t.Parallel()
+	s, err := New(context.Background(), Options{Type: v1alpha1.SplitterTypeMarkdown, ChunkSize: 100, ChunkOverlap: 0})
+	require.NoError(t, err)
+	text := "# Handbook\nintro\n## Leave\nask the leader first\n```\n# not a header\n```\n### Sick leave\nshow the proof\n## Salary\npaid monthly\n"
+	docs, err := s.SplitDocuments([]schema.Document{{PageContent: text, Metadata: map[string]any{documentloaders.FileNameCol: "handbook.md"}}})
+	require.NoError(t, err)
+	headings := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		headings = append(headings, doc.Metadata[documentloaders.HeadingCol].(string))
+		assert.Equal(t, "handbook.md", doc.Metadata[documentloaders.FileNameCol])
+	}
+	assert.Equal(t, []string{"Handbook", "Handbook > Leave", "Handbook > Leave > Sick leave", "Handbook > Salary"}, headings)
+	assert.Contains(t, docs[1].PageContent, "# not a header")
+}
+
+func TestSentenceSplitter(t *testing.T) {
+	t.Parallel()
+	s, err := New(context.Background(), Options{Type: v1alpha1.SplitterTypeSentence, ChunkSize: 30, ChunkOverlap: 15})
+	require.NoError(t, err)
+	chunks, err := s.SplitText("The price is 3.5 dollars. It is cheap! 旷工按天计算。请假需审批。")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"The price is 3.5 dollars.", "It is cheap! 旷工按天计算。请假需审批。"}, chunks)
+}
+
+type fakeEmbedder struct{}
+
+func (fakeEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	res := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		if strings.Contains(text, "cat") {
+			res = append(res, []float32{1, 0})
+		} else {
+			res = append(res, []float32{0, 1})
+		}
+	}
+	return res, nil
+}
+
+func (fakeEmbedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	return []float32{1, 0}, nil
+}
+
+func TestSemanticSplitter(t *testing.T) {
+	t.Parallel()
+	_, err := New(context.Background(), Options{Type: v1alpha1.SplitterTypeSemantic, ChunkSize: 100})
+	require.ErrorIs(t, err, ErrNoEmbedder)
+
+	s, err := New(context.Background(), Options{Type: v1alpha1.SplitterTypeSemantic, ChunkSize: 100, Embedder: fakeEmbedder{}})
+	require.NoError(t, err)
+	chunks, err := s.SplitText("A cat sleeps. The cat eats. Stocks went up. Bonds went down.")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A cat sleeps. The cat eats.", "Stocks went up. Bonds went down."}, chunks)
+}
@@ -47,6 +47,11 @@ func New(tool *v1alpha1.Tool) (*Tool, error) {
 	return &Tool{client: client}, err
 }
 
+// NewWithOptions creates a new bing search tool with the client options
+func NewWithOptions(opts ...Option) *Tool {
+	return &Tool{client: NewBingClient(opts...)}
+}
+
 func NewFromToolSpec(tool *v1alpha1.Tool) (client *BingClient, err error) {
 	var countVal int
 	apikey := tool.Params[ParamAPIKey]
@@ -0,0 +1,231 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package openapi wraps the REST endpoints described by an OpenAPI v3 document as agent tools.
+// Only the parts of the document needed to call an endpoint are parsed:
+// servers, paths, operations, parameters and whether an operation has a json request body.
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const ToolName = "openapi"
+
+var (
+	ErrNoServer     = errors.New("no server url in the openapi document")
+	ErrNoOperations = errors.New("no operations found in the openapi document")
+)
+
+// Document is the subset of an OpenAPI v3 document used by the tool
+type Document struct {
+	OpenAPI string              `json:"openapi"`
+	Info    Info                `json:"info"`
+	Servers []Server            `json:"servers"`
+	Paths   map[string]PathItem `json:"paths"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type PathItem struct {
+	Parameters []Parameter `json:"parameters"`
+	Get        *Operation  `json:"get"`
+	Put        *Operation  `json:"put"`
+	Post       *Operation  `json:"post"`
+	Delete     *Operation  `json:"delete"`
+	Patch      *Operation  `json:"patch"`
+}
+
+type Operation struct {
+	OperationID string       `json:"operationId"`
+	Summary     string       `json:"summary"`
+	Description string       `json:"description"`
+	Parameters  []Parameter  `json:"parameters"`
+	RequestBody *RequestBody `json:"requestBody"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description"`
+	Required    bool                 `json:"required"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description"`
+	Properties  map[string]*Schema `json:"properties"`
+	Required    []string           `json:"required"`
+	Items       *Schema            `json:"items"`
+}
+
+// toJSONSchema converts the schema to a map which can be sent to the llm as function parameters
+func (s *Schema) toJSONSchema() map[string]any {
+	res := map[string]any{"type": paramType(s)}
+	if s.Description != "" {
+		res["description"] = s.Description
+	}
+	if len(s.Properties) > 0 {
+		properties := make(map[string]any, len(s.Properties))
+		for name, p := range s.Properties {
+			if p != nil {
+				properties[name] = p.toJSONSchema()
+			}
+		}
+		res["properties"] = properties
+	}
+	if len(s.Required) > 0 {
+		res["required"] = s.Required
+	}
+	if s.Items != nil {
+		res["items"] = s.Items.toJSONSchema()
+	}
+	return res
+}
+
+// Endpoint is one operation in the document, which is exposed as one tool
+type Endpoint struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Description string
+	Parameters  []Parameter
+	RequestBody *RequestBody
+}
+
+// ParseDocument parses an OpenAPI v3 document in json or yaml
+func ParseDocument(data []byte) (*Document, error) {
+	doc := &Document{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse openapi document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported openapi version %q, only 3.x is supported", doc.OpenAPI)
+	}
+	return doc, nil
+}
+
+// ServerURL returns the first server url in the document
+func (d *Document) ServerURL() string {
+	for _, s := range d.Servers {
+		if s.URL != "" {
+			return s.URL
+		}
+	}
+	return ""
+}
+
+// Endpoints returns the operations in the document, sorted by path and method.
+// If operationIDs is not empty, only the operations with these ids are returned,
+// and an error is returned if one of them is not found.
+func (d *Document) Endpoints(operationIDs ...string) ([]Endpoint, error) {
+	wanted := make(map[string]bool, len(operationIDs))
+	for _, id := range operationIDs {
+		wanted[id] = false
+	}
+	res := make([]Endpoint, 0)
+	for path, item := range d.Paths {
+		for method, op := range map[string]*Operation{
+			http.MethodGet:    item.Get,
+			http.MethodPut:    item.Put,
+			http.MethodPost:   item.Post,
+			http.MethodDelete: item.Delete,
+			http.MethodPatch:  item.Patch,
+		} {
+			if op == nil {
+				continue
+			}
+			if op.OperationID == "" {
+				return nil, fmt.Errorf("operation %s %s has no operationId", method, path)
+			}
+			if len(wanted) > 0 {
+				if _, ok := wanted[op.OperationID]; !ok {
+					continue
+				}
+				wanted[op.OperationID] = true
+			}
+			res = append(res, Endpoint{
+				Method:      method,
+				Path:        path,
+				OperationID: op.OperationID,
+				Summary:     op.Summary,
+				Description: op.Description,
+				Parameters:  mergeParameters(item.Parameters, op.Parameters),
+				RequestBody: op.RequestBody,
+			})
+		}
+	}
+	for id, found := range wanted {
+		if !found {
+			return nil, fmt.Errorf("operation %s not found in the openapi document", id)
+		}
+	}
+	if len(res) == 0 {
+		return nil, ErrNoOperations
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Path != res[j].Path {
+			return res[i].Path < res[j].Path
+		}
+		return res[i].Method < res[j].Method
+	})
+	return res, nil
+}
+
+// mergeParameters merges the path level parameters with the operation level ones,
+// the operation level one wins if they have the same name and location
+func mergeParameters(pathLevel, operationLevel []Parameter) []Parameter {
+	res := make([]Parameter, 0, len(pathLevel)+len(operationLevel))
+	res = append(res, operationLevel...)
+	for _, p := range pathLevel {
+		overridden := false
+		for _, o := range operationLevel {
+			if o.Name == p.Name && o.In == p.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			res = append(res, p)
+		}
+	}
+	return res
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const petstore = `
+openapi: "3.0.0"
+info:
+  title: Petstore
+servers:
+- url: http://petstore.swagger.io/v1
+paths:
+  /pets/{petId}:
+    parameters:
+    - name: petId
+      in: path
+      required: true
+      schema:
+        type: string
+    get:
+      operationId: showPetById
+      summary: Info for a specific pet
+      parameters:
+      - name: verbose
+        in: query
+        schema:
+          type: boolean
+    put:
+      operationId: updatePet
+      summary: Update a pet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+`
+
+func TestOpenAPITool(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"method": r.Method,
+			"path":   r.URL.Path,
+			"query":  r.URL.RawQuery,
+			"body":   string(body),
+		})
+	}))
+	defer server.Close()
+
+	doc, err := ParseDocument([]byte(petstore))
+	require.NoError(t, err)
+	require.Equal(t, "http://petstore.swagger.io/v1", doc.ServerURL())
+
+	tools, err := New(doc, server.URL, nil, WithHeader("Authorization", "Bearer token"))
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+	require.Equal(t, "showPetById", tools[0].Name())
+	require.Equal(t, "updatePet", tools[1].Name())
+
+	resp, err := tools[0].Call(context.Background(), `{"petId": "cat 1", "verbose": true}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"method":"GET","path":"/pets/cat 1","query":"verbose=true","body":""}`, resp)
+
+	resp, err = tools[1].Call(context.Background(), `{"petId": "1", "body": {"name": "tom"}}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"method":"PUT","path":"/pets/1","query":"","body":"{\"name\":\"tom\"}"}`, resp)
+
+	_, err = tools[1].Call(context.Background(), `{"petId": "1"}`)
+	require.Error(t, err)
+
+	_, err = New(doc, "", []string{"deletePet"})
+	require.Error(t, err)
+}
@@ -0,0 +1,326 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/tools"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// bodyKeyInInput is the key of the request body in the json input of the tool
+	bodyKeyInInput = "body"
+	// maxResponseLength is the max length of the response returned to the llm
+	maxResponseLength = 4096
+	defaultTimeout    = 30 * time.Second
+)
+
+// Tool calls one endpoint described in an OpenAPI document
+type Tool struct {
+	endpoint         Endpoint
+	serverURL        string
+	headers          map[string]string
+	client           *http.Client
+	CallbacksHandler callbacks.Handler
+}
+
+var _ tools.Tool = &Tool{}
+
+type options struct {
+	headers map[string]string
+	timeout time.Duration
+}
+
+type Option func(*options)
+
+// WithHeader adds a header to every request, like the auth header
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		o.headers[key] = value
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		if timeout > 0 {
+			o.timeout = timeout
+		}
+	}
+}
+
+// New creates one tool for each endpoint in the document.
+// serverURL overrides the server url in the document if it is not empty.
+func New(doc *Document, serverURL string, operationIDs []string, opts ...Option) ([]*Tool, error) {
+	o := &options{headers: make(map[string]string), timeout: defaultTimeout}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if serverURL == "" {
+		serverURL = doc.ServerURL()
+	}
+	if serverURL == "" {
+		return nil, ErrNoServer
+	}
+	endpoints, err := doc.Endpoints(operationIDs...)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: o.timeout}
+	res := make([]*Tool, 0, len(endpoints))
+	for _, e := range endpoints {
+		res = append(res, &Tool{
+			endpoint:  e,
+			serverURL: strings.TrimSuffix(serverURL, "/"),
+			headers:   o.headers,
+			client:    client,
+		})
+	}
+	return res, nil
+}
+
+func (t *Tool) Name() string {
+	return t.endpoint.OperationID
+}
+
+func (t *Tool) Description() string {
+	var b strings.Builder
+	desc := t.endpoint.Summary
+	if t.endpoint.Description != "" {
+		if desc != "" {
+			desc += ". "
+		}
+		desc += t.endpoint.Description
+	}
+	if desc == "" {
+		desc = fmt.Sprintf("Call %s %s", t.endpoint.Method, t.endpoint.Path)
+	}
+	b.WriteString(desc)
+	if len(t.endpoint.Parameters) == 0 && t.endpoint.RequestBody == nil {
+		b.WriteString(" The input is ignored.")
+		return b.String()
+	}
+	b.WriteString(" The input should be a json object with the fields:")
+	for _, p := range t.endpoint.Parameters {
+		b.WriteString(fmt.Sprintf(" %s (%s", p.Name, paramType(p.Schema)))
+		if p.Required {
+			b.WriteString(", required")
+		}
+		b.WriteString(")")
+		if p.Description != "" {
+			b.WriteString(": " + p.Description)
+		}
+		b.WriteString(";")
+	}
+	if t.endpoint.RequestBody != nil {
+		b.WriteString(fmt.Sprintf(" %s (object", bodyKeyInInput))
+		if t.endpoint.RequestBody.Required {
+			b.WriteString(", required")
+		}
+		b.WriteString(")")
+		if schema := jsonBodySchema(t.endpoint.RequestBody); schema != nil && len(schema.Properties) > 0 {
+			fields := make([]string, 0, len(schema.Properties))
+			for name, s := range schema.Properties {
+				fields = append(fields, fmt.Sprintf("%s (%s)", name, paramType(s)))
+			}
+			sort.Strings(fields)
+			b.WriteString(" with the fields " + strings.Join(fields, ", "))
+		}
+		b.WriteString(";")
+	}
+	return b.String()
+}
+
+// Parameters returns the json schema of the input, which is built from the parameters and the request body of the endpoint
+func (t *Tool) Parameters() map[string]any {
+	properties := make(map[string]any)
+	required := make([]string, 0)
+	for _, p := range t.endpoint.Parameters {
+		properties[p.Name] = map[string]any{"type": paramType(p.Schema), "description": p.Description}
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	if t.endpoint.RequestBody != nil {
+		body := map[string]any{"type": "object", "description": t.endpoint.RequestBody.Description}
+		if schema := jsonBodySchema(t.endpoint.RequestBody); schema != nil {
+			body = schema.toJSONSchema()
+		}
+		properties[bodyKeyInInput] = body
+		if t.endpoint.RequestBody.Required {
+			required = append(required, bodyKeyInInput)
+		}
+	}
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+func (t *Tool) Call(ctx context.Context, input string) (string, error) {
+	klog.FromContext(ctx).V(3).Info(fmt.Sprintf("running tool %s", t.Name()))
+	if t.CallbacksHandler != nil {
+		t.CallbacksHandler.HandleToolStart(ctx, input)
+	}
+	result, err := t.call(ctx, input)
+	if err != nil {
+		if t.CallbacksHandler != nil {
+			t.CallbacksHandler.HandleToolError(ctx, err)
+		}
+		return "", err
+	}
+	if t.CallbacksHandler != nil {
+		t.CallbacksHandler.HandleToolEnd(ctx, result)
+	}
+	return result, nil
+}
+
+func (t *Tool) call(ctx context.Context, input string) (string, error) {
+	args, err := t.parseInput(input)
+	if err != nil {
+		return "", err
+	}
+	path := t.endpoint.Path
+	query := url.Values{}
+	headers := make(map[string]string)
+	for _, p := range t.endpoint.Parameters {
+		v, ok := args[p.Name]
+		if !ok || v == nil {
+			if p.Required {
+				return "", fmt.Errorf("param %s is required", p.Name)
+			}
+			continue
+		}
+		value := formatValue(v)
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(value))
+		case "query":
+			query.Set(p.Name, value)
+		case "header":
+			headers[p.Name] = value
+		}
+	}
+	reqURL := t.serverURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	var body io.Reader
+	if t.endpoint.RequestBody != nil {
+		if v, ok := args[bodyKeyInInput]; ok && v != nil {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+			body = bytes.NewReader(data)
+		} else if t.endpoint.RequestBody.Required {
+			return "", fmt.Errorf("%s is required", bodyKeyInInput)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, t.endpoint.Method, reqURL, body)
+	if err != nil {
+		return "", err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseLength))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("%s %s returns %d: %s", t.endpoint.Method, path, resp.StatusCode, string(data))
+	}
+	return string(data), nil
+}
+
+// parseInput parses the json input from the llm.
+// If the input is not a json object and the endpoint has only one param, the whole input is used as its value.
+func (t *Tool) parseInput(input string) (map[string]any, error) {
+	args := make(map[string]any)
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return args, nil
+	}
+	if err := json.Unmarshal([]byte(input), &args); err == nil {
+		return args, nil
+	}
+	if len(t.endpoint.Parameters) == 1 && t.endpoint.RequestBody == nil {
+		args[t.endpoint.Parameters[0].Name] = strings.Trim(input, "\"'")
+		return args, nil
+	}
+	return nil, fmt.Errorf("the input of tool %s should be a json object, but got: %s", t.Name(), input)
+}
+
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []any:
+		s := make([]string, 0, len(val))
+		for _, item := range val {
+			s = append(s, formatValue(item))
+		}
+		return strings.Join(s, ",")
+	default:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	}
+}
+
+func paramType(s *Schema) string {
+	if s == nil || s.Type == "" {
+		return "string"
+	}
+	return s.Type
+}
+
+func jsonBodySchema(body *RequestBody) *Schema {
+	for contentType, media := range body.Content {
+		if strings.Contains(contentType, "json") {
+			return media.Schema
+		}
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlquery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/tools"
+	"k8s.io/klog/v2"
+
+	"github.com/kubeagi/arcadia/pkg/datasource"
+)
+
+const (
+	ToolName = "sql_query"
+
+	defaultTimeout = 10 * time.Second
+	defaultMaxRows = 100
+	// maxCellLength is the max length of a value in the result table, longer values are truncated
+	maxCellLength = 200
+)
+
+var (
+	ErrNoTables    = errors.New("no table can be queried")
+	ErrNotReadOnly = errors.New("only one SELECT statement is allowed")
+	ErrEmptyQuery  = errors.New("empty query")
+	// writeKeywords finds the data-modifying statements in WITH, which are rejected by the read-only transaction anyway
+	writeKeywords   = regexp.MustCompile(`(?i)\b(insert|update|delete|merge)\b`)
+	leadingKeywords = regexp.MustCompile(`(?i)^\(*\s*(select|with)\b`)
+	codeFence       = regexp.MustCompile("(?s)^```[a-zA-Z]*\\s*(.*?)\\s*```$")
+)
+
+// DB runs the read-only queries, it is implemented by *datasource.PostgreSQL
+type DB interface {
+	QueryReadOnly(ctx context.Context, query string, options datasource.ReadOnlyQueryOptions) (*datasource.TableRows, error)
+}
+
+// Tool answers questions about the data in PostgreSQL by running the SELECT statements written by the llm.
+// Only the allowed tables and a few functions can be used, and the statements run in a read-only transaction
+// with a timeout. These checks are not a security boundary, the datasource must use a read-only role which can
+// only read the allowed tables.
+type Tool struct {
+	db      DB
+	tables  []datasource.TableSchema
+	allowed map[string]bool
+	options datasource.ReadOnlyQueryOptions
+
+	CallbacksHandler callbacks.Handler
+	// ActionHandler gets the executed sql by HandleText, agents stream it with the tool actions
+	ActionHandler callbacks.Handler
+}
+
+var _ tools.Tool = &Tool{}
+
+type Option func(*Tool)
+
+// WithTimeout sets the statement timeout of a query
+func WithTimeout(timeout time.Duration) Option {
+	return func(t *Tool) {
+		if timeout > 0 {
+			t.options.Timeout = timeout
+		}
+	}
+}
+
+// WithMaxRows sets the max number of rows returned to the llm
+func WithMaxRows(maxRows int) Option {
+	return func(t *Tool) {
+		if maxRows > 0 {
+			t.options.MaxRows = maxRows
+		}
+	}
+}
+
+// New creates the tool which can read the tables, the tables are described in the tool description for the llm
+func New(db DB, tables []datasource.TableSchema, opts ...Option) (*Tool, error) {
+	if len(tables) == 0 {
+		return nil, ErrNoTables
+	}
+	t := &Tool{
+		db:      db,
+		tables:  tables,
+		allowed: make(map[string]bool, len(tables)),
+		options: datasource.ReadOnlyQueryOptions{Timeout: defaultTimeout, MaxRows: defaultMaxRows},
+	}
+	seen := make(map[string]bool)
+	for _, table := range tables {
+		t.allowed[table.FullName()] = true
+		if !seen[table.Schema] {
+			seen[table.Schema] = true
+			t.options.SearchPath = append(t.options.SearchPath, table.Schema)
+		}
+	}
+	t.options.AllowedTables = t.allowed
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t, nil
+}
+
+func (t *Tool) Name() string {
+	return ToolName
+}
+
+func (t *Tool) Description() string {
+	var b strings.Builder
+	b.WriteString("Query the data in a PostgreSQL database to answer questions about it. ")
+	b.WriteString(fmt.Sprintf("The input should be one PostgreSQL SELECT statement, at most %d rows are returned as a markdown table. ", t.options.MaxRows))
+	b.WriteString("Only the tables below can be queried:")
+	for _, table := range t.tables {
+		columns := make([]string, 0, len(table.Columns))
+		for _, c := range table.Columns {
+			columns = append(columns, c.Name+" "+c.Type)
+		}
+		b.WriteString(fmt.Sprintf("\n%s(%s)", table.FullName(), strings.Join(columns, ", ")))
+	}
+	return b.String()
+}
+
+// Parameters returns the json schema of the input for function calling agents
+func (t *Tool) Parameters() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"query": map[string]any{"type": "string", "description": "one PostgreSQL SELECT statement"},
+		},
+		"required": []string{"query"},
+	}
+}
+
+// SetActionHandler sets the handler which gets the executed sql
+func (t *Tool) SetActionHandler(handler callbacks.Handler) {
+	t.ActionHandler = handler
+}
+
+func (t *Tool) Call(ctx context.Context, input string) (string, error) {
+	klog.FromContext(ctx).V(3).Info(fmt.Sprintf("running tool %s", t.Name()))
+	if t.CallbacksHandler != nil {
+		t.CallbacksHandler.HandleToolStart(ctx, input)
+	}
+	result, err := t.call(ctx, input)
+	if err != nil {
+		if t.CallbacksHandler != nil {
+			t.CallbacksHandler.HandleToolError(ctx, err)
+		}
+		// the error goes back to the llm, so it can fix the query
+		return fmt.Sprintf("query failed: %s", err), nil
+	}
+	if t.CallbacksHandler != nil {
+		t.CallbacksHandler.HandleToolEnd(ctx, result)
+	}
+	return result, nil
+}
+
+func (t *Tool) call(ctx context.Context, input string) (string, error) {
+	query, err := ParseQuery(input)
+	if err != nil {
+		return "", err
+	}
+	if t.CallbacksHandler != nil {
+		t.CallbacksHandler.HandleText(ctx, "Executing SQL: "+query)
+	}
+	if t.ActionHandler != nil {
+		t.ActionHandler.HandleText(ctx, "Executing SQL: "+query)
+	}
+	if t.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		// a bit longer than the statement timeout, so the error from the database is returned
+		ctx, cancel = context.WithTimeout(ctx, t.options.Timeout+time.Second)
+		defer cancel()
+	}
+	rows, err := t.db.QueryReadOnly(ctx, query, t.options)
+	if err != nil {
+		return "", err
+	}
+	return MarkdownTable(rows), nil
+}
+
+// ParseQuery gets the sql from the input, which is the sql itself or a json object with the query field,
+// and checks it is one SELECT statement.
+func ParseQuery(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	var args struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal([]byte(input), &args); err == nil {
+		input = strings.TrimSpace(args.Query)
+	}
+	if m := codeFence.FindStringSubmatch(input); m != nil {
+		input = m[1]
+	}
+	query := strings.TrimSpace(strings.TrimSuffix(input, ";"))
+	if query == "" {
+		return "", ErrEmptyQuery
+	}
+	code := stripLiteralsAndComments(query)
+	if strings.Contains(code, ";") || !leadingKeywords.MatchString(strings.TrimSpace(code)) || writeKeywords.MatchString(code) {
+		return "", ErrNotReadOnly
+	}
+	return query, nil
+}
+
+// stripLiteralsAndComments replaces the string literals, quoted identifiers and comments with spaces,
+// so the keywords in them are not checked
+func stripLiteralsAndComments(query string) string {
+	var b strings.Builder
+	r := []rune(query)
+	for i := 0; i < len(r); i++ {
+		switch {
+		case r[i] == '\'' || r[i] == '"':
+			quote := r[i]
+			for i++; i < len(r); i++ {
+				if r[i] == quote {
+					if i+1 < len(r) && r[i+1] == quote {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			b.WriteRune(' ')
+		case r[i] == '-' && i+1 < len(r) && r[i+1] == '-':
+			for i < len(r) && r[i] != '\n' {
+				i++
+			}
+			b.WriteRune(' ')
+		case r[i] == '/' && i+1 < len(r) && r[i+1] == '*':
+			for i += 2; i+1 < len(r) && (r[i] != '*' || r[i+1] != '/'); i++ {
+				continue
+			}
+			// skip the ending slash
+			i++
+			b.WriteRune(' ')
+		default:
+			b.WriteRune(r[i])
+		}
+	}
+	return b.String()
+}
+
+// MarkdownTable formats the rows as a markdown table
+func MarkdownTable(rows *datasource.TableRows) string {
+	if len(rows.Rows) == 0 {
+		return "The query returns no rows."
+	}
+	var b strings.Builder
+	writeRow := func(values []string) {
+		b.WriteString("|")
+		for _, v := range values {
+			b.WriteString(" " + markdownCell(v) + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(rows.Columns)
+	b.WriteString("|" + strings.Repeat(" --- |", len(rows.Columns)) + "\n")
+	for _, row := range rows.Rows {
+		writeRow(row)
+	}
+	if rows.Truncated {
+		b.WriteString(fmt.Sprintf("\nOnly the first %d rows are shown.", len(rows.Rows)))
+	}
+	return b.String()
+}
+
+func markdownCell(v string) string {
+	if r := []rune(v); len(r) > maxCellLength {
+		v = string(r[:maxCellLength]) + "..."
+	}
+	v = strings.ReplaceAll(v, "|", "\\|")
+	return strings.Join(strings.Fields(v), " ")
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlquery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/callbacks"
+
+	"github.com/kubeagi/arcadia/pkg/datasource"
+)
+
+func TestParseQuery(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		input string
+		query string
+		err   error
+	}{
+		{input: "SELECT * FROM orders;", query: "SELECT * FROM orders"},
+		{input: `{"query": "select count(*) from orders"}`, query: "select count(*) from orders"},
+		{input: "```sql\nWITH t AS (SELECT 1) SELECT * FROM t\n```", query: "WITH t AS (SELECT 1) SELECT * FROM t"},
+		{input: "SELECT 'a;b', \"delete\" FROM t -- update\n", query: "SELECT 'a;b', \"delete\" FROM t -- update"},
+		{input: "SELECT /* ; */ last_update FROM t", query: "SELECT /* ; */ last_update FROM t"},
+		{input: "SELECT 1; DROP TABLE orders", err: ErrNotReadOnly},
+		{input: "DELETE FROM orders", err: ErrNotReadOnly},
+		{input: "WITH d AS (DELETE FROM orders RETURNING *) SELECT * FROM d", err: ErrNotReadOnly},
+		{input: " ; ", err: ErrEmptyQuery},
+	}
+	for _, c := range cases {
+		query, err := ParseQuery(c.input)
+		require.ErrorIs(t, err, c.err, c.input)
+		require.Equal(t, c.query, query, c.input)
+	}
+}
+
+func TestMarkdownTable(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "The query returns no rows.", MarkdownTable(&datasource.TableRows{Columns: []string{"id"}}))
+	rows := &datasource.TableRows{
+		Columns:   []string{"id", "note"},
+		Rows:      [][]string{{"1", "a|b"}, {"2", "line\nbreak"}},
+		Truncated: true,
+	}
+	require.Equal(t, "| id | note |\n| --- | --- |\n| 1 | a\\|b |\n| 2 | line break |\n\nOnly the first 2 rows are shown.", MarkdownTable(rows))
+}
+
+type fakeDB struct {
+	query   string
+	options datasource.ReadOnlyQueryOptions
+}
+
+func (f *fakeDB) QueryReadOnly(_ context.Context, query string, options datasource.ReadOnlyQueryOptions) (*datasource.TableRows, error) {
+	f.query, f.options = query, options
+	return &datasource.TableRows{Columns: []string{"count"}, Rows: [][]string{{"3"}}}, nil
+}
+
+type textHandler struct {
+	callbacks.SimpleHandler
+	texts []string
+}
+
+func (h *textHandler) HandleText(_ context.Context, text string) {
+	h.texts = append(h.texts, text)
+}
+
+func TestToolCall(t *testing.T) {
+	t.Parallel()
+	db := &fakeDB{}
+	tables := []datasource.TableSchema{
+		{Schema: "sales", Name: "orders", Columns: []datasource.ColumnSchema{{Name: "id", Type: "integer"}, {Name: "amount", Type: "numeric"}}},
+		{Schema: "public", Name: "users", Columns: []datasource.ColumnSchema{{Name: "name", Type: "text"}}},
+	}
+	tool, err := New(db, tables, WithMaxRows(10))
+	require.NoError(t, err)
+	require.Contains(t, tool.Description(), "sales.orders(id integer, amount numeric)")
+	handler := &textHandler{}
+	tool.SetActionHandler(handler)
+
+	out, err := tool.Call(context.Background(), "SELECT count(*) FROM orders")
+	require.NoError(t, err)
+	require.Equal(t, "| count |\n| --- |\n| 3 |\n", out)
+	require.Equal(t, "SELECT count(*) FROM orders", db.query)
+	require.Equal(t, []string{"Executing SQL: SELECT count(*) FROM orders"}, handler.texts)
+	require.Equal(t, 10, db.options.MaxRows)
+	require.Equal(t, []string{"sales", "public"}, db.options.SearchPath)
+	require.Equal(t, map[string]bool{"sales.orders": true, "public.users": true}, db.options.AllowedTables)
+
+	// the error goes back to the llm
+	out, err = tool.Call(context.Background(), "DROP TABLE orders")
+	require.NoError(t, err)
+	require.Contains(t, out, ErrNotReadOnly.Error())
+
+	_, err = New(db, nil)
+	require.ErrorIs(t, err, ErrNoTables)
+}
@@ -46,6 +46,13 @@ func New(tool *v1alpha1.Tool) (*Tool, error) {
 	}, nil
 }
 
+// NewWithAPIKey creates a new weather tool with the api key
+func NewWithAPIKey(apiKey string) *Tool {
+	return &Tool{
+		client: internal.New(apiKey),
+	}
+}
+
 func (t Tool) Name() string {
 	return ToolName
 }
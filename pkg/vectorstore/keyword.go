@@ -0,0 +1,316 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectorstore
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	lanchaingoschema "github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores"
+	"k8s.io/klog/v2"
+)
+
+var (
+	ErrKeywordSearchNotSupported = errors.New("keyword search is only supported by pgvector")
+	ErrKeywordIndexTooLarge      = errors.New("the collection is too large for keyword search")
+)
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// KeywordIndex is an in-process BM25 index of the documents in a vectorstore collection.
+// Han characters are indexed as unigrams and bigrams since Chinese text has no spaces,
+// and codes like `PN-2023-001` are indexed both as a whole and by their parts.
+type KeywordIndex struct {
+	docs      []lanchaingoschema.Document
+	lengths   []int
+	postings  map[string][]posting
+	avgLength float64
+}
+
+type posting struct {
+	doc  int
+	freq int
+}
+
+// NewKeywordIndex creates the index of the documents
+func NewKeywordIndex(docs []lanchaingoschema.Document) *KeywordIndex {
+	idx := &KeywordIndex{
+		docs:     docs,
+		lengths:  make([]int, len(docs)),
+		postings: make(map[string][]posting),
+	}
+	total := 0
+	for i, doc := range docs {
+		tokens := tokenize(doc.PageContent)
+		idx.lengths[i] = len(tokens)
+		total += len(tokens)
+		freqs := make(map[string]int)
+		for _, t := range tokens {
+			freqs[t]++
+		}
+		for t, f := range freqs {
+			idx.postings[t] = append(idx.postings[t], posting{doc: i, freq: f})
+		}
+	}
+	if len(docs) > 0 {
+		idx.avgLength = float64(total) / float64(len(docs))
+	}
+	return idx
+}
+
+// Len returns the number of documents in the index
+func (idx *KeywordIndex) Len() int {
+	return len(idx.docs)
+}
+
+// Search returns at most num documents matching the query ordered by the BM25 score, the score is stored in Document.Score.
+// The returned documents have their own metadata maps, so they can be changed by callers.
+func (idx *KeywordIndex) Search(query string, num int) []lanchaingoschema.Document {
+	scores := make(map[int]float64)
+	seen := make(map[string]bool)
+	n := float64(len(idx.docs))
+	for _, t := range tokenize(query) {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		postings := idx.postings[t]
+		if len(postings) == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+		for _, p := range postings {
+			tf := float64(p.freq)
+			norm := 1 - bm25B + bm25B*float64(idx.lengths[p.doc])/idx.avgLength
+			scores[p.doc] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+	matched := make([]int, 0, len(scores))
+	for i := range scores {
+		matched = append(matched, i)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if scores[matched[i]] != scores[matched[j]] {
+			return scores[matched[i]] > scores[matched[j]]
+		}
+		return matched[i] < matched[j]
+	})
+	if len(matched) > num {
+		matched = matched[:num]
+	}
+	res := make([]lanchaingoschema.Document, 0, len(matched))
+	for _, i := range matched {
+		doc := idx.docs[i]
+		metadata := make(map[string]any, len(doc.Metadata)+1)
+		for k, v := range doc.Metadata {
+			metadata[k] = v
+		}
+		res = append(res, lanchaingoschema.Document{PageContent: doc.PageContent, Metadata: metadata, Score: float32(scores[i])})
+	}
+	return res
+}
+
+func isHan(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r)
+}
+
+// isConnector returns if the rune connects the parts of a code, like `-` in `PN-2023-001`
+func isConnector(r rune) bool {
+	return r == '-' || r == '_' || r == '.' || r == '/'
+}
+
+func tokenize(text string) []string {
+	tokens := make([]string, 0)
+	word := make([]rune, 0)
+	flushWord := func() {
+		w := strings.TrimFunc(string(word), isConnector)
+		word = word[:0]
+		if w == "" {
+			return
+		}
+		tokens = append(tokens, w)
+		if strings.IndexFunc(w, isConnector) >= 0 {
+			tokens = append(tokens, strings.FieldsFunc(w, isConnector)...)
+		}
+	}
+	prevHan := rune(-1)
+	for _, r := range strings.ToLower(text) {
+		if isHan(r) {
+			flushWord()
+			tokens = append(tokens, string(r))
+			if prevHan >= 0 {
+				tokens = append(tokens, string([]rune{prevHan, r}))
+			}
+			prevHan = r
+			continue
+		}
+		prevHan = -1
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, r)
+		case isConnector(r) && len(word) > 0:
+			word = append(word, r)
+		default:
+			flushWord()
+		}
+	}
+	flushWord()
+	return tokens
+}
+
+// maxKeywordIndexDocuments is the max number of documents in all the cached keyword indexes,
+// the least recently used indexes are evicted when there are more
+var maxKeywordIndexDocuments = 200000
+
+// keywordIndexes caches the keyword index of each collection with the version of the collection content.
+// The entries are ordered by the last use in lru, and documents is the number of documents in all the indexes.
+var keywordIndexes = struct {
+	sync.Mutex
+	entries   map[string]*keywordIndexEntry
+	lru       *list.List
+	documents int
+}{entries: make(map[string]*keywordIndexEntry), lru: list.New()}
+
+type keywordIndexEntry struct {
+	// loading is held by the request loading the index
+	loading sync.Mutex
+	key     string
+	element *list.Element
+	// version and index are protected by the lock of keywordIndexes
+	version string
+	index   *KeywordIndex
+}
+
+// GetKeywordIndex returns the keyword index of the collection in the vectorstore.
+// The index is loaded from the vectorstore and cached in process, and it is loaded again when the version changes,
+// so the version should change whenever documents are added to or removed from the collection. While the index of
+// the new version is loading, the other requests use the index of the previous version instead of waiting for it.
+// The index is kept in process instead of using the full-text search of postgres, whose parsers don't split Chinese words,
+// so the collections with more than maxKeywordIndexDocuments documents are not indexed.
+func GetKeywordIndex(ctx context.Context, s vectorstores.VectorStore, key, version string) (*KeywordIndex, error) {
+	store, ok := s.(*PGVectorStore)
+	if !ok {
+		return nil, ErrKeywordSearchNotSupported
+	}
+	entry, index, indexVersion := useKeywordIndexEntry(key)
+	if index != nil && indexVersion == version {
+		return index, nil
+	}
+
+	if !entry.loading.TryLock() {
+		if index != nil {
+			return index, nil
+		}
+		entry.loading.Lock()
+	}
+	defer entry.loading.Unlock()
+	keywordIndexes.Lock()
+	index, indexVersion = entry.index, entry.version
+	keywordIndexes.Unlock()
+	if index != nil && indexVersion == version {
+		return index, nil
+	}
+	docs, err := store.Documents(ctx, maxKeywordIndexDocuments+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) > maxKeywordIndexDocuments {
+		return nil, fmt.Errorf("%w: the collection has more than %d documents", ErrKeywordIndexTooLarge, maxKeywordIndexDocuments)
+	}
+	index = NewKeywordIndex(docs)
+	storeKeywordIndex(entry, index, version)
+	klog.FromContext(ctx).V(3).Info("keyword index loaded", "key", key, "version", version, "documents", len(docs))
+	return index, nil
+}
+
+// useKeywordIndexEntry returns the entry of the key with its index and version, the entry is created if not found
+func useKeywordIndexEntry(key string) (*keywordIndexEntry, *KeywordIndex, string) {
+	keywordIndexes.Lock()
+	defer keywordIndexes.Unlock()
+	entry, ok := keywordIndexes.entries[key]
+	if ok {
+		keywordIndexes.lru.MoveToFront(entry.element)
+	} else {
+		entry = &keywordIndexEntry{key: key}
+		entry.element = keywordIndexes.lru.PushFront(entry)
+		keywordIndexes.entries[key] = entry
+	}
+	return entry, entry.index, entry.version
+}
+
+// storeKeywordIndex sets the index of the entry, and evicts the least recently used entries if there are too many documents
+func storeKeywordIndex(entry *keywordIndexEntry, index *KeywordIndex, version string) {
+	keywordIndexes.Lock()
+	defer keywordIndexes.Unlock()
+	if keywordIndexes.entries[entry.key] != entry {
+		// evicted while loading
+		return
+	}
+	if entry.index != nil {
+		keywordIndexes.documents -= entry.index.Len()
+	}
+	entry.index, entry.version = index, version
+	keywordIndexes.documents += index.Len()
+	for keywordIndexes.documents > maxKeywordIndexDocuments {
+		oldest := keywordIndexes.lru.Back().Value.(*keywordIndexEntry)
+		if oldest == entry {
+			break
+		}
+		keywordIndexes.lru.Remove(oldest.element)
+		delete(keywordIndexes.entries, oldest.key)
+		if oldest.index != nil {
+			keywordIndexes.documents -= oldest.index.Len()
+		}
+	}
+}
+
+// Documents returns at most limit documents in the collection, or all the documents if limit is not positive
+func (s *PGVectorStore) Documents(ctx context.Context, limit int) ([]lanchaingoschema.Document, error) {
+	sql := fmt.Sprintf(`SELECT e.document, e.cmetadata FROM %s e JOIN %s c ON c.uuid = e.collection_id WHERE c.name = $1`,
+		s.PGVector.EmbeddingTableName, s.PGVector.CollectionTableName)
+	args := []any{s.PGVector.CollectionName}
+	if limit > 0 {
+		sql += " LIMIT $2"
+		args = append(args, limit)
+	}
+	rows, err := s.Conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	docs := make([]lanchaingoschema.Document, 0)
+	for rows.Next() {
+		doc := lanchaingoschema.Document{}
+		if err := rows.Scan(&doc.PageContent, &doc.Metadata); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 KubeAGI.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vectorstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lanchaingoschema "github.com/tmc/langchaingo/schema"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []string{"policy", "pn-2023-001", "pn", "2023", "001", "ends"}, tokenize("Policy PN-2023-001 ends."))
+	assert.Equal(t, []string{"旷", "工", "旷工", "a1"}, tokenize("旷工A1"))
+}
+
+func TestKeywordIndex(t *testing.T) {
+	t.Parallel()
+	idx := NewKeywordIndex([]lanchaingoschema.Document{
+		{PageContent: "The warranty of product X-100 is two years", Metadata: map[string]any{"file": "a"}},
+		{PageContent: "员工请假需要提前审批"},
+		{PageContent: "Product X-200 has no warranty, the product is cheap"},
+	})
+	require.Equal(t, 3, idx.Len())
+
+	docs := idx.Search("x-100 warranty", 5)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "The warranty of product X-100 is two years", docs[0].PageContent)
+	assert.Greater(t, docs[0].Score, docs[1].Score)
+	// the metadata of the result can be changed without changing the index
+	docs[0].Metadata["file"] = "b"
+	assert.Equal(t, "a", idx.Search("x-100", 1)[0].Metadata["file"])
+
+	docs = idx.Search("请假", 1)
+	require.Len(t, docs, 1)
+	assert.Equal(t, "员工请假需要提前审批", docs[0].PageContent)
+
+	assert.Empty(t, idx.Search("nothing", 5))
+}
+
+func TestKeywordIndexCache(t *testing.T) {
+	old := maxKeywordIndexDocuments
+	maxKeywordIndexDocuments = 5
+	defer func() { maxKeywordIndexDocuments = old }()
+	newIndex := func(n int) *KeywordIndex {
+		return NewKeywordIndex(make([]lanchaingoschema.Document, n))
+	}
+	load := func(key, version string, n int) *KeywordIndex {
+		entry, _, _ := useKeywordIndexEntry(key)
+		index := newIndex(n)
+		storeKeywordIndex(entry, index, version)
+		return index
+	}
+
+	a := load("test/a", "1", 2)
+	load("test/b", "1", 2)
+	_, index, version := useKeywordIndexEntry("test/a")
+	assert.Same(t, a, index)
+	assert.Equal(t, "1", version)
+	// b is the least recently used one
+	load("test/c", "1", 2)
+	_, index, _ = useKeywordIndexEntry("test/b")
+	assert.Nil(t, index)
+	_, index, _ = useKeywordIndexEntry("test/a")
+	assert.Same(t, a, index)
+
+	// the new version replaces the documents of the old one
+	a2 := load("test/a", "2", 3)
+	_, index, version = useKeywordIndexEntry("test/a")
+	assert.Same(t, a2, index)
+	assert.Equal(t, "2", version)
+	keywordIndexes.Lock()
+	assert.LessOrEqual(t, keywordIndexes.documents, maxKeywordIndexDocuments)
+	keywordIndexes.Unlock()
+}
@@ -179,3 +179,14 @@ func (s *PGVectorStore) RemoveExist(ctx context.Context, log logr.Logger, docume
 	}
 	return doc, nil
 }
+
+// RemoveByMetadata removes the documents whose metadata has the value of field, and one of the values of key
+func (s *PGVectorStore) RemoveByMetadata(ctx context.Context, field, value, key string, values []string) (int64, error) {
+	sql := fmt.Sprintf(`DELETE FROM %s e USING %s c WHERE c.uuid = e.collection_id AND c.name = $1 AND e.cmetadata->>$2 = $3 AND e.cmetadata->>$4 = ANY($5)`,
+		s.PGVector.EmbeddingTableName, s.PGVector.CollectionTableName)
+	tag, err := s.Conn.Exec(ctx, sql, s.PGVector.CollectionName, field, value, key, values)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
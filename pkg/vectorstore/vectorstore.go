@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	arcadiav1alpha1 "github.com/kubeagi/arcadia/api/base/v1alpha1"
+	pkgdocumentloaders "github.com/kubeagi/arcadia/pkg/documentloaders"
 )
 
 var (
@@ -110,31 +111,69 @@ func RemoveCollection(ctx context.Context, log logr.Logger, vs *arcadiav1alpha1.
 	return err
 }
 
+// RemoveTableRows removes the documents of the rows with the keys of the table from the collection,
+// so the updated rows can be added again. Only pgvector supports it.
+func RemoveTableRows(ctx context.Context, log logr.Logger, vs *arcadiav1alpha1.VectorStore, collectionName string, c client.Client, table string, keys []string) error {
+	if vs.Spec.Type() != arcadiav1alpha1.VectorStoreTypePGVector {
+		return ErrUnsupportedVectorStoreType
+	}
+	v, finish, err := NewPGVectorStore(ctx, vs, c, nil, collectionName)
+	if finish != nil {
+		defer finish()
+	}
+	if err != nil {
+		return err
+	}
+	removed, err := v.RemoveByMetadata(ctx, pkgdocumentloaders.FileNameCol, table, pkgdocumentloaders.RowKeyCol, keys)
+	if err != nil {
+		return err
+	}
+	log.V(3).Info("remove the documents of the updated rows", "table", table, "rows", len(keys), "documents", removed)
+	return nil
+}
+
 func AddDocuments(ctx context.Context, log logr.Logger, vs *arcadiav1alpha1.VectorStore, embedder embeddings.Embedder, collectionName string, c client.Client, documents []lanchaingoschema.Document) (err error) {
+	return AddDocumentsInBatches(ctx, log, vs, embedder, collectionName, c, func(add func(documents []lanchaingoschema.Document) error) error {
+		return add(documents)
+	})
+}
+
+// AddDocumentsInBatches creates the vectorstore once, and adds the documents passed to add by stream batch by batch,
+// so the documents of a large file don't need to be in memory at the same time.
+func AddDocumentsInBatches(ctx context.Context, log logr.Logger, vs *arcadiav1alpha1.VectorStore, embedder embeddings.Embedder, collectionName string, c client.Client, stream func(add func(documents []lanchaingoschema.Document) error) error) (err error) {
 	s, finish, err := NewVectorStore(ctx, vs, embedder, collectionName, c)
 	if err != nil {
 		return err
 	}
-	log.Info("handle file: add documents to embedder")
-	if store, ok := s.(*PGVectorStore); ok {
-		// now only pgvector support Row-level updates
-		log.V(3).Info("handle file: use pgvector, filter out exist documents...")
-		if documents, err = store.RemoveExist(ctx, log, documents); err != nil {
+	if finish != nil {
+		defer finish()
+	}
+	add := func(documents []lanchaingoschema.Document) (err error) {
+		log.Info("handle file: add documents to embedder", "documents", len(documents))
+		if store, ok := s.(*PGVectorStore); ok {
+			// now only pgvector support Row-level updates
+			log.V(3).Info("handle file: use pgvector, filter out exist documents...")
+			if documents, err = store.RemoveExist(ctx, log, documents); err != nil {
+				return err
+			}
+			log.V(3).Info("handle file: use pgvector, filter out exist documents done")
+		}
+		if len(documents) == 0 {
+			return nil
+		}
+		for i, doc := range documents {
+			log.V(5).Info(fmt.Sprintf("add doc to vectorstore, document[%d]: embedding:%s, metadata:%v", i, doc.PageContent, doc.Metadata))
+		}
+		log.V(3).Info("handle file: add documents, may take long time...")
+		if _, err = s.AddDocuments(ctx, documents); err != nil {
 			return err
 		}
-		log.V(3).Info("handle file: use pgvector, filter out exist documents done")
-	}
-	for i, doc := range documents {
-		log.V(5).Info(fmt.Sprintf("add doc to vectorstore, document[%d]: embedding:%s, metadata:%v", i, doc.PageContent, doc.Metadata))
+		log.V(3).Info("handle file: add documents done")
+		return nil
 	}
-	log.V(3).Info("handle file: add documents, may take long time...")
-	if _, err = s.AddDocuments(ctx, documents); err != nil {
+	if err = stream(add); err != nil {
 		return err
 	}
-	log.V(3).Info("handle file: add documents done")
-	if finish != nil {
-		finish()
-	}
 	log.V(3).Info("handle file succeeded")
 	return nil
 }